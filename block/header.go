@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package block
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/btp"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// Header is a block header decoded without database. It implements header
+// related methods of module.BlockData, so it can be used to verify votes of
+// the block. Other methods of module.BlockData shall not be used.
+type Header struct {
+	module.BlockData
+	format   V2HeaderFormat
+	proposer module.Address
+	id       []byte
+}
+
+// NewHeaderFromBytes decodes bytes returned by MarshalHeader of the block.
+func NewHeaderFromBytes(bs []byte) (*Header, error) {
+	v, r, err := PeekVersion(bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	if v != module.BlockVersion2 {
+		return nil, errors.UnsupportedError.Errorf("unsupported block version %d", v)
+	}
+	h := new(Header)
+	if err := v2Codec.Unmarshal(r, &h.format); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidHeader")
+	}
+	if h.proposer, err = newProposer(h.format.Proposer); err != nil {
+		return nil, err
+	}
+	h.id = crypto.SHA3Sum256(v2Codec.MustMarshalToBytes(&h.format))
+	return h, nil
+}
+
+func (h *Header) Version() int {
+	return h.format.Version
+}
+
+func (h *Header) ID() []byte {
+	return h.id
+}
+
+func (h *Header) Height() int64 {
+	return h.format.Height
+}
+
+func (h *Header) PrevID() []byte {
+	return h.format.PrevID
+}
+
+func (h *Header) VotesHash() []byte {
+	return h.format.VotesHash
+}
+
+func (h *Header) NextValidatorsHash() []byte {
+	return h.format.NextValidatorsHash
+}
+
+func (h *Header) Timestamp() int64 {
+	return h.format.Timestamp
+}
+
+func (h *Header) Proposer() module.Address {
+	return h.proposer
+}
+
+func (h *Header) LogsBloom() module.LogsBloom {
+	return txresult.NewLogsBloomFromCompressed(h.format.LogsBloom)
+}
+
+func (h *Header) Result() []byte {
+	return h.format.Result
+}
+
+func (h *Header) NetworkSectionFilter() module.BitSetFilter {
+	return module.BitSetFilterFromBytes(h.format.NSFilter, btp.NSFilterCap)
+}
+
+func (h *Header) Bytes() []byte {
+	return v2Codec.MustMarshalToBytes(&h.format)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestNewHeaderFromBytes(t *testing.T) {
+	proposer := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	hf := &V2HeaderFormat{
+		Version:            module.BlockVersion2,
+		Height:             10,
+		Timestamp:          1000,
+		Proposer:           proposer.Bytes(),
+		PrevID:             crypto.SHA3Sum256([]byte("prev")),
+		VotesHash:          crypto.SHA3Sum256([]byte("votes")),
+		NextValidatorsHash: crypto.SHA3Sum256([]byte("validators")),
+		Result:             []byte("result"),
+	}
+	bs := codec.BC.MustMarshalToBytes(hf)
+	h, err := NewHeaderFromBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.SHA3Sum256(bs), h.ID())
+	assert.EqualValues(t, 10, h.Height())
+	assert.EqualValues(t, 1000, h.Timestamp())
+	assert.True(t, proposer.Equal(h.Proposer()))
+	assert.Equal(t, hf.PrevID, h.PrevID())
+	assert.Equal(t, hf.VotesHash, h.VotesHash())
+	assert.Equal(t, hf.NextValidatorsHash, h.NextValidatorsHash())
+	assert.Equal(t, hf.Result, h.Result())
+	assert.Equal(t, bs, h.Bytes())
+
+	hf.Version = module.BlockVersion1
+	_, err = NewHeaderFromBytes(codec.BC.MustMarshalToBytes(hf))
+	assert.Error(t, err)
+
+	_, err = NewHeaderFromBytes([]byte{0x01})
+	assert.Error(t, err)
+}
@@ -27,7 +27,9 @@ const (
 )
 
 const (
-	keyLastBlockHeight = "block.lastHeight"
+	// KeyLastBlockHeight is the key of the last finalized block height in
+	// db.ChainProperty. It's written at the end of finalization.
+	KeyLastBlockHeight = "block.lastHeight"
 	genesisHeight      = 0
 	ConfigCacheCap     = 10
 )
@@ -615,7 +617,7 @@ func NewManager(
 	}
 
 	var height int64
-	err = chainPropBucket.Get(db.Raw(keyLastBlockHeight), &height)
+	err = chainPropBucket.Get(db.Raw(KeyLastBlockHeight), &height)
 	if errors.NotFoundError.Equals(err) || (err == nil && height == 0) {
 		if err := m.finalizeGenesis(); err != nil {
 			return nil, err
@@ -1026,7 +1028,7 @@ func (m *manager) finalize(bn *bnode, updatePCM bool) error {
 	if err != nil {
 		return err
 	}
-	if err = chainProp.Set(db.Raw(keyLastBlockHeight), block.Height()); err != nil {
+	if err = chainProp.Set(db.Raw(KeyLastBlockHeight), block.Height()); err != nil {
 		return err
 	}
 
@@ -1599,7 +1601,7 @@ func (m *manager) _export(blk module.Block, ctx *merkle.CopyContext, flag int) e
 		if err := ctx.Copy(db.BlockHeaderHashByHeight, hb); err != nil {
 			return err
 		}
-		if err := ctx.Set(db.ChainProperty, []byte(keyLastBlockHeight), hb); err != nil {
+		if err := ctx.Set(db.ChainProperty, []byte(KeyLastBlockHeight), hb); err != nil {
 			return err
 		}
 	}
@@ -1850,7 +1852,7 @@ func GetLastHeightWithCodec(dbase db.Database, c codec.Codec) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	bs, err := bk.Get([]byte(keyLastBlockHeight))
+	bs, err := bk.Get([]byte(KeyLastBlockHeight))
 	if err != nil || bs == nil {
 		return 0, err
 	}
@@ -1882,7 +1884,7 @@ func SetLastHeight(dbase db.Database, c codec.Codec, height int64) error {
 	if c == nil {
 		c = codec.BC
 	}
-	err = bk.Set([]byte(keyLastBlockHeight), c.MustMarshalToBytes(height))
+	err = bk.Set([]byte(KeyLastBlockHeight), c.MustMarshalToBytes(height))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"github.com/icon-project/goloop/common/crypto/bls12381"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	bls12381DSA = "bls/bls12381"
+
+	// bls12381RegistrationKeyLen is the length of the public key with its
+	// proof of possession.
+	bls12381RegistrationKeyLen = bls12381.PublicKeySize + bls12381.SignatureSize
+)
+
+// bls12381DSAModule handles public keys of BLS12-381. To prevent rogue key
+// attacks on aggregated signatures, a key shall be registered with its proof
+// of possession (public key || proof), and the canonical form is the
+// compressed public key.
+type bls12381DSAModule struct {
+}
+
+func (m bls12381DSAModule) Name() string {
+	return bls12381DSA
+}
+
+func (m bls12381DSAModule) Verify(pubKey []byte) error {
+	_, err := m.Canonicalize(pubKey)
+	return err
+}
+
+func (m bls12381DSAModule) Canonicalize(pubKey []byte) ([]byte, error) {
+	if len(pubKey) != bls12381RegistrationKeyLen {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidKeyLength(len=%d,exp=%d)", len(pubKey), bls12381RegistrationKeyLen)
+	}
+	pk, err := bls12381.PublicKeyFromBytes(pubKey[:bls12381.PublicKeySize])
+	if err != nil {
+		return nil, err
+	}
+	pop, err := bls12381.SignatureFromBytes(pubKey[bls12381.PublicKeySize:])
+	if err != nil {
+		return nil, err
+	}
+	if !pk.VerifyProofOfPossession(pop) {
+		return nil, errors.IllegalArgumentError.New("InvalidProofOfPossession")
+	}
+	return pk.Bytes(), nil
+}
+
+var bls12381DSAModuleInstance bls12381DSAModule
+
+func init() {
+	registerDSAModule(bls12381DSAModuleInstance)
+}
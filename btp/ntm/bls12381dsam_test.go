@@ -0,0 +1,55 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/crypto/bls12381"
+)
+
+func TestBLS12381DSAModule_Canonicalize(t *testing.T) {
+	assert := assert.New(t)
+
+	sk, err := bls12381.GenerateSecretKey()
+	assert.NoError(err)
+	pk := sk.PublicKey().Bytes()
+	pop := sk.ProofOfPossession().Bytes()
+	key := append(append([]byte{}, pk...), pop...)
+
+	dsam := DSAModuleForName(bls12381DSA)
+	assert.NoError(dsam.Verify(key))
+	cKey, err := dsam.Canonicalize(key)
+	assert.NoError(err)
+	assert.Equal(pk, cKey)
+
+	// proof of possession is required
+	assert.Error(dsam.Verify(pk))
+	assert.Error(dsam.Verify(key[:len(key)-1]))
+
+	// proof of possession of other key
+	sk2, err := bls12381.GenerateSecretKey()
+	assert.NoError(err)
+	key2 := append(append([]byte{}, pk...), sk2.ProofOfPossession().Bytes()...)
+	assert.Error(dsam.Verify(key2))
+
+	// signature instead of proof of possession
+	key3 := append(append([]byte{}, pk...), sk.Sign(pk).Bytes()...)
+	assert.Error(dsam.Verify(key3))
+}
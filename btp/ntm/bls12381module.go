@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/sha256"
+
+	"github.com/icon-project/goloop/common/crypto/bls12381"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+)
+
+// bls12381 network type uses SHA-256 for hashes and BLS12-381 signatures
+// aggregated into one signature for the validators.
+
+const (
+	bls12381UID = "bls12381"
+
+	bls12381BytesByHash = "b" + db.BytesByHash
+	bls12381ListByRoot  = "b" + db.ListByMerkleRootBase
+)
+
+var bls12381ModuleInstance *networkTypeModule
+
+type bls12381ModuleCore struct{}
+
+func (m *bls12381ModuleCore) UID() string {
+	return bls12381UID
+}
+
+func (m *bls12381ModuleCore) AppendHash(out []byte, data []byte) []byte {
+	h := sha256.Sum256(data)
+	return append(out, h[:]...)
+}
+
+func (m *bls12381ModuleCore) DSAModule() module.DSAModule {
+	return bls12381DSAModuleInstance
+}
+
+func (m *bls12381ModuleCore) NewProofContextFromBytes(bs []byte) (proofContextCore, error) {
+	return newBLS12381ProofContextFromBytes(bls12381ModuleInstance, bs)
+}
+
+func (m *bls12381ModuleCore) NewProofContext(keys [][]byte) (proofContextCore, error) {
+	return newBLS12381ProofContext(bls12381ModuleInstance, keys)
+}
+
+// AddressFromPubKey returns the compressed public key since validators are
+// identified by their keys in the proof context.
+func (m *bls12381ModuleCore) AddressFromPubKey(pubKey []byte) ([]byte, error) {
+	pk, err := bls12381.PublicKeyFromBytes(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return pk.Bytes(), nil
+}
+
+func (m *bls12381ModuleCore) BytesByHashBucket() db.BucketID {
+	return bls12381BytesByHash
+}
+
+func (m *bls12381ModuleCore) ListByMerkleRootBucket() db.BucketID {
+	return bls12381ListByRoot
+}
+
+func (m *bls12381ModuleCore) NewProofFromBytes(bs []byte) (module.BTPProof, error) {
+	return newBLS12381ProofFromBytes(bs)
+}
+
+func (m *bls12381ModuleCore) NetworkTypeKeyFromDSAKey(key []byte) ([]byte, error) {
+	return key, nil
+}
+
+func init() {
+	bls12381ModuleInstance = register(bls12381UID, &bls12381ModuleCore{})
+}
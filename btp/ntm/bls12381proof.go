@@ -0,0 +1,377 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/icon-project/goloop/common/cache"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto/bls12381"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// signerBitmap is a bitmap of validator indexes. Bit i is in byte i/8 and
+// the least significant bit comes first.
+type signerBitmap []byte
+
+func newSignerBitmap(n int) signerBitmap {
+	return make(signerBitmap, (n+7)/8)
+}
+
+func (bm signerBitmap) has(i int) bool {
+	return i >= 0 && i/8 < len(bm) && bm[i/8]&(1<<(i%8)) != 0
+}
+
+func (bm signerBitmap) set(i int) {
+	bm[i/8] |= 1 << (i % 8)
+}
+
+func (bm signerBitmap) count() int {
+	cnt := 0
+	for _, b := range bm {
+		for ; b != 0; b &= b - 1 {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (bm signerBitmap) isSubsetOf(bm2 signerBitmap) bool {
+	for i, b := range bm {
+		if i >= len(bm2) {
+			if b != 0 {
+				return false
+			}
+		} else if b&^bm2[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (bm signerBitmap) isDisjoint(bm2 signerBitmap) bool {
+	for i := 0; i < len(bm) && i < len(bm2); i++ {
+		if bm[i]&bm2[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bls12381ProofPart is a signature of a validator. A part taken from an
+// aggregated proof carries the whole aggregation in Signers and Signature,
+// and Index shall be one of the signers.
+type bls12381ProofPart struct {
+	Index     int
+	Signers   []byte
+	Signature []byte
+}
+
+func (pp *bls12381ProofPart) Bytes() []byte {
+	return codec.MustMarshalToBytes(pp)
+}
+
+func (pp *bls12381ProofPart) signers(n int) signerBitmap {
+	if pp.Signers != nil {
+		return pp.Signers
+	}
+	bm := newSignerBitmap(n)
+	bm.set(pp.Index)
+	return bm
+}
+
+// bls12381Proof is an aggregated signature of validators in Signers.
+type bls12381Proof struct {
+	Count     int
+	Signers   []byte
+	Signature []byte
+	bytes     []byte
+}
+
+func newBLS12381ProofFromBytes(bs []byte) (*bls12381Proof, error) {
+	var p bls12381Proof
+	_, err := codec.UnmarshalFromBytes(bs, &p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Count < 0 || len(p.Signers) != len(newSignerBitmap(p.Count)) {
+		return nil, errors.Errorf("invalid bls12381 proof count=%d len(signers)=%d", p.Count, len(p.Signers))
+	}
+	return &p, nil
+}
+
+func (p *bls12381Proof) Bytes() []byte {
+	if p.bytes == nil {
+		p.bytes = codec.MustMarshalToBytes(p)
+	}
+	return p.bytes
+}
+
+// Add aggregates the signature of the part if the signers of the part are
+// not in the proof. Parts from the same aggregation are added only once.
+func (p *bls12381Proof) Add(pp module.BTPProofPart) {
+	bpp := pp.(*bls12381ProofPart)
+	if bpp.Index < 0 || bpp.Index >= p.Count {
+		return
+	}
+	signers := bpp.signers(p.Count)
+	if len(signers) != len(p.Signers) {
+		return
+	}
+	if p.Signature == nil {
+		p.Signers = append([]byte{}, signers...)
+		p.Signature = bpp.Signature
+		p.bytes = nil
+		return
+	}
+	if !signerBitmap(p.Signers).isDisjoint(signers) {
+		return
+	}
+	sig, err := bls12381.SignatureFromBytes(p.Signature)
+	if err != nil {
+		return
+	}
+	sig2, err := bls12381.SignatureFromBytes(bpp.Signature)
+	if err != nil {
+		return
+	}
+	for i, b := range signers {
+		p.Signers[i] |= b
+	}
+	p.Signature = sig.Aggregate(sig2).Bytes()
+	p.bytes = nil
+}
+
+func (p *bls12381Proof) ValidatorCount() int {
+	return p.Count
+}
+
+func (p *bls12381Proof) ProofPartAt(i int) module.BTPProofPart {
+	signers := signerBitmap(p.Signers)
+	if !signers.has(i) {
+		return nil
+	}
+	if signers.count() == 1 {
+		return &bls12381ProofPart{Index: i, Signature: p.Signature}
+	}
+	return &bls12381ProofPart{
+		Index:     i,
+		Signers:   p.Signers,
+		Signature: p.Signature,
+	}
+}
+
+type bls12381ProofContext struct {
+	Validators [][]byte
+	mod        *networkTypeModule
+	bytes      cache.ByteSlice
+	pubKeys    []*bls12381.PublicKey
+	keyToIndex map[string]int
+
+	mu       sync.Mutex
+	verified []byte
+}
+
+func (pc *bls12381ProofContext) setup() error {
+	pc.pubKeys = make([]*bls12381.PublicKey, len(pc.Validators))
+	pc.keyToIndex = make(map[string]int, len(pc.Validators))
+	for i, key := range pc.Validators {
+		if key == nil {
+			continue
+		}
+		pk, err := bls12381.PublicKeyFromBytes(key)
+		if err != nil {
+			return errors.Wrapf(err, "invalid key index=%d key=%x", i, key)
+		}
+		pc.pubKeys[i] = pk
+		pc.keyToIndex[string(key)] = i
+	}
+	return nil
+}
+
+func newBLS12381ProofContext(
+	mod *networkTypeModule,
+	keys [][]byte,
+) (*bls12381ProofContext, error) {
+	pc := &bls12381ProofContext{
+		Validators: make([][]byte, 0, len(keys)),
+		mod:        mod,
+	}
+	for _, key := range keys {
+		pc.Validators = append(pc.Validators, key)
+	}
+	if err := pc.setup(); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+func newBLS12381ProofContextFromBytes(
+	mod *networkTypeModule,
+	bytes []byte,
+) (*bls12381ProofContext, error) {
+	pc := &bls12381ProofContext{
+		mod: mod,
+	}
+	if bytes != nil {
+		_, err := codec.UnmarshalFromBytes(bytes, pc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := pc.setup(); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+func (pc *bls12381ProofContext) NetworkTypeModule() module.NetworkTypeModule {
+	return pc.mod
+}
+
+func (pc *bls12381ProofContext) Bytes() []byte {
+	return pc.bytes.Get(func() []byte {
+		if pc.Validators == nil {
+			return nil
+		}
+		return codec.MustMarshalToBytes(pc)
+	})
+}
+
+// verifyAggregation verifies the aggregated signature of the signers. The
+// last verified aggregation is remembered since parts of an aggregated proof
+// carry the same aggregation.
+func (pc *bls12381ProofContext) verifyAggregation(dHash []byte, signers signerBitmap, sigBytes []byte) error {
+	if len(signers) != len(newSignerBitmap(len(pc.Validators))) {
+		return errors.Errorf("invalid signers len=%d numValidators=%d", len(signers), len(pc.Validators))
+	}
+	key := bytes.Join([][]byte{dHash, signers, sigBytes}, nil)
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if bytes.Equal(pc.verified, key) {
+		return nil
+	}
+	sig, err := bls12381.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return err
+	}
+	pks := make([]*bls12381.PublicKey, 0, len(pc.Validators))
+	for i := 0; i < len(signers)*8; i++ {
+		if !signers.has(i) {
+			continue
+		}
+		if i >= len(pc.pubKeys) || pc.pubKeys[i] == nil {
+			return errors.Errorf("invalid signer index=%d", i)
+		}
+		pks = append(pks, pc.pubKeys[i])
+	}
+	if !bls12381.FastAggregateVerify(pks, dHash, sig) {
+		return errors.Errorf("invalid signature signers=%x", []byte(signers))
+	}
+	pc.verified = key
+	return nil
+}
+
+// VerifyPart returns validator index and error
+func (pc *bls12381ProofContext) VerifyPart(dHash []byte, pp module.BTPProofPart) (int, error) {
+	bpp := pp.(*bls12381ProofPart)
+	if bpp.Index < 0 || bpp.Index >= len(pc.Validators) {
+		return -1, errors.Errorf("invalid proof part index=%d numValidators=%d", bpp.Index, len(pc.Validators))
+	}
+	if bpp.Signers == nil {
+		pk := pc.pubKeys[bpp.Index]
+		if pk == nil {
+			return -1, errors.Errorf("invalid proof part. no key for index=%d", bpp.Index)
+		}
+		sig, err := bls12381.SignatureFromBytes(bpp.Signature)
+		if err != nil {
+			return -1, err
+		}
+		if !pk.Verify(dHash, sig) {
+			return -1, errors.Errorf("invalid proof part. bad signature index=%d key=%x", bpp.Index, pc.Validators[bpp.Index])
+		}
+		return bpp.Index, nil
+	}
+	if !signerBitmap(bpp.Signers).has(bpp.Index) {
+		return -1, errors.Errorf("invalid proof part. index=%d is not in signers=%x", bpp.Index, bpp.Signers)
+	}
+	if err := pc.verifyAggregation(dHash, bpp.Signers, bpp.Signature); err != nil {
+		return -1, err
+	}
+	return bpp.Index, nil
+}
+
+func (pc *bls12381ProofContext) NewProofPartFromBytes(ppBytes []byte) (module.BTPProofPart, error) {
+	var pp bls12381ProofPart
+	_, err := codec.UnmarshalFromBytes(ppBytes, &pp)
+	if err != nil {
+		return nil, err
+	}
+	return &pp, err
+}
+
+func (pc *bls12381ProofContext) Verify(dHash []byte, p module.BTPProof) error {
+	bp := p.(*bls12381Proof)
+	if bp.Count != len(pc.Validators) {
+		return errors.Errorf("invalid validator count numValidators=%d proof.count=%d", len(pc.Validators), bp.Count)
+	}
+	valid := signerBitmap(bp.Signers).count()
+	if valid <= 2*len(pc.Validators)/3 {
+		return errors.Errorf("not enough signers numValidator=%d numSigners=%d", len(pc.Validators), valid)
+	}
+	return pc.verifyAggregation(dHash, bp.Signers, bp.Signature)
+}
+
+func (pc *bls12381ProofContext) NewProofFromBytes(proofBytes []byte) (module.BTPProof, error) {
+	return newBLS12381ProofFromBytes(proofBytes)
+}
+
+func (pc *bls12381ProofContext) NewProofPart(
+	dHash []byte,
+	wp module.WalletProvider,
+) (module.BTPProofPart, error) {
+	w := wp.WalletFor(bls12381DSA)
+	if w == nil {
+		return nil, errors.Errorf("no wallet for uid=%s dsa=%s", pc.mod.UID(), bls12381DSA)
+	}
+	idx, ok := pc.keyToIndex[string(w.PublicKey())]
+	if !ok {
+		return nil, errors.Errorf("not validator key=%x", w.PublicKey())
+	}
+	sig, err := w.Sign(dHash)
+	if err != nil {
+		return nil, err
+	}
+	return &bls12381ProofPart{
+		Index:     idx,
+		Signature: sig,
+	}, nil
+}
+
+func (pc *bls12381ProofContext) DSA() string {
+	return bls12381DSA
+}
+
+func (pc *bls12381ProofContext) NewProof() module.BTPProof {
+	return &bls12381Proof{
+		Count:   len(pc.Validators),
+		Signers: newSignerBitmap(len(pc.Validators)),
+	}
+}
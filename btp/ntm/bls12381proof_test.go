@@ -0,0 +1,217 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto/bls12381"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+func newBLS12381WalletProvider() (*walletProvider, module.BaseWallet) {
+	sk, _ := bls12381.GenerateSecretKey()
+	w := wallet.NewBLSWallet(sk)
+	wp := walletProvider{
+		wallets: map[string]module.BaseWallet{
+			bls12381DSA: w,
+		},
+	}
+	return &wp, w
+}
+
+func newBLS12381TestSetup(t *testing.T, count int) *testSetup {
+	s := &testSetup{
+		assert:  assert.New(t),
+		count:   count,
+		wallets: make([]*walletProvider, 0, count),
+		pubKeys: make([][]byte, 0, count),
+	}
+	for i := 0; i < count; i++ {
+		wp, w := newBLS12381WalletProvider()
+		s.wallets = append(s.wallets, wp)
+		s.pubKeys = append(s.pubKeys, w.PublicKey())
+	}
+	var err error
+	s.pc, err = bls12381ModuleInstance.NewProofContext(s.pubKeys)
+	s.assert.NoError(err)
+	return s
+}
+
+func sha256Sum(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return h[:]
+}
+
+func TestBLS12381ProofContext_NewProofPart(t *testing.T) {
+	s := newBLS12381TestSetup(t, 4)
+	msgHash := sha256Sum([]byte("abc"))
+	for i := 0; i < s.count; i++ {
+		pp, err := s.pc.NewProofPart(msgHash, s.wallets[i])
+		s.assert.NoError(err)
+		idx, err := s.pc.VerifyPart(msgHash, pp)
+		s.assert.NoError(err)
+		s.assert.Equal(i, idx)
+
+		pp2, err := s.pc.NewProofPartFromBytes(pp.Bytes())
+		s.assert.NoError(err)
+		idx, err = s.pc.VerifyPart(msgHash, pp2)
+		s.assert.NoError(err)
+		s.assert.Equal(i, idx)
+
+		_, err = s.pc.VerifyPart(sha256Sum([]byte("abcd")), pp)
+		s.assert.Error(err)
+	}
+
+	wp, _ := newBLS12381WalletProvider()
+	_, err := s.pc.NewProofPart(msgHash, wp)
+	s.assert.Error(err)
+
+	swp, _ := newSecp256k1WalletProvider()
+	_, err = s.pc.NewProofPart(msgHash, swp)
+	s.assert.Error(err)
+}
+
+func TestBLS12381ProofContext_Verify(t *testing.T) {
+	msgHash := sha256Sum([]byte("abc"))
+	testCase := []struct {
+		ok      bool
+		ppCount int
+		pkCount int
+	}{
+		{false, 0, 1},
+		{true, 1, 1},
+
+		{false, 2, 3},
+		{true, 3, 3},
+
+		{false, 2, 4},
+		{true, 3, 4},
+
+		{false, 4, 7},
+		{true, 5, 7},
+	}
+	for _, c := range testCase {
+		s := newBLS12381TestSetup(t, c.pkCount)
+		p := s.newProofOfLen(c.ppCount, msgHash)
+		err := s.pc.Verify(msgHash, p)
+		if c.ok {
+			s.assert.NoError(err, "Verify exp=%v ppCount=%d pkCount=%d", c.ok, c.ppCount, c.pkCount)
+		} else {
+			s.assert.Error(err, "Verify exp=%v ppCount=%d pkCount=%d", c.ok, c.ppCount, c.pkCount)
+		}
+
+		// single aggregated signature regardless of the number of parts
+		bp := p.(*bls12381Proof)
+		if c.ppCount > 0 {
+			s.assert.Len(bp.Signature, bls12381.SignatureSize)
+		}
+
+		p2, err := bls12381ModuleInstance.NewProofFromBytes(p.Bytes())
+		s.assert.NoError(err)
+		s.assert.Equal(c.pkCount, p2.ValidatorCount())
+		err = s.pc.Verify(msgHash, p2)
+		if c.ok {
+			s.assert.NoError(err)
+		} else {
+			s.assert.Error(err)
+		}
+	}
+}
+
+func TestBLS12381Proof_Decompose(t *testing.T) {
+	s := newBLS12381TestSetup(t, 4)
+	msgHash := sha256Sum([]byte("abc"))
+	p := s.newProofOfLen(3, msgHash)
+
+	// each part of the aggregated proof can be verified for its validator
+	// and assembled again into the same proof.
+	p2 := s.pc.NewProof()
+	for i := 0; i < p.ValidatorCount(); i++ {
+		pp := p.ProofPartAt(i)
+		if i >= 3 {
+			s.assert.Nil(pp)
+			continue
+		}
+		pp2, err := s.pc.NewProofPartFromBytes(pp.Bytes())
+		s.assert.NoError(err)
+		idx, err := s.pc.VerifyPart(msgHash, pp2)
+		s.assert.NoError(err)
+		s.assert.Equal(i, idx)
+		p2.Add(pp2)
+	}
+	s.assert.Equal(p.Bytes(), p2.Bytes())
+	s.assert.NoError(s.pc.Verify(msgHash, p2))
+
+	// aggregated part with index out of signers
+	pp := p.ProofPartAt(0).(*bls12381ProofPart)
+	pp.Index = 3
+	_, err := s.pc.VerifyPart(msgHash, pp)
+	s.assert.Error(err)
+
+	// adding remaining individual part
+	pp3, err := s.pc.NewProofPart(msgHash, s.wallets[3])
+	s.assert.NoError(err)
+	p2.Add(pp3)
+	s.assert.Equal(4, signerBitmap(p2.(*bls12381Proof).Signers).count())
+	s.assert.NoError(s.pc.Verify(msgHash, p2))
+}
+
+func TestBLS12381ProofContext_codec(t *testing.T) {
+	s := newBLS12381TestSetup(t, 4)
+	msgHash := sha256Sum([]byte("abc"))
+	p := s.newProofOfLen(3, msgHash)
+	pc2, err := bls12381ModuleInstance.NewProofContextFromBytes(s.pc.Bytes())
+	s.assert.NoError(err)
+	s.assert.NoError(pc2.Verify(msgHash, p))
+
+	var bp bls12381Proof
+	codec.MustUnmarshalFromBytes(p.Bytes(), &bp)
+	s.assert.NoError(pc2.Verify(msgHash, &bp))
+
+	_, err = bls12381ModuleInstance.NewProofContext([][]byte{
+		s.pubKeys[0], s.pubKeys[1][1:],
+	})
+	s.assert.Error(err)
+}
+
+func TestBLS12381Module_WalletFromSecp256k1(t *testing.T) {
+	assert := assert.New(t)
+	w := wallet.New()
+	bw := w.(module.WalletProvider).WalletFor(bls12381DSA)
+	assert.NotNil(bw)
+	assert.Equal(bw.PublicKey(), w.(module.WalletProvider).WalletFor(bls12381DSA).PublicKey())
+
+	pw := bw.(interface{ PublicKeyWithProof() []byte })
+	key, err := DSAModuleForName(bls12381DSA).Canonicalize(pw.PublicKeyWithProof())
+	assert.NoError(err)
+	assert.Equal(bw.PublicKey(), key)
+
+	pc, err := bls12381ModuleInstance.NewProofContext([][]byte{key})
+	assert.NoError(err)
+	msgHash := sha256Sum([]byte("abc"))
+	pp, err := pc.NewProofPart(msgHash, w.(module.WalletProvider))
+	assert.NoError(err)
+	p := pc.NewProof()
+	p.Add(pp)
+	assert.NoError(pc.Verify(msgHash, p))
+}
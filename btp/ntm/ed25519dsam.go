@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	ed25519DSA = "eddsa/ed25519"
+)
+
+type ed25519DSAModule struct {
+}
+
+func (m ed25519DSAModule) Name() string {
+	return ed25519DSA
+}
+
+func (m ed25519DSAModule) Verify(pubKey []byte) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidKeyLength(len=%d,exp=%d)", len(pubKey), ed25519.PublicKeySize)
+	}
+	// SetBytes accepts non-canonical encodings, so it checks the encoding
+	// of the decoded point.
+	p, err := new(edwards25519.Point).SetBytes(pubKey)
+	if err != nil || !bytes.Equal(p.Bytes(), pubKey) {
+		return errors.IllegalArgumentError.New("InvalidPoint")
+	}
+	if p.MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return errors.IllegalArgumentError.New("SmallOrderPoint")
+	}
+	return nil
+}
+
+func (m ed25519DSAModule) Canonicalize(pubKey []byte) ([]byte, error) {
+	if err := m.Verify(pubKey); err != nil {
+		return nil, err
+	}
+	return append([]byte{}, pubKey...), nil
+}
+
+var ed25519DSAModuleInstance ed25519DSAModule
+
+func init() {
+	registerDSAModule(ed25519DSAModuleInstance)
+}
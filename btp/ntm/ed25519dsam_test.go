@@ -0,0 +1,229 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+// test vectors from RFC 8032 7.1
+var ed25519Vectors = []struct {
+	secret, pubKey, msg, sig string
+}{
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		"",
+		"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+	},
+	{
+		"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		"3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		"72",
+		"92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00",
+	},
+	{
+		"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+		"fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+		"af82",
+		"6291d657deec24024827e69c3abe01a30ce548a284743a445e3680d7db5ac3ac18ff9b538d16f290ae67f760984dc6594a7c15e9716ed28dc027beceea1ec40a",
+	},
+}
+
+func mustDecodeHex(s string) []byte {
+	bs, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+func TestEd25519DSAModule_Verify(t *testing.T) {
+	assert := assert.New(t)
+	dsam := DSAModuleForName(ed25519DSA)
+
+	for _, v := range ed25519Vectors {
+		pk := mustDecodeHex(v.pubKey)
+		assert.NoError(dsam.Verify(pk))
+		cKey, err := dsam.Canonicalize(pk)
+		assert.NoError(err)
+		assert.Equal(pk, cKey)
+		assert.Error(dsam.Verify(pk[1:]))
+	}
+
+	invalid := []string{
+		// identity
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		// point of order 2
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// point of order 4
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		// point of order 8
+		"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a",
+		// non-canonical y (p)
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// x = 0 with sign bit
+		"0100000000000000000000000000000000000000000000000000000000000080",
+		// not on the curve
+		"0200000000000000000000000000000000000000000000000000000000000000",
+	}
+	for _, s := range invalid {
+		assert.Error(dsam.Verify(mustDecodeHex(s)), "key=%s", s)
+	}
+}
+
+func TestEd25519ProofContext_KnownVectors(t *testing.T) {
+	assert := assert.New(t)
+
+	var keys [][]byte
+	for _, v := range ed25519Vectors {
+		keys = append(keys, mustDecodeHex(v.pubKey))
+	}
+	pc, err := ed25519ModuleInstance.NewProofContext(keys)
+	assert.NoError(err)
+
+	for i, v := range ed25519Vectors {
+		msg := mustDecodeHex(v.msg)
+		sk := ed25519.NewKeyFromSeed(mustDecodeHex(v.secret))
+		wp := &walletProvider{
+			wallets: map[string]module.BaseWallet{
+				ed25519DSA: wallet.NewEd25519Wallet(sk),
+			},
+		}
+		pp, err := pc.NewProofPart(msg, wp)
+		assert.NoError(err)
+		epp := pp.(*ed25519ProofPart)
+		assert.Equal(i, epp.Index)
+		assert.Equal(v.sig, hex.EncodeToString(epp.Signature))
+
+		pp2, err := pc.NewProofPartFromBytes(codec.MustMarshalToBytes(&ed25519ProofPart{
+			Index:     i,
+			Signature: mustDecodeHex(v.sig),
+		}))
+		assert.NoError(err)
+		idx, err := pc.VerifyPart(msg, pp2)
+		assert.NoError(err)
+		assert.Equal(i, idx)
+
+		_, err = pc.VerifyPart(append(msg, 0), pp2)
+		assert.Error(err)
+	}
+}
+
+func newEd25519TestSetup(t *testing.T, count int) *testSetup {
+	s := &testSetup{
+		assert:  assert.New(t),
+		count:   count,
+		wallets: make([]*walletProvider, 0, count),
+		pubKeys: make([][]byte, 0, count),
+	}
+	for i := 0; i < count; i++ {
+		w := wallet.New()
+		bw := w.(module.WalletProvider).WalletFor(ed25519DSA)
+		s.wallets = append(s.wallets, &walletProvider{
+			wallets: map[string]module.BaseWallet{ed25519DSA: bw},
+		})
+		s.pubKeys = append(s.pubKeys, bw.PublicKey())
+	}
+	var err error
+	s.pc, err = ed25519ModuleInstance.NewProofContext(s.pubKeys)
+	s.assert.NoError(err)
+	return s
+}
+
+func TestEd25519ProofContext_Verify(t *testing.T) {
+	msgHash := sha256Sum([]byte("abc"))
+	testCase := []struct {
+		ok      bool
+		ppCount int
+		pkCount int
+	}{
+		{false, 0, 1},
+		{true, 1, 1},
+
+		{false, 2, 3},
+		{true, 3, 3},
+
+		{false, 2, 4},
+		{true, 3, 4},
+
+		{false, 4, 7},
+		{true, 5, 7},
+	}
+	for _, c := range testCase {
+		s := newEd25519TestSetup(t, c.pkCount)
+		p := s.newProofOfLen(c.ppCount, msgHash)
+		err := s.pc.Verify(msgHash, p)
+		if c.ok {
+			s.assert.NoError(err, "Verify exp=%v ppCount=%d pkCount=%d", c.ok, c.ppCount, c.pkCount)
+		} else {
+			s.assert.Error(err, "Verify exp=%v ppCount=%d pkCount=%d", c.ok, c.ppCount, c.pkCount)
+		}
+		p2, err := ed25519ModuleInstance.NewProofFromBytes(p.Bytes())
+		s.assert.NoError(err)
+		err = s.pc.Verify(msgHash, p2)
+		if c.ok {
+			s.assert.NoError(err)
+		} else {
+			s.assert.Error(err)
+		}
+		pc2, err := ed25519ModuleInstance.NewProofContextFromBytes(s.pc.Bytes())
+		s.assert.NoError(err)
+		err = pc2.Verify(msgHash, p2)
+		if c.ok {
+			s.assert.NoError(err)
+		} else {
+			s.assert.Error(err)
+		}
+	}
+}
+
+func TestEd25519Proof_SwappedSignature(t *testing.T) {
+	s := newEd25519TestSetup(t, 4)
+	msgHash := sha256Sum([]byte("abc"))
+	p := s.newProofOfLen(4, msgHash).(*ed25519Proof)
+	p.Signatures[0], p.Signatures[1] = p.Signatures[1], p.Signatures[0]
+	p.bytes = nil
+	s.assert.Error(s.pc.Verify(msgHash, p))
+}
+
+func TestEd25519ProofContext_ConcurrentProofParts(t *testing.T) {
+	s := newEd25519TestSetup(t, 4)
+	msgHash := sha256Sum([]byte("abc"))
+	pc, err := ed25519ModuleInstance.NewProofContextFromBytes(s.pc.Bytes())
+	s.assert.NoError(err)
+
+	var wg sync.WaitGroup
+	for _, wp := range s.wallets {
+		wg.Add(1)
+		go func(wp *walletProvider) {
+			defer wg.Done()
+			_, err := pc.NewProofPart(msgHash, wp)
+			s.assert.NoError(err)
+		}(wp)
+	}
+	wg.Wait()
+}
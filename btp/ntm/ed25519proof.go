@@ -0,0 +1,231 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/ed25519"
+
+	"github.com/icon-project/goloop/common/cache"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+type ed25519ProofPart struct {
+	Index     int
+	Signature []byte
+}
+
+func (pp *ed25519ProofPart) Bytes() []byte {
+	return codec.MustMarshalToBytes(pp)
+}
+
+// ed25519Proof keeps signatures in the order of validators. Since ed25519
+// signatures can not recover the public key, the index of a signature
+// identifies its validator.
+type ed25519Proof struct {
+	Signatures [][]byte
+	bytes      []byte
+}
+
+func newEd25519ProofFromBytes(bs []byte) (*ed25519Proof, error) {
+	var p ed25519Proof
+	_, err := codec.UnmarshalFromBytes(bs, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, err
+}
+
+func (p *ed25519Proof) Bytes() []byte {
+	if p.bytes == nil {
+		p.bytes = codec.MustMarshalToBytes(p)
+	}
+	return p.bytes
+}
+
+func (p *ed25519Proof) Add(pp module.BTPProofPart) {
+	epp := pp.(*ed25519ProofPart)
+	p.Signatures[epp.Index] = epp.Signature
+	p.bytes = nil
+}
+
+func (p *ed25519Proof) ValidatorCount() int {
+	return len(p.Signatures)
+}
+
+func (p *ed25519Proof) ProofPartAt(i int) module.BTPProofPart {
+	if p.Signatures[i] == nil {
+		return nil
+	}
+	return &ed25519ProofPart{i, p.Signatures[i]}
+}
+
+type ed25519ProofContext struct {
+	Validators [][]byte
+	mod        *networkTypeModule
+	bytes      cache.ByteSlice
+	keyToIndex map[string]int
+}
+
+func newEd25519ProofContext(
+	mod *networkTypeModule,
+	keys [][]byte,
+) (*ed25519ProofContext, error) {
+	pc := &ed25519ProofContext{
+		Validators: make([][]byte, 0, len(keys)),
+		keyToIndex: make(map[string]int, len(keys)),
+		mod:        mod,
+	}
+	for i, key := range keys {
+		if key != nil {
+			if len(key) != ed25519.PublicKeySize {
+				return nil, errors.Errorf("invalid key index=%d key=%x", i, key)
+			}
+			pc.keyToIndex[string(key)] = i
+		}
+		pc.Validators = append(pc.Validators, key)
+	}
+	return pc, nil
+}
+
+func newEd25519ProofContextFromBytes(
+	mod *networkTypeModule,
+	bytes []byte,
+) (*ed25519ProofContext, error) {
+	pc := &ed25519ProofContext{
+		mod: mod,
+	}
+	if bytes != nil {
+		_, err := codec.UnmarshalFromBytes(bytes, pc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pc.keyToIndex = make(map[string]int, len(pc.Validators))
+	for i, k := range pc.Validators {
+		if k != nil {
+			pc.keyToIndex[string(k)] = i
+		}
+	}
+	return pc, nil
+}
+
+// indexOf returns the index of the key. The map is built on construction,
+// so it's safe to be called concurrently.
+func (pc *ed25519ProofContext) indexOf(key []byte) (int, bool) {
+	idx, ok := pc.keyToIndex[string(key)]
+	return idx, ok
+}
+
+func (pc *ed25519ProofContext) NetworkTypeModule() module.NetworkTypeModule {
+	return pc.mod
+}
+
+func (pc *ed25519ProofContext) Bytes() []byte {
+	return pc.bytes.Get(func() []byte {
+		if pc.Validators == nil {
+			return nil
+		}
+		return codec.MustMarshalToBytes(pc)
+	})
+}
+
+// VerifyPart returns validator index and error
+func (pc *ed25519ProofContext) VerifyPart(dHash []byte, pp module.BTPProofPart) (int, error) {
+	epp := pp.(*ed25519ProofPart)
+	if epp.Index < 0 || epp.Index >= len(pc.Validators) {
+		return -1, errors.Errorf("invalid proof part index=%d numValidators=%d", epp.Index, len(pc.Validators))
+	}
+	key := pc.Validators[epp.Index]
+	if len(key) != ed25519.PublicKeySize {
+		return -1, errors.Errorf("invalid proof part. no key for index=%d", epp.Index)
+	}
+	if len(epp.Signature) != ed25519.SignatureSize {
+		return -1, errors.Errorf("invalid proof part. bad signature length index=%d len=%d", epp.Index, len(epp.Signature))
+	}
+	if !ed25519.Verify(key, dHash, epp.Signature) {
+		return -1, errors.Errorf("invalid proof part. bad signature index=%d key=%x", epp.Index, key)
+	}
+	return epp.Index, nil
+}
+
+func (pc *ed25519ProofContext) NewProofPartFromBytes(ppBytes []byte) (module.BTPProofPart, error) {
+	var pp ed25519ProofPart
+	_, err := codec.UnmarshalFromBytes(ppBytes, &pp)
+	if err != nil {
+		return nil, err
+	}
+	return &pp, err
+}
+
+func (pc *ed25519ProofContext) Verify(dHash []byte, p module.BTPProof) error {
+	ep := p.(*ed25519Proof)
+	if len(ep.Signatures) != len(pc.Validators) {
+		return errors.Errorf("invalid validator count numValidators=%d proof.count=%d", len(pc.Validators), len(ep.Signatures))
+	}
+	valid := 0
+	for i, sig := range ep.Signatures {
+		if sig == nil {
+			continue
+		}
+		if _, err := pc.VerifyPart(dHash, &ed25519ProofPart{i, sig}); err != nil {
+			return err
+		}
+		valid++
+	}
+	if valid <= 2*len(pc.Validators)/3 {
+		return errors.Errorf("not enough proof parts numValidator=%d numProofParts=%d", len(pc.Validators), valid)
+	}
+	return nil
+}
+
+func (pc *ed25519ProofContext) NewProofFromBytes(proofBytes []byte) (module.BTPProof, error) {
+	return newEd25519ProofFromBytes(proofBytes)
+}
+
+func (pc *ed25519ProofContext) NewProofPart(
+	dHash []byte,
+	wp module.WalletProvider,
+) (module.BTPProofPart, error) {
+	w := wp.WalletFor(ed25519DSA)
+	if w == nil {
+		return nil, errors.Errorf("no wallet for uid=%s dsa=%s", pc.mod.UID(), ed25519DSA)
+	}
+	idx, ok := pc.indexOf(w.PublicKey())
+	if !ok {
+		return nil, errors.Errorf("not validator key=%x", w.PublicKey())
+	}
+	sig, err := w.Sign(dHash)
+	if err != nil {
+		return nil, err
+	}
+	return &ed25519ProofPart{
+		Index:     idx,
+		Signature: sig,
+	}, nil
+}
+
+func (pc *ed25519ProofContext) DSA() string {
+	return ed25519DSA
+}
+
+func (pc *ed25519ProofContext) NewProof() module.BTPProof {
+	return &ed25519Proof{
+		Signatures: make([][]byte, len(pc.Validators)),
+	}
+}
@@ -52,3 +52,23 @@ type ExecutionResult interface {
 	TotalFee() *big.Int
 	VirtualFee() *big.Int
 }
+
+// AccountVote is an amount of voting (delegation or bond) to a target.
+type AccountVote struct {
+	Address module.Address
+	Value   *big.Int
+}
+
+// AccountStatus is the platform specific status of an account.
+type AccountStatus struct {
+	Stake       *big.Int
+	Delegation  *big.Int
+	Bond        *big.Int
+	Delegations []AccountVote
+}
+
+// AccountStatusReader is implemented by the platform which keeps additional
+// status of accounts in its extension. It's used for the account index.
+type AccountStatusReader interface {
+	GetAccountStatus(ess state.ExtensionSnapshot, addr module.Address) (*AccountStatus, error)
+}
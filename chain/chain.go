@@ -17,6 +17,7 @@ import (
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/common/trie/cache"
+	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/consensus"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/network"
@@ -84,6 +85,7 @@ type singleChain struct {
 
 	dbLock   sync.RWMutex
 	database db.Database
+	barrier  *writeBarrier
 	vld      module.CommitVoteSetDecoder
 	pd       module.PatchDecoder
 	sm       module.ServiceManager
@@ -106,6 +108,7 @@ type singleChain struct {
 	lastErr    error
 	mtx        sync.RWMutex
 	task       chainTask
+	backup     chainTask
 	termWaiter *sync.Cond
 
 	// monitor
@@ -118,6 +121,8 @@ const (
 	DefaultContractDir = "contract"
 	DefaultCacheDir    = "cache"
 	DefaultTmpDBDir    = "tmp"
+
+	chainGenesisZipFileName = "genesis.zip"
 )
 
 func (c *singleChain) Database() db.Database {
@@ -255,6 +260,14 @@ func (c *singleChain) ValidateTxOnSend() bool {
 	return c.cfg.ValidateTxOnSend
 }
 
+func (c *singleChain) AccountIndex() bool {
+	return c.cfg.AccountIndex
+}
+
+func (c *singleChain) StateRetention() int64 {
+	return c.cfg.StateRetention
+}
+
 func (c *singleChain) State() (string, int64, error) {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
@@ -267,12 +280,19 @@ func (c *singleChain) State() (string, int64, error) {
 				height = blk.Height()
 			}
 		}
-		return c.task.DetailOf(c.state), height, c.lastErr
+		return c._withBackupDetail(c.task.DetailOf(c.state)), height, c.lastErr
 	default:
-		return c.state.String(), c.lastBlockHeight(), c.lastErr
+		return c._withBackupDetail(c.state.String()), c.lastBlockHeight(), c.lastErr
 	}
 }
 
+func (c *singleChain) _withBackupDetail(detail string) string {
+	if c.backup != nil {
+		return detail + ", " + c.backup.DetailOf(Started)
+	}
+	return detail
+}
+
 func (c *singleChain) lastBlockHeight() int64 {
 	if c.database == nil {
 		return 0
@@ -413,6 +433,12 @@ func (c *singleChain) prepareDatabase(chainDir string) error {
 		_ = cdb.Close()
 		return errors.Wrapf(err, "UnknownCacheStrategy(%s)", c.cfg.NodeCache)
 	}
+	if c.cfg.StateRetention > 0 {
+		c.barrier = newWriteBarrier(cdb)
+		cdb = c.barrier
+	} else {
+		c.barrier = nil
+	}
 	cacheDir := path.Join(chainDir, DefaultCacheDir)
 	c.database = cache.AttachManager(cdb, cacheDir, mLevel, fLevel, stores)
 	return nil
@@ -603,6 +629,55 @@ func (c *singleChain) Backup(file string, extra []string) error {
 	return c._runTask(task, false)
 }
 
+// IncrementalBackup starts to back up blocks and states added after
+// the base height to the file.
+func (c *singleChain) IncrementalBackup(file string, base int64) error {
+	if len(file) == 0 {
+		return errors.IllegalArgumentError.New("NoBackupFile")
+	}
+	if base <= 0 {
+		return errors.IllegalArgumentError.Errorf("InvalidBaseHeight(base=%d)", base)
+	}
+	task := newTaskIncrementalBackup(c, file, base)
+	return c._runTask(task, false)
+}
+
+// OnlineBackup starts to back up the chain to the file while the chain
+// keeps running. It uses a checkpoint of the database for consistency.
+func (c *singleChain) OnlineBackup(file string, extra []string) error {
+	if len(file) == 0 {
+		return errors.IllegalArgumentError.New("NoBackupFile")
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	switch c.state {
+	case Created, Initializing, InitializeFailed, Terminating, Terminated:
+		return errors.InvalidStateError.Errorf(
+			"InvalidStateForBackup(state=%s)", c.state.String())
+	}
+	if c.backup != nil {
+		return errors.InvalidStateError.Errorf(
+			"BackupInProgress(%s)", c.backup.String())
+	}
+	task := newTaskOnlineBackup(c, file, extra)
+	if err := task.Start(); err != nil {
+		c.logger.Infof("Fail to start %s err=%v", task.String(), err)
+		return err
+	}
+	c.logger.Infof("STARTED %s", task.String())
+	c.backup = task
+	go func() {
+		err := task.Wait()
+		c.logger.Infof("DONE %s err=%+v", task.String(), err)
+
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		c.backup = nil
+	}()
+	return nil
+}
+
 type TaskFactory func(c *singleChain, params json.RawMessage) (chainTask, error)
 
 var taskFactories = map[string]TaskFactory{}
@@ -644,6 +719,9 @@ func (c *singleChain) _terminate() {
 func (c *singleChain) Term() error {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
+	if c.backup != nil {
+		c.backup.Stop()
+	}
 	switch c.state {
 	case Terminated:
 		return errors.InvalidStateError.New("AlreadyTerminated")
@@ -668,7 +746,6 @@ func (c *singleChain) Verify() error {
 func (c *singleChain) Reset(gs string, height int64, blockHash []byte) error {
 	if len(gs) == 0 {
 		chainDir := c.cfg.AbsBaseDir()
-		const chainGenesisZipFileName = "genesis.zip"
 		gs = path.Join(chainDir, chainGenesisZipFileName)
 	}
 	task := newTaskReset(c, gs, height, blockHash)
@@ -688,6 +765,13 @@ func (a *addrWallet) Sign(data []byte) ([]byte, error) {
 	return a.w.Sign(data)
 }
 
+func (a *addrWallet) SignWithContext(data []byte, ctx *wallet.SignContext) ([]byte, error) {
+	if cs, ok := a.w.(wallet.ContextSigner); ok {
+		return cs.SignWithContext(data, ctx)
+	}
+	return a.w.Sign(data)
+}
+
 func (a *addrWallet) PublicKey() []byte {
 	addr, err := a.mod.AddressFromPubKey(a.w.PublicKey())
 	if err != nil {
@@ -697,6 +781,9 @@ func (a *addrWallet) PublicKey() []byte {
 }
 
 func (c *singleChain) WalletFor(dsa string) module.BaseWallet {
+	if wp, ok := c.wallet.(module.WalletProvider); ok {
+		return wp.WalletFor(dsa)
+	}
 	switch dsa {
 	case "ecdsa/secp256k1":
 		return c.wallet
@@ -37,17 +37,22 @@ type Config struct {
 	Platform string `json:"platform,omitempty"`
 
 	// static
-	SeedAddr         string `json:"seed_addr"`
-	Role             uint   `json:"role"`
-	ConcurrencyLevel int    `json:"concurrency_level,omitempty"`
-	NormalTxPoolSize int    `json:"normal_tx_pool,omitempty"`
-	PatchTxPoolSize  int    `json:"patch_tx_pool,omitempty"`
-	MaxBlockTxBytes  int    `json:"max_block_tx_bytes,omitempty"`
-	NodeCache        string `json:"node_cache,omitempty"`
-	AutoStart        bool   `json:"auto_start,omitempty"`
-	ChildrenLimit    *int   `json:"children_limit,omitempty"`
-	NephewsLimit     *int   `json:"nephews_limit,omitempty"`
-	ValidateTxOnSend bool   `json:"validate_tx_on_send,omitempty"`
+	SeedAddr         string  `json:"seed_addr"`
+	Role             uint    `json:"role"`
+	ConcurrencyLevel int     `json:"concurrency_level,omitempty"`
+	NormalTxPoolSize int     `json:"normal_tx_pool,omitempty"`
+	PatchTxPoolSize  int     `json:"patch_tx_pool,omitempty"`
+	MaxBlockTxBytes  int     `json:"max_block_tx_bytes,omitempty"`
+	NodeCache        string  `json:"node_cache,omitempty"`
+	AutoStart        bool    `json:"auto_start,omitempty"`
+	ChildrenLimit    *int    `json:"children_limit,omitempty"`
+	NephewsLimit     *int    `json:"nephews_limit,omitempty"`
+	ValidateTxOnSend bool    `json:"validate_tx_on_send,omitempty"`
+	StateRetention   int64   `json:"state_retention,omitempty"`
+	StatePinned      []int64 `json:"state_pinned,omitempty"`
+	AccountIndex     bool    `json:"account_index,omitempty"`
+
+	Schedules []Schedule `json:"schedules,omitempty"`
 
 	// runtime
 	Channel        string `json:"channel"`
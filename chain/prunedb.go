@@ -0,0 +1,299 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package chain
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	// maxBarrierKeys is the maximum number of keys tracked by writeBarrier.
+	maxBarrierKeys = 1 << 20
+)
+
+var errBarrierOverflow = errors.NewBase(errors.InvalidStateError, "BarrierOverflow")
+
+// writeBarrier tracks trie nodes written to the database, so the pruner
+// doesn't delete nodes which are written while it collects garbage.
+type writeBarrier struct {
+	db.Database
+
+	// lock is held exclusively while the pruner deletes nodes.
+	lock sync.RWMutex
+
+	mtx      sync.Mutex
+	maxKeys  int
+	inflight map[string]struct{} // written after the last finalization
+	written  map[string]struct{} // written after the activation
+	active   bool
+	overflow bool
+}
+
+type barrierTrieBucket struct {
+	db.Bucket
+	barrier *writeBarrier
+}
+
+func (b *barrierTrieBucket) Set(key, value []byte) error {
+	b.barrier.lock.RLock()
+	defer b.barrier.lock.RUnlock()
+
+	b.barrier.onWrite(key)
+	return b.Bucket.Set(key, value)
+}
+
+type barrierPropertyBucket struct {
+	db.Bucket
+	barrier *writeBarrier
+}
+
+func (b *barrierPropertyBucket) Set(key, value []byte) error {
+	if err := b.Bucket.Set(key, value); err != nil {
+		return err
+	}
+	if string(key) == block.KeyLastBlockHeight {
+		b.barrier.onFinalize()
+	}
+	return nil
+}
+
+func (b *writeBarrier) GetBucket(id db.BucketID) (db.Bucket, error) {
+	bk, err := b.Database.GetBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	switch id {
+	case db.MerkleTrie:
+		return &barrierTrieBucket{bk, b}, nil
+	case db.ChainProperty:
+		return &barrierPropertyBucket{bk, b}, nil
+	default:
+		return bk, nil
+	}
+}
+
+func (b *writeBarrier) Checkpoint(dir string) (db.CheckpointWriter, error) {
+	return db.Checkpoint(b.Database, dir)
+}
+
+func (b *writeBarrier) onWrite(key []byte) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	k := string(key)
+	if b.inflight != nil {
+		if len(b.inflight) < b.maxKeys {
+			b.inflight[k] = struct{}{}
+		} else {
+			b.inflight = nil
+		}
+	}
+	if b.active && !b.overflow {
+		if len(b.written) < b.maxKeys {
+			b.written[k] = struct{}{}
+		} else {
+			b.overflow = true
+			b.written = nil
+		}
+	}
+}
+
+func (b *writeBarrier) onFinalize() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.inflight = make(map[string]struct{})
+}
+
+// activate starts tracking written nodes including nodes written after
+// the last finalization. It returns false if it can't track them.
+func (b *writeBarrier) activate() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.inflight == nil {
+		return false
+	}
+	b.written = make(map[string]struct{}, len(b.inflight))
+	for k := range b.inflight {
+		b.written[k] = struct{}{}
+	}
+	b.active = true
+	b.overflow = false
+	return true
+}
+
+func (b *writeBarrier) deactivate() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.active = false
+	b.overflow = false
+	b.written = nil
+}
+
+// deleteNodes deletes trie nodes except ones written after the activation.
+// It returns the number of deleted nodes and the sum of their sizes.
+func (b *writeBarrier) deleteNodes(keys [][]byte, sizes []int64) (int64, int64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.mtx.Lock()
+	written, overflow := b.written, b.overflow
+	b.mtx.Unlock()
+	if overflow {
+		return 0, 0, errBarrierOverflow
+	}
+
+	bk, err := b.Database.GetBucket(db.MerkleTrie)
+	if err != nil {
+		return 0, 0, err
+	}
+	var deleted, reclaimed int64
+	for i, key := range keys {
+		if _, ok := written[string(key)]; ok {
+			continue
+		}
+		if err := bk.Delete(key); err != nil {
+			return deleted, reclaimed, err
+		}
+		deleted += 1
+		reclaimed += sizes[i]
+	}
+	return deleted, reclaimed, nil
+}
+
+func newWriteBarrier(dbase db.Database) *writeBarrier {
+	return &writeBarrier{
+		Database: dbase,
+		maxKeys:  maxBarrierKeys,
+		inflight: make(map[string]struct{}),
+	}
+}
+
+// nodeSetDatabase is used as a destination of merkle.CopyContext to
+// collect trie nodes. Trie nodes set to the database are recorded in the
+// store with their sizes, and it returns values from the source only for
+// recorded nodes, so that each node is visited only once.
+// Entries of other buckets are ignored.
+type nodeSetDatabase struct {
+	src   db.Bucket
+	store db.Bucket
+
+	lock  sync.Mutex
+	count int64
+}
+
+type nodeSetBucket struct {
+	*nodeSetDatabase
+}
+
+func (b nodeSetBucket) Get(key []byte) ([]byte, error) {
+	if ok, err := b.store.Has(key); err != nil || !ok {
+		return nil, err
+	}
+	return b.src.Get(key)
+}
+
+func (b nodeSetBucket) Has(key []byte) (bool, error) {
+	return b.store.Has(key)
+}
+
+func (b nodeSetBucket) Set(key []byte, value []byte) error {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(value)))
+	if err := b.store.Set(key, size[:]); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	b.count += 1
+	b.lock.Unlock()
+	return nil
+}
+
+func (b nodeSetBucket) Delete(key []byte) error {
+	return b.store.Delete(key)
+}
+
+type discardBucket struct{}
+
+func (discardBucket) Get(key []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (discardBucket) Has(key []byte) (bool, error) {
+	return false, nil
+}
+
+func (discardBucket) Set(key []byte, value []byte) error {
+	return nil
+}
+
+func (discardBucket) Delete(key []byte) error {
+	return nil
+}
+
+func (d *nodeSetDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	if id == db.MerkleTrie {
+		return nodeSetBucket{d}, nil
+	}
+	return discardBucket{}, nil
+}
+
+func (d *nodeSetDatabase) Close() error {
+	return nil
+}
+
+// Count returns the number of recorded nodes.
+func (d *nodeSetDatabase) Count() int64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.count
+}
+
+// Iterate calls fn with the key and the size of each recorded node.
+func (d *nodeSetDatabase) Iterate(fn func(key []byte, size int64) bool) error {
+	itr, ok := d.store.(db.Iterable)
+	if !ok {
+		return errors.UnsupportedError.Errorf("NotIterable(bucket=%T)", d.store)
+	}
+	return itr.Iterate(func(key, value []byte) bool {
+		if len(value) != 8 {
+			return true
+		}
+		return fn(key, int64(binary.BigEndian.Uint64(value)))
+	})
+}
+
+func newNodeSetDatabase(src db.Database, store db.Database) (*nodeSetDatabase, error) {
+	sbk, err := src.GetBucket(db.MerkleTrie)
+	if err != nil {
+		return nil, err
+	}
+	bk, err := store.GetBucket(db.MerkleTrie)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeSetDatabase{
+		src:   sbk,
+		store: bk,
+	}, nil
+}
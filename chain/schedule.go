@@ -0,0 +1,213 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package chain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	ScheduleTaskBackup = "backup"
+	ScheduleTaskPrune  = "prune"
+)
+
+// Schedule describes a maintenance task run periodically for the chain.
+// It runs on the time matching Cron, or on every multiple of Blocks.
+type Schedule struct {
+	Name   string          `json:"name"`
+	Task   string          `json:"task"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Cron   string          `json:"cron,omitempty"`
+	Blocks int64           `json:"blocks,omitempty"`
+}
+
+func (s *Schedule) Validate() error {
+	if len(s.Name) == 0 {
+		return errors.IllegalArgumentError.New("NoScheduleName")
+	}
+	switch s.Task {
+	case ScheduleTaskBackup, ScheduleTaskPrune:
+	default:
+		if _, ok := taskFactories[s.Task]; !ok {
+			return errors.IllegalArgumentError.Errorf(
+				"UnknownScheduleTask(name=%s,task=%s)", s.Name, s.Task)
+		}
+	}
+	if (len(s.Cron) == 0) == (s.Blocks <= 0) {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidScheduleTrigger(name=%s,cron=%q,blocks=%d)", s.Name, s.Cron, s.Blocks)
+	}
+	if len(s.Cron) > 0 {
+		if _, err := ParseCron(s.Cron); err != nil {
+			return errors.IllegalArgumentError.Wrapf(err, "InvalidCron(name=%s)", s.Name)
+		}
+	}
+	return nil
+}
+
+// ValidateSchedules checks schedules and the uniqueness of their names.
+func ValidateSchedules(schedules []Schedule) error {
+	names := make(map[string]bool)
+	for i := range schedules {
+		if err := schedules[i].Validate(); err != nil {
+			return err
+		}
+		if names[schedules[i].Name] {
+			return errors.IllegalArgumentError.Errorf(
+				"DuplicateScheduleName(name=%s)", schedules[i].Name)
+		}
+		names[schedules[i].Name] = true
+	}
+	return nil
+}
+
+// Cron is a parsed cron expression with five fields
+// (minute, hour, day of month, month and day of week).
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	anyDOM, anyDOW                bool
+}
+
+var cronDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+func parseCronField(s string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			v, err := strconv.Atoi(part[idx+1:])
+			if err != nil || v <= 0 {
+				return 0, errors.IllegalArgumentError.Errorf("InvalidStep(%q)", part)
+			}
+			step = v
+			part = part[:idx]
+		}
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			v, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, errors.IllegalArgumentError.Errorf("InvalidValue(%q)", part)
+			}
+			from, to = v, v
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.IllegalArgumentError.Errorf("InvalidValue(%q)", part)
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return 0, errors.IllegalArgumentError.Errorf(
+				"OutOfRange(%q,min=%d,max=%d)", part, min, max)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// ParseCron parses the cron expression. Each field supports "*", values,
+// ranges ("1-5"), lists ("1,3") and steps ("*/10"). Descriptors like
+// "@daily" and "@hourly" are also supported.
+func ParseCron(s string) (*Cron, error) {
+	if d, ok := cronDescriptors[s]; ok {
+		s = d
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidCronFields(%q)", s)
+	}
+	c := new(Cron)
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// both of 0 and 7 are Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.anyDOM = fields[2] == "*"
+	c.anyDOW = fields[4] == "*"
+	return c, nil
+}
+
+func (c *Cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDOM && c.anyDOW:
+		return true
+	case c.anyDOM:
+		return dow
+	case c.anyDOW:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first time matching the expression after t.
+// It returns zero time if there is no matching time in five years.
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
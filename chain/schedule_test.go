@@ -0,0 +1,91 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package chain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, s := range []string{
+		"* * * * *", "*/15 0-6 * * 1-5", "0 3 1,15 * *", "@daily", "5 4 * * 7",
+	} {
+		_, err := ParseCron(s)
+		assert.NoError(t, err, s)
+	}
+	for _, s := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *",
+	} {
+		_, err := ParseCron(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	base := time.Date(2023, 3, 15, 10, 20, 30, 0, time.UTC) // Wednesday
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2023, 3, 15, 10, 21, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2023, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 0", time.Date(2023, 3, 19, 2, 30, 0, 0, time.UTC)},
+		{"30 2 * * 7", time.Date(2023, 3, 19, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// either of day of month or day of week
+		{"0 0 1 * 5", time.Date(2023, 3, 17, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		spec, err := ParseCron(c.spec)
+		assert.NoError(t, err, c.spec)
+		assert.Equal(t, c.next, spec.Next(base), c.spec)
+	}
+
+	spec, err := ParseCron("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, spec.Next(base).IsZero())
+}
+
+func TestValidateSchedules(t *testing.T) {
+	assert.NoError(t, ValidateSchedules([]Schedule{
+		{Name: "backup", Task: ScheduleTaskBackup, Cron: "@daily"},
+		{Name: "prune", Task: ScheduleTaskPrune, Blocks: 100000},
+		{Name: "pause", Task: "pause", Cron: "0 3 * * *"},
+	}))
+	assert.Error(t, ValidateSchedules([]Schedule{
+		{Task: ScheduleTaskBackup, Cron: "@daily"},
+	}))
+	assert.Error(t, ValidateSchedules([]Schedule{
+		{Name: "unknown", Task: "unknown", Cron: "@daily"},
+	}))
+	assert.Error(t, ValidateSchedules([]Schedule{
+		{Name: "both", Task: ScheduleTaskBackup, Cron: "@daily", Blocks: 100},
+	}))
+	assert.Error(t, ValidateSchedules([]Schedule{
+		{Name: "none", Task: ScheduleTaskBackup},
+	}))
+	assert.Error(t, ValidateSchedules([]Schedule{
+		{Name: "backup", Task: ScheduleTaskBackup, Cron: "@daily"},
+		{Name: "backup", Task: ScheduleTaskPrune, Blocks: 100},
+	}))
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+type writerDatabase struct {
+	w     *Writer
+	cache db.Database
+}
+
+type writerBucket struct {
+	id    db.BucketID
+	w     *Writer
+	cache db.Bucket
+}
+
+func (b *writerBucket) Get(key []byte) ([]byte, error) {
+	return b.cache.Get(key)
+}
+
+func (b *writerBucket) Has(key []byte) (bool, error) {
+	return b.cache.Has(key)
+}
+
+// Set adds the entry to the snapshot unless the same value is already added.
+func (b *writerBucket) Set(key []byte, value []byte) error {
+	old, err := b.cache.Get(key)
+	if err != nil {
+		return err
+	}
+	if old != nil && bytes.Equal(old, value) {
+		return nil
+	}
+	if err := b.cache.Set(key, value); err != nil {
+		return err
+	}
+	return b.w.Add(b.id, key, value)
+}
+
+func (b *writerBucket) Delete(key []byte) error {
+	return errors.UnsupportedError.New("SnapshotWriterUnsupportDelete")
+}
+
+func (d *writerDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	bk, err := d.cache.GetBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	return &writerBucket{id: id, w: d.w, cache: bk}, nil
+}
+
+func (d *writerDatabase) Close() error {
+	return nil
+}
+
+// NewDatabaseWithWriter returns a database writing entries to the snapshot.
+// cache keeps written entries for reading them back while exporting and
+// for skipping duplicate entries. It's not closed with the database.
+func NewDatabaseWithWriter(w *Writer, cache db.Database) db.Database {
+	return &writerDatabase{w: w, cache: cache}
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package snapshot implements the file format for the state snapshot of
+// a chain at a height.
+//
+// A snapshot file starts with Magic and the format version (uint16) followed
+// by frames. Each frame is composed of
+//
+//	kind(1) | size(4) | payload(size) | checksum(32)
+//
+// where checksum is SHA3-256 of kind, size and payload. The first frame
+// has Metadata, and data frames follow it. Each data frame has a chunk of
+// entries (bucket, key, value). The last frame is the trailer having the
+// number of data frames and entries for detecting truncated files.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	Magic   = "GLSNAPSH"
+	Version = 1
+
+	DefaultChunkSize = 4 * 1024 * 1024
+	MaxFrameSize     = 64 * 1024 * 1024
+)
+
+const (
+	frameMetadata byte = iota + 1
+	frameData
+	frameTrailer
+)
+
+const (
+	frameHeaderSize = 5
+	checksumSize    = crypto.HashLen
+)
+
+var ErrInvalidSnapshot = errors.NewBase(errors.IllegalArgumentError, "InvalidSnapshot")
+
+// Metadata describes the block of the snapshot. Votes are the commit votes
+// for the block, which are used to verify the block with the validators
+// in the snapshot.
+type Metadata struct {
+	NID     int32
+	CID     int32
+	Channel string
+	Height  int64
+	BlockID []byte
+	Votes   []byte
+}
+
+type entry struct {
+	Bucket string
+	Key    []byte
+	Value  []byte
+}
+
+type trailer struct {
+	Chunks  int
+	Entries int64
+}
+
+func writeFrame(w io.Writer, kind byte, payload []byte) error {
+	buf := make([]byte, frameHeaderSize+len(payload), frameHeaderSize+len(payload)+checksumSize)
+	buf[0] = kind
+	binary.BigEndian.PutUint32(buf[1:frameHeaderSize], uint32(len(payload)))
+	copy(buf[frameHeaderSize:], payload)
+	buf = append(buf, crypto.SHA3Sum256(buf)...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, errors.Wrap(ErrInvalidSnapshot, "fail to read frame header")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxFrameSize {
+		return 0, nil, errors.Wrapf(ErrInvalidSnapshot, "TooLargeFrame(size=%d)", size)
+	}
+	buf := make([]byte, frameHeaderSize+int(size)+checksumSize)
+	copy(buf, header[:])
+	if _, err := io.ReadFull(r, buf[frameHeaderSize:]); err != nil {
+		return 0, nil, errors.Wrap(ErrInvalidSnapshot, "fail to read frame")
+	}
+	body := buf[:frameHeaderSize+int(size)]
+	if !bytes.Equal(crypto.SHA3Sum256(body), buf[len(body):]) {
+		return 0, nil, errors.Wrapf(ErrInvalidSnapshot, "InvalidChecksum(kind=%d,size=%d)", header[0], size)
+	}
+	return header[0], body[frameHeaderSize:], nil
+}
+
+// Writer writes a snapshot. Entries are buffered up to the chunk size, and
+// Close shall be called to write remaining entries and the trailer.
+type Writer struct {
+	w         io.Writer
+	chunkSize int
+	entries   []entry
+	size      int
+	chunks    int
+	count     int64
+	closed    bool
+}
+
+func NewWriter(w io.Writer, meta *Metadata) (*Writer, error) {
+	header := make([]byte, len(Magic)+2)
+	copy(header, Magic)
+	binary.BigEndian.PutUint16(header[len(Magic):], Version)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if err := writeFrame(w, frameMetadata, codec.BC.MustMarshalToBytes(meta)); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:         w,
+		chunkSize: DefaultChunkSize,
+	}, nil
+}
+
+// SetChunkSize sets the size of entries in a data frame.
+func (w *Writer) SetChunkSize(size int) {
+	if size > 0 && size <= MaxFrameSize/2 {
+		w.chunkSize = size
+	}
+}
+
+func (w *Writer) Add(bid db.BucketID, key, value []byte) error {
+	if w.closed {
+		return errors.InvalidStateError.New("SnapshotWriterClosed")
+	}
+	w.entries = append(w.entries, entry{
+		Bucket: string(bid),
+		Key:    key,
+		Value:  value,
+	})
+	w.size += len(bid) + len(key) + len(value)
+	w.count += 1
+	if w.size >= w.chunkSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *Writer) flush() error {
+	if len(w.entries) == 0 {
+		return nil
+	}
+	bs, err := codec.BC.MarshalToBytes(w.entries)
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(w.w, frameData, bs); err != nil {
+		return err
+	}
+	w.chunks += 1
+	w.entries = nil
+	w.size = 0
+	return nil
+}
+
+// Entries returns the number of entries added.
+func (w *Writer) Entries() int64 {
+	return w.count
+}
+
+// Close writes remaining entries and the trailer. It doesn't close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	return writeFrame(w.w, frameTrailer, codec.BC.MustMarshalToBytes(&trailer{
+		Chunks:  w.chunks,
+		Entries: w.count,
+	}))
+}
+
+// Reader reads entries of a snapshot after verifying checksums of frames.
+type Reader struct {
+	r       io.Reader
+	meta    Metadata
+	entries []entry
+	chunks  int
+	count   int64
+	done    bool
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	header := make([]byte, len(Magic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(ErrInvalidSnapshot, "fail to read header")
+	}
+	if string(header[:len(Magic)]) != Magic {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "InvalidMagic(magic=%q)", header[:len(Magic)])
+	}
+	if v := binary.BigEndian.Uint16(header[len(Magic):]); v != Version {
+		return nil, errors.UnsupportedError.Errorf("UnsupportedSnapshotVersion(version=%d)", v)
+	}
+	kind, payload, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	if kind != frameMetadata {
+		return nil, errors.Wrapf(ErrInvalidSnapshot, "NoMetadata(kind=%d)", kind)
+	}
+	sr := &Reader{r: r}
+	if _, err := codec.BC.UnmarshalFromBytes(payload, &sr.meta); err != nil {
+		return nil, errors.Wrap(ErrInvalidSnapshot, "fail to decode metadata")
+	}
+	return sr, nil
+}
+
+func (r *Reader) Metadata() *Metadata {
+	return &r.meta
+}
+
+func (r *Reader) readChunk() error {
+	kind, payload, err := readFrame(r.r)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case frameData:
+		var entries []entry
+		if _, err := codec.BC.UnmarshalFromBytes(payload, &entries); err != nil {
+			return errors.Wrapf(ErrInvalidSnapshot, "fail to decode chunk(idx=%d)", r.chunks)
+		}
+		r.entries = entries
+		r.chunks += 1
+		return nil
+	case frameTrailer:
+		var t trailer
+		if _, err := codec.BC.UnmarshalFromBytes(payload, &t); err != nil {
+			return errors.Wrap(ErrInvalidSnapshot, "fail to decode trailer")
+		}
+		if t.Chunks != r.chunks || t.Entries != r.count {
+			return errors.Wrapf(ErrInvalidSnapshot,
+				"MismatchTrailer(chunks=%d,entries=%d,exp_chunks=%d,exp_entries=%d)",
+				r.chunks, r.count, t.Chunks, t.Entries)
+		}
+		r.done = true
+		return nil
+	default:
+		return errors.Wrapf(ErrInvalidSnapshot, "UnknownFrame(kind=%d)", kind)
+	}
+}
+
+// Next returns the next entry. It returns io.EOF after the trailer is
+// verified.
+func (r *Reader) Next() (db.BucketID, []byte, []byte, error) {
+	for len(r.entries) == 0 {
+		if r.done {
+			return "", nil, nil, io.EOF
+		}
+		if err := r.readChunk(); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	e := r.entries[0]
+	r.entries = r.entries[1:]
+	r.count += 1
+	return db.BucketID(e.Bucket), e.Key, e.Value, nil
+}
+
+// ImportTo writes all entries to the database in order. Keys of entries
+// for the buckets with a hasher are verified with their values. cb is
+// called with the number of entries after each chunk if it's not nil.
+func (r *Reader) ImportTo(dbase db.Database, cb func(entries int64) error) error {
+	buckets := make(map[db.BucketID]db.Bucket)
+	for {
+		chunks := r.chunks
+		bid, key, value, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if cb != nil && chunks != r.chunks {
+			if err := cb(r.count - 1); err != nil {
+				return err
+			}
+		}
+		if hasher := bid.Hasher(); hasher != nil {
+			if !bytes.Equal(hasher.Hash(value), key) {
+				return errors.Wrapf(ErrInvalidSnapshot,
+					"InvalidHash(bucket=%q,key=%#x)", bid, key)
+			}
+		}
+		bk, ok := buckets[bid]
+		if !ok {
+			if bk, err = dbase.GetBucket(bid); err != nil {
+				return err
+			}
+			buckets[bid] = bk
+		}
+		if err := bk.Set(key, value); err != nil {
+			return err
+		}
+	}
+	if cb != nil {
+		return cb(r.count)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+func writeSnapshot(t *testing.T, meta *Metadata, n int) ([]byte, map[string][]byte) {
+	buf := bytes.NewBuffer(nil)
+	w, err := NewWriter(buf, meta)
+	assert.NoError(t, err)
+	w.SetChunkSize(256)
+
+	dbase := NewDatabaseWithWriter(w, db.NewMapDB())
+	mbk, err := dbase.GetBucket(db.MerkleTrie)
+	assert.NoError(t, err)
+	cbk, err := dbase.GetBucket(db.ChainProperty)
+	assert.NoError(t, err)
+
+	values := make(map[string][]byte)
+	for i := 0; i < n; i++ {
+		v := []byte(fmt.Sprintf("value-%d", i))
+		k := crypto.SHA3Sum256(v)
+		assert.NoError(t, mbk.Set(k, v))
+		// duplicate entries are written only once
+		assert.NoError(t, mbk.Set(k, v))
+		values[string(k)] = v
+	}
+	assert.NoError(t, cbk.Set([]byte("last"), []byte{1}))
+	assert.NoError(t, cbk.Set([]byte("last"), []byte{2}))
+	assert.EqualValues(t, n+2, w.Entries())
+	assert.NoError(t, w.Close())
+	return buf.Bytes(), values
+}
+
+func TestSnapshot_Basic(t *testing.T) {
+	meta := &Metadata{
+		NID:     1,
+		CID:     0x1234,
+		Channel: "test",
+		Height:  10,
+		BlockID: crypto.SHA3Sum256([]byte("block")),
+		Votes:   []byte("votes"),
+	}
+	bs, values := writeSnapshot(t, meta, 100)
+
+	r, err := NewReader(bytes.NewReader(bs))
+	assert.NoError(t, err)
+	assert.Equal(t, meta, r.Metadata())
+
+	dbase := db.NewMapDB()
+	var progress int64
+	err = r.ImportTo(dbase, func(entries int64) error {
+		progress = entries
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 102, progress)
+
+	mbk, _ := dbase.GetBucket(db.MerkleTrie)
+	for k, v := range values {
+		v2, err := mbk.Get([]byte(k))
+		assert.NoError(t, err)
+		assert.Equal(t, v, v2)
+	}
+	cbk, _ := dbase.GetBucket(db.ChainProperty)
+	v, err := cbk.Get([]byte("last"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2}, v)
+
+	_, _, _, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSnapshot_Invalid(t *testing.T) {
+	meta := &Metadata{Height: 1}
+	bs, _ := writeSnapshot(t, meta, 20)
+
+	t.Run("magic", func(t *testing.T) {
+		bs2 := append([]byte{}, bs...)
+		bs2[0] = 'X'
+		_, err := NewReader(bytes.NewReader(bs2))
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+
+	t.Run("version", func(t *testing.T) {
+		bs2 := append([]byte{}, bs...)
+		bs2[len(Magic)+1] = Version + 1
+		_, err := NewReader(bytes.NewReader(bs2))
+		assert.True(t, errors.UnsupportedError.Equals(err))
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		bs2 := append([]byte{}, bs...)
+		bs2[len(bs2)/2] ^= 0xff
+		r, err := NewReader(bytes.NewReader(bs2))
+		assert.NoError(t, err)
+		err = r.ImportTo(db.NewMapDB(), nil)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(bs[:len(bs)-10]))
+		assert.NoError(t, err)
+		err = r.ImportTo(db.NewMapDB(), nil)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		w, err := NewWriter(buf, meta)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Add(db.MerkleTrie, []byte("key"), []byte("value")))
+		assert.NoError(t, w.Close())
+
+		r, err := NewReader(buf)
+		assert.NoError(t, err)
+		err = r.ImportTo(db.NewMapDB(), nil)
+		assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	})
+}
@@ -0,0 +1,398 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package chain
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/service"
+)
+
+const (
+	statePruneInterval  = 10 * time.Second
+	statePruneMinBlocks = 1000
+	statePruneMaxBlocks = 10000
+	statePruneBatchSize = 1024
+	statePruneDir       = "prune"
+
+	keyStatePrunedHeight = "state.prunedHeight"
+)
+
+const (
+	prunePhaseIdle    = "idle"
+	prunePhaseCollect = "collecting"
+	prunePhaseMark    = "marking"
+	prunePhaseSweep   = "sweeping"
+	prunePhaseFailed  = "failed"
+)
+
+// statePruner deletes trie nodes reachable only from the states of old
+// blocks while the chain is running. States of the last N blocks and the
+// pinned heights are kept.
+//
+// Each cycle collects nodes of the states in [pruned, target), then marks
+// nodes of the states in [target, last] and the pinned heights. Collected
+// nodes without marks are deleted except ones written during the cycle,
+// which are tracked by writeBarrier.
+type statePruner struct {
+	chain     *singleChain
+	barrier   *writeBarrier
+	retention int64
+	pinned    []int64
+	minBlocks int64
+	maxBlocks int64
+	metric    *metric.PruningMetric
+
+	// onPhase is called on each phase change if it's set.
+	onPhase func(phase string)
+
+	stop chan struct{}
+	done chan struct{}
+
+	lock       sync.Mutex
+	phase      string
+	pruned     int64
+	collected  int64
+	marked     int64
+	deleted    int64
+	reclaimed  int64
+	collectSet *nodeSetDatabase
+	markSet    *nodeSetDatabase
+	lastErr    error
+}
+
+func (p *statePruner) Detail() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	collected, marked := p.collected, p.marked
+	if p.collectSet != nil {
+		collected = p.collectSet.Count()
+	}
+	if p.markSet != nil {
+		marked = p.markSet.Count()
+	}
+	phase := p.phase
+	if p.lastErr != nil {
+		phase = fmt.Sprintf("%s(%v)", phase, p.lastErr)
+	}
+	return fmt.Sprintf("state pruning %s pruned=%d collected=%d marked=%d deleted=%d reclaimed=%d",
+		phase, p.pruned, collected, marked, p.deleted, p.reclaimed)
+}
+
+func (p *statePruner) setPhase(phase string, err error) {
+	p.lock.Lock()
+	p.phase = phase
+	p.lastErr = err
+	p.lock.Unlock()
+
+	if p.onPhase != nil {
+		p.onPhase(phase)
+	}
+}
+
+func (p *statePruner) setNodeSets(collect, mark *nodeSetDatabase) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.collectSet != nil {
+		p.collected = p.collectSet.Count()
+	}
+	if p.markSet != nil {
+		p.marked = p.markSet.Count()
+	}
+	p.collectSet, p.markSet = collect, mark
+}
+
+func (p *statePruner) onDelete(deleted, reclaimed int64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.deleted += deleted
+	p.reclaimed += reclaimed
+}
+
+func (p *statePruner) interrupted() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *statePruner) onProgress(height int64, resolved, unresolved int) error {
+	if p.interrupted() {
+		return errors.ErrInterrupted
+	}
+	return nil
+}
+
+func (p *statePruner) Start() {
+	go p.run()
+}
+
+func (p *statePruner) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+func (p *statePruner) run() {
+	defer close(p.done)
+
+	c := p.chain
+	pruned, err := p.loadPrunedHeight()
+	if err != nil {
+		c.logger.Warnf("StatePruner: fail to load pruned height err=%+v", err)
+		p.setPhase(prunePhaseFailed, err)
+		return
+	}
+	p.lock.Lock()
+	p.pruned = pruned
+	p.lock.Unlock()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(statePruneInterval):
+		}
+		if err := p.prune(); err != nil {
+			if errors.InterruptedError.Equals(err) {
+				return
+			}
+			c.logger.Warnf("StatePruner: fail to prune err=%+v", err)
+			p.setPhase(prunePhaseFailed, err)
+		}
+	}
+}
+
+func (p *statePruner) loadPrunedHeight() (int64, error) {
+	c := p.chain
+	bk, err := c.database.GetBucket(db.ChainProperty)
+	if err != nil {
+		return 0, err
+	}
+	bs, err := bk.Get([]byte(keyStatePrunedHeight))
+	if err != nil {
+		return 0, err
+	}
+	if bs != nil {
+		var height int64
+		if _, err := codec.BC.UnmarshalFromBytes(bs, &height); err != nil {
+			return 0, err
+		}
+		return height, nil
+	}
+
+	// blocks before the genesis may not exist if the chain was pruned or
+	// imported from a snapshot.
+	last := block.GetLastHeightOf(c.database)
+	lowest := sort.Search(int(last), func(h int) bool {
+		_, err := c.bm.GetBlockByHeight(int64(h))
+		return err == nil
+	})
+	return int64(lowest), nil
+}
+
+func (p *statePruner) storePrunedHeight(height int64) error {
+	bk, err := p.chain.database.GetBucket(db.ChainProperty)
+	if err != nil {
+		return err
+	}
+	return bk.Set([]byte(keyStatePrunedHeight), codec.BC.MustMarshalToBytes(height))
+}
+
+func (p *statePruner) exportState(height int64, dst db.Database) error {
+	c := p.chain
+	blk, err := c.bm.GetBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	return service.ExportState(c.plt, c.database, blk.Result(),
+		blk.NextValidatorsHash(), dst, p.onProgress)
+}
+
+func (p *statePruner) openNodeSet(dir, name string) (*nodeSetDatabase, func(), error) {
+	c := p.chain
+	store, err := db.Open(dir, c.cfg.DBType, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes, err := newNodeSetDatabase(c.database, store)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+	return nodes, func() { store.Close() }, nil
+}
+
+func (p *statePruner) prune() error {
+	c := p.chain
+	p.lock.Lock()
+	pruned := p.pruned
+	p.lock.Unlock()
+
+	target := block.GetLastHeightOf(c.database) - p.retention + 1
+	if target > pruned+p.maxBlocks {
+		target = pruned + p.maxBlocks
+	}
+	if target-pruned < p.minBlocks {
+		return nil
+	}
+
+	if !p.barrier.activate() {
+		c.logger.Infof("StatePruner: skip pruning for too many writes")
+		return nil
+	}
+	defer p.barrier.deactivate()
+
+	// states finalized before the activation are marked, and nodes written
+	// after the activation are kept by the barrier.
+	last := block.GetLastHeightOf(c.database)
+
+	dir := path.Join(c.cfg.AbsBaseDir(), DefaultTmpDBDir, statePruneDir)
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	collectSet, closeCollect, err := p.openNodeSet(dir, "collect")
+	if err != nil {
+		return err
+	}
+	defer closeCollect()
+	markSet, closeMark, err := p.openNodeSet(dir, "mark")
+	if err != nil {
+		return err
+	}
+	defer closeMark()
+	p.setNodeSets(collectSet, markSet)
+	defer p.setNodeSets(nil, nil)
+
+	c.logger.Infof("StatePruner: prune states from=%d to=%d last=%d",
+		pruned, target-1, last)
+
+	p.setPhase(prunePhaseCollect, nil)
+	for h := pruned; h < target; h++ {
+		// collected nodes are deleted only if they are not marked, so
+		// it's safe to skip missing nodes of old states.
+		if err := p.exportState(h, collectSet); err != nil {
+			if !errors.NotFoundError.Equals(err) {
+				return err
+			}
+			c.logger.Debugf("StatePruner: skip missing state height=%d err=%v", h, err)
+		}
+	}
+
+	p.setPhase(prunePhaseMark, nil)
+	for h := target; h <= last; h++ {
+		if err := p.exportState(h, markSet); err != nil {
+			return errors.Wrapf(err, "fail to mark state height=%d", h)
+		}
+	}
+	for _, h := range p.pinned {
+		if h >= target {
+			continue
+		}
+		if err := p.exportState(h, markSet); err != nil {
+			return errors.Wrapf(err, "fail to mark pinned state height=%d", h)
+		}
+	}
+
+	p.setPhase(prunePhaseSweep, nil)
+	var deleted, reclaimed int64
+	keys := make([][]byte, 0, statePruneBatchSize)
+	sizes := make([]int64, 0, statePruneBatchSize)
+	flush := func() error {
+		d, r, err := p.barrier.deleteNodes(keys, sizes)
+		deleted += d
+		reclaimed += r
+		p.onDelete(d, r)
+		keys, sizes = keys[:0], sizes[:0]
+		return err
+	}
+	markBK, err := markSet.GetBucket(db.MerkleTrie)
+	if err != nil {
+		return err
+	}
+	var sweepErr error
+	err = collectSet.Iterate(func(key []byte, size int64) bool {
+		if marked, err := markBK.Has(key); err != nil || marked {
+			sweepErr = err
+			return err == nil
+		}
+		keys = append(keys, append([]byte(nil), key...))
+		sizes = append(sizes, size)
+		if len(keys) >= statePruneBatchSize {
+			if sweepErr = flush(); sweepErr != nil {
+				return false
+			}
+			if p.interrupted() {
+				sweepErr = errors.ErrInterrupted
+				return false
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = sweepErr
+	}
+	if err == nil && len(keys) > 0 {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := p.storePrunedHeight(target); err != nil {
+		return err
+	}
+	p.lock.Lock()
+	p.pruned = target
+	p.lock.Unlock()
+	p.metric.OnPrune(target, deleted, reclaimed)
+	p.setPhase(prunePhaseIdle, nil)
+	c.logger.Infof("StatePruner: pruned states to=%d deleted=%d reclaimed=%d",
+		target-1, deleted, reclaimed)
+	return nil
+}
+
+func newStatePruner(c *singleChain, barrier *writeBarrier) *statePruner {
+	return &statePruner{
+		chain:     c,
+		barrier:   barrier,
+		retention: c.cfg.StateRetention,
+		pinned:    c.cfg.StatePinned,
+		minBlocks: statePruneMinBlocks,
+		maxBlocks: statePruneMaxBlocks,
+		metric:    metric.NewPruningMetric(c.metricCtx),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		phase:     prunePhaseIdle,
+	}
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/test"
+)
+
+func setLastHeight(t *testing.T, dbase db.Database, height int64) {
+	bk, err := dbase.GetBucket(db.ChainProperty)
+	assert.NoError(t, err)
+	err = bk.Set([]byte(block.KeyLastBlockHeight), codec.BC.MustMarshalToBytes(height))
+	assert.NoError(t, err)
+}
+
+func setNodes(t *testing.T, dbase db.Database, keys ...string) {
+	bk, err := dbase.GetBucket(db.MerkleTrie)
+	assert.NoError(t, err)
+	for _, k := range keys {
+		assert.NoError(t, bk.Set([]byte(k), []byte("value of "+k)))
+	}
+}
+
+func hasNode(t *testing.T, dbase db.Database, key string) bool {
+	bk, err := dbase.GetBucket(db.MerkleTrie)
+	assert.NoError(t, err)
+	ok, err := bk.Has([]byte(key))
+	assert.NoError(t, err)
+	return ok
+}
+
+func deleteNodes(b *writeBarrier, keys ...string) (int64, error) {
+	var bks [][]byte
+	var sizes []int64
+	for _, k := range keys {
+		bks = append(bks, []byte(k))
+		sizes = append(sizes, 1)
+	}
+	deleted, _, err := b.deleteNodes(bks, sizes)
+	return deleted, err
+}
+
+func TestWriteBarrier_KeepsWrittenAfterFinalization(t *testing.T) {
+	b := newWriteBarrier(db.NewMapDB())
+
+	setNodes(t, b, "n1")
+	setLastHeight(t, b, 1)
+	setNodes(t, b, "n2")
+
+	// n2 is written after the last finalization, so it may belong to
+	// the block not yet finalized.
+	assert.True(t, b.activate())
+	setNodes(t, b, "n3")
+
+	deleted, err := deleteNodes(b, "n1", "n2", "n3")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	assert.False(t, hasNode(t, b, "n1"))
+	assert.True(t, hasNode(t, b, "n2"))
+	assert.True(t, hasNode(t, b, "n3"))
+
+	// written nodes are not tracked after deactivation
+	b.deactivate()
+	setLastHeight(t, b, 2)
+	assert.True(t, b.activate())
+	deleted, err = deleteNodes(b, "n2", "n3")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+	b.deactivate()
+}
+
+func TestWriteBarrier_Overflow(t *testing.T) {
+	b := newWriteBarrier(db.NewMapDB())
+	b.maxKeys = 2
+
+	// too many writes after the last finalization
+	setNodes(t, b, "n1", "n2", "n3")
+	assert.False(t, b.activate())
+	setLastHeight(t, b, 1)
+	assert.True(t, b.activate())
+
+	// too many writes after the activation
+	setNodes(t, b, "n4", "n5", "n6")
+	_, err := deleteNodes(b, "n1", "n2", "n3")
+	assert.Equal(t, errBarrierOverflow, err)
+	assert.True(t, hasNode(t, b, "n1"))
+	b.deactivate()
+
+	setLastHeight(t, b, 2)
+	assert.True(t, b.activate())
+	deleted, err := deleteNodes(b, "n1", "n2", "n3")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, deleted)
+	b.deactivate()
+}
+
+func TestNodeSetDatabase(t *testing.T) {
+	src := db.NewMapDB()
+	setNodes(t, src, "n1", "n2")
+
+	nodes, err := newNodeSetDatabase(src, db.NewMapDB())
+	assert.NoError(t, err)
+	bk, err := nodes.GetBucket(db.MerkleTrie)
+	assert.NoError(t, err)
+
+	// nodes are visited only after they are set
+	v, err := bk.Get([]byte("n1"))
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+	assert.NoError(t, bk.Set([]byte("n1"), []byte("value of n1")))
+	v, err = bk.Get([]byte("n1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value of n1"), v)
+	ok, err := bk.Has([]byte("n2"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// entries of other buckets are ignored
+	pbk, err := nodes.GetBucket(db.ChainProperty)
+	assert.NoError(t, err)
+	assert.NoError(t, pbk.Set([]byte("key"), []byte("value")))
+
+	assert.EqualValues(t, 1, nodes.Count())
+	sizes := make(map[string]int64)
+	err = nodes.Iterate(func(key []byte, size int64) bool {
+		sizes[string(key)] = size
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"n1": int64(len("value of n1"))}, sizes)
+}
+
+type prunerTest struct {
+	*testing.T
+	node    *test.Node
+	barrier *writeBarrier
+	pruner  *statePruner
+}
+
+func newPrunerTest(t *testing.T, retention int64, pinned ...int64) *prunerTest {
+	barrier := newWriteBarrier(db.NewMapDB())
+	nd := test.NewNode(t, test.UseDB(barrier))
+	t.Cleanup(nd.Close)
+	c := &singleChain{
+		database: barrier,
+		barrier:  barrier,
+		bm:       nd.BM,
+		plt:      nd.Platform,
+		cfg: Config{
+			BaseDir:        t.TempDir(),
+			DBType:         string(db.GoLevelDBBackend),
+			StateRetention: retention,
+			StatePinned:    pinned,
+		},
+		logger:    nd.Chain.Logger(),
+		metricCtx: context.Background(),
+	}
+	p := newStatePruner(c, barrier)
+	p.minBlocks = 1
+	return &prunerTest{t, nd, barrier, p}
+}
+
+// finalizeBlock finalizes a block with the transaction setting the value of
+// the test variable, so that each value makes its own trie nodes.
+func (t *prunerTest) finalizeBlock(value string) {
+	blk := t.node.GetLastBlock()
+	tx := test.NewTx().SetTimestamp(blk.Timestamp() + blk.Height()).SetVarTest(&value)
+	t.node.ProposeFinalizeBlockWithTX(consensus.NewEmptyCommitVoteList(), tx.String())
+}
+
+func (t *prunerTest) isReadable(height int64) bool {
+	return t.pruner.exportState(height, db.NewMapDB()) == nil
+}
+
+func (t *prunerTest) assertReadable(from, to int64, pinned ...int64) {
+	for h := from; h <= to; h++ {
+		assert.True(t, t.isReadable(h), "state of height=%d", h)
+	}
+	for _, h := range pinned {
+		assert.True(t, t.isReadable(h), "state of pinned height=%d", h)
+	}
+}
+
+func TestStatePruner_Prune(t *testing.T) {
+	pt := newPrunerTest(t, 5, 3)
+	for i := 0; i < 20; i++ {
+		pt.finalizeBlock(fmt.Sprintf("value%d", i))
+	}
+	last := pt.node.LastBlock.Height()
+	target := last - 5 + 1
+
+	assert.NoError(t, pt.pruner.prune())
+	assert.Contains(t, pt.pruner.Detail(), prunePhaseIdle)
+	assert.NotZero(t, pt.pruner.deleted)
+
+	pt.assertReadable(target, last, 3)
+	assert.False(t, pt.isReadable(target-1))
+	assert.False(t, pt.isReadable(5))
+
+	pruned, err := pt.pruner.loadPrunedHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, target, pruned)
+
+	// nothing to prune until enough blocks are finalized
+	pt.pruner.pruned = pruned
+	pt.pruner.minBlocks = 3
+	pt.finalizeBlock("value20")
+	deleted := pt.pruner.deleted
+	assert.NoError(t, pt.pruner.prune())
+	assert.Equal(t, deleted, pt.pruner.deleted)
+	pt.assertReadable(target, pt.node.LastBlock.Height(), 3)
+}
+
+func TestStatePruner_FinalizeDuringPrune(t *testing.T) {
+	pt := newPrunerTest(t, 5, 2)
+	for i := 0; i < 20; i++ {
+		pt.finalizeBlock(fmt.Sprintf("value%d", i))
+	}
+	target := pt.node.LastBlock.Height() - 5 + 1
+
+	// values of the states to be pruned are set again, so nodes identical
+	// to the collected ones are written during the cycle.
+	recreated := map[string]string{
+		prunePhaseCollect: "value4",
+		prunePhaseMark:    "value6",
+		prunePhaseSweep:   "value8",
+	}
+	pt.pruner.onPhase = func(phase string) {
+		if value, ok := recreated[phase]; ok {
+			pt.finalizeBlock(value)
+			pt.finalizeBlock(fmt.Sprintf("%s-%s", phase, value))
+		}
+	}
+	assert.NoError(t, pt.pruner.prune())
+	pt.pruner.onPhase = nil
+	assert.NotZero(t, pt.pruner.deleted)
+
+	last := pt.node.LastBlock.Height()
+	pt.assertReadable(target, last, 2)
+
+	// blocks are built on the states written during the cycle
+	for i := 0; i < 3; i++ {
+		pt.finalizeBlock(fmt.Sprintf("value%d", 30+i))
+	}
+	pt.assertReadable(target, pt.node.LastBlock.Height(), 2)
+}
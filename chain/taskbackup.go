@@ -18,6 +18,8 @@ package chain
 
 import (
 	"archive/zip"
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,13 +29,24 @@ import (
 	"path"
 	"sort"
 	"sync/atomic"
+	"time"
 
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/snapshot"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
 )
 
-const TemporalBackupFile = ".backup"
+const (
+	TemporalBackupFile = ".backup"
+
+	// IncrementalBackupEntry is the name of the entry in an incremental
+	// backup containing the delta in the snapshot format.
+	IncrementalBackupEntry = "delta.snapshot"
+)
 
 type BackupInfo struct {
 	NID     common.HexInt32 `json:"nid"`
@@ -41,6 +54,22 @@ type BackupInfo struct {
 	Channel string          `json:"channel"`
 	Height  int64           `json:"height"`
 	Codec   string          `json:"codec"`
+	Online  bool            `json:"online,omitempty"`
+
+	// BlockID is the ID of the last block in the backup.
+	BlockID common.HexBytes `json:"block_id,omitempty"`
+
+	// Base is the height of the base backup for an incremental backup.
+	// It's zero for a full backup.
+	Base int64 `json:"base,omitempty"`
+
+	// BaseID is the ID of the block at the base height for an incremental
+	// backup. The delta is applied only on the same block.
+	BaseID common.HexBytes `json:"base_id,omitempty"`
+}
+
+func (info *BackupInfo) IsIncremental() bool {
+	return info.Base > 0
 }
 
 var backupStates = map[State]string{
@@ -54,6 +83,8 @@ type taskBackup struct {
 	chain   *singleChain
 	file    string
 	extra   []string
+	online  bool
+	base    int64
 	fd      io.WriteCloser
 	zw      *zip.Writer
 	current int32
@@ -63,6 +94,12 @@ type taskBackup struct {
 }
 
 func (t *taskBackup) String() string {
+	if t.base > 0 {
+		return fmt.Sprintf("IncrementalBackup(file=%s,base=%d)", path.Base(t.file), t.base)
+	}
+	if t.online {
+		return fmt.Sprintf("OnlineBackup(file=%s)", path.Base(t.file))
+	}
 	return fmt.Sprintf("Backup(file=%s)", path.Base(t.file))
 }
 
@@ -111,20 +148,38 @@ func (t *taskBackup) Start() (ret error) {
 	t.fd = tmp
 	t.zw = zip.NewWriter(tmp)
 
-	if err := writeBackupInfo(t.zw, &BackupInfo{
-		NID:     common.HexInt32{Value: int32(t.chain.NID())},
-		CID:     common.HexInt32{Value: int32(t.chain.CID())},
-		Channel: t.chain.Channel(),
-		Height:  t.chain.lastBlockHeight(),
-		Codec:   codec.BC.Name(),
-	}); err != nil {
-		return err
-	}
+	backup := t._backupOnline
+	if t.base > 0 {
+		if err := t.chain.prepareManagers(); err != nil {
+			return err
+		}
+		backup = func() error {
+			defer t.chain.releaseManagers()
+			return t._backupIncremental()
+		}
+	} else if !t.online {
+		height := t.chain.lastBlockHeight()
+		id, err := blockIDByHeight(t.chain.database, height)
+		if err != nil {
+			return err
+		}
+		if err := writeBackupInfo(t.zw, &BackupInfo{
+			NID:     common.HexInt32{Value: int32(t.chain.NID())},
+			CID:     common.HexInt32{Value: int32(t.chain.CID())},
+			Channel: t.chain.Channel(),
+			Height:  height,
+			Codec:   codec.BC.Name(),
+			BlockID: id,
+		}); err != nil {
+			return err
+		}
 
-	t.chain.releaseDatabase()
+		t.chain.releaseDatabase()
+		backup = t._backup
+	}
 
 	go func() {
-		err := t._backup()
+		err := backup()
 		if err == nil {
 			err = os.Rename(tmp.Name(), t.file)
 		}
@@ -269,6 +324,191 @@ func (t *taskBackup) _backup() error {
 	return nil
 }
 
+// _checkpoint makes a consistent copy of the database of the chain in
+// the directory, then it returns the last block height and its ID of
+// the copy.
+func (t *taskBackup) _checkpoint(dir string) (int64, []byte, error) {
+	c := t.chain
+	dbDir := path.Join(dir, DefaultDBDir)
+	var cp db.CheckpointWriter
+	var err error
+	// only taking the view blocks releasing the database. entries are
+	// copied without the lock, so interruption stops it on termination.
+	c.DoDBTask(func(dbase db.Database) {
+		if dbase == nil {
+			err = errors.InvalidStateError.New("DatabaseReleased")
+			return
+		}
+		cp, err = db.Checkpoint(dbase, dbDir)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	err = cp.Write(t._isInterrupted)
+	cp.Release()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	dbase, err := c.openDatabase(dbDir, c.cfg.DBType)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer dbase.Close()
+	height, err := block.GetLastHeight(dbase)
+	if err != nil {
+		return 0, nil, err
+	}
+	id, err := blockIDByHeight(dbase, height)
+	if err != nil {
+		return 0, nil, err
+	}
+	return height, id, nil
+}
+
+// _backupOnline writes a checkpoint of the database with extra files
+// while the chain is running. WAL and contract directories are not
+// included. They are not required to restore the chain.
+func (t *taskBackup) _backupOnline() error {
+	defer t.fd.Close()
+	defer t.zw.Close()
+
+	chainDir := t.chain.cfg.AbsBaseDir()
+	cpDir, err := ioutil.TempDir(chainDir, TemporalBackupFile)
+	if err != nil {
+		return errors.Wrap(err, "Fail to make temporal directory")
+	}
+	defer os.RemoveAll(cpDir)
+
+	height, id, err := t._checkpoint(cpDir)
+	if err != nil {
+		return err
+	}
+	if err := writeBackupInfo(t.zw, &BackupInfo{
+		NID:     common.HexInt32{Value: int32(t.chain.NID())},
+		CID:     common.HexInt32{Value: int32(t.chain.CID())},
+		Channel: t.chain.Channel(),
+		Height:  height,
+		Codec:   codec.BC.Name(),
+		Online:  true,
+		BlockID: id,
+	}); err != nil {
+		return err
+	}
+
+	dbCount, err := countFiles(path.Join(cpDir, DefaultDBDir))
+	if err != nil {
+		return err
+	}
+	extraCount, err := t._countFiles(chainDir, t.extra)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&t.total, int32(dbCount+extraCount))
+
+	if err := zipWrite(t.zw, cpDir, DefaultDBDir, t.OnWrite); err != nil {
+		return err
+	}
+	for _, name := range t.extra {
+		if err := zipWrite(t.zw, chainDir, name, t.OnWrite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *taskBackup) onExport(height int64, _, _ int) error {
+	if t._isInterrupted() {
+		return errors.ErrInterrupted
+	}
+	atomic.StoreInt32(&t.current, int32(height-t.base))
+	return nil
+}
+
+// _backupIncremental writes blocks, receipts and trie nodes added after
+// the base height. Entries reachable from the base height are exported
+// to a temporal database first, and they are skipped while it writes
+// the delta.
+func (t *taskBackup) _backupIncremental() error {
+	c := t.chain
+	defer t.fd.Close()
+	defer t.zw.Close()
+
+	blk, err := c.bm.GetLastBlock()
+	if err != nil {
+		return err
+	}
+	height := blk.Height()
+	if height <= t.base {
+		return errors.InvalidStateError.Errorf(
+			"NoBlocksAfterBase(base=%d,height=%d)", t.base, height)
+	}
+	baseBlk, err := c.bm.GetBlockByHeight(t.base)
+	if err != nil {
+		return err
+	}
+	if err := writeBackupInfo(t.zw, &BackupInfo{
+		NID:     common.HexInt32{Value: int32(c.NID())},
+		CID:     common.HexInt32{Value: int32(c.CID())},
+		Channel: c.Channel(),
+		Height:  height,
+		Codec:   codec.BC.Name(),
+		BlockID: blk.ID(),
+		Base:    t.base,
+		BaseID:  baseBlk.ID(),
+	}); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&t.total, int32(height-t.base))
+
+	cacheDir := path.Join(c.cfg.AbsBaseDir(), DefaultTmpDBDir)
+	_ = os.RemoveAll(cacheDir)
+	cache, err := c.openDatabase(cacheDir, c.cfg.DBType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Must(cache.Close())
+		log.Must(os.RemoveAll(cacheDir))
+	}()
+	if err := c.bm.ExportBlocks(t.base, t.base, cache, func(int64, int, int) error {
+		if t._isInterrupted() {
+			return errors.ErrInterrupted
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrapf(err, "fail to export base height=%d", t.base)
+	}
+
+	zf, err := t.zw.CreateHeader(&zip.FileHeader{
+		Name:     IncrementalBackupEntry,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(zf)
+	sw, err := snapshot.NewWriter(bw, &snapshot.Metadata{
+		NID:     int32(c.NID()),
+		CID:     int32(c.CID()),
+		Channel: c.Channel(),
+		Height:  height,
+		BlockID: blk.ID(),
+	})
+	if err != nil {
+		return err
+	}
+	dbase := snapshot.NewDatabaseWithWriter(sw, cache)
+	if err := c.bm.ExportBlocks(t.base+1, height, dbase, t.onExport); err != nil {
+		return err
+	}
+	if err := sw.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
 func (t *taskBackup) Stop() {
 	if t.file == "" {
 		// if it's manual backup we need to recover database
@@ -291,6 +531,35 @@ func newTaskBackup(chain *singleChain, file string, extra []string) chainTask {
 	}
 }
 
+func newTaskOnlineBackup(chain *singleChain, file string, extra []string) chainTask {
+	return &taskBackup{
+		chain:  chain,
+		file:   file,
+		extra:  extra,
+		online: true,
+	}
+}
+
+func newTaskIncrementalBackup(chain *singleChain, file string, base int64) chainTask {
+	return &taskBackup{
+		chain: chain,
+		file:  file,
+		base:  base,
+	}
+}
+
+func blockIDByHeight(dbase db.Database, height int64) ([]byte, error) {
+	id, err := db.DoGetWithBucketID(dbase, db.BlockHeaderHashByHeight,
+		codec.BC.MustMarshalToBytes(height))
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		return nil, errors.NotFoundError.Errorf("NoBlock(height=%d)", height)
+	}
+	return id, nil
+}
+
 func writeBackupInfo(zw *zip.Writer, info *BackupInfo) error {
 	bs, err := json.Marshal(info)
 	if err != nil {
@@ -324,3 +593,89 @@ func ReadBackupInfo(zr *zip.Reader) (*BackupInfo, error) {
 	}
 	return info, nil
 }
+
+// ApplyIncrementalBackup writes the delta of the incremental backup to
+// the database, which should be restored up to the base height of it.
+func ApplyIncrementalBackup(zr *zip.Reader, dbase db.Database) error {
+	info, err := ReadBackupInfo(zr)
+	if err != nil {
+		return err
+	}
+	if !info.IsIncremental() {
+		return errors.IllegalArgumentError.New("NotIncrementalBackup")
+	}
+	if len(info.BaseID) == 0 {
+		return errors.IllegalArgumentError.New("NoBaseBlockID")
+	}
+	if height, err := block.GetLastHeight(dbase); err != nil {
+		return err
+	} else if height != info.Base {
+		return errors.InvalidStateError.Errorf(
+			"InvalidBaseHeight(base=%d,height=%d)", info.Base, height)
+	}
+	baseID, err := blockIDByHeight(dbase, info.Base)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(baseID, info.BaseID) {
+		return errors.InvalidStateError.Errorf(
+			"BaseBlockMismatch(base=%d,exp=%#x,real=%#x)",
+			info.Base, []byte(info.BaseID), baseID)
+	}
+
+	var entry *zip.File
+	for _, f := range zr.File {
+		if f.Name == IncrementalBackupEntry {
+			entry = f
+			break
+		}
+	}
+	if entry == nil {
+		return errors.NotFoundError.Errorf("NoDeltaEntry(name=%s)", IncrementalBackupEntry)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	sr, err := snapshot.NewReader(bufio.NewReader(rc))
+	if err != nil {
+		return err
+	}
+	meta := sr.Metadata()
+	if meta.Height != info.Height || int(meta.NID) != int(info.NID.Value) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidDeltaMetadata(height=%d,nid=%#x,exp_height=%d,exp_nid=%#x)",
+			meta.Height, meta.NID, info.Height, info.NID.Value)
+	}
+	if err := sr.ImportTo(dbase, nil); err != nil {
+		return err
+	}
+
+	// the delta should follow the base block
+	hb, err := db.DoGetWithBucketID(dbase, db.BlockHeaderHashByHeight,
+		codec.BC.MustMarshalToBytes(info.Base+1))
+	if err != nil {
+		return err
+	}
+	bs, err := db.DoGetWithBucketID(dbase, db.BytesByHash, hb)
+	if err != nil || bs == nil {
+		return errors.NotFoundError.Wrapf(err, "NoBlockHeader(height=%d)", info.Base+1)
+	}
+	hdr, err := block.NewHeaderFromBytes(bs)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hdr.PrevID(), baseID) {
+		return errors.InvalidStateError.Errorf(
+			"BaseBlockMismatch(base=%d,exp=%#x,real=%#x)",
+			info.Base, baseID, hdr.PrevID())
+	}
+	if height, err := block.GetLastHeight(dbase); err != nil {
+		return err
+	} else if height != info.Height {
+		return errors.InvalidStateError.Errorf(
+			"InvalidLastHeight(exp=%d,real=%d)", info.Height, height)
+	}
+	return nil
+}
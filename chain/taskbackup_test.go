@@ -0,0 +1,189 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/test"
+)
+
+type backupTest struct {
+	*testing.T
+	node  *test.Node
+	chain *singleChain
+}
+
+func newBackupTest(t *testing.T) *backupTest {
+	nd := test.NewNode(t)
+	t.Cleanup(nd.Close)
+	c := &singleChain{
+		database: nd.Chain.Database(),
+		bm:       nd.BM,
+		plt:      nd.Platform,
+		cfg: Config{
+			NID:     nd.Chain.NID(),
+			Channel: "test",
+			BaseDir: t.TempDir(),
+			DBType:  string(db.GoLevelDBBackend),
+		},
+		logger:    nd.Chain.Logger(),
+		metricCtx: context.Background(),
+	}
+	return &backupTest{t, nd, c}
+}
+
+// finalizeBlocks finalizes blocks with the transactions setting the value
+// of the test variable.
+func (t *backupTest) finalizeBlocks(n int, value string) {
+	for i := 0; i < n; i++ {
+		blk := t.node.GetLastBlock()
+		tx := test.NewTx().SetTimestamp(blk.Timestamp() + blk.Height()).SetVarTest(&value)
+		t.node.ProposeFinalizeBlockWithTX(consensus.NewEmptyCommitVoteList(), tx.String())
+	}
+}
+
+// fullBackup returns a database with blocks and states up to the last
+// block as a restored full backup.
+func (t *backupTest) fullBackup() db.Database {
+	dbase := db.NewMapDB()
+	last := t.node.GetLastBlock().Height()
+	assert.NoError(t, t.node.BM.ExportBlocks(0, last, dbase, nil))
+	return dbase
+}
+
+// incrementalBackup makes an incremental backup from the base height and
+// returns the reader of it.
+func (t *backupTest) incrementalBackup(base int64) *zip.Reader {
+	file := path.Join(t.TempDir(), "backup.zip")
+	fd, err := os.Create(file)
+	assert.NoError(t, err)
+
+	task := newTaskIncrementalBackup(t.chain, file, base).(*taskBackup)
+	task.fd = fd
+	task.zw = zip.NewWriter(fd)
+	assert.NoError(t, task._backupIncremental())
+
+	zr, err := zip.OpenReader(file)
+	assert.NoError(t, err)
+	t.Cleanup(func() { zr.Close() })
+	return &zr.Reader
+}
+
+func (t *backupTest) assertRestored(dbase db.Database) {
+	last := t.node.GetLastBlock()
+	height, err := block.GetLastHeight(dbase)
+	assert.NoError(t, err)
+	assert.Equal(t, last.Height(), height)
+	for h := int64(0); h <= height; h++ {
+		blk, err := t.node.BM.GetBlockByHeight(h)
+		assert.NoError(t, err)
+		id, err := blockIDByHeight(dbase, h)
+		assert.NoError(t, err)
+		assert.Equal(t, blk.ID(), id)
+	}
+
+	// blocks and states of the restored database are complete
+	nd := test.NewNode(t.T, test.UseDB(dbase))
+	defer nd.Close()
+	assert.NoError(t, nd.BM.ExportBlocks(0, height, db.NewMapDB(), nil))
+}
+
+func TestIncrementalBackup_RoundTrip(t *testing.T) {
+	bt := newBackupTest(t)
+	bt.finalizeBlocks(3, "base")
+	base := bt.fullBackup()
+
+	bt.finalizeBlocks(3, "delta")
+	zr := bt.incrementalBackup(3)
+
+	info, err := ReadBackupInfo(zr)
+	assert.NoError(t, err)
+	assert.True(t, info.IsIncremental())
+	assert.EqualValues(t, 3, info.Base)
+	assert.EqualValues(t, 6, info.Height)
+	baseBlk, err := bt.node.BM.GetBlockByHeight(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, baseBlk.ID(), info.BaseID)
+	assert.EqualValues(t, bt.node.GetLastBlock().ID(), info.BlockID)
+
+	assert.NoError(t, ApplyIncrementalBackup(zr, base))
+	bt.assertRestored(base)
+}
+
+func TestIncrementalBackup_Chain(t *testing.T) {
+	bt := newBackupTest(t)
+	bt.finalizeBlocks(2, "base")
+	base := bt.fullBackup()
+
+	bt.finalizeBlocks(2, "delta1")
+	zr1 := bt.incrementalBackup(2)
+	bt.finalizeBlocks(2, "delta2")
+	zr2 := bt.incrementalBackup(4)
+
+	// deltas are applied in order only
+	err := ApplyIncrementalBackup(zr2, base)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+
+	assert.NoError(t, ApplyIncrementalBackup(zr1, base))
+	assert.NoError(t, ApplyIncrementalBackup(zr2, base))
+	bt.assertRestored(base)
+}
+
+func TestIncrementalBackup_InvalidBase(t *testing.T) {
+	bt := newBackupTest(t)
+	bt.finalizeBlocks(3, "base")
+	bt.finalizeBlocks(3, "delta")
+	zr := bt.incrementalBackup(3)
+
+	// backup of other chain at the same height
+	other := newBackupTest(t)
+	other.finalizeBlocks(3, "other")
+	base := other.fullBackup()
+
+	err := ApplyIncrementalBackup(zr, base)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+	height, err := block.GetLastHeight(base)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, height)
+	_, err = blockIDByHeight(base, 4)
+	assert.True(t, errors.NotFoundError.Equals(err))
+}
+
+func TestIncrementalBackup_NoBlocksAfterBase(t *testing.T) {
+	bt := newBackupTest(t)
+	bt.finalizeBlocks(2, "base")
+
+	file := path.Join(t.TempDir(), "backup.zip")
+	fd, err := os.Create(file)
+	assert.NoError(t, err)
+	task := newTaskIncrementalBackup(bt.chain, file, 2).(*taskBackup)
+	task.fd = fd
+	task.zw = zip.NewWriter(fd)
+	err = task._backupIncremental()
+	assert.True(t, errors.InvalidStateError.Equals(err))
+}
@@ -23,6 +23,7 @@ import (
 type taskConsensus struct {
 	chain  *singleChain
 	result resultStore
+	pruner *statePruner
 }
 
 var consensusStates = map[State]string{
@@ -37,6 +38,9 @@ func (t *taskConsensus) String() string {
 }
 
 func (t *taskConsensus) DetailOf(s State) string {
+	if s == Started && t.pruner != nil {
+		return consensusStates[s] + ", " + t.pruner.Detail()
+	}
 	if name, ok := consensusStates[s]; ok {
 		return name
 	} else {
@@ -66,10 +70,17 @@ func (t *taskConsensus) _start(c *singleChain) error {
 	if err := c.nm.Start(); err != nil {
 		return err
 	}
+	if c.barrier != nil {
+		t.pruner = newStatePruner(c, c.barrier)
+		t.pruner.Start()
+	}
 	return nil
 }
 
 func (t *taskConsensus) Stop() {
+	if t.pruner != nil {
+		t.pruner.Stop()
+	}
 	t.chain.srv.RemoveChain(t.chain.cfg.Channel)
 	t.chain.releaseManagers()
 	t.result.SetValue(errors.ErrInterrupted)
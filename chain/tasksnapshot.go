@@ -0,0 +1,614 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/chain/snapshot"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	TaskSnapshotExport = "export_snapshot"
+	TaskSnapshotImport = "import_snapshot"
+)
+
+type SnapshotExportParams struct {
+	File   string `json:"file"`
+	Height int64  `json:"height"`
+}
+
+type SnapshotImportParams struct {
+	File    string          `json:"file"`
+	BlockID common.HexBytes `json:"block_id,omitempty"`
+}
+
+func resolveTaskFile(c *singleChain, file string) string {
+	if len(file) == 0 {
+		return file
+	}
+	return c.cfg.ResolveAbsolute(file)
+}
+
+var snapshotExportStates = map[State]string{
+	Starting: "snapshot export starting",
+	Stopping: "snapshot export stopping",
+	Failed:   "snapshot export failed",
+	Finished: "snapshot export done",
+}
+
+type taskSnapshotExport struct {
+	chain  *singleChain
+	result resultStore
+	file   string
+	height int64
+	stop   int32
+
+	current    int64
+	resolved   uint64
+	unresolved uint64
+	entries    int64
+}
+
+func (t *taskSnapshotExport) String() string {
+	return fmt.Sprintf("SnapshotExport(height=%d,file=%s)", t.height, path.Base(t.file))
+}
+
+func (t *taskSnapshotExport) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("snapshot export height=%d resolved=%d unresolved=%d entries=%d",
+			atomic.LoadInt64(&t.current),
+			atomic.LoadUint64(&t.resolved),
+			atomic.LoadUint64(&t.unresolved),
+			atomic.LoadInt64(&t.entries))
+	default:
+		if st, ok := snapshotExportStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskSnapshotExport) Start() error {
+	if t.height <= 0 {
+		return errors.IllegalArgumentError.Errorf("InvalidHeight(height=%d)", t.height)
+	}
+	if len(t.file) == 0 {
+		return errors.IllegalArgumentError.New("NoSnapshotFile")
+	}
+	if err := t.chain.prepareManagers(); err != nil {
+		return err
+	}
+	blk, err := t.chain.bm.GetLastBlock()
+	if err != nil {
+		t.chain.releaseManagers()
+		return err
+	}
+	// votes for the block are in the next block.
+	if t.height >= blk.Height() {
+		t.chain.releaseManagers()
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d,last=%d)", t.height, blk.Height())
+	}
+	go t.doExport()
+	return nil
+}
+
+func (t *taskSnapshotExport) doExport() {
+	err := t._export()
+	t.result.SetValue(err)
+}
+
+func (t *taskSnapshotExport) onProgress(height int64, r, u int) error {
+	if atomic.LoadInt32(&t.stop) != 0 {
+		return errors.ErrInterrupted
+	}
+	atomic.StoreInt64(&t.current, height)
+	atomic.StoreUint64(&t.resolved, uint64(r))
+	atomic.StoreUint64(&t.unresolved, uint64(u))
+	return nil
+}
+
+func (t *taskSnapshotExport) _export() (rerr error) {
+	c := t.chain
+	defer c.releaseManagers()
+
+	blk, err := c.bm.GetBlockByHeight(t.height)
+	if err != nil {
+		return err
+	}
+	if blk.Version() != module.BlockVersion2 {
+		return errors.UnsupportedError.Errorf(
+			"UnsupportedBlockVersion(height=%d,version=%d)", t.height, blk.Version())
+	}
+	nblk, err := c.bm.GetBlockByHeight(t.height + 1)
+	if err != nil {
+		return errors.InvalidStateError.Errorf("No next block height=%d", t.height)
+	}
+
+	chainDir := c.cfg.AbsBaseDir()
+	cacheDir := path.Join(chainDir, DefaultTmpDBDir)
+	_ = os.RemoveAll(cacheDir)
+	cache, err := c.openDatabase(cacheDir, c.cfg.DBType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Must(cache.Close())
+		log.Must(os.RemoveAll(cacheDir))
+	}()
+
+	tmp := t.file + TempSuffix
+	_ = os.Remove(tmp)
+	fd, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_EXCL|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if fd != nil {
+			_ = fd.Close()
+		}
+		if rerr != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	bw := bufio.NewWriter(fd)
+	sw, err := snapshot.NewWriter(bw, &snapshot.Metadata{
+		NID:     int32(c.NID()),
+		CID:     int32(c.CID()),
+		Channel: c.Channel(),
+		Height:  t.height,
+		BlockID: blk.ID(),
+		Votes:   nblk.Votes().Bytes(),
+	})
+	if err != nil {
+		return err
+	}
+	c.logger.Infof("Export snapshot height=%d file=%s", t.height, t.file)
+	dbase := snapshot.NewDatabaseWithWriter(sw, cache)
+	if err := c.bm.ExportBlocks(t.height, t.height, dbase, func(height int64, r, u int) error {
+		atomic.StoreInt64(&t.entries, sw.Entries())
+		return t.onProgress(height, r, u)
+	}); err != nil {
+		return err
+	}
+	if err := sw.Close(); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&t.entries, sw.Entries())
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		return err
+	}
+	err = fd.Close()
+	fd = nil
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.file); err != nil {
+		return errors.UnknownError.Wrapf(err, "fail to rename %s to %s", tmp, t.file)
+	}
+	return nil
+}
+
+func (t *taskSnapshotExport) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskSnapshotExport) Wait() error {
+	return t.result.Wait()
+}
+
+func newTaskSnapshotExport(c *singleChain, params json.RawMessage) (chainTask, error) {
+	var p SnapshotExportParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+	}
+	return &taskSnapshotExport{
+		chain:  c,
+		file:   resolveTaskFile(c, p.File),
+		height: p.Height,
+	}, nil
+}
+
+var snapshotImportStates = map[State]string{
+	Starting: "snapshot import starting",
+	Stopping: "snapshot import stopping",
+	Failed:   "snapshot import failed",
+	Finished: "snapshot import done",
+}
+
+type taskSnapshotImport struct {
+	chain   *singleChain
+	result  resultStore
+	file    string
+	gsfile  string
+	blockID []byte
+	stop    int32
+	entries int64
+}
+
+func (t *taskSnapshotImport) String() string {
+	return fmt.Sprintf("SnapshotImport(file=%s)", path.Base(t.file))
+}
+
+func (t *taskSnapshotImport) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("snapshot import entries=%d", atomic.LoadInt64(&t.entries))
+	default:
+		if st, ok := snapshotImportStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskSnapshotImport) Start() error {
+	if len(t.file) == 0 {
+		return errors.IllegalArgumentError.New("NoSnapshotFile")
+	}
+	if _, err := os.Stat(t.file); err != nil {
+		return errors.IllegalArgumentError.Wrapf(err, "InvalidSnapshotFile(file=%s)", t.file)
+	}
+	if len(t.blockID) != 0 && len(t.blockID) != crypto.HashLen {
+		return errors.IllegalArgumentError.Errorf("InvalidBlockID(id=%#x)", t.blockID)
+	}
+	go t.doImport()
+	return nil
+}
+
+func (t *taskSnapshotImport) doImport() {
+	err := t._import()
+	t.result.SetValue(err)
+}
+
+func (t *taskSnapshotImport) onProgress(entries int64) error {
+	if atomic.LoadInt32(&t.stop) != 0 {
+		return errors.ErrInterrupted
+	}
+	atomic.StoreInt64(&t.entries, entries)
+	return nil
+}
+
+// snapshotAnchor is the trusted information to verify the block of the
+// snapshot, which is either the expected block ID or the validators of the
+// existing chain.
+type snapshotAnchor struct {
+	blockID    []byte
+	validators module.ValidatorList
+}
+
+func (a *snapshotAnchor) checkBlockID(id []byte) error {
+	if a.blockID != nil && !bytes.Equal(a.blockID, id) {
+		return errors.InvalidStateError.Errorf(
+			"UntrustedBlockID(exp=%#x,real=%#x)", a.blockID, id)
+	}
+	return nil
+}
+
+func getHeaderByHeight(dbase db.Database, height int64) (*block.Header, error) {
+	id, err := db.DoGetWithBucketID(dbase, db.BlockHeaderHashByHeight,
+		codec.BC.MustMarshalToBytes(height))
+	if err != nil || id == nil {
+		return nil, errors.NotFoundError.Wrapf(err, "NoBlockHeader(height=%d)", height)
+	}
+	return getHeaderByID(dbase, id)
+}
+
+// getHeaderByID returns the header of the block. It fails if the header
+// doesn't match to the ID.
+func getHeaderByID(dbase db.Database, id []byte) (*block.Header, error) {
+	bs, err := db.DoGetWithBucketID(dbase, db.BytesByHash, id)
+	if err != nil || bs == nil {
+		return nil, errors.NotFoundError.Wrapf(err, "NoBlockHeader(id=%#x)", id)
+	}
+	hdr, err := block.NewHeaderFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hdr.ID(), id) {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidBlockHeader(exp=%#x,real=%#x)", id, hdr.ID())
+	}
+	return hdr, nil
+}
+
+// loadSnapshotAnchor returns the anchor for the block of the snapshot. The
+// block ID given by the operator is used if it exists. Otherwise, the block
+// of the existing chain at the height or the validators of the last block
+// of the existing chain is used.
+func loadSnapshotAnchor(dbase db.Database, blockID []byte, height int64) (*snapshotAnchor, error) {
+	if len(blockID) != 0 {
+		return &snapshotAnchor{blockID: blockID}, nil
+	}
+	last, err := block.GetLastHeight(dbase)
+	if err != nil {
+		return nil, err
+	}
+	if last >= height {
+		if hdr, err := getHeaderByHeight(dbase, height); err == nil {
+			return &snapshotAnchor{blockID: hdr.ID()}, nil
+		}
+	}
+	hdr, err := getHeaderByHeight(dbase, last)
+	if err != nil {
+		return nil, errors.InvalidStateError.Wrap(err,
+			"NoTrustedAnchor(block ID is required)")
+	}
+	vl, err := state.ValidatorSnapshotFromHash(dbase, hdr.NextValidatorsHash())
+	if err != nil {
+		return nil, err
+	}
+	if vl == nil || vl.Len() == 0 {
+		return nil, errors.InvalidStateError.Errorf(
+			"NoTrustedAnchor(block ID is required, no validators at height=%d)", last)
+	}
+	return &snapshotAnchor{validators: vl}, nil
+}
+
+// verifySnapshotBlock checks the block of the snapshot in the database
+// with the votes and the trusted anchor. Without the trusted block ID, the
+// votes are verified with the trusted validators.
+func verifySnapshotBlock(dbase db.Database, meta *snapshot.Metadata, votes module.CommitVoteSet, anchor *snapshotAnchor) (*block.Header, error) {
+	if err := anchor.checkBlockID(meta.BlockID); err != nil {
+		return nil, err
+	}
+	hdr, err := getHeaderByID(dbase, meta.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	if hdr.Height() != meta.Height {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidBlockHeight(exp=%d,real=%d)", meta.Height, hdr.Height())
+	}
+	hb, err := db.DoGetWithBucketID(dbase, db.BlockHeaderHashByHeight,
+		codec.BC.MustMarshalToBytes(meta.Height))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hb, meta.BlockID) {
+		return nil, errors.InvalidStateError.Errorf(
+			"BlockIDInvalid(exp=%#x,real=%#x)", meta.BlockID, hb)
+	}
+	if height, err := block.GetLastHeight(dbase); err != nil {
+		return nil, err
+	} else if height != meta.Height {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidLastHeight(exp=%d,real=%d)", meta.Height, height)
+	}
+
+	vl := anchor.validators
+	if vl == nil {
+		phdr, err := getHeaderByID(dbase, hdr.PrevID())
+		if err != nil {
+			return nil, err
+		}
+		pvl, err := state.ValidatorSnapshotFromHash(dbase, phdr.NextValidatorsHash())
+		if err != nil {
+			return nil, err
+		}
+		if pvl == nil || pvl.Len() == 0 {
+			return nil, errors.InvalidStateError.Errorf(
+				"NoValidators(hash=%#x)", phdr.NextValidatorsHash())
+		}
+		vl = pvl
+	}
+	if _, err := votes.VerifyBlock(hdr, vl); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
+
+func (t *taskSnapshotImport) _loadSnapshot(dbDir string) (rmeta *snapshot.Metadata, rvotes module.CommitVoteSet, rerr error) {
+	c := t.chain
+	fd, err := os.Open(t.file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fd.Close()
+
+	sr, err := snapshot.NewReader(bufio.NewReader(fd))
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := sr.Metadata()
+	if int(meta.NID) != c.NID() || int(meta.CID) != c.CID() {
+		return nil, nil, errors.InvalidStateError.Errorf(
+			"InvalidSnapshotChain(nid=%#x,cid=%#x,exp_nid=%#x,exp_cid=%#x)",
+			meta.NID, meta.CID, c.NID(), c.CID())
+	}
+	votes := c.CommitVoteSetDecoder()(meta.Votes)
+	if votes == nil {
+		return nil, nil, errors.IllegalArgumentError.New("InvalidSnapshotVotes")
+	}
+	anchor, err := loadSnapshotAnchor(c.database, t.blockID, meta.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+	// check the block ID before importing the snapshot.
+	if err := anchor.checkBlockID(meta.BlockID); err != nil {
+		return nil, nil, err
+	}
+
+	_ = os.RemoveAll(dbDir)
+	dbase, err := c.openDatabase(dbDir, c.cfg.DBType)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		log.Must(dbase.Close())
+		if rerr != nil {
+			log.Must(os.RemoveAll(dbDir))
+		}
+	}()
+
+	c.logger.Infof("Import snapshot height=%d file=%s", meta.Height, t.file)
+	if err := sr.ImportTo(dbase, t.onProgress); err != nil {
+		return nil, nil, err
+	}
+	if _, err := verifySnapshotBlock(dbase, meta, votes, anchor); err != nil {
+		return nil, nil, err
+	}
+	return meta, votes, nil
+}
+
+func (t *taskSnapshotImport) _exportGenesis(height int64, votes module.CommitVoteSet, gsfile string) (rerr error) {
+	if err := t.chain.prepareManagers(); err != nil {
+		return err
+	}
+	defer t.chain.releaseManagers()
+	blk, err := t.chain.bm.GetBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	fd, err := os.OpenFile(gsfile, os.O_CREATE|os.O_WRONLY|os.O_EXCL|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	gsw := gs.NewGenesisStorageWriter(fd)
+	defer func() {
+		_ = gsw.Close()
+		_ = fd.Close()
+		if rerr != nil {
+			_ = os.Remove(gsfile)
+		}
+	}()
+	if err := t.chain.bm.ExportGenesis(blk, votes, gsw); err != nil {
+		return errors.Wrap(err, "fail on exporting genesis storage")
+	}
+	return nil
+}
+
+func (t *taskSnapshotImport) _import() (ret error) {
+	c := t.chain
+	chainDir := c.cfg.AbsBaseDir()
+	dbDir := path.Join(chainDir, DefaultDBDir)
+	dbDirNew := dbDir + TempSuffix
+
+	meta, votes, err := t._loadSnapshot(dbDirNew)
+	if err != nil {
+		return err
+	}
+
+	var rb Revertible
+	defer func() {
+		rb.RevertOrCommit(ret != nil)
+	}()
+	rb.Append(func(revert bool) {
+		if revert {
+			log.Must(os.RemoveAll(dbDirNew))
+		}
+	})
+
+	// replace with new database
+	c.releaseDatabase()
+	rb.Append(func(revert bool) {
+		if revert {
+			c.ensureDatabase()
+		}
+	})
+	if err := rb.Delete(dbDir); err != nil {
+		return err
+	}
+	if err := rb.Rename(dbDirNew, dbDir); err != nil {
+		return err
+	}
+	c.ensureDatabase()
+	rb.Append(func(revert bool) {
+		if revert {
+			c.releaseDatabase()
+		}
+	})
+
+	// remove other directories
+	for _, dir := range []string{DefaultContractDir, DefaultWALDir, DefaultCacheDir} {
+		if err := rb.Delete(path.Join(chainDir, dir)); err != nil {
+			return err
+		}
+	}
+
+	// create pruned genesis
+	if err := rb.Delete(t.gsfile); err != nil {
+		return err
+	}
+	if err := t._exportGenesis(meta.Height, votes, t.gsfile); err != nil {
+		return err
+	}
+	rb.Append(func(revert bool) {
+		if revert {
+			_ = os.Remove(t.gsfile)
+		}
+	})
+
+	// reload new genesis
+	g, err := loadGenesisStorage(t.gsfile)
+	if err != nil {
+		return err
+	}
+	c.cfg.GenesisStorage = g
+	c.cfg.Genesis = g.Genesis()
+	return nil
+}
+
+func (t *taskSnapshotImport) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskSnapshotImport) Wait() error {
+	return t.result.Wait()
+}
+
+func newTaskSnapshotImport(c *singleChain, params json.RawMessage) (chainTask, error) {
+	var p SnapshotImportParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+	}
+	return &taskSnapshotImport{
+		chain:   c,
+		file:    resolveTaskFile(c, p.File),
+		gsfile:  path.Join(c.cfg.AbsBaseDir(), chainGenesisZipFileName),
+		blockID: p.BlockID,
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(TaskSnapshotExport, newTaskSnapshotExport)
+	registerTaskFactory(TaskSnapshotImport, newTaskSnapshotImport)
+}
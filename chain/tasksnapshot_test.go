@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/chain/snapshot"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/test"
+)
+
+func TestVerifySnapshotBlock(t *testing.T) {
+	nd := test.NewNode(t)
+	defer nd.Close()
+
+	nd.ProposeFinalizeBlockWithTX(consensus.NewEmptyCommitVoteList(),
+		test.NewTx().SetValidatorsNode(nd).String())
+	// validators are applied to the next block of the result
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	for i := 0; i < 3; i++ {
+		nd.ProposeFinalizeBlock(nd.NewVoteListForLastBlock())
+	}
+	dbase := nd.Chain.Database()
+	blk := nd.GetLastBlock()
+	meta := &snapshot.Metadata{
+		Height:  blk.Height(),
+		BlockID: blk.ID(),
+	}
+	votes := nd.NewVoteListForLastBlock()
+
+	// the block of the existing chain
+	anchor, err := loadSnapshotAnchor(dbase, nil, blk.Height())
+	assert.NoError(t, err)
+	assert.Equal(t, blk.ID(), anchor.blockID)
+	_, err = verifySnapshotBlock(dbase, meta, votes, anchor)
+	assert.NoError(t, err)
+
+	// the block given by the operator
+	_, err = verifySnapshotBlock(dbase, meta, votes, &snapshotAnchor{blockID: blk.ID()})
+	assert.NoError(t, err)
+	_, err = verifySnapshotBlock(dbase, meta, votes, &snapshotAnchor{blockID: blk.PrevID()})
+	assert.Error(t, err)
+
+	// validators of the last block of the existing chain
+	anchor, err = loadSnapshotAnchor(dbase, nil, blk.Height()+10)
+	assert.NoError(t, err)
+	assert.Nil(t, anchor.blockID)
+	_, err = verifySnapshotBlock(dbase, meta, votes, anchor)
+	assert.NoError(t, err)
+
+	// votes of the validators in the snapshot are not trusted
+	other, err := state.ValidatorFromAddress(wallet.New().Address())
+	assert.NoError(t, err)
+	vs, err := state.ValidatorSnapshotFromSlice(db.NewMapDB(), []module.Validator{other})
+	assert.NoError(t, err)
+	_, err = verifySnapshotBlock(dbase, meta, votes, &snapshotAnchor{validators: vs})
+	assert.Error(t, err)
+
+	// no anchor without the existing chain
+	_, err = loadSnapshotAnchor(db.NewMapDB(), nil, blk.Height())
+	assert.Error(t, err)
+}
+
+func TestGetHeaderByID(t *testing.T) {
+	nd := test.NewNode(t)
+	defer nd.Close()
+
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	blk := nd.GetLastBlock()
+	dbase := nd.Chain.Database()
+
+	hdr, err := getHeaderByID(dbase, blk.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, blk.Height(), hdr.Height())
+
+	// header stored with the ID of the other block
+	bs, err := db.DoGetWithBucketID(dbase, db.BytesByHash, blk.PrevID())
+	assert.NoError(t, err)
+	forged := db.NewMapDB()
+	bk, err := forged.GetBucket(db.BytesByHash)
+	assert.NoError(t, err)
+	assert.NoError(t, bk.Set(blk.ID(), bs))
+	_, err = getHeaderByID(forged, blk.ID())
+	assert.Error(t, err)
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package bind
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+// Contract is the base of generated bindings. It calls methods of the
+// SCORE at Address through Client.
+type Contract struct {
+	Client    *client.ClientV3
+	Address   module.Address
+	NetworkID int64
+}
+
+func NewContract(c *client.ClientV3, addr module.Address, nid int64) *Contract {
+	return &Contract{
+		Client:    c,
+		Address:   addr,
+		NetworkID: nid,
+	}
+}
+
+func callData(method string, params map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{"method": method}
+	if len(params) > 0 {
+		data["params"] = params
+	}
+	return data
+}
+
+// Call calls the read-only method with params, and stores the result to
+// the value pointed by ret. Nil parameters are omitted for using their
+// default values.
+func (c *Contract) Call(method string, params map[string]interface{}, ret interface{}) error {
+	param := &v3.CallParam{
+		ToAddress: jsonrpc.Address(c.Address.String()),
+		DataType:  "call",
+		Data:      callData(method, params),
+	}
+	res, err := c.Client.Call(param)
+	if err != nil {
+		return err
+	}
+	if ret == nil {
+		return nil
+	}
+	return DecodeValue(res, ret)
+}
+
+// Transact returns a builder of the transaction calling the method with
+// params.
+func (c *Contract) Transact(method string, params map[string]interface{}) *TxBuilder {
+	return &TxBuilder{
+		contract: c,
+		data:     callData(method, params),
+	}
+}
+
+// EventFilter returns the filter of the event for client.MonitorEvent.
+// Nil values of indexed match any value.
+func (c *Contract) EventFilter(signature string, indexed ...interface{}) (*server.EventFilter, error) {
+	f := &server.EventFilter{
+		Addr:      common.AddressToPtr(c.Address),
+		Signature: signature,
+	}
+	for i, v := range indexed {
+		jso, err := EncodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		if jso == nil {
+			f.Indexed = append(f.Indexed, nil)
+			continue
+		}
+		s, ok := jso.(string)
+		if !ok {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"InvalidIndexedValue(idx=%d,value=%v)", i, v)
+		}
+		f.Indexed = append(f.Indexed, &s)
+	}
+	return f, nil
+}
+
+// UnpackEvent checks the signature and the address of the event log, and
+// stores its indexed and data values to outs in order.
+func (c *Contract) UnpackEvent(el *client.EventLog, signature string, outs ...interface{}) error {
+	if el == nil || len(el.Indexed) == 0 || el.Indexed[0] == nil || *el.Indexed[0] != signature {
+		return errors.IllegalArgumentError.Errorf("SignatureMismatch(exp=%s)", signature)
+	}
+	if el.Addr.Address() == nil || !el.Addr.Address().Equal(c.Address) {
+		return errors.IllegalArgumentError.Errorf("AddressMismatch(exp=%s,addr=%s)", c.Address, el.Addr)
+	}
+	values := append(append([]*string{}, el.Indexed[1:]...), el.Data...)
+	if len(values) != len(outs) {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidEventLog(exp=%d,values=%d)", len(outs), len(values))
+	}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if err := DecodeValue(*v, outs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TxBuilder builds and sends a transaction calling a writable method.
+type TxBuilder struct {
+	contract  *Contract
+	data      map[string]interface{}
+	value     *big.Int
+	stepLimit *big.Int
+}
+
+// WithValue sets the value to transfer to the SCORE with the call. It's
+// allowed only for payable methods.
+func (b *TxBuilder) WithValue(v *big.Int) *TxBuilder {
+	b.value = v
+	return b
+}
+
+// WithStepLimit sets the step limit of the transaction. If it's not set,
+// the estimated steps are used.
+func (b *TxBuilder) WithStepLimit(v *big.Int) *TxBuilder {
+	b.stepLimit = v
+	return b
+}
+
+// Param returns the transaction parameter sent by the wallet. It's not
+// signed yet.
+func (b *TxBuilder) Param(w module.Wallet) (*v3.TransactionParam, error) {
+	c := b.contract
+	param := &v3.TransactionParam{
+		Version:     jsonrpc.HexIntFromInt64(module.TransactionVersion3),
+		FromAddress: jsonrpc.Address(w.Address().String()),
+		ToAddress:   jsonrpc.Address(c.Address.String()),
+		NetworkID:   jsonrpc.HexIntFromInt64(c.NetworkID),
+		DataType:    "call",
+		Data:        b.data,
+	}
+	if b.value != nil {
+		param.Value = jsonrpc.HexIntFromBigInt(b.value)
+	}
+	if b.stepLimit != nil {
+		param.StepLimit = jsonrpc.HexIntFromBigInt(b.stepLimit)
+	} else {
+		steps, err := c.Client.EstimateStep(&v3.TransactionParamForEstimate{
+			Version:     param.Version,
+			FromAddress: param.FromAddress,
+			ToAddress:   param.ToAddress,
+			Value:       param.Value,
+			NetworkID:   param.NetworkID,
+			DataType:    param.DataType,
+			Data:        param.Data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		param.StepLimit = jsonrpc.HexIntFromBigInt(steps.Value())
+	}
+	return param, nil
+}
+
+// Send signs the transaction with the wallet and sends it. It returns
+// the hash of the transaction.
+func (b *TxBuilder) Send(w module.Wallet) ([]byte, error) {
+	param, err := b.Param(w)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := b.contract.Client.SendTransaction(w, param)
+	if err != nil {
+		return nil, err
+	}
+	return hash.Bytes(), nil
+}
+
+// SendAndWait sends the transaction and waits for its result.
+func (b *TxBuilder) SendAndWait(w module.Wallet) (*client.TransactionResult, error) {
+	hash, err := b.Send(w)
+	if err != nil {
+		return nil, err
+	}
+	return b.contract.Client.WaitTransactionResult(&v3.TransactionHashParam{
+		Hash: jsonrpc.HexBytes("0x" + hex.EncodeToString(hash)),
+	})
+}
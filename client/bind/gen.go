@@ -0,0 +1,331 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/service/scoreapi"
+)
+
+// ParseAPI parses the output of icx_getScoreApi.
+func ParseAPI(bs []byte) ([]*scoreapi.Method, error) {
+	return scoreapi.MethodsFromJSON(bs)
+}
+
+// exportedName returns the exported Go identifier for the name in the
+// SCORE API. For example, "_owner" becomes "Owner" and "token_uri"
+// becomes "TokenUri".
+func exportedName(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, c := range s {
+		if c == '_' || !(unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		sb.WriteRune(c)
+	}
+	name := sb.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// localName returns the unexported Go identifier for the name in the
+// SCORE API.
+func localName(s string) string {
+	name := []rune(exportedName(s))
+	name[0] = unicode.ToLower(name[0])
+	res := string(name)
+	if token.IsKeyword(res) {
+		res += "_"
+	}
+	return res
+}
+
+// names assigns unique identifiers.
+type names map[string]bool
+
+func (ns names) unique(name string) string {
+	res := name
+	for i := 2; ns[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	ns[res] = true
+	return res
+}
+
+type generator struct {
+	name    string
+	types   names
+	structs bytes.Buffer
+	body    bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+// goType returns the Go type of the data type. Structs with fields are
+// declared with the name.
+func (g *generator) goType(dt scoreapi.DataType, fields []scoreapi.Field, name string) string {
+	prefix := strings.Repeat("[]", dt.ListDepth())
+	switch dt.Tag() {
+	case scoreapi.TInteger:
+		return prefix + "*big.Int"
+	case scoreapi.TString:
+		return prefix + "string"
+	case scoreapi.TBytes:
+		return prefix + "[]byte"
+	case scoreapi.TBool:
+		return prefix + "bool"
+	case scoreapi.TAddress:
+		return prefix + "module.Address"
+	case scoreapi.TList:
+		return prefix + "[]interface{}"
+	case scoreapi.TStruct:
+		if len(fields) > 0 {
+			name = g.declareStruct(name, fields)
+			if prefix == "" {
+				return "*" + name
+			}
+			return prefix + name
+		}
+	}
+	return prefix + "map[string]interface{}"
+}
+
+func (g *generator) declareStruct(name string, fields []scoreapi.Field) string {
+	name = g.types.unique(name)
+	fnames := names{}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s is a struct type used by %s.\n", name, g.name)
+	fmt.Fprintf(&sb, "type %s struct {\n", name)
+	for _, f := range fields {
+		fname := fnames.unique(exportedName(f.Name))
+		ft := g.goType(f.Type, f.Fields, name+fname)
+		fmt.Fprintf(&sb, "\t%s %s `json:\"%s\"`\n", fname, ft, f.Name)
+	}
+	sb.WriteString("}\n\n")
+	g.structs.WriteString(sb.String())
+	return name
+}
+
+// zeroValue returns the zero value expression of the Go type.
+func zeroValue(t string) string {
+	switch t {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	return "nil"
+}
+
+func (g *generator) params(m *scoreapi.Method, reserved ...string) ([]string, []string) {
+	pnames := names{}
+	for _, r := range reserved {
+		pnames[r] = true
+	}
+	args := make([]string, len(m.Inputs))
+	decls := make([]string, len(m.Inputs))
+	for i, input := range m.Inputs {
+		args[i] = pnames.unique(localName(input.Name))
+		decls[i] = args[i] + " " + g.goType(input.Type, input.Fields,
+			exportedName(m.Name)+exportedName(input.Name))
+	}
+	return args, decls
+}
+
+func quotedNames(m *scoreapi.Method) string {
+	qs := make([]string, len(m.Inputs))
+	for i, input := range m.Inputs {
+		qs[i] = fmt.Sprintf("%q", input.Name)
+	}
+	return strings.Join(qs, ", ")
+}
+
+func (g *generator) encodeParams(m *scoreapi.Method, args []string, onError string) {
+	if len(m.Inputs) == 0 {
+		g.printf("\tvar params map[string]interface{}\n")
+		return
+	}
+	g.printf("\tparams, err := bind.EncodeParams([]string{%s}, %s)\n",
+		quotedNames(m), strings.Join(args, ", "))
+	g.printf("\tif err != nil {\n\t\treturn %s\n\t}\n", onError)
+}
+
+var reservedParams = []string{"s", "params", "err", "ret", "ev"}
+
+func (g *generator) readOnly(m *scoreapi.Method, fname string) {
+	args, decls := g.params(m, reservedParams...)
+	rtype := ""
+	if len(m.Outputs) > 0 {
+		rtype = g.goType(m.Outputs[0], nil, fname+"Result")
+	}
+	g.printf("// %s calls the read-only method %s.\n", fname, m.Name)
+	if rtype == "" {
+		g.printf("func (s *%s) %s(%s) error {\n", g.name, fname, strings.Join(decls, ", "))
+		g.encodeParams(m, args, "err")
+		g.printf("\treturn s.Contract.Call(%q, params, nil)\n}\n\n", m.Name)
+		return
+	}
+	zero := zeroValue(rtype)
+	g.printf("func (s *%s) %s(%s) (%s, error) {\n", g.name, fname, strings.Join(decls, ", "), rtype)
+	g.encodeParams(m, args, zero+", err")
+	g.printf("\tvar ret %s\n", rtype)
+	g.printf("\tif err := s.Contract.Call(%q, params, &ret); err != nil {\n", m.Name)
+	g.printf("\t\treturn %s, err\n\t}\n", zero)
+	g.printf("\treturn ret, nil\n}\n\n")
+}
+
+func (g *generator) writable(m *scoreapi.Method, fname string) {
+	args, decls := g.params(m, reservedParams...)
+	g.printf("// %s returns the builder of the transaction calling %s.\n", fname, m.Name)
+	if m.IsPayable() {
+		g.printf("// The method is payable, so the value may be set with WithValue.\n")
+	}
+	g.printf("func (s *%s) %s(%s) (*bind.TxBuilder, error) {\n", g.name, fname, strings.Join(decls, ", "))
+	g.encodeParams(m, args, "nil, err")
+	g.printf("\treturn s.Contract.Transact(%q, params), nil\n}\n\n", m.Name)
+}
+
+func filterType(t string) string {
+	switch t {
+	case "string", "bool":
+		return "*" + t
+	}
+	return t
+}
+
+func (g *generator) event(m *scoreapi.Method) {
+	ename := exportedName(m.Name)
+	tname := g.types.unique(ename + "Event")
+	sname := g.types.unique(tname + "Signature")
+
+	g.printf("// %s is the signature of the event %s.\n", sname, m.Name)
+	g.printf("const %s = %q\n\n", sname, m.Signature())
+
+	fnames := names{"Log": true}
+	fields := make([]string, len(m.Inputs))
+	g.printf("// %s is the decoded event log of %s.\n", tname, m.Name)
+	g.printf("type %s struct {\n", tname)
+	for i, input := range m.Inputs {
+		fields[i] = fnames.unique(exportedName(input.Name))
+		g.printf("\t%s %s\n", fields[i], g.goType(input.Type, input.Fields, tname+fields[i]))
+	}
+	g.printf("\tLog *client.EventLog\n}\n\n")
+
+	outs := make([]string, len(fields))
+	for i, f := range fields {
+		outs[i] = "&ev." + f
+	}
+	g.printf("// Parse%s decodes the event log of %s.\n", tname, m.Name)
+	g.printf("func (s *%s) Parse%s(el *client.EventLog) (*%s, error) {\n", g.name, tname, tname)
+	g.printf("\tev := &%s{Log: el}\n", tname)
+	g.printf("\tif err := s.Contract.UnpackEvent(el, %s", sname)
+	if len(outs) > 0 {
+		g.printf(", %s", strings.Join(outs, ", "))
+	}
+	g.printf("); err != nil {\n\t\treturn nil, err\n\t}\n")
+	g.printf("\treturn ev, nil\n}\n\n")
+
+	pnames := names{}
+	for _, r := range reservedParams {
+		pnames[r] = true
+	}
+	var args, decls []string
+	for i := 0; i < m.Indexed && i < len(m.Inputs); i++ {
+		input := m.Inputs[i]
+		arg := pnames.unique(localName(input.Name))
+		args = append(args, arg)
+		decls = append(decls, arg+" "+filterType(g.goType(input.Type, input.Fields, tname+fields[i])))
+	}
+	g.printf("// Filter%s returns the filter of %s for MonitorEvent.\n", tname, m.Name)
+	if len(args) > 0 {
+		g.printf("// Nil values match any value.\n")
+	}
+	g.printf("func (s *%s) Filter%s(%s) (*server.EventFilter, error) {\n", g.name, tname, strings.Join(decls, ", "))
+	g.printf("\treturn s.Contract.EventFilter(%s", sname)
+	if len(args) > 0 {
+		g.printf(", %s", strings.Join(args, ", "))
+	}
+	g.printf(")\n}\n\n")
+}
+
+// Generate returns the Go source of the binding type named name in the
+// package pkg for the methods of the SCORE.
+func Generate(pkg, name string, methods []*scoreapi.Method) ([]byte, error) {
+	if !token.IsIdentifier(pkg) || !token.IsIdentifier(name) || !token.IsExported(name) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidName(pkg=%s,name=%s)", pkg, name)
+	}
+	g := &generator{
+		name:  name,
+		types: names{name: true},
+	}
+	g.printf("// %s is the binding of the SCORE.\n", name)
+	g.printf("type %s struct {\n\t*bind.Contract\n}\n\n", name)
+	g.printf("// New%s returns the binding of the SCORE at the address.\n", name)
+	g.printf("func New%s(c *client.ClientV3, addr module.Address, nid int64) *%s {\n", name, name)
+	g.printf("\treturn &%s{bind.NewContract(c, addr, nid)}\n}\n\n", name)
+
+	fnames := names{}
+	for _, m := range methods {
+		switch {
+		case m.IsReadOnly():
+			g.readOnly(m, fnames.unique(exportedName(m.Name)))
+		case m.IsExternal():
+			g.writable(m, fnames.unique(exportedName(m.Name)))
+		}
+	}
+	for _, m := range methods {
+		if m.IsEvent() {
+			g.event(m)
+		}
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by scorebind; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	src.WriteString("import (\n" +
+		"\t\"math/big\"\n\n" +
+		"\t\"github.com/icon-project/goloop/client\"\n" +
+		"\t\"github.com/icon-project/goloop/client/bind\"\n" +
+		"\t\"github.com/icon-project/goloop/module\"\n" +
+		"\t\"github.com/icon-project/goloop/server\"\n" +
+		")\n\n")
+	src.Write(g.body.Bytes())
+	src.Write(g.structs.Bytes())
+	out, err := imports.Process("", src.Bytes(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "FailToFormat")
+	}
+	return out, nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package bind
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/service/scoreapi"
+)
+
+const testAPI = `[
+  {
+    "type": "function", "name": "balanceOf",
+    "inputs": [ { "name": "_owner", "type": "Address" } ],
+    "outputs": [ { "type": "int" } ],
+    "readonly": "0x1"
+  },
+  {
+    "type": "function", "name": "transfer",
+    "inputs": [
+      { "name": "_to", "type": "Address" },
+      { "name": "_value", "type": "int" },
+      { "name": "_data", "type": "bytes", "default": null }
+    ],
+    "outputs": []
+  },
+  {
+    "type": "function", "name": "setConfig",
+    "inputs": [
+      {
+        "name": "config", "type": "[]struct",
+        "fields": [
+          { "name": "name", "type": "str" },
+          { "name": "owners", "type": "[]Address" },
+          { "name": "option", "type": "struct",
+            "fields": [ { "name": "enabled", "type": "bool" } ] }
+        ]
+      }
+    ],
+    "outputs": [],
+    "payable": "0x1"
+  },
+  { "type": "fallback", "name": "fallback", "inputs": [], "payable": "0x1" },
+  {
+    "type": "eventlog", "name": "Transfer",
+    "inputs": [
+      { "name": "_from", "type": "Address", "indexed": "0x1" },
+      { "name": "_to", "type": "Address", "indexed": "0x1" },
+      { "name": "_value", "type": "int" },
+      { "name": "_data", "type": "bytes" }
+    ]
+  }
+]`
+
+func TestParseAPI(t *testing.T) {
+	methods, err := ParseAPI([]byte(testAPI))
+	assert.NoError(t, err)
+	assert.Len(t, methods, 5)
+
+	assert.True(t, methods[0].IsReadOnly())
+	assert.Equal(t, []scoreapi.DataType{scoreapi.Integer}, methods[0].Outputs)
+
+	assert.True(t, methods[1].IsExternal())
+	assert.False(t, methods[1].IsReadOnly())
+	assert.Equal(t, 2, methods[1].Indexed)
+
+	assert.True(t, methods[2].IsPayable())
+	assert.Equal(t, scoreapi.ListTypeOf(1, scoreapi.Struct), methods[2].Inputs[0].Type)
+	assert.Len(t, methods[2].Inputs[0].Fields, 3)
+	assert.Len(t, methods[2].Inputs[0].Fields[2].Fields, 1)
+
+	assert.True(t, methods[3].IsFallback())
+
+	assert.True(t, methods[4].IsEvent())
+	assert.Equal(t, 2, methods[4].Indexed)
+	assert.Equal(t, "Transfer(Address,Address,int,bytes)", methods[4].Signature())
+
+	_, err = ParseAPI([]byte(`[{"type":"function","name":"f","inputs":[{"name":"a","type":"float"}]}]`))
+	assert.Error(t, err)
+	_, err = ParseAPI([]byte(`[{"type":"unknown","name":"f","inputs":[]}]`))
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	methods, err := ParseAPI([]byte(testAPI))
+	assert.NoError(t, err)
+
+	_, err = Generate("token", "token", methods)
+	assert.Error(t, err)
+
+	src, err := Generate("token", "Token", methods)
+	assert.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "token.go", src, parser.AllErrors)
+	assert.NoError(t, err)
+
+	code := strings.Join(strings.Fields(string(src)), " ")
+	for _, s := range []string{
+		"func NewToken(c *client.ClientV3, addr module.Address, nid int64) *Token",
+		"func (s *Token) BalanceOf(owner module.Address) (*big.Int, error)",
+		"func (s *Token) Transfer(to module.Address, value *big.Int, data []byte) (*bind.TxBuilder, error)",
+		"func (s *Token) SetConfig(config []SetConfigConfig) (*bind.TxBuilder, error)",
+		"Owners []module.Address `json:\"owners\"`",
+		"Option *SetConfigConfigOption `json:\"option\"`",
+		"Enabled bool `json:\"enabled\"`",
+		"const TransferEventSignature = \"Transfer(Address,Address,int,bytes)\"",
+		"func (s *Token) ParseTransferEvent(el *client.EventLog) (*TransferEvent, error)",
+		"func (s *Token) FilterTransferEvent(from module.Address, to module.Address) (*server.EventFilter, error)",
+	} {
+		assert.Contains(t, code, s)
+	}
+	assert.NotContains(t, code, "Fallback")
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "Owner", exportedName("_owner"))
+	assert.Equal(t, "TokenUri", exportedName("token_uri"))
+	assert.Equal(t, "X1st", exportedName("1st"))
+	assert.Equal(t, "type_", localName("type"))
+	assert.Equal(t, "balanceOf", localName("balanceOf"))
+
+	ns := names{}
+	assert.Equal(t, "a", ns.unique("a"))
+	assert.Equal(t, "a2", ns.unique("a"))
+}
@@ -0,0 +1,277 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package bind
+
+import (
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+)
+
+var (
+	bigIntType  = reflect.TypeOf((*big.Int)(nil))
+	addressType = reflect.TypeOf((*module.Address)(nil)).Elem()
+	bytesType   = reflect.TypeOf([]byte(nil))
+)
+
+// fieldName returns the name of the field in the SCORE API. It uses
+// the name in json tag if it exists.
+func fieldName(f reflect.StructField) string {
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// EncodeValue returns the JSON object of the value for parameters of the
+// SCORE. Nil values are encoded to nil.
+func EncodeValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return encodeValue(reflect.ValueOf(v))
+}
+
+func encodeValue(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	switch v.Type() {
+	case bigIntType:
+		return intconv.FormatBigInt(v.Interface().(*big.Int)), nil
+	case bytesType:
+		return "0x" + hex.EncodeToString(v.Bytes()), nil
+	}
+	if v.Type().Implements(addressType) {
+		return v.Interface().(module.Address).String(), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "0x1", nil
+		}
+		return "0x0", nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intconv.FormatInt(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return intconv.FormatUint(v.Uint()), nil
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			jso, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = jso
+		}
+		return list, nil
+	case reflect.Struct:
+		obj := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			jso, err := encodeValue(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			obj[fieldName(f)] = jso
+		}
+		return obj, nil
+	case reflect.Map:
+		obj := make(map[string]interface{})
+		for itr := v.MapRange(); itr.Next(); {
+			if itr.Key().Kind() != reflect.String {
+				return nil, errors.IllegalArgumentError.Errorf(
+					"InvalidKeyType(type=%s)", v.Type())
+			}
+			jso, err := encodeValue(itr.Value())
+			if err != nil {
+				return nil, err
+			}
+			obj[itr.Key().String()] = jso
+		}
+		return obj, nil
+	case reflect.Ptr, reflect.Interface:
+		return encodeValue(v.Elem())
+	default:
+		return nil, errors.IllegalArgumentError.Errorf(
+			"UnsupportedType(type=%s)", v.Type())
+	}
+}
+
+// EncodeParams returns the parameters of the call with the values. Nil
+// values are omitted for using their default values.
+func EncodeParams(names []string, values ...interface{}) (map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	for i, name := range names {
+		jso, err := EncodeValue(values[i])
+		if err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err,
+				"InvalidParam(name=%s)", name)
+		}
+		if jso != nil {
+			params[name] = jso
+		}
+	}
+	return params, nil
+}
+
+// DecodeValue stores the JSON object returned by the SCORE to the value
+// pointed by ptr.
+func DecodeValue(jso interface{}, ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidTarget(type=%T)", ptr)
+	}
+	return decodeValue(jso, v.Elem())
+}
+
+func invalidValue(jso interface{}, t reflect.Type) error {
+	return errors.IllegalArgumentError.Errorf(
+		"InvalidValue(value=%v,type=%s)", jso, t)
+}
+
+func decodeValue(jso interface{}, v reflect.Value) error {
+	t := v.Type()
+	if jso == nil {
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(jso))
+		return nil
+	}
+	s, isString := jso.(string)
+	switch t {
+	case bigIntType:
+		if !isString {
+			return invalidValue(jso, t)
+		}
+		value := new(big.Int)
+		if err := intconv.ParseBigInt(value, s); err != nil {
+			return invalidValue(jso, t)
+		}
+		v.Set(reflect.ValueOf(value))
+		return nil
+	case bytesType:
+		if !isString || !strings.HasPrefix(s, "0x") {
+			return invalidValue(jso, t)
+		}
+		bs, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return invalidValue(jso, t)
+		}
+		v.SetBytes(bs)
+		return nil
+	case addressType:
+		if !isString {
+			return invalidValue(jso, t)
+		}
+		addr, err := common.NewAddressFromString(s)
+		if err != nil {
+			return invalidValue(jso, t)
+		}
+		v.Set(reflect.ValueOf(addr))
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if !isString || (s != "0x0" && s != "0x1") {
+			return invalidValue(jso, t)
+		}
+		v.SetBool(s == "0x1")
+	case reflect.String:
+		if !isString {
+			return invalidValue(jso, t)
+		}
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isString {
+			return invalidValue(jso, t)
+		}
+		value, err := intconv.ParseInt(s, t.Bits())
+		if err != nil {
+			return invalidValue(jso, t)
+		}
+		v.SetInt(value)
+	case reflect.Slice:
+		list, ok := jso.([]interface{})
+		if !ok {
+			return invalidValue(jso, t)
+		}
+		sv := reflect.MakeSlice(t, len(list), len(list))
+		for i, item := range list {
+			if err := decodeValue(item, sv.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(sv)
+	case reflect.Map:
+		obj, ok := jso.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			return invalidValue(jso, t)
+		}
+		mv := reflect.MakeMapWithSize(t, len(obj))
+		for key, item := range obj {
+			ev := reflect.New(t.Elem()).Elem()
+			if err := decodeValue(item, ev); err != nil {
+				return err
+			}
+			mv.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), ev)
+		}
+		v.Set(mv)
+	case reflect.Struct:
+		obj, ok := jso.(map[string]interface{})
+		if !ok {
+			return invalidValue(jso, t)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if err := decodeValue(obj[fieldName(f)], v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		ev := reflect.New(t.Elem())
+		if err := decodeValue(jso, ev.Elem()); err != nil {
+			return err
+		}
+		v.Set(ev)
+	default:
+		return errors.IllegalArgumentError.Errorf(
+			"UnsupportedType(type=%s)", t)
+	}
+	return nil
+}
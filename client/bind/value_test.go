@@ -0,0 +1,120 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package bind
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+type testOption struct {
+	Enabled bool `json:"enabled"`
+}
+
+type testConfig struct {
+	Name   string           `json:"name"`
+	Owners []module.Address `json:"owners"`
+	Amount *big.Int         `json:"amount"`
+	Data   []byte           `json:"data"`
+	Option *testOption      `json:"option"`
+}
+
+func TestEncodeDecodeValue(t *testing.T) {
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	cfg := []testConfig{{
+		Name:   "test",
+		Owners: []module.Address{addr},
+		Amount: big.NewInt(-16),
+		Data:   []byte{0x12, 0x34},
+		Option: &testOption{Enabled: true},
+	}}
+	jso, err := EncodeValue(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name":   "test",
+			"owners": []interface{}{addr.String()},
+			"amount": "-0x10",
+			"data":   "0x1234",
+			"option": map[string]interface{}{"enabled": "0x1"},
+		},
+	}, jso)
+
+	var cfg2 []testConfig
+	assert.NoError(t, DecodeValue(jso, &cfg2))
+	assert.Equal(t, cfg, cfg2)
+
+	var value *big.Int
+	assert.Error(t, DecodeValue("test", &value))
+	assert.Error(t, DecodeValue(jso, value))
+
+	var any interface{}
+	assert.NoError(t, DecodeValue(jso, &any))
+	assert.Equal(t, jso, any)
+}
+
+func TestEncodeParams(t *testing.T) {
+	var data []byte
+	var to module.Address
+	params, err := EncodeParams([]string{"_value", "_to", "_data"}, big.NewInt(1), to, data)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"_value": "0x1"}, params)
+
+	_, err = EncodeParams([]string{"_value"}, 1.5)
+	assert.Error(t, err)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestContract_Event(t *testing.T) {
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+	c := NewContract(nil, score, 1)
+	sig := "Transfer(Address,Address,int)"
+
+	f, err := c.EventFilter(sig, from, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Compile())
+	assert.True(t, f.Addr.Equal(score))
+	assert.Equal(t, []*string{strPtr(from.String()), nil}, f.Indexed)
+
+	el := &client.EventLog{
+		Addr:    jsonrpc.Address(score.String()),
+		Indexed: []*string{strPtr(sig), strPtr(from.String()), nil},
+		Data:    []*string{strPtr("0x10")},
+	}
+	var evFrom, evTo module.Address
+	var evValue *big.Int
+	assert.NoError(t, c.UnpackEvent(el, sig, &evFrom, &evTo, &evValue))
+	assert.True(t, from.Equal(evFrom))
+	assert.Nil(t, evTo)
+	assert.Equal(t, int64(16), evValue.Int64())
+
+	assert.Error(t, c.UnpackEvent(el, "Approval(Address,Address,int)", &evFrom, &evTo, &evValue))
+	assert.Error(t, c.UnpackEvent(el, sig, &evFrom, &evTo))
+
+	other := NewContract(nil, common.MustNewAddressFromString("cx0000000000000000000000000000000000000002"), 1)
+	assert.Error(t, other.UnpackEvent(el, sig, &evFrom, &evTo, &evValue))
+}
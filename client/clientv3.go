@@ -148,6 +148,26 @@ type NetworkInfo struct {
 	StepPrice jsonrpc.HexInt `json:"stepPrice"`
 }
 
+//refer service/manager.go accountProof.ToJSON
+type StorageProof struct {
+	Key   jsonrpc.HexBytes   `json:"key"`
+	Value jsonrpc.HexBytes   `json:"value"`
+	Proof []jsonrpc.HexBytes `json:"proof"`
+}
+
+//refer server/v3/api_v3.go getProof
+type AccountProof struct {
+	Address       jsonrpc.Address    `json:"address"`
+	Height        jsonrpc.HexInt     `json:"height"`
+	BlockHash     jsonrpc.HexBytes   `json:"blockHash"`
+	StateHash     jsonrpc.HexBytes   `json:"stateHash"`
+	Account       jsonrpc.HexBytes   `json:"account"`
+	Balance       jsonrpc.HexInt     `json:"balance"`
+	StorageHash   jsonrpc.HexBytes   `json:"storageHash,omitempty"`
+	AccountProof  []jsonrpc.HexBytes `json:"accountProof"`
+	StorageProofs []StorageProof     `json:"storageProofs"`
+}
+
 //refer service/state/btp.go:887 network.ToJSON
 //refer server/v3/api_v3.go:692 getBTPNetworkInfo
 type BTPNetworkInfo struct {
@@ -273,24 +293,43 @@ func (c *ClientV3) GetTransactionByHash(param *v3.TransactionHashParam) (*Transa
 	return t, nil
 }
 
-var txSerializeExcludes = map[string]bool{"signature": true}
+var txSerializeExcludes = map[string]bool{"signature": true, "feePayerSignature": true}
 
-func SignTransaction(w module.Wallet, param *v3.TransactionParam) error {
+func signTransactionParam(w module.Wallet, param *v3.TransactionParam) (string, error) {
 	js, err := json.Marshal(param)
 	if err != nil {
-		return err
+		return "", err
 	}
 	bs, err := transaction.SerializeJSON(js, nil, txSerializeExcludes)
 	if err != nil {
-		return err
+		return "", err
 	}
 	bs = append([]byte("icx_sendTransaction."), bs...)
 	sig, err := w.Sign(crypto.SHA3Sum256(bs))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func SignTransaction(w module.Wallet, param *v3.TransactionParam) error {
+	sig, err := signTransactionParam(w, param)
 	if err != nil {
 		return err
 	}
+	param.Signature = sig
+	return nil
+}
 
-	param.Signature = base64.StdEncoding.EncodeToString(sig)
+// SignTransactionAsFeePayer sets the wallet as the fee payer of the
+// transaction and signs it. The sender should sign the transaction after it.
+func SignTransactionAsFeePayer(w module.Wallet, param *v3.TransactionParam) error {
+	param.FeePayer = jsonrpc.Address(w.Address().String())
+	sig, err := signTransactionParam(w, param)
+	if err != nil {
+		return err
+	}
+	param.FeePayerSignature = sig
 	return nil
 }
 
@@ -438,6 +477,15 @@ func (c *ClientV3) GetScoreStatus(param *v3.ScoreAddressParam) (interface{}, err
 	return result, nil
 }
 
+func (c *ClientV3) GetProof(param *v3.ProofAccountParam) (*AccountProof, error) {
+	result := &AccountProof{}
+	_, err := c.Do("icx_getProof", param, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *ClientV3) GetNetworkInfo() (*NetworkInfo, error) {
 	var result *NetworkInfo
 	_, err := c.Do("icx_getNetworkInfo", nil, &result)
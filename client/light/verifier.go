@@ -0,0 +1,255 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package light implements a light client which follows the chain with block
+// headers and votes only. It starts from a trusted block, and verifies
+// following headers with commit votes signed by the tracked validators.
+package light
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+	"github.com/icon-project/goloop/service/state"
+)
+
+var ErrVerify = errors.NewBase(errors.IllegalArgumentError, "VerifyError")
+
+// Source provides data for the verifier.
+type Source interface {
+	// GetBlockHeaderByHeight returns bytes of the block header.
+	GetBlockHeaderByHeight(height int64) ([]byte, error)
+	// GetVotesByHeight returns bytes of the commit votes for the block.
+	GetVotesByHeight(height int64) ([]byte, error)
+	// GetDataByHash returns bytes of the data (e.g. validator list).
+	GetDataByHash(hash []byte) ([]byte, error)
+}
+
+type clientSource struct {
+	c *client.ClientV3
+}
+
+func (s *clientSource) GetBlockHeaderByHeight(height int64) ([]byte, error) {
+	return s.c.GetBlockHeaderByHeight(&v3.BlockHeightParam{
+		Height: jsonrpc.HexInt(intconv.FormatInt(height)),
+	})
+}
+
+func (s *clientSource) GetVotesByHeight(height int64) ([]byte, error) {
+	return s.c.GetVotesByHeight(&v3.BlockHeightParam{
+		Height: jsonrpc.HexInt(intconv.FormatInt(height)),
+	})
+}
+
+func (s *clientSource) GetDataByHash(hash []byte) ([]byte, error) {
+	return s.c.GetDataByHash(&v3.DataHashParam{
+		Hash: jsonrpc.HexBytes("0x" + hex.EncodeToString(hash)),
+	})
+}
+
+// NewClientSource returns Source using JSON-RPC APIs of the node.
+func NewClientSource(c *client.ClientV3) Source {
+	return &clientSource{c}
+}
+
+// Verifier tracks the last verified header and validators of the next block.
+type Verifier struct {
+	src   Source
+	dbase db.Database
+
+	header     *block.Header
+	validators module.ValidatorList
+}
+
+// NewVerifier returns a verifier starting from the trusted block of the
+// height and the id.
+func NewVerifier(src Source, height int64, id []byte) (*Verifier, error) {
+	v := &Verifier{
+		src:   src,
+		dbase: db.NewMapDB(),
+	}
+	h, err := v.getHeader(height)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(h.ID(), id) {
+		return nil, errors.Wrapf(ErrVerify,
+			"InvalidTrustedBlock(height=%d,exp=%x,real=%x)", height, id, h.ID())
+	}
+	if v.validators, err = v.getValidators(h.NextValidatorsHash()); err != nil {
+		return nil, err
+	}
+	v.header = h
+	return v, nil
+}
+
+func (v *Verifier) Height() int64 {
+	return v.header.Height()
+}
+
+// Header returns the last verified header.
+func (v *Verifier) Header() *block.Header {
+	return v.header
+}
+
+// Validators returns validators of the next block.
+func (v *Verifier) Validators() module.ValidatorList {
+	return v.validators
+}
+
+func (v *Verifier) getHeader(height int64) (*block.Header, error) {
+	bs, err := v.src.GetBlockHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	h, err := block.NewHeaderFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+	if h.Height() != height {
+		return nil, errors.Wrapf(ErrVerify,
+			"InvalidHeight(exp=%d,real=%d)", height, h.Height())
+	}
+	return h, nil
+}
+
+func (v *Verifier) getValidators(hash []byte) (module.ValidatorList, error) {
+	if len(hash) == 0 {
+		return nil, errors.Wrap(ErrVerify, "NoValidators")
+	}
+	bk, err := v.dbase.GetBucket(db.BytesByHash)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := bk.Has(hash); err != nil {
+		return nil, err
+	} else if !ok {
+		bs, err := v.src.GetDataByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(crypto.SHA3Sum256(bs), hash) {
+			return nil, errors.Wrapf(ErrVerify, "InvalidValidators(hash=%x)", hash)
+		}
+		if err := bk.Set(hash, bs); err != nil {
+			return nil, err
+		}
+	}
+	vl, err := state.ValidatorSnapshotFromHash(v.dbase, hash)
+	if err != nil {
+		return nil, err
+	}
+	if vl.Len() == 0 {
+		return nil, errors.Wrap(ErrVerify, "NoValidators")
+	}
+	return vl, nil
+}
+
+func (v *Verifier) verifyVotes(h *block.Header) error {
+	bs, err := v.src.GetVotesByHeight(h.Height())
+	if err != nil {
+		return err
+	}
+	votes := consensus.NewCommitVoteSetFromBytes(bs)
+	if votes == nil {
+		return errors.Wrapf(ErrVerify, "InvalidVotes(height=%d)", h.Height())
+	}
+	if _, err := votes.VerifyBlock(h, v.validators); err != nil {
+		return errors.Wrapf(ErrVerify,
+			"InvalidVotes(height=%d,err=%v)", h.Height(), err)
+	}
+	return nil
+}
+
+// TrySkip tries to verify the header of the height with the votes signed by
+// the tracked validators. It succeeds only if the validators of the block
+// are the same as the tracked one, which is checked with the header of the
+// previous block. It returns false if the validators are changed.
+func (v *Verifier) TrySkip(height int64) (bool, error) {
+	if height <= v.Height() {
+		return false, errors.IllegalArgumentError.Errorf(
+			"AlreadyVerified(height=%d,last=%d)", height, v.Height())
+	}
+	prev := v.header
+	if height-1 > v.Height() {
+		var err error
+		if prev, err = v.getHeader(height - 1); err != nil {
+			return false, err
+		}
+		if !bytes.Equal(prev.NextValidatorsHash(), v.header.NextValidatorsHash()) {
+			return false, nil
+		}
+	}
+	h, err := v.getHeader(height)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(h.PrevID(), prev.ID()) {
+		return false, errors.Wrapf(ErrVerify,
+			"InvalidPrevID(height=%d,exp=%x,real=%x)", height, prev.ID(), h.PrevID())
+	}
+	if err := v.verifyVotes(h); err != nil {
+		return false, err
+	}
+	if !bytes.Equal(h.NextValidatorsHash(), v.header.NextValidatorsHash()) {
+		if v.validators, err = v.getValidators(h.NextValidatorsHash()); err != nil {
+			return false, err
+		}
+	}
+	v.header = h
+	return true, nil
+}
+
+// VerifyNext verifies the header of the next block.
+func (v *Verifier) VerifyNext() error {
+	_, err := v.TrySkip(v.Height() + 1)
+	return err
+}
+
+// SyncTo verifies headers up to the height. If skip is true, it skips
+// heights while the validators are unchanged, otherwise it verifies all
+// headers. cb is called for each verified header if it's not nil.
+func (v *Verifier) SyncTo(height int64, skip bool, cb func(h *block.Header)) error {
+	for v.Height() < height {
+		target := v.Height() + 1
+		if skip {
+			target = height
+		}
+		for {
+			if ok, err := v.TrySkip(target); err != nil {
+				return err
+			} else if ok {
+				break
+			}
+			// always succeeds for the next height
+			target = v.Height() + (target-v.Height())/2
+		}
+		if cb != nil {
+			cb(v.header)
+		}
+	}
+	return nil
+}
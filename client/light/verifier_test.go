@@ -0,0 +1,210 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package light
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+type testChain struct {
+	headers [][]byte
+	votes   [][]byte
+	data    map[string][]byte
+
+	headerReqs int
+}
+
+func (c *testChain) GetBlockHeaderByHeight(height int64) ([]byte, error) {
+	c.headerReqs++
+	if height < 0 || height >= int64(len(c.headers)) {
+		return nil, errors.NotFoundError.Errorf("NoBlock(height=%d)", height)
+	}
+	return c.headers[height], nil
+}
+
+func (c *testChain) GetVotesByHeight(height int64) ([]byte, error) {
+	if height < 0 || height >= int64(len(c.votes)) {
+		return nil, errors.NotFoundError.Errorf("NoVotes(height=%d)", height)
+	}
+	return c.votes[height], nil
+}
+
+func (c *testChain) GetDataByHash(hash []byte) ([]byte, error) {
+	if bs, ok := c.data[string(hash)]; ok {
+		return bs, nil
+	}
+	return nil, errors.NotFoundError.Errorf("NoData(hash=%x)", hash)
+}
+
+func newTestWallets(n int) []module.Wallet {
+	ws := make([]module.Wallet, n)
+	for i := range ws {
+		ws[i] = wallet.New()
+	}
+	return ws
+}
+
+func newValidatorList(t *testing.T, ws []module.Wallet) module.ValidatorList {
+	vs := make([]module.Validator, len(ws))
+	for i, w := range ws {
+		v, err := state.ValidatorFromPublicKey(w.PublicKey())
+		assert.NoError(t, err)
+		vs[i] = v
+	}
+	vl, err := state.ValidatorSnapshotFromSlice(db.NewMapDB(), vs)
+	assert.NoError(t, err)
+	return vl
+}
+
+// newTestChain returns a chain whose validators are changed at the heights
+// in changes. Wallets used for each block are returned.
+func newTestChain(t *testing.T, height int64, changes ...int64) (*testChain, [][]module.Wallet) {
+	c := &testChain{data: make(map[string][]byte)}
+	ws := newTestWallets(4)
+	vl := newValidatorList(t, ws)
+	c.data[string(vl.Hash())] = vl.Bytes()
+	signers := make([][]module.Wallet, height+1)
+	var prevID []byte
+	for h := int64(0); h <= height; h++ {
+		signers[h] = ws
+		if len(changes) > 0 && changes[0] == h {
+			changes = changes[1:]
+			ws = newTestWallets(4)
+			vl = newValidatorList(t, ws)
+			c.data[string(vl.Hash())] = vl.Bytes()
+		}
+		hbs := codec.BC.MustMarshalToBytes(&block.V2HeaderFormat{
+			Version:            module.BlockVersion2,
+			Height:             h,
+			Timestamp:          h * 1000,
+			PrevID:             prevID,
+			NextValidatorsHash: vl.Hash(),
+		})
+		hdr, err := block.NewHeaderFromBytes(hbs)
+		assert.NoError(t, err)
+		prevID = hdr.ID()
+		c.headers = append(c.headers, hbs)
+
+		var msgs []*consensus.VoteMessage
+		if h > 0 {
+			for _, w := range signers[h][:3] {
+				msgs = append(msgs, consensus.NewVoteMessage(
+					w, consensus.VoteTypePrecommit, h, 0, hdr.ID(),
+					nil, h*1000, nil, nil, 0,
+				))
+			}
+		}
+		c.votes = append(c.votes, consensus.NewCommitVoteList(nil, msgs...).Bytes())
+	}
+	return c, signers
+}
+
+func headerID(t *testing.T, c *testChain, height int64) []byte {
+	h, err := block.NewHeaderFromBytes(c.headers[height])
+	assert.NoError(t, err)
+	return h.ID()
+}
+
+func TestVerifier_Basic(t *testing.T) {
+	c, _ := newTestChain(t, 10, 5)
+
+	_, err := NewVerifier(c, 0, make([]byte, 32))
+	assert.True(t, errors.Is(err, ErrVerify))
+
+	v, err := NewVerifier(c, 0, headerID(t, c, 0))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, v.Height())
+
+	var heights []int64
+	err = v.SyncTo(10, false, func(h *block.Header) {
+		heights = append(heights, h.Height())
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, heights)
+	assert.Equal(t, headerID(t, c, 10), v.Header().ID())
+}
+
+func TestVerifier_Skip(t *testing.T) {
+	c, _ := newTestChain(t, 100, 40)
+
+	v, err := NewVerifier(c, 0, headerID(t, c, 0))
+	assert.NoError(t, err)
+	c.headerReqs = 0
+
+	var heights []int64
+	err = v.SyncTo(100, true, func(h *block.Header) {
+		heights = append(heights, h.Height())
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, headerID(t, c, 100), v.Header().ID())
+	assert.Contains(t, heights, int64(40))
+	assert.Less(t, c.headerReqs, 40)
+
+	// no validator change, then skip directly
+	c, _ = newTestChain(t, 100)
+	v, err = NewVerifier(c, 0, headerID(t, c, 0))
+	assert.NoError(t, err)
+	ok, err := v.TrySkip(100)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, 100, v.Height())
+}
+
+func TestVerifier_InvalidVotes(t *testing.T) {
+	c, signers := newTestChain(t, 3)
+
+	// not enough votes
+	hdr, err := block.NewHeaderFromBytes(c.headers[2])
+	assert.NoError(t, err)
+	var msgs []*consensus.VoteMessage
+	for _, w := range signers[2][:2] {
+		msgs = append(msgs, consensus.NewVoteMessage(
+			w, consensus.VoteTypePrecommit, 2, 0, hdr.ID(), nil, 0, nil, nil, 0,
+		))
+	}
+	c.votes[2] = consensus.NewCommitVoteList(nil, msgs...).Bytes()
+
+	v, err := NewVerifier(c, 0, headerID(t, c, 0))
+	assert.NoError(t, err)
+	assert.NoError(t, v.VerifyNext())
+	err = v.VerifyNext()
+	assert.True(t, errors.Is(err, ErrVerify))
+	assert.EqualValues(t, 1, v.Height())
+
+	// votes by others
+	ws := newTestWallets(4)
+	msgs = nil
+	for _, w := range ws[:3] {
+		msgs = append(msgs, consensus.NewVoteMessage(
+			w, consensus.VoteTypePrecommit, 2, 0, hdr.ID(), nil, 0, nil, nil, 0,
+		))
+	}
+	c.votes[2] = consensus.NewCommitVoteList(nil, msgs...).Bytes()
+	err = v.VerifyNext()
+	assert.True(t, errors.Is(err, ErrVerify))
+}
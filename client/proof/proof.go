@@ -0,0 +1,297 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package proof verifies proofs returned by icx_getProofForResult and
+// icx_getProofForEvents against a block header, so that light clients can
+// check receipts and events without trusting the node serving them.
+package proof
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie/ompt"
+	"github.com/icon-project/goloop/icon/merkle/hexary"
+	"github.com/icon-project/goloop/module"
+)
+
+var ErrVerify = errors.NewBase(errors.IllegalArgumentError, "VerifyError")
+
+// BlockHeader is a header returned by icx_getBlockHeaderByHeight.
+type BlockHeader struct {
+	block.V2HeaderFormat
+	result *resultFormat
+}
+
+// resultFormat is the format of BlockHeader.Result.
+type resultFormat struct {
+	StateHash         []byte
+	PatchReceiptHash  []byte
+	NormalReceiptHash []byte
+	ExtensionData     []byte
+	BTPData           []byte
+}
+
+func NewBlockHeaderFromBytes(bs []byte) (*BlockHeader, error) {
+	h := new(BlockHeader)
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &h.V2HeaderFormat); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidHeader")
+	}
+	if h.Version != module.BlockVersion2 {
+		return nil, errors.UnsupportedError.Errorf("UnsupportedBlockVersion(ver=%d)", h.Version)
+	}
+	h.result = new(resultFormat)
+	if len(h.Result) > 0 {
+		if _, err := codec.BC.UnmarshalFromBytes(h.Result, h.result); err != nil {
+			return nil, errors.IllegalArgumentError.Wrap(err, "InvalidResult")
+		}
+	}
+	return h, nil
+}
+
+// ID returns the hash of the block.
+func (h *BlockHeader) ID() []byte {
+	return crypto.SHA3Sum256(codec.BC.MustMarshalToBytes(&h.V2HeaderFormat))
+}
+
+// NormalReceiptHash returns root hash of the receipts for the normal
+// transactions of the previous block.
+func (h *BlockHeader) NormalReceiptHash() []byte {
+	return h.result.NormalReceiptHash
+}
+
+func (h *BlockHeader) PatchReceiptHash() []byte {
+	return h.result.PatchReceiptHash
+}
+
+// StateHash returns root hash of the world state after the transactions of
+// the previous block.
+func (h *BlockHeader) StateHash() []byte {
+	return h.result.StateHash
+}
+
+type EventLog struct {
+	Addr    common.Address
+	Indexed [][]byte
+	Data    [][]byte
+}
+
+type eventLogJSON struct {
+	Addr    common.Address    `json:"scoreAddress"`
+	Indexed []json.RawMessage `json:"indexed"`
+	Data    []common.HexBytes `json:"data"`
+}
+
+// MarshalJSON shows the signature of the event as a string and others as
+// hex bytes since the types of parameters are unknown.
+func (e *EventLog) MarshalJSON() ([]byte, error) {
+	jso := eventLogJSON{
+		Addr:    e.Addr,
+		Indexed: make([]json.RawMessage, len(e.Indexed)),
+		Data:    make([]common.HexBytes, len(e.Data)),
+	}
+	for i, v := range e.Indexed {
+		var s interface{} = common.HexBytes(v)
+		if i == 0 {
+			s = string(v)
+		}
+		jso.Indexed[i], _ = json.Marshal(s)
+	}
+	for i, v := range e.Data {
+		jso.Data[i] = v
+	}
+	return json.Marshal(&jso)
+}
+
+// Receipt has the fields of a receipt required for verification. The fields
+// after EventLogsHash are not decoded.
+type Receipt struct {
+	Status             module.Status
+	To                 common.Address
+	CumulativeStepUsed common.HexInt
+	StepUsed           common.HexInt
+	StepPrice          common.HexInt
+	LogsBloom          []byte
+	EventLogs          []*EventLog
+	SCOREAddress       *common.Address
+	EventLogsHash      []byte
+}
+
+type receiptJSON struct {
+	Status             common.HexUint16 `json:"status"`
+	To                 common.Address   `json:"to"`
+	CumulativeStepUsed common.HexInt    `json:"cumulativeStepUsed"`
+	StepUsed           common.HexInt    `json:"stepUsed"`
+	StepPrice          common.HexInt    `json:"stepPrice"`
+	SCOREAddress       *common.Address  `json:"scoreAddress,omitempty"`
+	EventLogs          []*EventLog      `json:"eventLogs,omitempty"`
+	EventLogsHash      common.HexBytes  `json:"eventLogsHash,omitempty"`
+}
+
+func (r *Receipt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&receiptJSON{
+		Status:             common.HexUint16{Value: uint16(r.Status)},
+		To:                 r.To,
+		CumulativeStepUsed: r.CumulativeStepUsed,
+		StepUsed:           r.StepUsed,
+		StepPrice:          r.StepPrice,
+		SCOREAddress:       r.SCOREAddress,
+		EventLogs:          r.EventLogs,
+		EventLogsHash:      r.EventLogsHash,
+	})
+}
+
+func keyForIndex(idx int) []byte {
+	return codec.BC.MustMarshalToBytes(uint(idx))
+}
+
+// VerifyMPT verifies the proof of the key in Merkle Patricia Trie of the
+// root, and returns the value.
+func VerifyMPT(root, key []byte, proof [][]byte) ([]byte, error) {
+	if len(root) == 0 {
+		return nil, errors.Wrap(ErrVerify, "EmptyRoot")
+	}
+	if len(proof) == 0 {
+		return nil, errors.Wrap(ErrVerify, "EmptyProof")
+	}
+	value, err := ompt.NewMPTForBytes(db.NewMapDB(), root).Prove(key, proof)
+	if err != nil {
+		return nil, errors.Wrapf(ErrVerify, "InvalidProof(key=%x,err=%v)", key, err)
+	}
+	return value, nil
+}
+
+// VerifyReceipt verifies the proof of the receipt at the index in the
+// receipts of normal transactions of the header.
+func VerifyReceipt(h *BlockHeader, idx int, proof [][]byte) (*Receipt, error) {
+	value, err := VerifyMPT(h.NormalReceiptHash(), keyForIndex(idx), proof)
+	if err != nil {
+		return nil, err
+	}
+	r := new(Receipt)
+	if _, err := codec.BC.UnmarshalFromBytes(value, r); err != nil {
+		return nil, errors.Wrapf(ErrVerify, "InvalidReceipt(err=%v)", err)
+	}
+	return r, nil
+}
+
+// VerifyEvent verifies the proof of the event at the index in the receipt.
+func VerifyEvent(r *Receipt, idx int, proof [][]byte) (*EventLog, error) {
+	if r.EventLogsHash == nil {
+		return nil, errors.UnsupportedError.New("NoEventLogsHash")
+	}
+	value, err := VerifyMPT(r.EventLogsHash, keyForIndex(idx), proof)
+	if err != nil {
+		return nil, err
+	}
+	ev := new(EventLog)
+	if _, err := codec.BC.UnmarshalFromBytes(value, ev); err != nil {
+		return nil, errors.Wrapf(ErrVerify, "InvalidEvent(err=%v)", err)
+	}
+	return ev, nil
+}
+
+// VerifyReceiptAndEvents verifies proofs returned by icx_getProofForEvents.
+// The first proof is for the receipt, and others are for the events.
+func VerifyReceiptAndEvents(
+	h *BlockHeader, idx int, events []int, proofs [][][]byte,
+) (*Receipt, []*EventLog, error) {
+	if len(proofs) != len(events)+1 {
+		return nil, nil, errors.Wrapf(ErrVerify,
+			"InvalidProofCount(exp=%d,real=%d)", len(events)+1, len(proofs))
+	}
+	r, err := VerifyReceipt(h, idx, proofs[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	evs := make([]*EventLog, len(events))
+	for i, ei := range events {
+		if evs[i], err = VerifyEvent(r, ei, proofs[i+1]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return r, evs, nil
+}
+
+// VerifyHexary verifies the full proof of the hash at the key in hexary
+// Merkle tree of the header.
+func VerifyHexary(header *hexary.MerkleHeader, key int64, hash []byte, proof [][]byte) error {
+	if key < 0 || key >= header.Leaves {
+		return errors.Wrapf(ErrVerify, "InvalidKey(key=%d,leaves=%d)", key, header.Leaves)
+	}
+	if len(proof) != hexary.LevelFromLen(header.Leaves) {
+		return errors.Wrapf(ErrVerify, "InvalidProofLength(len=%d)", len(proof))
+	}
+	bk, err := db.NewMapDB().GetBucket(db.BytesByHash)
+	if err != nil {
+		return err
+	}
+	mt, err := hexary.NewMerkleTree(bk, header, -1)
+	if err != nil {
+		return err
+	}
+	if err := mt.Add(key, hash, proof); err != nil {
+		if errors.Is(err, hexary.ErrVerify) {
+			return errors.Wrap(ErrVerify, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// Account has the fields of an account required for verification. The
+// fields after StorageHash are not decoded.
+type Account struct {
+	Version     int
+	Balance     *big.Int
+	IsContract  bool
+	StorageHash []byte
+}
+
+// VerifyAccount verifies the proof of the account in the world state of the
+// state hash.
+func VerifyAccount(stateHash []byte, addr module.Address, proof [][]byte) (*Account, error) {
+	value, err := VerifyMPT(stateHash, crypto.SHA3Sum256(addr.ID()), proof)
+	if err != nil {
+		return nil, err
+	}
+	acc := new(Account)
+	if _, err := codec.BC.UnmarshalFromBytes(value, acc); err != nil {
+		return nil, errors.Wrapf(ErrVerify, "InvalidAccount(err=%v)", err)
+	}
+	return acc, nil
+}
+
+// VerifyStorage verifies the proof of the value for the key in the storage
+// of an account, and returns the value.
+func VerifyStorage(storageHash, key []byte, proof [][]byte) ([]byte, error) {
+	return VerifyMPT(storageHash, key, proof)
+}
+
+// CheckBlockID checks whether the header has the id.
+func CheckBlockID(h *BlockHeader, id []byte) error {
+	if !bytes.Equal(h.ID(), id) {
+		return errors.Wrapf(ErrVerify, "InvalidBlockID(exp=%x,real=%x)", id, h.ID())
+	}
+	return nil
+}
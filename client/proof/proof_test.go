@@ -0,0 +1,188 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/merkle/hexary"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+func newTestReceipts(t *testing.T, dbase db.Database) module.ReceiptList {
+	to := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	var rcts []txresult.Receipt
+	for i := 0; i < 3; i++ {
+		r := txresult.NewReceipt(dbase, module.LatestRevision, to)
+		for j := 0; j < 3; j++ {
+			r.AddLog(to, [][]byte{[]byte("Event(int)"), {byte(i)}}, [][]byte{{byte(j)}})
+		}
+		r.SetResult(module.StatusSuccess, big.NewInt(int64(100+i)), big.NewInt(10), nil)
+		r.SetCumulativeStepUsed(big.NewInt(int64(100 * (i + 1))))
+		assert.NoError(t, r.Flush())
+		rcts = append(rcts, r)
+	}
+	rl := txresult.NewReceiptListFromSlice(dbase, rcts)
+	assert.NoError(t, rl.Flush())
+	return rl
+}
+
+func newTestHeader(t *testing.T, receiptHash []byte) *BlockHeader {
+	result := codec.BC.MustMarshalToBytes(&resultFormat{
+		NormalReceiptHash: receiptHash,
+	})
+	bs := codec.BC.MustMarshalToBytes(&block.V2HeaderFormat{
+		Version: module.BlockVersion2,
+		Height:  10,
+		Result:  result,
+	})
+	h, err := NewBlockHeaderFromBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.SHA3Sum256(bs), h.ID())
+	assert.NoError(t, CheckBlockID(h, crypto.SHA3Sum256(bs)))
+	assert.True(t, errors.Is(CheckBlockID(h, make([]byte, 32)), ErrVerify))
+	return h
+}
+
+func TestVerifyReceiptAndEvents(t *testing.T) {
+	dbase := db.NewMapDB()
+	rl := newTestReceipts(t, dbase)
+	h := newTestHeader(t, rl.Hash())
+
+	for i := 0; i < 3; i++ {
+		proof, err := rl.GetProof(i)
+		assert.NoError(t, err)
+		r, err := VerifyReceipt(h, i, proof)
+		assert.NoError(t, err)
+		assert.Equal(t, module.StatusSuccess, r.Status)
+		assert.Equal(t, int64(100+i), r.StepUsed.Int64())
+
+		rct, err := rl.Get(i)
+		assert.NoError(t, err)
+		proofs := [][][]byte{proof}
+		events := []int{0, 2}
+		for _, ei := range events {
+			ep, err := rct.GetProofOfEvent(ei)
+			assert.NoError(t, err)
+			proofs = append(proofs, ep)
+		}
+		r, evs, err := VerifyReceiptAndEvents(h, i, events, proofs)
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+		assert.Len(t, evs, 2)
+		for j, ev := range evs {
+			assert.Equal(t, []byte{byte(i)}, ev.Indexed[1])
+			assert.Equal(t, []byte{byte(events[j])}, ev.Data[0])
+		}
+
+		// proofs for wrong indexes
+		_, err = VerifyReceipt(h, (i+1)%3, proof)
+		assert.True(t, errors.Is(err, ErrVerify))
+		_, _, err = VerifyReceiptAndEvents(h, i, []int{1, 2}, proofs)
+		assert.True(t, errors.Is(err, ErrVerify))
+		_, _, err = VerifyReceiptAndEvents(h, i, []int{0}, proofs)
+		assert.True(t, errors.Is(err, ErrVerify))
+	}
+
+	// proof against other header
+	proof, err := rl.GetProof(0)
+	assert.NoError(t, err)
+	_, err = VerifyReceipt(newTestHeader(t, crypto.SHA3Sum256([]byte("x"))), 0, proof)
+	assert.True(t, errors.Is(err, ErrVerify))
+}
+
+func TestVerifyHexary(t *testing.T) {
+	const leaves = 16*16 + 1
+	bk, _ := db.NewMapDB().GetBucket("")
+	abk, _ := db.NewMapDB().GetBucket("")
+	acc, err := hexary.NewAccumulator(bk, abk, "")
+	assert.NoError(t, err)
+	hashes := make([][]byte, leaves)
+	for i := range hashes {
+		hashes[i] = crypto.SHA3Sum256(codec.BC.MustMarshalToBytes(int64(i)))
+		assert.NoError(t, acc.Add(hashes[i]))
+	}
+	header, err := acc.Finalize()
+	assert.NoError(t, err)
+	mt, err := hexary.NewMerkleTree(bk, header, -1)
+	assert.NoError(t, err)
+
+	for _, i := range []int64{0, 15, 16, 255, 256} {
+		proof, err := mt.Prove(i, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, VerifyHexary(header, i, hashes[i], proof))
+
+		err = VerifyHexary(header, i, hashes[(i+1)%leaves], proof)
+		assert.True(t, errors.Is(err, ErrVerify))
+		err = VerifyHexary(header, i, hashes[i], proof[1:])
+		assert.True(t, errors.Is(err, ErrVerify))
+	}
+	err = VerifyHexary(header, leaves, hashes[0], nil)
+	assert.True(t, errors.Is(err, ErrVerify))
+}
+
+func TestVerifyAccountAndStorage(t *testing.T) {
+	dbase := db.NewMapDB()
+	ws := state.NewWorldState(dbase, nil, nil, nil, nil)
+	eoa := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000003")
+	missing := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+	ws.GetAccountState(eoa.ID()).SetBalance(big.NewInt(100))
+	as := ws.GetAccountState(score.ID())
+	as.SetBalance(big.NewInt(200))
+	for i := 0; i < 20; i++ {
+		_, err := as.SetValue([]byte{byte(i)}, []byte{byte(i), byte(i)})
+		assert.NoError(t, err)
+	}
+	wss := ws.GetSnapshot()
+	assert.NoError(t, wss.Flush())
+
+	acc, err := VerifyAccount(wss.StateHash(), eoa, wss.GetProofOfAccount(eoa.ID()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 100, acc.Balance.Int64())
+	assert.Nil(t, acc.StorageHash)
+
+	ass := wss.GetAccountSnapshot(score.ID())
+	acc, err = VerifyAccount(wss.StateHash(), score, wss.GetProofOfAccount(score.ID()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 200, acc.Balance.Int64())
+	assert.Equal(t, ass.StorageHash(), acc.StorageHash)
+	for i := 0; i < 20; i++ {
+		value, err := VerifyStorage(acc.StorageHash, []byte{byte(i)}, ass.GetProofOfValue([]byte{byte(i)}))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{byte(i), byte(i)}, value)
+	}
+	assert.Nil(t, ass.GetProofOfValue([]byte{100}))
+
+	// proof for other account
+	_, err = VerifyAccount(wss.StateHash(), score, wss.GetProofOfAccount(eoa.ID()))
+	assert.True(t, errors.Is(err, ErrVerify))
+	assert.Nil(t, wss.GetProofOfAccount(missing.ID()))
+	_, err = VerifyStorage(wss.StateHash(), []byte{1}, ass.GetProofOfValue([]byte{1}))
+	assert.True(t, errors.Is(err, ErrVerify))
+}
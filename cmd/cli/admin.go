@@ -146,6 +146,14 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 				param.NephewsLimit = &nephewsLimit
 			}
 			param.ValidateTxOnSend, _ = fs.GetBool("validate_tx_on_send")
+			param.StateRetention, _ = fs.GetInt64("state_retention")
+			param.StatePinned, _ = fs.GetInt64Slice("state_pinned")
+			param.AccountIndex, _ = fs.GetBool("account_index")
+			if schedules, _ := fs.GetString("schedules"); len(schedules) > 0 {
+				if err := json.Unmarshal([]byte(schedules), &param.Schedules); err != nil {
+					return errors.Errorf("invalid schedules err=%+v", err)
+				}
+			}
 
 			var buf *bytes.Buffer
 			if len(genesisZip) > 0 {
@@ -203,6 +211,10 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	joinFlags.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	joinFlags.Bool("validate_tx_on_send", false, "Validate transaction on send")
+	joinFlags.Int64("state_retention", 0, "Number of recent blocks to keep states for (0: disable state pruning)")
+	joinFlags.Int64Slice("state_pinned", nil, "Heights of states to keep with state pruning - Comma separated")
+	joinFlags.Bool("account_index", false, "Keep index of accounts for debug APIs")
+	joinFlags.String("schedules", "", "Schedules of maintenance tasks in JSON")
 
 	leaveCmd := &cobra.Command{
 		Use:   "leave CID",
@@ -259,6 +271,25 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	inspectCmd.Flags().StringP("format", "f", "", "Format the output using the given Go template")
 	inspectCmd.Flags().Bool("informal", false, "Inspect with informal data")
 
+	scheduleCmd := &cobra.Command{
+		Use:   "schedule CID",
+		Short: "Show schedules of the chain with their run history",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := new(node.SchedulesView)
+			reqUrl := node.UrlChain + "/" + args[0] + "/schedule"
+			resp, err := adminClient.Get(reqUrl, v)
+			if err != nil {
+				return err
+			}
+			if err = JsonPrettyPrintln(os.Stdout, v); err != nil {
+				return errors.Errorf("failed JsonIntend resp=%+v, err=%+v", resp, err)
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(scheduleCmd)
+
 	opFunc := func(op string) func(cmd *cobra.Command, args []string) error {
 		return func(cmd *cobra.Command, args []string) error {
 			reqUrl := node.UrlChain + "/" + args[0] + "/" + op
@@ -392,8 +423,12 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fs := cmd.Flags()
 			manual, _ := fs.GetBool("manual")
+			online, _ := fs.GetBool("online")
+			base, _ := fs.GetString("base")
 			param := &node.ChainBackupParam{
 				Manual: manual,
+				Online: online,
+				Base:   base,
 			}
 			var v string
 			reqUrl := node.UrlChain + "/" + args[0] + "/backup"
@@ -408,6 +443,64 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	rootCmd.AddCommand(backupCmd)
 	backupFlags := backupCmd.Flags()
 	backupFlags.Bool("manual", false, "Manual backup mode (just release database)")
+	backupFlags.Bool("online", false, "Online backup mode (keep the chain running)")
+	backupFlags.String("base", "", "Name of the base backup for incremental backup")
+
+	exportSnapshotCmd := &cobra.Command{
+		Use:   "export_snapshot CID FILE",
+		Short: "Start to export the state snapshot at the height",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := &chain.SnapshotExportParams{File: args[1]}
+			param.Height, _ = fs.GetInt64("height")
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.TaskSnapshotExport
+			_, err := adminClient.PostWithJson(reqUrl, param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(exportSnapshotCmd)
+	exportSnapshotFlags := exportSnapshotCmd.Flags()
+	exportSnapshotFlags.Int64("height", 0, "Block Height")
+	MarkAnnotationRequired(exportSnapshotFlags, "height")
+
+	importSnapshotCmd := &cobra.Command{
+		Use:   "import_snapshot CID FILE",
+		Short: "Start to replace chain data with the state snapshot",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &chain.SnapshotImportParams{File: args[1]}
+			blockID := cmd.Flag("block_id").Value.String()
+			if len(blockID) > 0 {
+				if len(blockID) >= 2 && blockID[:2] == "0x" {
+					blockID = blockID[2:]
+				}
+				var err error
+				if param.BlockID, err = hex.DecodeString(blockID); err != nil {
+					return err
+				}
+			}
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.TaskSnapshotImport
+			_, err := adminClient.PostWithJson(reqUrl, param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(importSnapshotCmd)
+	importSnapshotFlags := importSnapshotCmd.Flags()
+	importSnapshotFlags.String("block_id", "",
+		"ID of the block of the snapshot, required if the chain can't verify it")
 
 	genesisCmd := &cobra.Command{
 		Use:   "genesis CID FILE",
@@ -539,6 +632,38 @@ func NewSystemCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comma
 	rootCmd.AddCommand(infoCmd)
 	infoCmd.Flags().StringP("format", "f", "", "Format the output using the given Go template")
 
+	inspectCmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Inspect execution engines",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmd.Flag("format").Value.String()
+			var v interface{}
+			params := &url.Values{}
+			if format == "" {
+				v = new(node.SystemInspectView)
+			} else {
+				v = new(string)
+				params.Add("format", format)
+			}
+			resp, err := adminClient.Get(node.UrlSystem+"/inspect", v, params)
+			if err != nil {
+				return err
+			}
+			if format == "" {
+				if err = JsonPrettyPrintln(os.Stdout, v); err != nil {
+					return errors.Errorf("failed JsonIntend resp=%+v, err=%+v", resp, err)
+				}
+			} else {
+				s := v.(*string)
+				fmt.Println(*s)
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(inspectCmd)
+	inspectCmd.Flags().StringP("format", "f", "", "Format the output using the given Go template")
+
 	configCmd := &cobra.Command{
 		Use:   "config KEY VALUE",
 		Short: "Configure system",
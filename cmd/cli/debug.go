@@ -24,10 +24,17 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
 )
 
+var systemSCOREAddresses = []string{
+	"cx0000000000000000000000000000000000000000",
+	"cx0000000000000000000000000000000000000001",
+}
+
 func DebugPersistentPreRunE(vc *viper.Viper, dbgClient *client.JsonRpcClient) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		if err := ValidateFlagsWithViper(vc, cmd.Flags()); err != nil {
@@ -68,5 +75,92 @@ func NewDebugCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	}
 	rootCmd.AddCommand(traceCmd)
 
+	stateDiffCmd := &cobra.Command{
+		Use:   "statediff HEIGHT1 [HEIGHT2]",
+		Short: "Get differences of accounts between states of two heights",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.StateDiffParam{}
+			for i, arg := range args {
+				h, err := intconv.ParseInt(arg, 64)
+				if err != nil {
+					return errors.Errorf("invalid height %s", arg)
+				}
+				if i == 0 {
+					param.Height1 = jsonrpc.HexInt(intconv.FormatInt(h))
+				} else {
+					param.Height2 = jsonrpc.HexInt(intconv.FormatInt(h))
+				}
+			}
+			fs := cmd.Flags()
+			addrs, _ := fs.GetStringSlice("address")
+			if system, _ := fs.GetBool("system"); system {
+				addrs = append(addrs, systemSCOREAddresses...)
+			}
+			for _, addr := range addrs {
+				param.Addresses = append(param.Addresses, jsonrpc.Address(addr))
+			}
+			diff, err := debugClient.Do("debug_getStateDiff", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, diff.Result)
+		},
+	}
+	rootCmd.AddCommand(stateDiffCmd)
+	stateDiffFlags := stateDiffCmd.Flags()
+	stateDiffFlags.StringSlice("address", nil, "Addresses of accounts to compare")
+	stateDiffFlags.Bool("system", false, "Compare system SCOREs")
+
+	accountsCmd := &cobra.Command{
+		Use:   "accounts",
+		Short: "Get accounts in the account index",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := &v3.AccountsParam{}
+			param.OrderBy, _ = fs.GetString("order_by")
+			skip, _ := fs.GetInt64("skip")
+			param.Skip = jsonrpc.HexInt(intconv.FormatInt(skip))
+			limit, _ := fs.GetInt64("limit")
+			param.Limit = jsonrpc.HexInt(intconv.FormatInt(limit))
+			accounts, err := debugClient.Do("debug_getAccounts", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, accounts.Result)
+		},
+	}
+	rootCmd.AddCommand(accountsCmd)
+	accountsFlags := accountsCmd.Flags()
+	accountsFlags.String("order_by", "address", "Order of accounts (address,balance,stake,delegation,bond)")
+	accountsFlags.Int64("skip", 0, "Number of accounts to skip")
+	accountsFlags.Int64("limit", 0, "Maximum number of accounts (0: uses server default value)")
+
+	delegatorsCmd := &cobra.Command{
+		Use:   "delegators ADDRESS",
+		Short: "Get accounts delegating to the address in the account index",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := &v3.DelegatorsParam{
+				Address: jsonrpc.Address(args[0]),
+			}
+			skip, _ := fs.GetInt64("skip")
+			param.Skip = jsonrpc.HexInt(intconv.FormatInt(skip))
+			limit, _ := fs.GetInt64("limit")
+			param.Limit = jsonrpc.HexInt(intconv.FormatInt(limit))
+			delegators, err := debugClient.Do("debug_getDelegators", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, delegators.Result)
+		},
+	}
+	rootCmd.AddCommand(delegatorsCmd)
+	delegatorsFlags := delegatorsCmd.Flags()
+	delegatorsFlags.Int64("skip", 0, "Number of accounts to skip")
+	delegatorsFlags.Int64("limit", 0, "Maximum number of accounts (0: uses server default value)")
+
 	return rootCmd, vc
 }
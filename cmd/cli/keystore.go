@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
 )
 
 func newKeystoreGenCmd(c string) *cobra.Command {
@@ -129,6 +130,7 @@ func publickeyFromKeyStore(c string) *cobra.Command {
 	keystorePath := flags.StringP("keystore", "k", "keystore.json", "Keystore file path")
 	secret := flags.StringP("secret", "s", "", "KeySecret file path")
 	pass := flags.StringP("password", "p", "gochain", "Password for the keystore")
+	dsa := flags.String("dsa", "", "DSA of the public key for BTP (e.g. bls/bls12381, eddsa/ed25519)")
 	cmd.Run = func(cmd *cobra.Command, args []string) {
 		var pb []byte
 		if kb, err := ioutil.ReadFile(*keystorePath); err != nil {
@@ -146,7 +148,23 @@ func publickeyFromKeyStore(c string) *cobra.Command {
 			if err != nil {
 				log.Panicf("Fail to decrypt KeyStore err=%+v", err)
 			}
-			fmt.Println("0x" + hex.EncodeToString(w.PublicKey()))
+			if *dsa == "" {
+				fmt.Println("0x" + hex.EncodeToString(w.PublicKey()))
+				return
+			}
+			var bw module.BaseWallet
+			if wp, ok := w.(module.WalletProvider); ok {
+				bw = wp.WalletFor(*dsa)
+			}
+			if bw == nil {
+				log.Panicf("Fail to get key for dsa=%s", *dsa)
+			}
+			// keys with proof of possession shall be registered with the proof
+			if pw, ok := bw.(interface{ PublicKeyWithProof() []byte }); ok {
+				fmt.Println("0x" + hex.EncodeToString(pw.PublicKeyWithProof()))
+			} else {
+				fmt.Println("0x" + hex.EncodeToString(bw.PublicKey()))
+			}
 		}
 	}
 	return cmd
@@ -14,7 +14,10 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/icon-project/goloop/block"
 	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/client/light"
+	"github.com/icon-project/goloop/client/proof"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/intconv"
@@ -23,6 +26,7 @@ import (
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
+	"github.com/icon-project/goloop/service/contract"
 )
 
 func RpcPersistentPreRunE(vc *viper.Viper, rpcClient *client.ClientV3) func(cmd *cobra.Command, args []string) error {
@@ -455,6 +459,142 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 				return JsonPrettyPrintln(os.Stdout, raw)
 			},
 		})
+	verifyCmd := &cobra.Command{
+		Use:   "verify HEIGHT TX_INDEX [EVENT_INDEXES]",
+		Short: "Verify proofs of the result and events with the block header",
+		Long: "Verify proofs of the result and events with the block header.\n" +
+			"HEIGHT is the height of the block including the result of the transaction,\n" +
+			"which is the next block of the block including the transaction.",
+		Args: ArgsWithDefaultErrorFunc(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return err
+			}
+			idx, err := intconv.ParseInt(args[1], 32)
+			if err != nil {
+				return err
+			}
+			var events []int
+			if len(args) > 2 {
+				for _, str := range strings.Split(args[2], ",") {
+					evt, err := intconv.ParseInt(str, 32)
+					if err != nil {
+						return err
+					}
+					events = append(events, int(evt))
+				}
+			}
+			hbs, err := rpcClient.GetBlockHeaderByHeight(&v3.BlockHeightParam{
+				Height: jsonrpc.HexInt(intconv.FormatInt(height)),
+			})
+			if err != nil {
+				return err
+			}
+			header, err := proof.NewBlockHeaderFromBytes(hbs)
+			if err != nil {
+				return err
+			}
+			if header.Height != height {
+				return errors.InvalidStateError.Errorf(
+					"InvalidHeaderHeight(exp=%d,real=%d)", height, header.Height)
+			}
+			if hash := cmd.Flag("hash").Value.String(); hash != "" {
+				id, err := hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+				if err != nil {
+					return err
+				}
+				if err := proof.CheckBlockID(header, id); err != nil {
+					return err
+				}
+			}
+			blockHash := jsonrpc.HexBytes("0x" + hex.EncodeToString(header.ID()))
+			var proofs [][][]byte
+			if len(events) == 0 {
+				p, err := rpcClient.GetProofForResult(&v3.ProofResultParam{
+					BlockHash: blockHash,
+					Index:     jsonrpc.HexInt(intconv.FormatInt(idx)),
+				})
+				if err != nil {
+					return err
+				}
+				proofs = [][][]byte{p}
+			} else {
+				evts := make([]jsonrpc.HexInt, len(events))
+				for i, evt := range events {
+					evts[i] = jsonrpc.HexInt(intconv.FormatInt(int64(evt)))
+				}
+				proofs, err = rpcClient.GetProofForEvents(&v3.ProofEventsParam{
+					BlockHash: blockHash,
+					Index:     jsonrpc.HexInt(intconv.FormatInt(idx)),
+					Events:    evts,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			rct, evs, err := proof.VerifyReceiptAndEvents(header, int(idx), events, proofs)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, map[string]interface{}{
+				"blockHeight": jsonrpc.HexInt(intconv.FormatInt(height)),
+				"blockHash":   blockHash,
+				"txIndex":     jsonrpc.HexInt(intconv.FormatInt(idx)),
+				"receipt":     rct,
+				"events":      evs,
+			})
+		},
+	}
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().String("hash", "", "Trusted hash of the block to check the header")
+
+	syncHeadersCmd := &cobra.Command{
+		Use:   "syncheaders HEIGHT BLOCK_HASH [TARGET_HEIGHT]",
+		Short: "Sync and verify block headers from the trusted block",
+		Long: "Sync and verify block headers from the trusted block with votes.\n" +
+			"It syncs up to the last block if TARGET_HEIGHT is not specified.",
+		Args: ArgsWithDefaultErrorFunc(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return err
+			}
+			id, err := hex.DecodeString(strings.TrimPrefix(args[1], "0x"))
+			if err != nil {
+				return err
+			}
+			var target int64
+			if len(args) > 2 {
+				if target, err = intconv.ParseInt(args[2], 64); err != nil {
+					return err
+				}
+			} else {
+				blk, err := rpcClient.GetLastBlock()
+				if err != nil {
+					return err
+				}
+				target = blk.Height
+			}
+			skip, err := cmd.Flags().GetBool("skip")
+			if err != nil {
+				return err
+			}
+			v, err := light.NewVerifier(light.NewClientSource(&rpcClient), height, id)
+			if err != nil {
+				return err
+			}
+			return v.SyncTo(target, skip, func(h *block.Header) {
+				_ = JsonPrettyPrintln(os.Stdout, map[string]interface{}{
+					"height":             jsonrpc.HexInt(intconv.FormatInt(h.Height())),
+					"blockHash":          common.HexBytes(h.ID()),
+					"nextValidatorsHash": common.HexBytes(h.NextValidatorsHash()),
+				})
+			})
+		},
+	}
+	rootCmd.AddCommand(syncHeadersCmd)
+	syncHeadersCmd.Flags().Bool("skip", true, "Skip headers while validators are unchanged")
 	scoreStatusCmd := &cobra.Command{
 		Use:   "scorestatus ADDRESS",
 		Short: "Get status of the smart contract",
@@ -479,6 +619,32 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 	flags = scoreStatusCmd.Flags()
 	flags.Int("height", -1, "BlockHeight")
 
+	accountProofCmd := &cobra.Command{
+		Use:   "accountproof ADDRESS [KEY...]",
+		Short: "Get proofs of the account and its storage values",
+		Args:  ArgsWithDefaultErrorFunc(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.ProofAccountParam{Address: jsonrpc.Address(args[0])}
+			for _, k := range args[1:] {
+				param.Keys = append(param.Keys, jsonrpc.HexBytes(k))
+			}
+			height, err := intconv.ParseInt(cmd.Flag("height").Value.String(), 64)
+			if err != nil {
+				return err
+			}
+			if height != -1 {
+				param.Height = jsonrpc.HexInt(intconv.FormatInt(height))
+			}
+			p, err := rpcClient.GetProof(param)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, p)
+		},
+	}
+	rootCmd.AddCommand(accountProofCmd)
+	accountProofCmd.Flags().Int("height", -1, "BlockHeight")
+
 	networkInfoCmd := &cobra.Command{
 		Use: "networkinfo",
 		Short: "Get network info of the endpoint",
@@ -631,7 +797,7 @@ func newBTPMessagesParam(args []string) (p *v3.BTPMessagesParam, err error) {
 func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Command {
 	var rpcClient client.ClientV3
 	var rpcClientSendTx func(w module.Wallet, params *v3.TransactionParam) (interface{}, error)
-	var rpcWallet module.Wallet
+	var rpcWallet, feePayerWallet module.Wallet
 	rootCmd, vc := NewCommand(parentCmd, parentVc, "sendtx", "SendTransaction")
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if err := RpcPersistentPreRunE(vc, &rpcClient)(cmd, args); err != nil {
@@ -663,7 +829,20 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 		} else {
 			save := vc.GetString("save")
 			rpcClientSendTx = func(w module.Wallet, p *v3.TransactionParam) (interface{}, error) {
-				txId, err := rpcClient.SendTransaction(w, p)
+				var txId *jsonrpc.HexBytes
+				var err error
+				if feePayerWallet != nil {
+					p.Timestamp = client.TimestampNow()
+					if err = client.SignTransactionAsFeePayer(feePayerWallet, p); err != nil {
+						return nil, err
+					}
+					if err = client.SignTransaction(w, p); err != nil {
+						return nil, err
+					}
+					txId, err = rpcClient.SendSignedTransaction(p)
+				} else {
+					txId, err = rpcClient.SendTransaction(w, p)
+				}
 				if len(save) > 0 {
 					if err := JsonPrettySaveFile(save, 0644, p); err != nil {
 						fmt.Fprintf(os.Stderr, "FAIL to save parameter file=%s err=%+v\n", save, err)
@@ -700,6 +879,15 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 		if err != nil {
 			return fmt.Errorf("fail to create wallet err=%+v", err)
 		}
+		if fpks := vc.GetString("fee_payer_key_store"); fpks != "" {
+			if kb, err = ioutil.ReadFile(fpks); err != nil {
+				return fmt.Errorf("fail to open KeyStore file=%s err=%+v", fpks, err)
+			}
+			feePayerWallet, err = wallet.NewFromKeyStore(kb, []byte(vc.GetString("fee_payer_key_password")))
+			if err != nil {
+				return fmt.Errorf("fail to create wallet for fee payer err=%+v", err)
+			}
+		}
 		return nil
 	}
 	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
@@ -796,6 +984,8 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 	rootPFlags.Int("wait_timeout", 10, "Timeout(sec) for wait transaction result")
 	rootPFlags.Bool("estimate", false, "Just estimate steps for the tx")
 	rootPFlags.String("save", "", "Store transaction to the file")
+	rootPFlags.String("fee_payer_key_store", "", "KeyStore file for the fee payer of sponsored transaction")
+	rootPFlags.String("fee_payer_key_password", "", "Password for the KeyStore file of the fee payer")
 	MarkAnnotationCustom(rootPFlags, "key_store", "nid")
 	BindPFlags(vc, rootCmd.PersistentFlags())
 	MarkAnnotationHidden(rootPFlags, "wait", "wait_interval", "wait_timeout")
@@ -1020,6 +1210,47 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 	callFlags.String("raw", "", "call with 'data' using raw json file or json-string")
 	MarkAnnotationRequired(callFlags, "to", "method")
 
+	multiCallCmd := &cobra.Command{
+		Use:   "multicall CALLS",
+		Short: "Atomic MultiCall Transaction with the list of calls in JSON file or json-string",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stepLimit := vc.GetInt64("step_limit")
+			nid, err := intconv.ParseInt(vc.GetString("nid"), 64)
+			if err != nil {
+				return err
+			}
+			var dataBytes []byte
+			if strings.HasPrefix(strings.TrimSpace(args[0]), "[") {
+				dataBytes = []byte(args[0])
+			} else if dataBytes, err = readFile(args[0]); err != nil {
+				return err
+			}
+			var calls []interface{}
+			if err := json.Unmarshal(dataBytes, &calls); err != nil {
+				return err
+			}
+			from := jsonrpc.Address(rpcWallet.Address().String())
+			param := &v3.TransactionParam{
+				Version:     v3.VersionValue,
+				FromAddress: from,
+				ToAddress:   from,
+				StepLimit:   jsonrpc.HexInt(intconv.FormatInt(stepLimit)),
+				NetworkID:   jsonrpc.HexInt(intconv.FormatInt(nid)),
+				DataType:    contract.DataTypeMultiCall,
+				Data:        calls,
+			}
+
+			txHash, err := rpcClientSendTx(rpcWallet, param)
+			if err != nil {
+				return err
+			}
+			vc.Set("txhash", txHash)
+			return JsonPrettyPrintln(os.Stdout, txHash)
+		},
+	}
+	rootCmd.AddCommand(multiCallCmd)
+
 	deployCmd := &cobra.Command{
 		Use:   "deploy SCORE_ZIP_FILE",
 		Short: "Deploy Transaction",
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -28,6 +29,10 @@ type ServerConfig struct {
 	KeyPlugin     string            `json:"key_plugin,omitempty"`
 	KeyPlgOptions map[string]string `json:"key_plugin_options,omitempty"`
 
+	KeyRemote       string          `json:"key_remote,omitempty"`
+	KeyRemoteAuth   json.RawMessage `json:"key_remote_auth,omitempty"`
+	KeyRemoteSigner string          `json:"key_remote_signer,omitempty"`
+
 	Wallet module.Wallet `json:"-"`
 
 	LogLevel     string               `json:"log_level"`
@@ -40,6 +45,11 @@ func (cfg *ServerConfig) GetAddress() module.Address {
 	if cfg.Wallet != nil {
 		return cfg.Wallet.Address()
 	}
+	if cfg.KeyRemote != "" {
+		if addr, err := common.NewAddressFromString(cfg.KeyRemoteSigner); err == nil {
+			return addr
+		}
+	}
 	if len(cfg.KeyStoreData) > 0 {
 		if addr, err := wallet.ReadAddressFromKeyStore(cfg.KeyStoreData); err == nil {
 			return addr
@@ -68,6 +78,10 @@ func (cfg *ServerConfig) MakesureWallet(gen bool) error {
 		}
 	}
 
+	if cfg.KeyRemote != "" {
+		return cfg.openRemoteWallet()
+	}
+
 	var privateKey *crypto.PrivateKey
 	if len(cfg.KeyStoreData) > 0 {
 		pass := cfg.KeyStorePass
@@ -107,6 +121,36 @@ func (cfg *ServerConfig) MakesureWallet(gen bool) error {
 	return nil
 }
 
+func (cfg *ServerConfig) openRemoteWallet() error {
+	if len(cfg.KeyRemoteAuth) == 0 {
+		return errors.New("key_remote_auth is required for key_remote")
+	}
+	signer, err := common.NewAddressFromString(cfg.KeyRemoteSigner)
+	if err != nil {
+		return errors.Errorf("invalid key_remote_signer=%q err=%+v",
+			cfg.KeyRemoteSigner, err)
+	}
+	pass := cfg.KeyStorePass
+	if pass == "" {
+		pass = DefaultKeyStorePass
+	}
+	authKey, err := wallet.DecryptKeyStore(cfg.KeyRemoteAuth, []byte(pass))
+	if err != nil {
+		return errors.Errorf("fail to decrypt key_remote_auth err=%+v", err)
+	}
+	w, err := wallet.OpenRemote(&wallet.RemoteConfig{
+		Address: cfg.KeyRemote,
+		AuthKey: authKey,
+		Signer:  signer,
+	})
+	if err != nil {
+		return errors.Errorf("fail to open remote wallet addr=%s err=%+v",
+			cfg.KeyRemote, err)
+	}
+	cfg.Wallet = w
+	return nil
+}
+
 func (cfg *ServerConfig) SetFilePath(path string) string {
 	o := cfg.StaticConfig.SetFilePath(path)
 	if cfg.LogWriter != nil && cfg.LogWriter.Filename != "" {
@@ -156,13 +200,16 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 	rootPFlags.String("key_secret", "", "Secret (password) file for KeyStore")
 	rootPFlags.String("key_plugin", "", "KeyPlugin file for wallet")
 	rootPFlags.StringToString("key_plugin_options", nil, "KeyPlugin options")
+	rootPFlags.String("key_remote", "", "Remote signer address (unix://path, tcp://host:port)")
+	rootPFlags.String("key_remote_auth", "", "KeyStore file for authentication to the remote signer")
+	rootPFlags.String("key_remote_signer", "", "Expected wallet address of the remote signer")
 	//
 	rootPFlags.String("log_forwarder_vendor", "", "LogForwarder vendor (fluentd,logstash)")
 	rootPFlags.String("log_forwarder_address", "", "LogForwarder address")
 	rootPFlags.String("log_forwarder_level", "info", "LogForwarder level")
 	rootPFlags.String("log_forwarder_name", "", "LogForwarder name")
 	rootPFlags.StringToString("log_forwarder_options", nil, "LogForwarder options, comma-separated 'key=value'")
-	rootPFlags.String("engines", "python", "Execution engines, comma-separated (python,java)")
+	rootPFlags.String("engines", "python", "Execution engines, comma-separated (python,java,go,wasm)")
 
 	rootPFlags.String("log_writer_filename", "", "Log filename (rotated files resides in same directory)")
 	rootPFlags.Int("log_writer_maxsize", 100, "Maximum log file size in MiB")
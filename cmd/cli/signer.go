@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+)
+
+func NewSignerCmd(c string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   c,
+		Short: "Run remote signer with the keystore",
+		Args:  cobra.NoArgs,
+	}
+	flags := cmd.Flags()
+	keyStore := flags.StringP("key_store", "k", "keystore.json", "KeyStore file for wallet")
+	secret := flags.StringP("key_secret", "s", "", "Secret (password) file for KeyStore")
+	pass := flags.StringP("key_password", "p", DefaultKeyStorePass, "Password for the KeyStore file")
+	listens := flags.StringSlice("listen", []string{"unix://signer.sock"},
+		"Listen addresses (unix://path, tcp://host:port)")
+	clients := flags.StringSlice("client", nil, "Public keys of allowed clients")
+	state := flags.String("state", "signer_state.json", "State file for double sign protection")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		kb, err := ioutil.ReadFile(*keyStore)
+		if err != nil {
+			return errors.Errorf("fail to open keystore file err=%+v", err)
+		}
+		pb := []byte(*pass)
+		if *secret != "" {
+			if pb, err = ioutil.ReadFile(*secret); err != nil {
+				return errors.Errorf("fail to open KeySecret err=%+v", err)
+			}
+		}
+		w, err := wallet.NewFromKeyStore(kb, pb)
+		if err != nil {
+			return errors.Errorf("fail to decrypt KeyStore err=%+v", err)
+		}
+
+		var keys []*crypto.PublicKey
+		for _, c := range *clients {
+			bs, err := hex.DecodeString(strings.TrimPrefix(c, "0x"))
+			if err != nil {
+				return errors.Errorf("invalid client key=%s err=%+v", c, err)
+			}
+			pk, err := crypto.ParsePublicKey(bs)
+			if err != nil {
+				return errors.Errorf("invalid client key=%s err=%+v", c, err)
+			}
+			keys = append(keys, pk)
+		}
+
+		logger := log.GlobalLogger()
+		signer, err := wallet.NewRemoteSigner(w, &wallet.RemoteSignerConfig{
+			Clients:   keys,
+			StatePath: *state,
+			Parser:    consensus.ParseSignContext,
+		}, logger)
+		if err != nil {
+			return err
+		}
+
+		var listeners []net.Listener
+		for _, addr := range *listens {
+			if strings.HasPrefix(addr, "unix://") {
+				_ = os.Remove(strings.TrimPrefix(addr, "unix://"))
+			}
+			l, err := wallet.ListenRemote(addr)
+			if err != nil {
+				signer.Close()
+				return errors.Errorf("fail to listen addr=%s err=%+v", addr, err)
+			}
+			logger.Infof("Listen addr=%s wallet=%s", addr, w.Address())
+			listeners = append(listeners, l)
+		}
+		OnInterrupt(func() {
+			signer.Close()
+		})
+
+		var wg sync.WaitGroup
+		errs := make(chan error, len(listeners))
+		for _, l := range listeners {
+			wg.Add(1)
+			go func(l net.Listener) {
+				defer wg.Done()
+				if err := signer.Serve(l); err != nil {
+					errs <- err
+					signer.Close()
+				}
+			}(l)
+		}
+		wg.Wait()
+		close(errs)
+		return <-errs
+	}
+	return cmd
+}
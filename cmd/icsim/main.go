@@ -0,0 +1,86 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/cmd/cli"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icsim"
+)
+
+func newRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run SCENARIO",
+		Short: "Run IISS scenario in YAML or JSON, and report rewards and penalties per term",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := icsim.LoadScenario(args[0])
+			if err != nil {
+				return err
+			}
+			fs := cmd.Flags()
+			asJSON, _ := fs.GetBool("json")
+			if verbose, _ := fs.GetBool("verbose"); !verbose {
+				log.GlobalLogger().SetLevel(log.WarnLevel)
+			}
+			out := cmd.OutOrStdout()
+			if asJSON {
+				out = nil
+			}
+			reports, err := sc.Run(out)
+			if asJSON {
+				if err := cli.JsonPrettyPrintln(cmd.OutOrStdout(), reports); err != nil {
+					return err
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if !asJSON {
+				fmt.Fprintln(cmd.OutOrStdout(), "Scenario finished successfully")
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.Bool("json", false, "Print reports of terms in JSON at the end")
+	flags.Bool("verbose", false, "Print logs of the simulator")
+	return cmd
+}
+
+func main() {
+	cmd := &cobra.Command{
+		Use:           os.Args[0],
+		Short:         "IISS simulator",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(newRunCmd())
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprint(os.Stderr, "Error:")
+		for ; err != nil; err = errors.Unwrap(err) {
+			fmt.Fprintf(os.Stderr, " %s", err.Error())
+		}
+		fmt.Fprintln(os.Stderr)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/client/bind"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+// loadAPI returns the API of the SCORE in the file, or the one at the
+// address through JSON-RPC API if the file is not specified.
+func loadAPI(file, uri, addr string) ([]byte, error) {
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	if uri == "" || addr == "" {
+		return nil, errors.IllegalArgumentError.New("API file or uri and address are required")
+	}
+	c := client.NewClientV3(uri)
+	apis, err := c.GetScoreApi(&v3.ScoreAddressParam{
+		Address: jsonrpc.Address(addr),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(apis)
+}
+
+func main() {
+	cmd := &cobra.Command{
+		Use:           os.Args[0] + " [API_FILE]",
+		Short:         "Generate typed Go bindings of a SCORE from its API",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			var file string
+			if len(args) > 0 {
+				file = args[0]
+			}
+			uri, _ := fs.GetString("uri")
+			addr, _ := fs.GetString("address")
+			bs, err := loadAPI(file, uri, addr)
+			if err != nil {
+				return err
+			}
+			methods, err := bind.ParseAPI(bs)
+			if err != nil {
+				return err
+			}
+			pkg, _ := fs.GetString("package")
+			name, _ := fs.GetString("type")
+			src, err := bind.Generate(pkg, name, methods)
+			if err != nil {
+				return err
+			}
+			if out, _ := fs.GetString("out"); out != "" {
+				return os.WriteFile(out, src, 0644)
+			}
+			_, err = cmd.OutOrStdout().Write(src)
+			return err
+		},
+	}
+	flags := cmd.Flags()
+	flags.String("uri", "", "URI of JSON-RPC API to get the API of the SCORE")
+	flags.String("address", "", "Address of the SCORE to get the API")
+	flags.String("package", "", "Package name of the generated file")
+	flags.String("type", "", "Type name of the binding")
+	flags.StringP("out", "o", "", "Output file (default: stdout)")
+	cmd.MarkFlagRequired("package")
+	cmd.MarkFlagRequired("type")
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprint(os.Stderr, "Error:")
+		for ; err != nil; err = errors.Unwrap(err) {
+			fmt.Fprintf(os.Stderr, " %s", err.Error())
+		}
+		fmt.Fprintln(os.Stderr)
+		os.Exit(1)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -70,25 +71,37 @@ func zipDirectory(fd io.Writer, p string) error {
 	return zfd.Close()
 }
 
-func makeDeploy(nid int64, from module.Wallet, src string, params interface{}) (interface{}, error) {
-	contentType := ""
-	content := ""
+func readContent(src string) (contentType string, content string, err error) {
 	if strings.HasSuffix(src, ".jar") {
-		contentType = "application/java"
 		data, err := ioutil.ReadFile(src)
 		if err != nil {
-			return nil, err
+			return "", "", err
 		}
-		content = "0x" + hex.EncodeToString(data)
+		return "application/java", "0x" + hex.EncodeToString(data), nil
+	} else if strings.HasSuffix(src, ".wasm") {
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			return "", "", err
+		}
+		return "application/wasm", "0x" + hex.EncodeToString(data), nil
 	} else {
-		contentType = "application/zip"
 		buf := bytes.NewBuffer(nil)
 		if err := zipDirectory(buf, src); err != nil {
-			return nil, err
+			return "", "", err
 		}
-		content = "0x" + hex.EncodeToString(buf.Bytes())
+		return "application/zip", "0x" + hex.EncodeToString(buf.Bytes()), nil
 	}
+}
 
+func makeDeploy(nid int64, from module.Wallet, src string, params interface{}) (interface{}, error) {
+	contentType, content, err := readContent(src)
+	if err != nil {
+		return nil, err
+	}
+	return makeDeployWithContent(nid, from, contentType, content, params)
+}
+
+func makeDeployWithContent(nid int64, from module.Wallet, contentType, content string, params interface{}) (interface{}, error) {
 	tx := map[string]interface{}{
 		"version":   "0x3",
 		"from":      from.Address(),
@@ -108,3 +121,46 @@ func makeDeploy(nid int64, from module.Wallet, src string, params interface{}) (
 	}
 	return tx, nil
 }
+
+// DeployMaker makes transactions deploying the SCORE repeatedly.
+type DeployMaker struct {
+	NID           int64
+	SourcePath    string
+	InstallParams map[string]string
+	GOD           module.Wallet
+
+	owner       module.Wallet
+	contentType string
+	content     string
+}
+
+func (m *DeployMaker) Prepare(client *Client) error {
+	m.owner = wallet.New()
+
+	contentType, content, err := readContent(m.SourcePath)
+	if err != nil {
+		return err
+	}
+	m.contentType, m.content = contentType, content
+
+	tx, err := makeCoinTransfer(m.NID, m.GOD, m.owner.Address(), callInitialBalance)
+	if err != nil {
+		return err
+	}
+	r, err := client.SendTxAndGetResult(tx, timeoutForCoinTransfer)
+	if err != nil {
+		return err
+	}
+	if r.Status.Value != 1 {
+		return errors.Errorf("FailToFundingOwner(failure=%+v)", r.Failure)
+	}
+	return nil
+}
+
+func (m *DeployMaker) MakeOne() (interface{}, error) {
+	return makeDeployWithContent(m.NID, m.owner, m.contentType, m.content, m.InstallParams)
+}
+
+func (m *DeployMaker) Dispose(tx interface{}) {
+	// do nothing
+}
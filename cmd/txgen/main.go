@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -26,6 +27,11 @@ func main() {
 	var index, last int64
 	var waitTimeout int64
 	var noWaitResult bool
+	var profilePath string
+	var reportPath string
+	var seed int64
+	var pollInterval int64
+	var drainTimeout int64
 
 	cmd := &cobra.Command{
 		Use: fmt.Sprintf("%s [urls]", os.Args[0]),
@@ -46,6 +52,11 @@ func main() {
 	flags.Int64VarP(&last, "last", "l", 0, "Last index value to be used for generating transaction")
 	flags.Int64Var(&waitTimeout, "wait", 0, "Wait for specified time (in ms) for each TX (enable to use sendAndWait)")
 	flags.BoolVar(&noWaitResult, "nowaitresult", false, "No wait for result for confirm in COIN transfer")
+	flags.StringVar(&profilePath, "profile", "", "File path to workload profile (JSON)")
+	flags.StringVar(&reportPath, "report", "", "File path to write report of the profile (JSON, or CSV with .csv extension)")
+	flags.Int64Var(&seed, "seed", 0, "Seed for choosing workloads of the profile (0 for current time)")
+	flags.Int64Var(&pollInterval, "poll", 100, "Interval (in ms) for checking new blocks in the profile")
+	flags.Int64Var(&drainTimeout, "drain", 30000, "Time (in ms) to wait for pending transactions after the profile")
 
 	cmd.RunE = func(cmd *cobra.Command, urls []string) error {
 		if len(urls) == 0 {
@@ -74,6 +85,34 @@ func main() {
 			log.Panicf("Fail to decrypt KeyStore err=%+v", err)
 		}
 
+		if len(profilePath) > 0 {
+			profile, err := LoadProfile(profilePath)
+			if err != nil {
+				return err
+			}
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			runner := &ProfileRunner{
+				Profile:      profile,
+				Concurrent:   concurrent,
+				NID:          nid,
+				GOD:          godWallet,
+				Seed:         seed,
+				PollInterval: time.Duration(pollInterval) * time.Millisecond,
+				DrainTimeout: time.Duration(drainTimeout) * time.Millisecond,
+			}
+			report, err := runner.Run(urls)
+			if err != nil {
+				return err
+			}
+			report.Print()
+			if len(reportPath) > 0 {
+				return report.WriteFile(reportPath)
+			}
+			return nil
+		}
+
 		var maker TransactionMaker
 		if len(scorePath) > 0 && len(methodName) > 0 {
 			maker = &CallMaker{
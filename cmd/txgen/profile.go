@@ -0,0 +1,262 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	WorkloadCoin   = "coin"
+	WorkloadToken  = "token"
+	WorkloadCall   = "call"
+	WorkloadDeploy = "deploy"
+)
+
+// Duration is time.Duration in JSON string like "30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Workload specifies a kind of transactions. Score is path to SCORE source
+// for token, deploy and call type, or address of deployed SCORE for call
+// type.
+type Workload struct {
+	Type          string            `json:"type"`
+	Score         string            `json:"score,omitempty"`
+	Method        string            `json:"method,omitempty"`
+	Params        map[string]string `json:"params,omitempty"`
+	InstallParams map[string]string `json:"installParams,omitempty"`
+	Wallets       int               `json:"wallets,omitempty"`
+}
+
+// Phase sends transactions of workloads in the mix with the weights for
+// the duration. Target TPS changes linearly from TPS to RampTo if RampTo is
+// specified.
+type Phase struct {
+	Name     string         `json:"name"`
+	Duration Duration       `json:"duration"`
+	TPS      int64          `json:"tps"`
+	RampTo   int64          `json:"rampTo,omitempty"`
+	Mix      map[string]int `json:"mix"`
+}
+
+// Profile defines workloads and phases using them. For example,
+//
+//	{
+//	  "name": "mixed",
+//	  "workloads": {
+//	    "transfer": {"type": "coin", "wallets": 100},
+//	    "token": {"type": "token", "score": "./token"},
+//	    "deploy": {"type": "deploy", "score": "./token.jar"}
+//	  },
+//	  "phases": [
+//	    {"name": "warmup", "duration": "30s", "tps": 100, "mix": {"transfer": 1}},
+//	    {"name": "ramp", "duration": "1m", "tps": 100, "rampTo": 1000,
+//	      "mix": {"transfer": 8, "token": 2}},
+//	    {"name": "deploy", "duration": "30s", "tps": 50,
+//	      "mix": {"transfer": 9, "deploy": 1}}
+//	  ]
+//	}
+type Profile struct {
+	Name      string               `json:"name,omitempty"`
+	Workloads map[string]*Workload `json:"workloads"`
+	Phases    []*Phase             `json:"phases"`
+}
+
+func LoadProfile(p string) (*Profile, error) {
+	bs, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "FailToReadProfile(path=%s)", p)
+	}
+	profile := new(Profile)
+	if err := json.Unmarshal(bs, profile); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidProfile(path=%s)", p)
+	}
+	if err := profile.Verify(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (p *Profile) Verify() error {
+	for name, w := range p.Workloads {
+		switch w.Type {
+		case WorkloadCoin:
+		case WorkloadToken, WorkloadDeploy, WorkloadCall:
+			if len(w.Score) == 0 {
+				return errors.IllegalArgumentError.Errorf("NoScore(workload=%s)", name)
+			}
+		default:
+			return errors.IllegalArgumentError.Errorf(
+				"UnknownWorkloadType(workload=%s,type=%s)", name, w.Type)
+		}
+	}
+	if len(p.Phases) == 0 {
+		return errors.IllegalArgumentError.New("NoPhases")
+	}
+	for i, ph := range p.Phases {
+		if len(ph.Name) == 0 {
+			return errors.IllegalArgumentError.Errorf("NoPhaseName(index=%d)", i)
+		}
+		if ph.Duration <= 0 || ph.TPS <= 0 || ph.RampTo < 0 {
+			return errors.IllegalArgumentError.Errorf("InvalidPhaseRate(phase=%s)", ph.Name)
+		}
+		total := 0
+		for name, weight := range ph.Mix {
+			if _, ok := p.Workloads[name]; !ok {
+				return errors.IllegalArgumentError.Errorf(
+					"UnknownWorkload(phase=%s,workload=%s)", ph.Name, name)
+			}
+			if weight < 0 {
+				return errors.IllegalArgumentError.Errorf(
+					"InvalidWeight(phase=%s,workload=%s)", ph.Name, name)
+			}
+			total += weight
+		}
+		if total == 0 {
+			return errors.IllegalArgumentError.Errorf("EmptyMix(phase=%s)", ph.Name)
+		}
+	}
+	return nil
+}
+
+// UsedWorkloads returns names of workloads used by phases in sorted order.
+func (p *Profile) UsedWorkloads() []string {
+	used := make(map[string]bool)
+	for _, ph := range p.Phases {
+		for name, weight := range ph.Mix {
+			if weight > 0 {
+				used[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TargetTPS returns target TPS after elapsed from the start of the phase.
+func (ph *Phase) TargetTPS(elapsed time.Duration) float64 {
+	if ph.RampTo == 0 || elapsed <= 0 {
+		return float64(ph.TPS)
+	}
+	ratio := float64(elapsed) / float64(ph.Duration)
+	if ratio > 1 {
+		ratio = 1
+	}
+	return float64(ph.TPS) + float64(ph.RampTo-ph.TPS)*ratio
+}
+
+type weightedMix struct {
+	names   []string
+	weights []int
+	total   int
+}
+
+func newWeightedMix(mix map[string]int) *weightedMix {
+	m := new(weightedMix)
+	for name := range mix {
+		m.names = append(m.names, name)
+	}
+	// sort names so that the same seed picks the same workloads.
+	sort.Strings(m.names)
+	for _, name := range m.names {
+		m.weights = append(m.weights, mix[name])
+		m.total += mix[name]
+	}
+	return m
+}
+
+func (m *weightedMix) Pick(r *rand.Rand) string {
+	v := r.Intn(m.total)
+	for i, w := range m.weights {
+		if v < w {
+			return m.names[i]
+		}
+		v -= w
+	}
+	return m.names[len(m.names)-1]
+}
+
+func (w *Workload) NewMaker(nid int64, god module.Wallet) TransactionMaker {
+	wallets := w.Wallets
+	if wallets <= 0 {
+		wallets = defaultWorkloadWallets
+	}
+	switch w.Type {
+	case WorkloadCoin:
+		return &CoinTransferMaker{
+			NID:         nid,
+			WalletCount: wallets,
+			GodWallet:   god,
+		}
+	case WorkloadToken:
+		method := w.Method
+		if len(method) == 0 {
+			method = "transfer"
+		}
+		return &TokenTransferMaker{
+			NID:         nid,
+			WalletCount: wallets,
+			SourcePath:  w.Score,
+			Method:      method,
+			GOD:         god,
+		}
+	case WorkloadDeploy:
+		return &DeployMaker{
+			NID:           nid,
+			SourcePath:    w.Score,
+			InstallParams: w.InstallParams,
+			GOD:           god,
+		}
+	default:
+		return &CallMaker{
+			NID:           nid,
+			SourcePath:    w.Score,
+			InstallParams: w.InstallParams,
+			Method:        w.Method,
+			CallParams:    w.Params,
+			GOD:           god,
+		}
+	}
+}
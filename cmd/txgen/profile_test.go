@@ -0,0 +1,181 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const testProfile = `{
+  "name": "mixed",
+  "workloads": {
+    "transfer": {"type": "coin", "wallets": 100},
+    "token": {"type": "token", "score": "./token"},
+    "deploy": {"type": "deploy", "score": "./token.jar"}
+  },
+  "phases": [
+    {"name": "warmup", "duration": "30s", "tps": 100, "mix": {"transfer": 1}},
+    {"name": "ramp", "duration": "1m", "tps": 100, "rampTo": 1000,
+      "mix": {"transfer": 8, "token": 2, "deploy": 0}}
+  ]
+}`
+
+func writeProfile(t *testing.T, s string) string {
+	p := path.Join(t.TempDir(), "profile.json")
+	assert.NoError(t, os.WriteFile(p, []byte(s), 0644))
+	return p
+}
+
+func TestLoadProfile(t *testing.T) {
+	p, err := LoadProfile(writeProfile(t, testProfile))
+	assert.NoError(t, err)
+	assert.Equal(t, "mixed", p.Name)
+	assert.Len(t, p.Workloads, 3)
+	assert.Equal(t, WorkloadCoin, p.Workloads["transfer"].Type)
+	assert.Equal(t, 100, p.Workloads["transfer"].Wallets)
+	assert.Len(t, p.Phases, 2)
+	assert.Equal(t, Duration(time.Minute), p.Phases[1].Duration)
+	assert.EqualValues(t, 1000, p.Phases[1].RampTo)
+	assert.Equal(t, map[string]int{"transfer": 8, "token": 2, "deploy": 0}, p.Phases[1].Mix)
+
+	// workloads with zero weight are not used
+	assert.Equal(t, []string{"token", "transfer"}, p.UsedWorkloads())
+}
+
+func TestLoadProfile_Invalid(t *testing.T) {
+	_, err := LoadProfile(path.Join(t.TempDir(), "none.json"))
+	assert.Error(t, err)
+
+	_, err = LoadProfile(writeProfile(t, `{"phases":[`))
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	_, err = LoadProfile(writeProfile(t, `{
+		"workloads": {"transfer": {"type": "coin"}},
+		"phases": [{"name": "p", "duration": "1x", "tps": 1, "mix": {"transfer": 1}}]
+	}`))
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+}
+
+func TestProfile_Verify(t *testing.T) {
+	coin := map[string]*Workload{"transfer": {Type: WorkloadCoin}}
+	phase := func(f func(ph *Phase)) []*Phase {
+		ph := &Phase{
+			Name:     "p",
+			Duration: Duration(time.Second),
+			TPS:      10,
+			Mix:      map[string]int{"transfer": 1},
+		}
+		if f != nil {
+			f(ph)
+		}
+		return []*Phase{ph}
+	}
+	cases := []struct {
+		name    string
+		profile *Profile
+		ok      bool
+	}{
+		{"Valid", &Profile{Workloads: coin, Phases: phase(nil)}, true},
+		{"UnknownType", &Profile{
+			Workloads: map[string]*Workload{"transfer": {Type: "unknown"}},
+			Phases:    phase(nil),
+		}, false},
+		{"NoScore", &Profile{
+			Workloads: map[string]*Workload{"transfer": {Type: WorkloadToken}},
+			Phases:    phase(nil),
+		}, false},
+		{"NoPhases", &Profile{Workloads: coin}, false},
+		{"NoPhaseName", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.Name = ""
+		})}, false},
+		{"NoDuration", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.Duration = 0
+		})}, false},
+		{"NoTPS", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.TPS = 0
+		})}, false},
+		{"NegativeRampTo", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.RampTo = -1
+		})}, false},
+		{"UnknownWorkload", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.Mix["token"] = 1
+		})}, false},
+		{"NegativeWeight", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.Mix["transfer"] = -1
+		})}, false},
+		{"EmptyMix", &Profile{Workloads: coin, Phases: phase(func(ph *Phase) {
+			ph.Mix["transfer"] = 0
+		})}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.profile.Verify()
+			if c.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.IllegalArgumentError.Equals(err), "err=%v", err)
+			}
+		})
+	}
+}
+
+func TestPhase_TargetTPS(t *testing.T) {
+	ph := &Phase{Duration: Duration(10 * time.Second), TPS: 100}
+	assert.Equal(t, 100.0, ph.TargetTPS(0))
+	assert.Equal(t, 100.0, ph.TargetTPS(5*time.Second))
+
+	ph.RampTo = 300
+	assert.Equal(t, 100.0, ph.TargetTPS(0))
+	assert.Equal(t, 200.0, ph.TargetTPS(5*time.Second))
+	assert.Equal(t, 300.0, ph.TargetTPS(10*time.Second))
+	assert.Equal(t, 300.0, ph.TargetTPS(20*time.Second))
+
+	ph.RampTo = 50
+	assert.Equal(t, 75.0, ph.TargetTPS(5*time.Second))
+}
+
+func TestWeightedMix_Pick(t *testing.T) {
+	mix := map[string]int{"a": 1, "b": 0, "c": 3}
+	m := newWeightedMix(mix)
+	assert.Equal(t, 4, m.total)
+
+	const picks = 10000
+	counts := make(map[string]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < picks; i++ {
+		counts[m.Pick(r)] += 1
+	}
+	assert.Zero(t, counts["b"])
+	assert.InDelta(t, picks/4, counts["a"], picks/50)
+	assert.InDelta(t, picks*3/4, counts["c"], picks/50)
+
+	// the same seed picks the same workloads
+	m2 := newWeightedMix(mix)
+	r1 := rand.New(rand.NewSource(2))
+	r2 := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, m.Pick(r1), m2.Pick(r2))
+	}
+}
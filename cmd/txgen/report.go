@@ -0,0 +1,323 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+type LatencyReport struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// PhaseReport is the result of a phase. Latencies are from submission to
+// finding the block including the transaction in milliseconds, so they
+// include up to the poll interval. AchievedTPS is the
+// number of included transactions divided by the time from the start of
+// the phase to the last inclusion, which is the duration of the phase at
+// least.
+type PhaseReport struct {
+	Name        string         `json:"name"`
+	Start       time.Time      `json:"start"`
+	Duration    Duration       `json:"duration"`
+	TargetTPS   float64        `json:"targetTPS"`
+	Submitted   int            `json:"submitted"`
+	Rejected    int            `json:"rejected"`
+	Included    int            `json:"included"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"`
+	Pending     int            `json:"pending"`
+	SubmitTPS   float64        `json:"submitTPS"`
+	AchievedTPS float64        `json:"achievedTPS"`
+	Latency     LatencyReport  `json:"latency"`
+	Failures    map[string]int `json:"failures"`
+	Workloads   map[string]int `json:"workloads"`
+}
+
+type Report struct {
+	Profile string         `json:"profile,omitempty"`
+	URLs    []string       `json:"urls"`
+	Phases  []*PhaseReport `json:"phases"`
+}
+
+type phaseRecord struct {
+	phase     *Phase
+	start     time.Time
+	end       time.Time
+	target    float64
+	submitted int
+	rejected  int
+	succeeded int
+	failed    int
+	lastIncl  time.Time
+	latencies []time.Duration
+	failures  map[string]int
+	workloads map[string]int
+}
+
+// Recorder collects events of transactions for reports.
+type Recorder struct {
+	lock   sync.Mutex
+	phases []*phaseRecord
+}
+
+func (r *Recorder) StartPhase(ph *Phase) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.phases = append(r.phases, &phaseRecord{
+		phase:     ph,
+		start:     time.Now(),
+		failures:  make(map[string]int),
+		workloads: make(map[string]int),
+	})
+	return len(r.phases) - 1
+}
+
+func (r *Recorder) EndPhase(idx int, target float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.phases[idx].end = time.Now()
+	r.phases[idx].target = target
+}
+
+func (r *Recorder) OnSubmit(idx int, workload string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	p := r.phases[idx]
+	p.submitted += 1
+	p.workloads[workload] += 1
+}
+
+func (r *Recorder) OnReject(idx int, workload string, reason string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	p := r.phases[idx]
+	p.rejected += 1
+	p.workloads[workload] += 1
+	p.failures[reason] += 1
+}
+
+func (r *Recorder) OnResult(tx *trackedTx, status module.Status) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	p := r.phases[tx.phase]
+	latency := tx.included.Sub(tx.submitted)
+	if latency < 0 {
+		latency = 0
+	}
+	p.latencies = append(p.latencies, latency)
+	if tx.included.After(p.lastIncl) {
+		p.lastIncl = tx.included
+	}
+	if status == module.StatusSuccess {
+		p.succeeded += 1
+	} else {
+		p.failed += 1
+		p.failures[status.String()] += 1
+	}
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return toMillis(sorted[idx])
+}
+
+func newLatencyReport(latencies []time.Duration) LatencyReport {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	var lr LatencyReport
+	if len(sorted) == 0 {
+		return lr
+	}
+	var sum time.Duration
+	for _, l := range sorted {
+		sum += l
+	}
+	lr.Avg = toMillis(sum) / float64(len(sorted))
+	lr.P50 = percentile(sorted, 50)
+	lr.P90 = percentile(sorted, 90)
+	lr.P95 = percentile(sorted, 95)
+	lr.P99 = percentile(sorted, 99)
+	lr.Max = toMillis(sorted[len(sorted)-1])
+	return lr
+}
+
+func (r *Recorder) Report(profile string, urls []string) *Report {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	report := &Report{
+		Profile: profile,
+		URLs:    urls,
+	}
+	for _, p := range r.phases {
+		duration := p.end.Sub(p.start)
+		included := p.succeeded + p.failed
+		pr := &PhaseReport{
+			Name:      p.phase.Name,
+			Start:     p.start,
+			Duration:  Duration(duration),
+			TargetTPS: p.target,
+			Submitted: p.submitted,
+			Rejected:  p.rejected,
+			Included:  included,
+			Succeeded: p.succeeded,
+			Failed:    p.failed,
+			Pending:   p.submitted - included,
+			Latency:   newLatencyReport(p.latencies),
+			Failures:  p.failures,
+			Workloads: p.workloads,
+		}
+		if duration > 0 {
+			pr.SubmitTPS = float64(p.submitted) / duration.Seconds()
+			if span := p.lastIncl.Sub(p.start); span > duration {
+				duration = span
+			}
+			pr.AchievedTPS = float64(included) / duration.Seconds()
+		}
+		report.Phases = append(report.Phases, pr)
+	}
+	return report
+}
+
+var csvHeader = []string{
+	"phase", "start", "duration_s", "target_tps",
+	"submitted", "rejected", "included", "succeeded", "failed", "pending",
+	"submit_tps", "achieved_tps",
+	"latency_avg_ms", "latency_p50_ms", "latency_p90_ms", "latency_p95_ms",
+	"latency_p99_ms", "latency_max_ms",
+	"failures", "workloads",
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		items[i] = fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return strings.Join(items, ";")
+}
+
+func (r *Report) WriteCSV(f *os.File) error {
+	w := csv.NewWriter(f)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, p := range r.Phases {
+		record := []string{
+			p.Name,
+			p.Start.Format(time.RFC3339),
+			formatFloat(time.Duration(p.Duration).Seconds()),
+			formatFloat(p.TargetTPS),
+			strconv.Itoa(p.Submitted),
+			strconv.Itoa(p.Rejected),
+			strconv.Itoa(p.Included),
+			strconv.Itoa(p.Succeeded),
+			strconv.Itoa(p.Failed),
+			strconv.Itoa(p.Pending),
+			formatFloat(p.SubmitTPS),
+			formatFloat(p.AchievedTPS),
+			formatFloat(p.Latency.Avg),
+			formatFloat(p.Latency.P50),
+			formatFloat(p.Latency.P90),
+			formatFloat(p.Latency.P95),
+			formatFloat(p.Latency.P99),
+			formatFloat(p.Latency.Max),
+			formatCounts(p.Failures),
+			formatCounts(p.Workloads),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteFile writes the report in CSV if the file has ".csv" extension, or
+// in JSON.
+func (r *Report) WriteFile(p string) error {
+	f, err := os.Create(p)
+	if err != nil {
+		return errors.Wrapf(err, "FailToCreateReport(path=%s)", p)
+	}
+	defer f.Close()
+
+	if strings.ToLower(path.Ext(p)) == ".csv" {
+		return r.WriteCSV(f)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) Print() {
+	for _, p := range r.Phases {
+		fmt.Printf("[%s] duration=%s submitted=%d included=%d failed=%d pending=%d "+
+			"submit_tps=%.2f achieved_tps=%.2f latency(ms) p50=%.1f p90=%.1f p99=%.1f max=%.1f\n",
+			p.Name, time.Duration(p.Duration).Round(time.Millisecond),
+			p.Submitted, p.Included, p.Failed, p.Pending,
+			p.SubmitTPS, p.AchievedTPS,
+			p.Latency.P50, p.Latency.P90, p.Latency.P99, p.Latency.Max)
+		if len(p.Failures) > 0 {
+			fmt.Printf("[%s] failures %s\n", p.Name, formatCounts(p.Failures))
+		}
+	}
+}
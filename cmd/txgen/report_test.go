@@ -0,0 +1,167 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+)
+
+func TestNewLatencyReport(t *testing.T) {
+	assert.Equal(t, LatencyReport{}, newLatencyReport(nil))
+
+	lr := newLatencyReport([]time.Duration{3 * time.Millisecond})
+	assert.Equal(t, LatencyReport{3, 3, 3, 3, 3, 3}, lr)
+
+	// 100ms, 99ms, ..., 1ms
+	var latencies []time.Duration
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	lr = newLatencyReport(latencies)
+	assert.Equal(t, 50.5, lr.Avg)
+	assert.Equal(t, 50.0, lr.P50)
+	assert.Equal(t, 90.0, lr.P90)
+	assert.Equal(t, 95.0, lr.P95)
+	assert.Equal(t, 99.0, lr.P99)
+	assert.Equal(t, 100.0, lr.Max)
+
+	// input is not modified
+	assert.Equal(t, 100*time.Millisecond, latencies[0])
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, 0.0, percentile(nil, 50))
+
+	sorted := []time.Duration{
+		1 * time.Millisecond,
+		2 * time.Millisecond,
+		3 * time.Millisecond,
+		4 * time.Millisecond,
+	}
+	assert.Equal(t, 1.0, percentile(sorted, 0))
+	assert.Equal(t, 1.0, percentile(sorted, 25))
+	assert.Equal(t, 2.0, percentile(sorted, 50))
+	assert.Equal(t, 3.0, percentile(sorted, 51))
+	assert.Equal(t, 4.0, percentile(sorted, 100))
+}
+
+func TestRecorder_Report(t *testing.T) {
+	var r Recorder
+	ph := &Phase{Name: "p1"}
+	idx := r.StartPhase(ph)
+	r.OnSubmit(idx, "transfer")
+	r.OnSubmit(idx, "transfer")
+	r.OnSubmit(idx, "token")
+	r.OnSubmit(idx, "token")
+	r.OnReject(idx, "token", "Rejected(-32000)")
+	r.EndPhase(idx, 150)
+
+	// make times deterministic
+	start := time.Unix(1000, 0)
+	p := r.phases[idx]
+	p.start = start
+	p.end = start.Add(2 * time.Second)
+
+	r.OnResult(&trackedTx{
+		phase:     idx,
+		submitted: start,
+		included:  start.Add(100 * time.Millisecond),
+	}, module.StatusSuccess)
+	r.OnResult(&trackedTx{
+		phase:     idx,
+		submitted: start,
+		included:  start.Add(4 * time.Second),
+	}, module.StatusReverted)
+	// included time before submission is counted as zero latency
+	r.OnResult(&trackedTx{
+		phase:     idx,
+		submitted: start.Add(time.Second),
+		included:  start,
+	}, module.StatusSuccess)
+
+	// phase without any transactions
+	r.StartPhase(&Phase{Name: "p2"})
+
+	report := r.Report("test", []string{"http://localhost:9080/api/v3"})
+	assert.Equal(t, "test", report.Profile)
+	assert.Len(t, report.Phases, 2)
+
+	pr := report.Phases[0]
+	assert.Equal(t, "p1", pr.Name)
+	assert.Equal(t, Duration(2*time.Second), pr.Duration)
+	assert.Equal(t, 150.0, pr.TargetTPS)
+	assert.Equal(t, 4, pr.Submitted)
+	assert.Equal(t, 1, pr.Rejected)
+	assert.Equal(t, 3, pr.Included)
+	assert.Equal(t, 2, pr.Succeeded)
+	assert.Equal(t, 1, pr.Failed)
+	assert.Equal(t, 1, pr.Pending)
+	assert.Equal(t, 2.0, pr.SubmitTPS)
+	// the last inclusion is after the end of the phase
+	assert.Equal(t, 0.75, pr.AchievedTPS)
+	assert.Equal(t, 100.0, pr.Latency.P50)
+	assert.Equal(t, 4000.0, pr.Latency.Max)
+	assert.Equal(t, map[string]int{
+		"Rejected(-32000)":             1,
+		module.StatusReverted.String(): 1,
+	}, pr.Failures)
+	assert.Equal(t, map[string]int{"transfer": 2, "token": 3}, pr.Workloads)
+
+	pr = report.Phases[1]
+	assert.Equal(t, "p2", pr.Name)
+	assert.Zero(t, pr.Submitted)
+	assert.Zero(t, pr.SubmitTPS)
+	assert.Zero(t, pr.AchievedTPS)
+	assert.Equal(t, LatencyReport{}, pr.Latency)
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	report := &Report{
+		Phases: []*PhaseReport{{
+			Name:      "p1",
+			Duration:  Duration(1500 * time.Millisecond),
+			Submitted: 10,
+			Failures:  map[string]int{"b": 2, "a": 1},
+			Workloads: map[string]int{"transfer": 10},
+		}},
+	}
+	file := path.Join(t.TempDir(), "report.csv")
+	assert.NoError(t, report.WriteFile(file))
+
+	f, err := os.Open(file)
+	assert.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, csvHeader, records[0])
+	row := records[1]
+	assert.Len(t, row, len(csvHeader))
+	assert.Equal(t, "p1", row[0])
+	assert.Equal(t, "1.50", row[2])
+	assert.Equal(t, "10", row[4])
+	assert.Equal(t, "a=1;b=2", row[len(row)-2])
+	assert.Equal(t, "transfer=10", row[len(row)-1])
+}
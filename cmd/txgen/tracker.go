@@ -0,0 +1,193 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const (
+	trackerResultWorkers = 8
+	trackerResultTimeout = 5 * time.Second
+)
+
+type trackedTx struct {
+	hash      string
+	phase     int
+	workload  string
+	submitted time.Time
+	included  time.Time
+}
+
+type blockForTracker struct {
+	Height       int64 `json:"height"`
+	Transactions []struct {
+		TxHash string `json:"txHash"`
+	} `json:"confirmed_transaction_list"`
+}
+
+// Tracker finds blocks including submitted transactions and gets results
+// of them.
+type Tracker struct {
+	client   *Client
+	recorder *Recorder
+	interval time.Duration
+
+	lock    sync.Mutex
+	pending map[string]*trackedTx
+	next    int64
+
+	resultCh chan *trackedTx
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewTracker(client *Client, recorder *Recorder, interval time.Duration) *Tracker {
+	return &Tracker{
+		client:   client,
+		recorder: recorder,
+		interval: interval,
+		pending:  make(map[string]*trackedTx),
+		resultCh: make(chan *trackedTx, 1024),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+func (t *Tracker) getBlock(method string, params interface{}) (*blockForTracker, error) {
+	blk := new(blockForTracker)
+	if _, err := t.client.Do(method, params, blk); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+// Start starts tracking from the block following the last block.
+func (t *Tracker) Start() error {
+	blk, err := t.getBlock("icx_getLastBlock", nil)
+	if err != nil {
+		return err
+	}
+	t.next = blk.Height + 1
+
+	t.wg.Add(1)
+	go t.scanLoop()
+	for i := 0; i < trackerResultWorkers; i++ {
+		t.wg.Add(1)
+		go t.resultLoop()
+	}
+	return nil
+}
+
+func (t *Tracker) Add(tx *trackedTx) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.pending[strings.ToLower(tx.hash)] = tx
+}
+
+func (t *Tracker) Pending() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return len(t.pending)
+}
+
+func (t *Tracker) scanLoop() {
+	defer t.wg.Done()
+	defer close(t.resultCh)
+
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case <-time.After(t.interval):
+		}
+		if err := t.scan(); err != nil {
+			log.Printf("Fail to scan blocks err=%+v", err)
+		}
+	}
+}
+
+func (t *Tracker) scan() error {
+	last, err := t.getBlock("icx_getLastBlock", nil)
+	if err != nil {
+		return err
+	}
+	for ; t.next <= last.Height; t.next++ {
+		blk, err := t.getBlock("icx_getBlockByHeight", map[string]interface{}{
+			"height": jsonrpc.HexIntFromInt64(t.next),
+		})
+		if err != nil {
+			return err
+		}
+		// timestamp of the block is decided before transactions arrive,
+		// so time of finding the block is used.
+		included := time.Now()
+		for _, tx := range blk.Transactions {
+			t.lock.Lock()
+			hash := strings.ToLower(tx.TxHash)
+			ttx, ok := t.pending[hash]
+			if ok {
+				delete(t.pending, hash)
+			}
+			t.lock.Unlock()
+			if ok {
+				ttx.included = included
+				t.resultCh <- ttx
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) resultLoop() {
+	defer t.wg.Done()
+
+	for tx := range t.resultCh {
+		r, err := t.client.GetTxResult(tx.hash, trackerResultTimeout)
+		if err != nil {
+			log.Printf("Fail to get result tx=%s err=%+v", tx.hash, err)
+			t.recorder.OnResult(tx, module.StatusTimeout)
+			continue
+		}
+		status := module.StatusSuccess
+		if r.Status.Value != 1 {
+			status = module.StatusUnknownFailure
+			if r.Failure != nil {
+				status = module.Status(r.Failure.Code.Value)
+			}
+		}
+		t.recorder.OnResult(tx, status)
+	}
+}
+
+// Drain waits until all transactions are included or timeout passes, then
+// stops tracking.
+func (t *Tracker) Drain(timeout time.Duration) {
+	limit := time.Now().Add(timeout)
+	for t.Pending() > 0 && time.Now().Before(limit) {
+		time.Sleep(t.interval)
+	}
+	close(t.stopCh)
+	t.wg.Wait()
+}
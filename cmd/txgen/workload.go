@@ -0,0 +1,202 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const (
+	defaultWorkloadWallets = 100
+	txPoolOverflowDelay    = 10 * time.Millisecond
+)
+
+// ProfileRunner sends transactions of workloads following phases of the
+// profile and reports the results of each phase.
+type ProfileRunner struct {
+	Profile    *Profile
+	Concurrent int
+	NID        int64
+	GOD        module.Wallet
+	Seed       int64
+
+	// PollInterval is the interval for checking new blocks.
+	PollInterval time.Duration
+
+	// DrainTimeout is the time to wait for pending transactions after the
+	// last phase.
+	DrainTimeout time.Duration
+
+	makers   map[string]TransactionMaker
+	recorder Recorder
+}
+
+func (r *ProfileRunner) Run(urls []string) (*Report, error) {
+	c := &Client{client.NewJsonRpcClient(&http.Client{}, urls[0])}
+	r.makers = make(map[string]TransactionMaker)
+	for _, name := range r.Profile.UsedWorkloads() {
+		maker := r.Profile.Workloads[name].NewMaker(r.NID, r.GOD)
+		if err := maker.Prepare(c); err != nil {
+			return nil, errors.Wrapf(err, "FailToPrepare(workload=%s)", name)
+		}
+		r.makers[name] = maker
+	}
+
+	tracker := NewTracker(c, &r.recorder, r.PollInterval)
+	if err := tracker.Start(); err != nil {
+		return nil, err
+	}
+	for _, ph := range r.Profile.Phases {
+		log.Printf("[#] Start phase %s", ph.Name)
+		r.runPhase(ph, urls, tracker)
+	}
+	log.Printf("[#] Wait for %d pending transactions", tracker.Pending())
+	tracker.Drain(r.DrainTimeout)
+	return r.recorder.Report(r.Profile.Name, urls), nil
+}
+
+func (r *ProfileRunner) runPhase(ph *Phase, urls []string, tracker *Tracker) {
+	idx := r.recorder.StartPhase(ph)
+	mix := newWeightedMix(ph.Mix)
+	start := time.Now()
+	end := start.Add(time.Duration(ph.Duration))
+	senders := r.Concurrent * len(urls)
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		for j := 0; j < r.Concurrent; j++ {
+			wg.Add(1)
+			c := &Client{client.NewJsonRpcClient(&http.Client{}, url)}
+			seed := r.Seed + int64(idx*senders+i*r.Concurrent+j)
+			go func() {
+				defer wg.Done()
+				s := &phaseSender{
+					runner:  r,
+					client:  c,
+					tracker: tracker,
+					phase:   ph,
+					index:   idx,
+					mix:     mix,
+					rnd:     rand.New(rand.NewSource(seed)),
+					senders: senders,
+				}
+				s.run(start, end)
+			}()
+		}
+	}
+	wg.Wait()
+
+	target := float64(ph.TPS)
+	if ph.RampTo > 0 {
+		target = float64(ph.TPS+ph.RampTo) / 2
+	}
+	r.recorder.EndPhase(idx, target)
+}
+
+type phaseSender struct {
+	runner  *ProfileRunner
+	client  *Client
+	tracker *Tracker
+	phase   *Phase
+	index   int
+	mix     *weightedMix
+	rnd     *rand.Rand
+	senders int
+}
+
+func (s *phaseSender) run(start, end time.Time) {
+	next := start
+	for {
+		now := time.Now()
+		if next.After(now) {
+			time.Sleep(next.Sub(now))
+			now = time.Now()
+		}
+		if !now.Before(end) {
+			return
+		}
+		s.sendOne()
+
+		rate := s.phase.TargetTPS(now.Sub(start))
+		delay := time.Duration(float64(time.Second) * float64(s.senders) / rate)
+		next = next.Add(delay)
+		if now.Sub(next) > delay*2 {
+			next = now
+		}
+	}
+}
+
+func (s *phaseSender) sendOne() {
+	name := s.mix.Pick(s.rnd)
+	maker := s.runner.makers[name]
+	tx, err := maker.MakeOne()
+	if err != nil {
+		log.Printf("Fail to make transaction workload=%s err=%+v", name, err)
+		return
+	}
+	defer maker.Dispose(tx)
+
+	submitted := time.Now()
+	hash, err := sendTransaction(s.client, tx)
+	if err != nil {
+		if re, ok := err.(*jsonrpc.Error); ok {
+			s.runner.recorder.OnReject(s.index, name, fmt.Sprintf("Rejected(%d)", re.Code))
+		} else {
+			log.Printf("Fail to send transaction workload=%s err=%+v", name, err)
+			s.runner.recorder.OnReject(s.index, name, "SendFailure")
+		}
+		return
+	}
+	s.runner.recorder.OnSubmit(s.index, name)
+	s.tracker.Add(&trackedTx{
+		hash:      hash,
+		phase:     s.index,
+		workload:  name,
+		submitted: submitted,
+	})
+}
+
+// sendTransaction sends the transaction and returns hash of it. It retries
+// while the transaction pool is full.
+func sendTransaction(c *Client, tx interface{}) (string, error) {
+	for {
+		r, err := c.Do("icx_sendTransaction", tx, nil)
+		if err != nil {
+			if re, ok := err.(*jsonrpc.Error); ok && re.Code == jsonrpc.ErrorCodeTxPoolOverflow {
+				time.Sleep(txPoolOverflowDelay)
+				continue
+			}
+			return "", err
+		}
+		var txHash string
+		if err := json.Unmarshal(r.Result, &txHash); err != nil {
+			return "", err
+		}
+		return txHash, nil
+	}
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bls12381 implements BLS signatures on BLS12-381 with public keys
+// in G1 and signatures in G2, following the proof of possession ciphersuite
+// BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_ of draft-irtf-cfrg-bls-signature.
+// Curve operations, hashing to G2 and pairings are done by kilic/bls12-381,
+// which is written in Go, so it doesn't require cgo.
+package bls12381
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	bls "github.com/kilic/bls12-381"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	SecretKeySize = 32
+	PublicKeySize = 48
+	SignatureSize = 96
+)
+
+var (
+	dstSignature = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	dstPoP       = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	keyGenSalt   = []byte("BLS-SIG-KEYGEN-SALT-")
+)
+
+// order is the order of the groups.
+var order = bls.NewG1().Q()
+
+type SecretKey struct {
+	k *big.Int
+}
+
+type PublicKey struct {
+	p *bls.PointG1
+}
+
+type Signature struct {
+	p *bls.PointG2
+}
+
+// NewSecretKeyFromIKM derives a secret key from the input key material as
+// KeyGen of draft-irtf-cfrg-bls-signature.
+func NewSecretKeyFromIKM(ikm []byte) (*SecretKey, error) {
+	if len(ikm) < 32 {
+		return nil, errors.IllegalArgumentError.New("TooShortIKM")
+	}
+	salt := keyGenSalt
+	k := new(big.Int)
+	for k.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		okm := make([]byte, 48)
+		r := hkdf.New(sha256.New, append(append([]byte{}, ikm...), 0), salt, []byte{0, 48})
+		if _, err := io.ReadFull(r, okm); err != nil {
+			return nil, errors.IllegalArgumentError.Wrap(err, "InvalidIKM")
+		}
+		k.SetBytes(okm).Mod(k, order)
+	}
+	return &SecretKey{k}, nil
+}
+
+func GenerateSecretKey() (*SecretKey, error) {
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, ikm); err != nil {
+		return nil, err
+	}
+	return NewSecretKeyFromIKM(ikm)
+}
+
+func SecretKeyFromBytes(bs []byte) (*SecretKey, error) {
+	if len(bs) != SecretKeySize {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidSecretKeyLength(len=%d)", len(bs))
+	}
+	k := new(big.Int).SetBytes(bs)
+	if k.Sign() == 0 || k.Cmp(order) >= 0 {
+		return nil, errors.IllegalArgumentError.New("InvalidSecretKey")
+	}
+	return &SecretKey{k}, nil
+}
+
+func (sk *SecretKey) Bytes() []byte {
+	return sk.k.FillBytes(make([]byte, SecretKeySize))
+}
+
+func (sk *SecretKey) PublicKey() *PublicKey {
+	g1 := bls.NewG1()
+	return &PublicKey{g1.MulScalarBig(g1.New(), g1.One(), sk.k)}
+}
+
+func (sk *SecretKey) sign(msg, dst []byte) *Signature {
+	g2 := bls.NewG2()
+	h, err := g2.HashToCurve(msg, dst)
+	if err != nil {
+		// it fails only with too long DST.
+		panic(err)
+	}
+	return &Signature{g2.MulScalarBig(g2.New(), h, sk.k)}
+}
+
+func (sk *SecretKey) Sign(msg []byte) *Signature {
+	return sk.sign(msg, dstSignature)
+}
+
+// ProofOfPossession returns the signature of the public key.
+func (sk *SecretKey) ProofOfPossession() *Signature {
+	return sk.sign(sk.PublicKey().Bytes(), dstPoP)
+}
+
+// PublicKeyFromBytes decodes the compressed public key. It rejects the
+// infinity and the points out of the subgroup.
+func PublicKeyFromBytes(bs []byte) (*PublicKey, error) {
+	if len(bs) != PublicKeySize {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidPublicKeyLength(len=%d)", len(bs))
+	}
+	g1 := bls.NewG1()
+	p, err := g1.FromCompressed(bs)
+	if err != nil || g1.IsZero(p) {
+		return nil, errors.IllegalArgumentError.New("InvalidPublicKey")
+	}
+	return &PublicKey{p}, nil
+}
+
+func (pk *PublicKey) Bytes() []byte {
+	return bls.NewG1().ToCompressed(pk.p)
+}
+
+func (pk *PublicKey) Equal(pk2 *PublicKey) bool {
+	return bls.NewG1().Equal(pk.p, pk2.p)
+}
+
+func verify(pk *bls.PointG1, msg, dst []byte, sig *bls.PointG2) bool {
+	h, err := bls.NewG2().HashToCurve(msg, dst)
+	if err != nil {
+		return false
+	}
+	e := bls.NewEngine()
+	e.AddPairInv(bls.NewG1().One(), sig)
+	e.AddPair(pk, h)
+	return e.Check()
+}
+
+// Verify verifies the signature of the message.
+func (pk *PublicKey) Verify(msg []byte, sig *Signature) bool {
+	return verify(pk.p, msg, dstSignature, sig.p)
+}
+
+// VerifyProofOfPossession verifies the proof of possession of the key.
+func (pk *PublicKey) VerifyProofOfPossession(pop *Signature) bool {
+	return verify(pk.p, pk.Bytes(), dstPoP, pop.p)
+}
+
+// AggregatePublicKeys returns the sum of public keys. Public keys shall be
+// verified with proof of possession before.
+func AggregatePublicKeys(pks []*PublicKey) (*PublicKey, error) {
+	if len(pks) == 0 {
+		return nil, errors.IllegalArgumentError.New("NoPublicKeys")
+	}
+	g1 := bls.NewG1()
+	agg := g1.Zero()
+	for _, pk := range pks {
+		g1.Add(agg, agg, pk.p)
+	}
+	return &PublicKey{agg}, nil
+}
+
+// SignatureFromBytes decodes the compressed signature. It rejects the
+// points out of the subgroup.
+func SignatureFromBytes(bs []byte) (*Signature, error) {
+	if len(bs) != SignatureSize {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidSignatureLength(len=%d)", len(bs))
+	}
+	p, err := bls.NewG2().FromCompressed(bs)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.New("InvalidSignature")
+	}
+	return &Signature{p}, nil
+}
+
+func (sig *Signature) Bytes() []byte {
+	return bls.NewG2().ToCompressed(sig.p)
+}
+
+func (sig *Signature) Equal(sig2 *Signature) bool {
+	return bls.NewG2().Equal(sig.p, sig2.p)
+}
+
+// Aggregate returns sum of the signatures.
+func (sig *Signature) Aggregate(sig2 *Signature) *Signature {
+	g2 := bls.NewG2()
+	return &Signature{g2.Add(g2.New(), sig.p, sig2.p)}
+}
+
+// FastAggregateVerify verifies the aggregated signature of the same message
+// signed by the public keys.
+func FastAggregateVerify(pks []*PublicKey, msg []byte, sig *Signature) bool {
+	agg, err := AggregatePublicKeys(pks)
+	if err != nil {
+		return false
+	}
+	return agg.Verify(msg, sig)
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bls12381
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	bs, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bs
+}
+
+// secret keys and messages of the test vectors from
+// https://github.com/ethereum/bls12-381-tests
+var (
+	vectorSecretKeys = []string{
+		"263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		"47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+		"328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+	}
+	vectorPublicKeys = []string{
+		"a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		"b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		"b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+	}
+)
+
+func TestPublicKeyVectors(t *testing.T) {
+	for i, s := range vectorSecretKeys {
+		sk, err := SecretKeyFromBytes(decodeHex(t, s))
+		assert.NoError(t, err)
+		assert.Equal(t, vectorPublicKeys[i], hex.EncodeToString(sk.PublicKey().Bytes()))
+	}
+}
+
+func TestSignVectors(t *testing.T) {
+	cases := []struct {
+		sk  string
+		msg byte
+		sig string
+	}{
+		{
+			vectorSecretKeys[0], 0x00,
+			"b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb515809" +
+				"0352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55",
+		},
+		{
+			vectorSecretKeys[0], 0x56,
+			"882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c2" +
+				"0767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb",
+		},
+		{
+			vectorSecretKeys[0], 0xab,
+			"91347bccf740d859038fcdcaf233eeceb2a436bcaaee9b2aa3bfb70efe29dfb2677562ccbea1c8e061fb9971b0753c24" +
+				"0622fab78489ce96768259fc01360346da5b9f579e5da0d941e4c6ba18a0e64906082375394f337fa1af2b7127b0d121",
+		},
+	}
+	for _, c := range cases {
+		sk, err := SecretKeyFromBytes(decodeHex(t, c.sk))
+		assert.NoError(t, err)
+		msg := bytes.Repeat([]byte{c.msg}, 32)
+		sig := sk.Sign(msg)
+		assert.Equal(t, c.sig, hex.EncodeToString(sig.Bytes()), "msg=%#x", c.msg)
+
+		sig2, err := SignatureFromBytes(decodeHex(t, c.sig))
+		assert.NoError(t, err)
+		assert.True(t, sk.PublicKey().Verify(msg, sig2))
+	}
+}
+
+func TestFastAggregateVerifyVector(t *testing.T) {
+	var pks []*PublicKey
+	for _, s := range vectorPublicKeys {
+		pk, err := PublicKeyFromBytes(decodeHex(t, s))
+		assert.NoError(t, err)
+		pks = append(pks, pk)
+	}
+	msg := bytes.Repeat([]byte{0xab}, 32)
+	sig, err := SignatureFromBytes(decodeHex(t,
+		"9712c3edd73a209c742b8250759db12549b3eaf43b5ca61376d9f30e2747dbcf842d8b2ac0901d2a093713e20284a767"+
+			"0fcf6954e9ab93de991bb9b313e664785a075fc285806fa5224c82bde146561b446ccfc706a64b8579513cfc4ff1d930"))
+	assert.NoError(t, err)
+	assert.True(t, FastAggregateVerify(pks, msg, sig))
+	assert.False(t, FastAggregateVerify(pks[:2], msg, sig))
+	assert.False(t, FastAggregateVerify(pks, bytes.Repeat([]byte{0x56}, 32), sig))
+}
+
+func TestKeyGenVector(t *testing.T) {
+	// test case 0 of EIP-2333 where the master key is derived by KeyGen
+	seed := decodeHex(t,
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e5349553"+
+			"1f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	sk, err := NewSecretKeyFromIKM(seed)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"6083874454709270928345386274498605044986640685124978867557563392430687146096",
+		new(big.Int).SetBytes(sk.Bytes()).String())
+}
+
+func TestSignAndVerify(t *testing.T) {
+	sk, err := GenerateSecretKey()
+	assert.NoError(t, err)
+	pk := sk.PublicKey()
+	msg := []byte("message")
+
+	sig := sk.Sign(msg)
+	assert.True(t, pk.Verify(msg, sig))
+	assert.False(t, pk.Verify([]byte("other"), sig))
+
+	sig2, err := SignatureFromBytes(sig.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, sig.Equal(sig2))
+	pk2, err := PublicKeyFromBytes(pk.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, pk.Equal(pk2))
+	sk2, err := SecretKeyFromBytes(sk.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, sk2.PublicKey().Equal(pk))
+
+	pop := sk.ProofOfPossession()
+	assert.True(t, pk.VerifyProofOfPossession(pop))
+	assert.False(t, pk.VerifyProofOfPossession(sig))
+}
+
+func TestInvalidEncodings(t *testing.T) {
+	infinity := make([]byte, PublicKeySize)
+	infinity[0] = 0xc0
+	_, err := PublicKeyFromBytes(infinity)
+	assert.Error(t, err)
+
+	pk := decodeHex(t, vectorPublicKeys[0])
+	_, err = PublicKeyFromBytes(pk[1:])
+	assert.Error(t, err)
+	pk[0] &^= 0x80
+	_, err = PublicKeyFromBytes(pk)
+	assert.Error(t, err)
+
+	_, err = SignatureFromBytes(make([]byte, SignatureSize))
+	assert.Error(t, err)
+
+	_, err = SecretKeyFromBytes(make([]byte, SecretKeySize))
+	assert.Error(t, err)
+	_, err = SecretKeyFromBytes(bytes.Repeat([]byte{0xff}, SecretKeySize))
+	assert.Error(t, err)
+}
+
+func TestKeyGen(t *testing.T) {
+	ikm := make([]byte, 32)
+	sk1, err := NewSecretKeyFromIKM(ikm)
+	assert.NoError(t, err)
+	sk2, err := NewSecretKeyFromIKM(ikm)
+	assert.NoError(t, err)
+	assert.Equal(t, sk1.Bytes(), sk2.Bytes())
+
+	ikm[0] = 1
+	sk3, err := NewSecretKeyFromIKM(ikm)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sk1.Bytes(), sk3.Bytes())
+
+	_, err = NewSecretKeyFromIKM(ikm[:31])
+	assert.Error(t, err)
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	msg := []byte("decision")
+	var pks []*PublicKey
+	var agg *Signature
+	for i := 0; i < 3; i++ {
+		sk, _ := GenerateSecretKey()
+		pks = append(pks, sk.PublicKey())
+		if agg == nil {
+			agg = sk.Sign(msg)
+		} else {
+			agg = agg.Aggregate(sk.Sign(msg))
+		}
+	}
+	assert.True(t, FastAggregateVerify(pks, msg, agg))
+	assert.False(t, FastAggregateVerify(pks[:2], msg, agg))
+	assert.False(t, FastAggregateVerify(pks, []byte("other"), agg))
+	assert.False(t, FastAggregateVerify(nil, msg, agg))
+}
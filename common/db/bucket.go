@@ -66,6 +66,9 @@ const (
 	// ListByMerkleRootBase is the base for the bucket that maps list
 	// from network type dependent merkle root(list)
 	ListByMerkleRootBase BucketID = "L"
+
+	// AccountIndex maps index entries of accounts from address.
+	AccountIndex BucketID = "A"
 )
 
 // internalKey returns key prefixed with the bucket's id.
@@ -17,6 +17,54 @@ type LayerDB interface {
 	Unwrap() Database
 }
 
+// CheckpointWriter writes a consistent view of the database taken by
+// Checkpointer. It can be used while the database is being updated.
+type CheckpointWriter interface {
+	// Write writes the view as the database with the same name in the
+	// directory. The directory can be opened with the same backend type.
+	// It stops with ErrInterrupted if interrupted (it may be nil) returns
+	// true while it's copying entries.
+	Write(interrupted func() bool) error
+
+	// Release releases resources of the view. It should be called even if
+	// Write fails.
+	Release()
+}
+
+// Checkpointer is implemented by the database which can make a consistent
+// copy of itself while it's being updated.
+type Checkpointer interface {
+	// Checkpoint takes a consistent view of the database to be written in
+	// the directory. It's cheap, so it can be called with the lock blocking
+	// updates of the database, then the view can be written without it.
+	Checkpoint(dir string) (CheckpointWriter, error)
+}
+
+// Checkpoint takes a consistent view of the database to be written in the
+// directory. It returns UnsupportedError if the backend doesn't support it.
+func Checkpoint(database Database, dir string) (CheckpointWriter, error) {
+	for {
+		switch d := database.(type) {
+		case Checkpointer:
+			return d.Checkpoint(dir)
+		case *databaseContext:
+			database = d.Database
+		default:
+			return nil, errors.UnsupportedError.Errorf("CheckpointUnsupported(db=%T)", database)
+		}
+	}
+}
+
+// Iterable is implemented by the bucket which can iterate its entries.
+type Iterable interface {
+	// Iterate calls fn for each entry of the bucket until fn returns false.
+	// Key and value are valid only in the call, and fn may modify the
+	// bucket. Entries modified during the iteration may or may not be
+	// visible. For the backends sharing a key space among buckets, entries
+	// of other buckets whose IDs start with the bucket ID are also visible.
+	Iterate(fn func(key, value []byte) bool) error
+}
+
 type BackendType string
 
 type dbCreator func(name string, dir string) (Database, error)
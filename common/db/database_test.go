@@ -17,9 +17,12 @@
 package db
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
 )
 
 func testDatabase_GetSetDelete(t *testing.T, creator dbCreator) {
@@ -258,3 +261,123 @@ func TestDatabase_OperationAfterClose(t *testing.T) {
 		})
 	}
 }
+
+func testDatabase_Checkpoint(t *testing.T, creator dbCreator) {
+	dir := t.TempDir()
+	key := []byte("hello")
+	value := []byte("world")
+	value2 := []byte("world2")
+
+	testDB, err := creator("test", dir)
+	assert.NoError(t, err)
+	defer testDB.Close()
+
+	bucket, err := testDB.GetBucket(MerkleTrie)
+	assert.NoError(t, err)
+	assert.NoError(t, bucket.Set(key, value))
+
+	cpDir := t.TempDir()
+	cp, err := Checkpoint(WithFlags(testDB, Flags{"test": true}), cpDir)
+	assert.NoError(t, err)
+
+	// changes after checkpoint are not applied
+	assert.NoError(t, bucket.Set(key, value2))
+	assert.NoError(t, cp.Write(nil))
+	cp.Release()
+
+	cpDB, err := creator("test", cpDir)
+	assert.NoError(t, err)
+	defer cpDB.Close()
+
+	bucket2, err := cpDB.GetBucket(MerkleTrie)
+	assert.NoError(t, err)
+	stored, err := bucket2.Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, value, stored)
+}
+
+func TestDatabase_Checkpoint(t *testing.T) {
+	for name, creator := range backends {
+		if name == MapDBBackend {
+			continue
+		}
+		t.Run(string(name), func(t *testing.T) {
+			testDatabase_Checkpoint(t, creator)
+		})
+	}
+	_, err := Checkpoint(NewMapDB(), t.TempDir())
+	assert.True(t, errors.UnsupportedError.Equals(err))
+}
+
+func TestGoLevelDB_CheckpointInterrupted(t *testing.T) {
+	testDB, err := NewGoLevelDB("test", t.TempDir())
+	assert.NoError(t, err)
+	defer testDB.Close()
+
+	bucket, err := testDB.GetBucket(MerkleTrie)
+	assert.NoError(t, err)
+	value := make([]byte, 1024)
+	for i := 0; i < 2*checkpointBatchSize/len(value); i++ {
+		assert.NoError(t, bucket.Set([]byte(fmt.Sprint(i)), value))
+	}
+
+	// it stops after the first batch
+	cp, err := testDB.Checkpoint(t.TempDir())
+	assert.NoError(t, err)
+	defer cp.Release()
+	calls := 0
+	err = cp.Write(func() bool {
+		calls++
+		return true
+	})
+	assert.True(t, errors.InterruptedError.Equals(err))
+	assert.Equal(t, 1, calls)
+}
+
+func testDatabase_Iterate(t *testing.T, creator dbCreator) {
+	testDB, err := creator("test", t.TempDir())
+	assert.NoError(t, err)
+	defer testDB.Close()
+
+	entries := map[string]string{
+		"key1": "value1",
+		"key2": "value2",
+		"key3": "value3",
+	}
+	bucket, err := testDB.GetBucket(BytesByHash)
+	assert.NoError(t, err)
+	for k, v := range entries {
+		assert.NoError(t, bucket.Set([]byte(k), []byte(v)))
+	}
+	other, err := testDB.GetBucket(TransactionLocatorByHash)
+	assert.NoError(t, err)
+	assert.NoError(t, other.Set([]byte("key4"), []byte("value4")))
+
+	itr, ok := bucket.(Iterable)
+	assert.True(t, ok)
+
+	found := make(map[string]string)
+	err = itr.Iterate(func(key, value []byte) bool {
+		found[string(key)] = string(value)
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, entries, found)
+
+	// stop iteration
+	count := 0
+	err = itr.Iterate(func(key, value []byte) bool {
+		count += 1
+		return false
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestDatabase_Iterate(t *testing.T) {
+	for name, creator := range backends {
+		t.Run(string(name), func(t *testing.T) {
+			testDatabase_Iterate(t, creator)
+		})
+	}
+}
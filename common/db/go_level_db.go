@@ -6,6 +6,9 @@ import (
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/icon-project/goloop/common/errors"
 )
 
 const GoLevelDBBackend BackendType = "goleveldb"
@@ -28,6 +31,7 @@ func NewGoLevelDBWithOpts(name string, dir string, o *opt.Options) (*GoLevelDB,
 		return nil, err
 	}
 	database := &GoLevelDB{
+		name:    name,
 		db:      db,
 		buckets: make(map[BucketID]Bucket),
 	}
@@ -41,6 +45,7 @@ var _ Database = (*GoLevelDB)(nil)
 
 type GoLevelDB struct {
 	lock    sync.Mutex
+	name    string
 	db      *leveldb.DB
 	buckets map[BucketID]Bucket
 }
@@ -79,6 +84,81 @@ func (db *GoLevelDB) Close() error {
 	return nil
 }
 
+const checkpointBatchSize = 4 * 1024 * 1024
+
+type goLevelCheckpoint struct {
+	ss   *leveldb.Snapshot
+	path string
+}
+
+// Write copies all entries in the snapshot to the new database. It checks
+// interruption for each batch.
+func (cp *goLevelCheckpoint) Write(interrupted func() bool) error {
+	dst, err := leveldb.OpenFile(cp.path, &opt.Options{
+		ErrorIfExist: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if dst != nil {
+			dst.Close()
+		}
+	}()
+
+	itr := cp.ss.NewIterator(nil, nil)
+	defer itr.Release()
+	batch := new(leveldb.Batch)
+	for itr.Next() {
+		batch.Put(itr.Key(), itr.Value())
+		if len(batch.Dump()) >= checkpointBatchSize {
+			if err := dst.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+			if interrupted != nil && interrupted() {
+				return errors.ErrInterrupted
+			}
+		}
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+	if interrupted != nil && interrupted() {
+		return errors.ErrInterrupted
+	}
+	if err := dst.Write(batch, nil); err != nil {
+		return err
+	}
+	err = dst.Close()
+	dst = nil
+	return err
+}
+
+func (cp *goLevelCheckpoint) Release() {
+	cp.ss.Release()
+}
+
+// Checkpoint takes a snapshot of the database. Entries are copied by Write
+// of the returned writer.
+func (db *GoLevelDB) Checkpoint(dir string) (CheckpointWriter, error) {
+	db.lock.Lock()
+	ldb := db.db
+	db.lock.Unlock()
+	if ldb == nil {
+		return nil, leveldb.ErrClosed
+	}
+
+	ss, err := ldb.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &goLevelCheckpoint{
+		ss:   ss,
+		path: filepath.Join(dir, db.name),
+	}, nil
+}
+
 //----------------------------------------
 // GetBucket
 
@@ -109,3 +189,21 @@ func (bucket *goLevelBucket) Set(key []byte, value []byte) error {
 func (bucket *goLevelBucket) Delete(key []byte) error {
 	return bucket.db.Delete(internalKey(bucket.id, key), nil)
 }
+
+// Iterate iterates entries in a snapshot of the database.
+func (bucket *goLevelBucket) Iterate(fn func(key, value []byte) bool) error {
+	ss, err := bucket.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer ss.Release()
+
+	itr := ss.NewIterator(util.BytesPrefix([]byte(bucket.id)), nil)
+	defer itr.Release()
+	for itr.Next() {
+		if !fn(itr.Key()[len(bucket.id):], itr.Value()) {
+			break
+		}
+	}
+	return itr.Error()
+}
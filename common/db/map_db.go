@@ -115,3 +115,20 @@ func (t *mapBucket) Delete(k []byte) error {
 	delete(t.real, string(k))
 	return nil
 }
+
+// Iterate iterates a copy of entries.
+func (t *mapBucket) Iterate(fn func(key, value []byte) bool) error {
+	t.mutex.Lock()
+	entries := make(map[string]string, len(t.real))
+	for k, v := range t.real {
+		entries[k] = v
+	}
+	t.mutex.Unlock()
+
+	for k, v := range entries {
+		if !fn([]byte(k), []byte(v)) {
+			break
+		}
+	}
+	return nil
+}
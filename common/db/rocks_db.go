@@ -49,6 +49,7 @@ func init() {
 }
 
 type RocksDB struct {
+	name    string
 	lock    sync.RWMutex
 	bkLock  sync.Mutex
 	buckets map[BucketID]*RocksBucket
@@ -132,6 +133,7 @@ func NewRocksDB(name string, dir string) (*RocksDB, error) {
 	ro := C.rocksdb_readoptions_create()
 	wo := C.rocksdb_writeoptions_create()
 	rdb := &RocksDB{
+		name:    name,
 		db:      hdl,
 		ro:      ro,
 		wo:      wo,
@@ -164,6 +166,46 @@ func (db *RocksDB) Close() error {
 	return nil
 }
 
+type rocksCheckpoint struct{}
+
+func (rocksCheckpoint) Write(_ func() bool) error {
+	return nil
+}
+
+func (rocksCheckpoint) Release() {}
+
+// Checkpoint makes a checkpoint of the database in the directory. Files
+// are hard-linked if the directory is in the same file system, so it's
+// made on the call and Write of the returned writer does nothing.
+func (db *RocksDB) Checkpoint(dir string) (CheckpointWriter, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, ErrAlreadyClosed
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	var cErr *C.char
+	cp := C.rocksdb_checkpoint_object_create(db.db, &cErr)
+	if cErr != nil {
+		defer C.rocksdb_free(unsafe.Pointer(cErr))
+		return nil, errors.New(C.GoString(cErr))
+	}
+	defer C.rocksdb_checkpoint_object_destroy(cp)
+
+	cDir := C.CString(path.Join(dir, db.name))
+	defer C.free(unsafe.Pointer(cDir))
+	C.rocksdb_checkpoint_create(cp, cDir, C.uint64_t(0), &cErr)
+	if cErr != nil {
+		defer C.rocksdb_free(unsafe.Pointer(cErr))
+		return nil, errors.New(C.GoString(cErr))
+	}
+	return rocksCheckpoint{}, nil
+}
+
 func (db *RocksDB) GetBucket(id BucketID) (Bucket, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -316,3 +358,61 @@ func (b *RocksBucket) Set(key []byte, value []byte) error {
 func (b *RocksBucket) Delete(key []byte) error {
 	return b.db.deleteValue(b.cf, key)
 }
+
+const rocksIteratePageSize = 1024
+
+// readPage reads entries of the column family from the start key up to
+// the limit. It starts from the first if start is nil.
+func (db *RocksDB) readPage(cf *C.rocksdb_column_family_handle_t, start []byte, limit int) ([][]byte, [][]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, nil, ErrAlreadyClosed
+	}
+	itr := C.rocksdb_create_iterator_cf(db.db, db.ro, cf)
+	defer C.rocksdb_iter_destroy(itr)
+
+	if start == nil {
+		C.rocksdb_iter_seek_to_first(itr)
+	} else {
+		C.rocksdb_iter_seek(itr, (*C.char)(unsafePointerOf(start)), C.size_t(len(start)))
+	}
+	var keys, values [][]byte
+	for ; len(keys) < limit && C.rocksdb_iter_valid(itr) != 0; C.rocksdb_iter_next(itr) {
+		var kLen, vLen C.size_t
+		cKey := C.rocksdb_iter_key(itr, &kLen)
+		cValue := C.rocksdb_iter_value(itr, &vLen)
+		keys = append(keys, C.GoBytes(unsafe.Pointer(cKey), C.int(kLen)))
+		values = append(values, C.GoBytes(unsafe.Pointer(cValue), C.int(vLen)))
+	}
+	var cErr *C.char
+	C.rocksdb_iter_get_error(itr, &cErr)
+	if cErr != nil {
+		defer C.rocksdb_free(unsafe.Pointer(cErr))
+		return nil, nil, errors.New(C.GoString(cErr))
+	}
+	return keys, values, nil
+}
+
+// Iterate iterates entries page by page not to hold the database while
+// fn is called.
+func (b *RocksBucket) Iterate(fn func(key, value []byte) bool) error {
+	var start []byte
+	for {
+		keys, values, err := b.db.readPage(b.cf, start, rocksIteratePageSize)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			if !fn(keys[i], values[i]) {
+				return nil
+			}
+		}
+		if len(keys) < rocksIteratePageSize {
+			return nil
+		}
+		last := keys[len(keys)-1]
+		start = append(last[:len(last):len(last)], 0)
+	}
+}
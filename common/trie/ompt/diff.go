@@ -0,0 +1,184 @@
+package ompt
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+// DiffHandler is called for each different entry of two tries.
+// op is -1 if the entry exists only in the first, 1 if it exists only in
+// the second, and 0 if values are different.
+type DiffHandler func(op int, key []byte, o1, o2 trie.Object) error
+
+type differ struct {
+	m1, m2  *mpt
+	handler DiffHandler
+}
+
+func (d *differ) diffObject(k string, o1, o2 trie.Object) error {
+	var err error
+	if o1, _, err = d.m1.getObject(o1); err != nil {
+		return err
+	}
+	if o2, _, err = d.m2.getObject(o2); err != nil {
+		return err
+	}
+	key := keysToBytes(k)
+	switch {
+	case o1 == nil && o2 == nil:
+		return nil
+	case o2 == nil:
+		return d.handler(-1, key, o1, nil)
+	case o1 == nil:
+		return d.handler(1, key, nil, o2)
+	case !bytes.Equal(o1.Bytes(), o2.Bytes()):
+		return d.handler(0, key, o1, o2)
+	default:
+		return nil
+	}
+}
+
+func realizeNode(m *mpt, n node) (node, error) {
+	if n == nil {
+		return nil, nil
+	}
+	return n.realize(m)
+}
+
+func (d *differ) diff(k string, n1, n2 node) error {
+	if n1 == nil && n2 == nil {
+		return nil
+	}
+	if n1 != nil && n2 != nil {
+		if h1 := n1.hash(); len(h1) > 0 && bytes.Equal(h1, n2.hash()) {
+			return nil
+		}
+	}
+	var err error
+	if n1, err = realizeNode(d.m1, n1); err != nil {
+		return err
+	}
+	if n2, err = realizeNode(d.m2, n2); err != nil {
+		return err
+	}
+	switch t1 := n1.(type) {
+	case *branch:
+		if t2, ok := n2.(*branch); ok {
+			l1, l2 := t1.rlock(), t2.rlock()
+			c1, c2 := t1.children, t2.children
+			v1, v2 := t1.value, t2.value
+			l1.Unlock()
+			l2.Unlock()
+			if err := d.diffObject(k, v1, v2); err != nil {
+				return err
+			}
+			for i := 0; i < 16; i++ {
+				if err := d.diff(k+string([]byte{byte(i)}), c1[i], c2[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *extension:
+		if t2, ok := n2.(*extension); ok && bytes.Equal(t1.keys, t2.keys) {
+			return d.diff(k+string(t1.keys), t1.next, t2.next)
+		}
+	}
+	return d.merge(k, n1, n2)
+}
+
+func (d *differ) iteratorOf(m *mpt, k string, n node) *iterator {
+	i := &iterator{m: m}
+	if n != nil {
+		i.stack = []iteratorItem{{k: k, n: n}}
+		i.Next()
+	}
+	return i
+}
+
+// merge compares all entries of the sub-tries in different shapes.
+func (d *differ) merge(k string, n1, n2 node) error {
+	for i1, i2 := d.iteratorOf(d.m1, k, n1), d.iteratorOf(d.m2, k, n2); i1.Has() || i2.Has(); {
+		o1, k1, err := i1.Get()
+		if err != nil {
+			return err
+		}
+		o2, k2, err := i2.Get()
+		if err != nil {
+			return err
+		}
+		op := bytes.Compare(k1, k2)
+		if !i2.Has() {
+			op = -1
+		} else if !i1.Has() {
+			op = 1
+		}
+		switch op {
+		case -1:
+			if err := d.handler(-1, k1, o1, nil); err != nil {
+				return err
+			}
+			if err := i1.Next(); err != nil {
+				return err
+			}
+		case 0:
+			if !bytes.Equal(o1.Bytes(), o2.Bytes()) {
+				if err := d.handler(0, k1, o1, o2); err != nil {
+					return err
+				}
+			}
+			if err := i1.Next(); err != nil {
+				return err
+			}
+			if err := i2.Next(); err != nil {
+				return err
+			}
+		case 1:
+			if err := d.handler(1, k2, nil, o2); err != nil {
+				return err
+			}
+			if err := i2.Next(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func mptOf(t interface{}) (*mpt, bool) {
+	switch m := t.(type) {
+	case nil:
+		return nil, true
+	case *mpt:
+		return m, true
+	case *mptForBytes:
+		return m.mpt, true
+	default:
+		return nil, false
+	}
+}
+
+// Diff calls handler for each different entry of two tries in the order
+// of keys. Sub-tries with the same hash are skipped without loading them.
+// Both tries should be ones of this package, and nil is regarded as an
+// empty trie.
+func Diff(t1, t2 interface{}, handler DiffHandler) error {
+	m1, ok1 := mptOf(t1)
+	m2, ok2 := mptOf(t2)
+	if !ok1 || !ok2 {
+		return errors.UnsupportedError.Errorf("UnsupportedTrie(t1=%T,t2=%T)", t1, t2)
+	}
+	d := &differ{m1: m1, m2: m2, handler: handler}
+	return d.diff("", m1.rootNode(), m2.rootNode())
+}
+
+func (m *mpt) rootNode() node {
+	if m == nil {
+		return nil
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.root
+}
@@ -0,0 +1,87 @@
+package ompt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+func TestDiff(t *testing.T) {
+	dbase := db.NewMapDB()
+
+	m1 := NewMutable(dbase, nil)
+	for i := 0; i < 300; i++ {
+		_, err := m1.Set([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%d", i)))
+		assert.NoError(t, err)
+	}
+	s1 := m1.GetSnapshot()
+	assert.NoError(t, s1.Flush())
+
+	m2 := NewMutable(dbase, s1.Hash())
+	_, err := m2.Delete([]byte("key010"))
+	assert.NoError(t, err)
+	_, err = m2.Set([]byte("key100"), []byte("changed"))
+	assert.NoError(t, err)
+	_, err = m2.Set([]byte("key2000"), []byte("added"))
+	assert.NoError(t, err)
+	s2 := m2.GetSnapshot()
+	assert.NoError(t, s2.Flush())
+
+	type result struct {
+		op     int
+		key    string
+		v1, v2 string
+	}
+	valueOf := func(o trie.Object) string {
+		if o == nil {
+			return ""
+		}
+		return string(o.Bytes())
+	}
+	var results []result
+	err = Diff(NewImmutable(dbase, s1.Hash()), NewImmutable(dbase, s2.Hash()),
+		func(op int, key []byte, o1, o2 trie.Object) error {
+			results = append(results, result{op, string(key), valueOf(o1), valueOf(o2)})
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []result{
+		{-1, "key010", "value10", ""},
+		{0, "key100", "value100", "changed"},
+		{1, "key2000", "", "added"},
+	}, results)
+
+	// same tries
+	err = Diff(s1, NewImmutable(dbase, s1.Hash()), func(op int, key []byte, o1, o2 trie.Object) error {
+		t.Errorf("unexpected difference key=%s", key)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// empty trie
+	var count int
+	err = Diff(NewImmutable(dbase, nil), s1, func(op int, key []byte, o1, o2 trie.Object) error {
+		assert.Equal(t, 1, op)
+		count += 1
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 300, count)
+
+	// nil is an empty trie
+	count = 0
+	err = Diff(s1, nil, func(op int, key []byte, o1, o2 trie.Object) error {
+		assert.Equal(t, -1, op)
+		count += 1
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 300, count)
+
+	err = Diff(s1, "invalid", nil)
+	assert.Error(t, err)
+}
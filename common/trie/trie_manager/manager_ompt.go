@@ -40,3 +40,26 @@ func SetCacheOfMutable(mutable trie.Mutable, cache *cache.NodeCache) {
 func SetCacheOfMutableForObject(mutable trie.MutableForObject, cache *cache.NodeCache) {
 	ompt.SetCacheOfMutableForObject(mutable, cache)
 }
+
+// DiffImmutableForObject calls handler for each different entry of two
+// tries. Unlike CompareImmutableForObject, it skips sub-tries with the same
+// hash. nil is regarded as an empty trie.
+func DiffImmutableForObject(t1, t2 trie.ImmutableForObject, handler ompt.DiffHandler) error {
+	return ompt.Diff(t1, t2, handler)
+}
+
+// DiffImmutable calls handler for each different entry of two tries.
+// Unlike CompareImmutable, it skips sub-tries with the same hash.
+// nil is regarded as an empty trie.
+func DiffImmutable(t1, t2 trie.Immutable, handler func(op int, key, v1, v2 []byte) error) error {
+	return ompt.Diff(t1, t2, func(op int, key []byte, o1, o2 trie.Object) error {
+		var v1, v2 []byte
+		if o1 != nil {
+			v1 = o1.Bytes()
+		}
+		if o2 != nil {
+			v2 = o2.Bytes()
+		}
+		return handler(op, key, v1, v2)
+	})
+}
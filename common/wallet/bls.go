@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wallet
+
+import (
+	"github.com/icon-project/goloop/common/crypto/bls12381"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	DSASecp256k1 = "ecdsa/secp256k1"
+	DSABLS12381  = "bls/bls12381"
+)
+
+type blsWallet struct {
+	skey *bls12381.SecretKey
+	pkey []byte
+}
+
+func (w *blsWallet) Sign(data []byte) ([]byte, error) {
+	return w.skey.Sign(data).Bytes(), nil
+}
+
+func (w *blsWallet) PublicKey() []byte {
+	return w.pkey
+}
+
+// PublicKeyWithProof returns the public key followed by its proof of
+// possession, which is required to register the key for BTP.
+func (w *blsWallet) PublicKeyWithProof() []byte {
+	return append(append([]byte{}, w.pkey...), w.skey.ProofOfPossession().Bytes()...)
+}
+
+func NewBLSWallet(sk *bls12381.SecretKey) module.BaseWallet {
+	return &blsWallet{
+		skey: sk,
+		pkey: sk.PublicKey().Bytes(),
+	}
+}
+
+// newBLSWalletFromSecret derives BLS key from the secret.
+func newBLSWalletFromSecret(secret []byte) (module.BaseWallet, error) {
+	sk, err := bls12381.NewSecretKeyFromIKM(secret)
+	if err != nil {
+		return nil, err
+	}
+	return NewBLSWallet(sk), nil
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wallet
+
+import (
+	"crypto/ed25519"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/module"
+)
+
+const DSAEd25519 = "eddsa/ed25519"
+
+var ed25519SeedDomain = []byte("goloop-ed25519-seed")
+
+type ed25519Wallet struct {
+	skey ed25519.PrivateKey
+}
+
+func (w *ed25519Wallet) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(w.skey, data), nil
+}
+
+func (w *ed25519Wallet) PublicKey() []byte {
+	return w.skey.Public().(ed25519.PublicKey)
+}
+
+func NewEd25519Wallet(sk ed25519.PrivateKey) module.BaseWallet {
+	return &ed25519Wallet{skey: sk}
+}
+
+// newEd25519WalletFromSecret derives ed25519 key from the secret.
+func newEd25519WalletFromSecret(secret []byte) module.BaseWallet {
+	seed := crypto.SHA3Sum256(append(append([]byte{}, ed25519SeedDomain...), secret...))
+	return NewEd25519Wallet(ed25519.NewKeyFromSeed(seed))
+}
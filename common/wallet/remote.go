@@ -0,0 +1,562 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// Remote signer protocol. Refer doc/remote_signer.md for the details.
+
+const (
+	remoteProtocolVersion = 1
+	remoteMaxFrameSize    = 1024 * 1024
+	remoteNonceSize       = 32
+	remoteDefaultTimeout  = 5 * time.Second
+
+	remoteDomainClient = "goloop-remote-signer/client"
+	remoteDomainServer = "goloop-remote-signer/server"
+	remoteKeyC2S       = "goloop-remote-signer/c2s"
+	remoteKeyS2C       = "goloop-remote-signer/s2c"
+
+	RemoteMethodPublicKey = "publicKey"
+	RemoteMethodSign      = "sign"
+)
+
+const (
+	SignKindProposal = "proposal"
+	SignKindVote     = "vote"
+	SignKindNTSD     = "ntsd"
+	SignKindPeerAuth = "peerAuth"
+)
+
+// PeerAuthMessageSize is the size of the message signed for authentication
+// of peers. It's shorter than any encoded consensus message, so a signature
+// for authentication can't be used as one for consensus.
+const PeerAuthMessageSize = 32
+
+// SignContext describes the message to be signed. For consensus messages,
+// Step is the vote type for votes, and Value identifies the content (block
+// ID for votes and block part set hash for proposals). For network type
+// section decisions, Step is the network type ID and Value is the hash of
+// the section. Message is the encoded message, and the data to be signed is
+// SHA3-256 of it (or Keccak-256 for the decisions of some network types).
+type SignContext struct {
+	Kind    string          `json:"kind"`
+	Height  int64           `json:"height"`
+	Round   int32           `json:"round"`
+	Step    int             `json:"step"`
+	Value   common.HexBytes `json:"value"`
+	Message common.HexBytes `json:"message"`
+}
+
+// SignContextParser decodes the message of the kind, and returns the
+// context of it.
+type SignContextParser func(kind string, msg []byte) (*SignContext, error)
+
+// ContextSigner is implemented by wallets which need to know what is signed,
+// for example, to refuse conflicting signatures for the same height and round.
+type ContextSigner interface {
+	SignWithContext(data []byte, ctx *SignContext) ([]byte, error)
+}
+
+type remoteHello struct {
+	Version   int             `json:"version"`
+	PublicKey common.HexBytes `json:"publicKey"`
+	Ephemeral common.HexBytes `json:"ephemeral"`
+	Nonce     common.HexBytes `json:"nonce"`
+}
+
+type remoteAuth struct {
+	Signature common.HexBytes `json:"signature"`
+}
+
+type remoteRequest struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type remoteSignParams struct {
+	Data    common.HexBytes `json:"data"`
+	Context *SignContext    `json:"context,omitempty"`
+}
+
+type remoteError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type remoteResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *remoteError    `json:"error,omitempty"`
+}
+
+func writeFrame(w io.Writer, b []byte) error {
+	if len(b) > remoteMaxFrameSize {
+		return errors.IllegalArgumentError.Errorf("TooLargeFrame(size=%d)", len(b))
+	}
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(len(b)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	sz := binary.BigEndian.Uint32(hdr[:])
+	if sz > remoteMaxFrameSize {
+		return nil, errors.IllegalArgumentError.Errorf("TooLargeFrame(size=%d)", sz)
+	}
+	b := make([]byte, sz)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// remoteConn is an authenticated and encrypted connection between
+// the remote wallet and the remote signer.
+type remoteConn struct {
+	conn    net.Conn
+	peer    *crypto.PublicKey
+	sealer  cipher.AEAD
+	opener  cipher.AEAD
+	sendSeq uint64
+	recvSeq uint64
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
+
+func seqNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+func (c *remoteConn) send(v interface{}) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sealed := c.sealer.Seal(nil, seqNonce(c.sealer, c.sendSeq), bs, nil)
+	c.sendSeq += 1
+	return writeFrame(c.conn, sealed)
+}
+
+func (c *remoteConn) receive(v interface{}) error {
+	sealed, err := readFrame(c.conn)
+	if err != nil {
+		return err
+	}
+	bs, err := c.opener.Open(nil, seqNonce(c.opener, c.recvSeq), sealed, nil)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "InvalidFrame")
+	}
+	c.recvSeq += 1
+	return json.Unmarshal(bs, v)
+}
+
+func (c *remoteConn) Close() error {
+	return c.conn.Close()
+}
+
+func sendJSONFrame(conn net.Conn, v interface{}) ([]byte, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bs, writeFrame(conn, bs)
+}
+
+func receiveJSONFrame(conn net.Conn, v interface{}) ([]byte, error) {
+	bs, err := readFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	return bs, json.Unmarshal(bs, v)
+}
+
+func newRemoteHello(w module.BaseWallet) (*remoteHello, *secp256k1.PrivateKey, error) {
+	ek, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, remoteNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return &remoteHello{
+		Version:   remoteProtocolVersion,
+		PublicKey: w.PublicKey(),
+		Ephemeral: ek.PubKey().SerializeCompressed(),
+		Nonce:     nonce,
+	}, ek, nil
+}
+
+func verifyRemoteAuth(hello *remoteHello, auth *remoteAuth, hash []byte) (*crypto.PublicKey, error) {
+	pk, err := crypto.ParsePublicKey(hello.PublicKey)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidPublicKey")
+	}
+	sig, err := crypto.ParseSignature(auth.Signature)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidSignature")
+	}
+	if !sig.Verify(hash, pk) {
+		return nil, errors.IllegalArgumentError.New("AuthenticationFailure")
+	}
+	return pk, nil
+}
+
+// remoteHandshake authenticates both ends with their identity keys and
+// derives session keys from ephemeral ECDH keys. Client sends its hello
+// first, then the server replies with its hello. Both ends sign the
+// transcript of hellos with their own domain and exchange the signatures.
+// accept is called with the public key of the peer after it's verified.
+func remoteHandshake(
+	conn net.Conn, client bool, w module.BaseWallet,
+	accept func(pk *crypto.PublicKey) error,
+) (*remoteConn, error) {
+	mine, ek, err := newRemoteHello(w)
+	if err != nil {
+		return nil, err
+	}
+	var peer remoteHello
+	var mineBytes, peerBytes []byte
+	if client {
+		if mineBytes, err = sendJSONFrame(conn, mine); err != nil {
+			return nil, err
+		}
+		if peerBytes, err = receiveJSONFrame(conn, &peer); err != nil {
+			return nil, err
+		}
+	} else {
+		if peerBytes, err = receiveJSONFrame(conn, &peer); err != nil {
+			return nil, err
+		}
+		if mineBytes, err = sendJSONFrame(conn, mine); err != nil {
+			return nil, err
+		}
+	}
+	if peer.Version != remoteProtocolVersion {
+		return nil, errors.UnsupportedError.Errorf(
+			"UnsupportedVersion(version=%d)", peer.Version)
+	}
+	if len(peer.Nonce) != remoteNonceSize {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidNonce(len=%d)", len(peer.Nonce))
+	}
+	pek, err := secp256k1.ParsePubKey(peer.Ephemeral)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidEphemeralKey")
+	}
+
+	var transcript []byte
+	if client {
+		transcript = crypto.SHA3Sum256(append(append([]byte{}, mineBytes...), peerBytes...))
+	} else {
+		transcript = crypto.SHA3Sum256(append(append([]byte{}, peerBytes...), mineBytes...))
+	}
+	authHash := func(domain string) []byte {
+		return crypto.SHA3Sum256(append([]byte(domain), transcript...))
+	}
+	myDomain, peerDomain := remoteDomainServer, remoteDomainClient
+	if client {
+		myDomain, peerDomain = peerDomain, myDomain
+	}
+
+	sendAuth := func() error {
+		sig, err := w.Sign(authHash(myDomain))
+		if err != nil {
+			return err
+		}
+		_, err = sendJSONFrame(conn, &remoteAuth{Signature: sig})
+		return err
+	}
+
+	// the server signs only after the client is authenticated.
+	if client {
+		if err := sendAuth(); err != nil {
+			return nil, err
+		}
+	}
+	var peerAuth remoteAuth
+	if _, err = receiveJSONFrame(conn, &peerAuth); err != nil {
+		return nil, err
+	}
+	pk, err := verifyRemoteAuth(&peer, &peerAuth, authHash(peerDomain))
+	if err != nil {
+		return nil, err
+	}
+	if err := accept(pk); err != nil {
+		return nil, err
+	}
+	if !client {
+		if err := sendAuth(); err != nil {
+			return nil, err
+		}
+	}
+
+	secret := secp256k1.GenerateSharedSecret(ek, pek)
+	sessionKey := func(domain string) []byte {
+		return crypto.SHA3Sum256(bytes.Join([][]byte{
+			[]byte(domain), secret, transcript,
+		}, nil))
+	}
+	c2s, err := newAEAD(sessionKey(remoteKeyC2S))
+	if err != nil {
+		return nil, err
+	}
+	s2c, err := newAEAD(sessionKey(remoteKeyS2C))
+	if err != nil {
+		return nil, err
+	}
+	rc := &remoteConn{conn: conn, peer: pk}
+	if client {
+		rc.sealer, rc.opener = c2s, s2c
+	} else {
+		rc.sealer, rc.opener = s2c, c2s
+	}
+	return rc, nil
+}
+
+// parseRemoteAddress parses the address of the remote signer. It accepts
+// "unix://<path>", "tcp://<host>:<port>" and "<host>:<port>".
+func parseRemoteAddress(addr string) (string, string) {
+	if strings.HasPrefix(addr, "unix://") {
+		return "unix", strings.TrimPrefix(addr, "unix://")
+	}
+	if strings.HasPrefix(addr, "tcp://") {
+		return "tcp", strings.TrimPrefix(addr, "tcp://")
+	}
+	return "tcp", addr
+}
+
+// ListenRemote opens a listener for the remote signer on the address.
+// Refer parseRemoteAddress for the format of the address.
+func ListenRemote(addr string) (net.Listener, error) {
+	network, address := parseRemoteAddress(addr)
+	return net.Listen(network, address)
+}
+
+type RemoteConfig struct {
+	// Address of the remote signer
+	Address string
+	// AuthKey is used to authenticate the wallet to the remote signer.
+	AuthKey *crypto.PrivateKey
+	// Signer is the expected address of the key in the remote signer.
+	Signer module.Address
+	// Timeout for connection and requests.
+	Timeout time.Duration
+}
+
+type remoteWallet struct {
+	address string
+	auth    module.Wallet
+	timeout time.Duration
+
+	mtx    sync.Mutex
+	conn   *remoteConn
+	lastID int64
+
+	pubKey *crypto.PublicKey
+	addr   module.Address
+}
+
+func (w *remoteWallet) Address() module.Address {
+	return w.addr
+}
+
+func (w *remoteWallet) PublicKey() []byte {
+	return w.pubKey.SerializeCompressed()
+}
+
+func (w *remoteWallet) Sign(data []byte) ([]byte, error) {
+	return w.SignWithContext(data, nil)
+}
+
+func (w *remoteWallet) SignWithContext(data []byte, ctx *SignContext) ([]byte, error) {
+	var sigBytes common.HexBytes
+	err := w.call(RemoteMethodSign, &remoteSignParams{
+		Data:    data,
+		Context: ctx,
+	}, &sigBytes)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.ParseSignature(sigBytes)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidSignature")
+	}
+	if !sig.Verify(data, w.pubKey) {
+		return nil, errors.IllegalArgumentError.New("SignatureMismatch")
+	}
+	return sigBytes, nil
+}
+
+func (w *remoteWallet) connect() (*remoteConn, error) {
+	network, address := parseRemoteAddress(w.address)
+	conn, err := net.DialTimeout(network, address, w.timeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(w.timeout))
+	rc, err := remoteHandshake(conn, true, w.auth, func(pk *crypto.PublicKey) error {
+		if w.pubKey != nil && !w.pubKey.Equal(pk) {
+			return errors.IllegalArgumentError.Errorf(
+				"UnexpectedSigner(key=%s)", pk.String())
+		}
+		return nil
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return rc, nil
+}
+
+// doCall sends the request over the connection. It returns true with the
+// error if the error comes from the transport.
+func (w *remoteWallet) doCall(conn *remoteConn, method string, params, result interface{}) (bool, error) {
+	var req remoteRequest
+	w.lastID += 1
+	req.ID = w.lastID
+	req.Method = method
+	if params != nil {
+		bs, err := json.Marshal(params)
+		if err != nil {
+			return false, err
+		}
+		req.Params = bs
+	}
+	_ = conn.conn.SetDeadline(time.Now().Add(w.timeout))
+	defer conn.conn.SetDeadline(time.Time{})
+	if err := conn.send(&req); err != nil {
+		return true, err
+	}
+	var res remoteResponse
+	if err := conn.receive(&res); err != nil {
+		return true, err
+	}
+	if res.ID != req.ID {
+		return true, errors.InvalidStateError.Errorf(
+			"InvalidResponseID(exp=%d,real=%d)", req.ID, res.ID)
+	}
+	if res.Error != nil {
+		return false, errors.Code(res.Error.Code).New(res.Error.Message)
+	}
+	return false, json.Unmarshal(res.Result, result)
+}
+
+// call sends the request to the remote signer. Transport failures make it
+// reconnect and retry once, but errors returned by the signer are not retried.
+func (w *remoteWallet) call(method string, params, result interface{}) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	var err error
+	for retry := 0; retry < 2; retry++ {
+		if w.conn == nil {
+			if w.conn, err = w.connect(); err != nil {
+				continue
+			}
+		}
+		var transport bool
+		if transport, err = w.doCall(w.conn, method, params, result); !transport {
+			return err
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+// OpenRemote returns a wallet signing data with the key managed by the
+// remote signer.
+func OpenRemote(cfg *RemoteConfig) (module.Wallet, error) {
+	if cfg.AuthKey == nil {
+		return nil, errors.IllegalArgumentError.New("NoAuthKey")
+	}
+	if cfg.Signer == nil {
+		return nil, errors.IllegalArgumentError.New("NoSignerAddress")
+	}
+	auth, err := NewFromPrivateKey(cfg.AuthKey)
+	if err != nil {
+		return nil, err
+	}
+	w := &remoteWallet{
+		address: cfg.Address,
+		auth:    auth,
+		timeout: cfg.Timeout,
+	}
+	if w.timeout <= 0 {
+		w.timeout = remoteDefaultTimeout
+	}
+	var pkBytes common.HexBytes
+	if err := w.call(RemoteMethodPublicKey, nil, &pkBytes); err != nil {
+		return nil, err
+	}
+	pk, err := crypto.ParsePublicKey(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !w.conn.peer.Equal(pk) {
+		w.conn.Close()
+		return nil, errors.IllegalArgumentError.New("InconsistentSignerKey")
+	}
+	addr := common.NewAccountAddressFromPublicKey(pk)
+	if !addr.Equal(cfg.Signer) {
+		w.conn.Close()
+		return nil, errors.IllegalArgumentError.Errorf(
+			"UnexpectedSigner(exp=%s,real=%s)", cfg.Signer, addr)
+	}
+	w.pubKey = pk
+	w.addr = addr
+	return w, nil
+}
@@ -0,0 +1,269 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wallet
+
+import (
+	"encoding/json"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+type testSigner struct {
+	*RemoteSigner
+	address string
+}
+
+// testMessage is the message for tests encoded in JSON. Timestamp makes
+// different messages with the same context.
+type testMessage struct {
+	SignContext
+	Timestamp int64 `json:"timestamp"`
+}
+
+func parseTestMessage(kind string, msg []byte) (*SignContext, error) {
+	var m testMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	if m.Kind != kind {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidKind(%s)", m.Kind)
+	}
+	return &m.SignContext, nil
+}
+
+// newTestContext returns the context with the message, and the data to be
+// signed for it.
+func newTestContext(kind string, h int64, r int32, step int, value string, ts int64) ([]byte, *SignContext) {
+	ctx := SignContext{
+		Kind:   kind,
+		Height: h,
+		Round:  r,
+		Step:   step,
+		Value:  []byte(value),
+	}
+	msg, _ := json.Marshal(&testMessage{ctx, ts})
+	ctx.Message = msg
+	return crypto.SHA3Sum256(msg), &ctx
+}
+
+func startTestSigner(t *testing.T, w module.Wallet, clients []*crypto.PublicKey, state string) *testSigner {
+	s, err := NewRemoteSigner(w, &RemoteSignerConfig{
+		Clients:   clients,
+		StatePath: state,
+		Parser:    parseTestMessage,
+	}, log.GlobalLogger())
+	assert.NoError(t, err)
+	addr := "unix://" + path.Join(t.TempDir(), "signer.sock")
+	l, err := ListenRemote(addr)
+	assert.NoError(t, err)
+	go s.Serve(l)
+	t.Cleanup(func() {
+		s.Close()
+	})
+	return &testSigner{s, addr}
+}
+
+func newTestKeyStoreWallet(t *testing.T) module.Wallet {
+	ks, err := KeyStoreFromWallet(New(), []byte("test"))
+	assert.NoError(t, err)
+	w, err := NewFromKeyStore(ks, []byte("test"))
+	assert.NoError(t, err)
+	return w
+}
+
+func TestRemote_Sign(t *testing.T) {
+	w := newTestKeyStoreWallet(t)
+	authKey, authPub := crypto.GenerateKeyPair()
+	s := startTestSigner(t, w, []*crypto.PublicKey{authPub}, "")
+
+	rw, err := OpenRemote(&RemoteConfig{
+		Address: s.address,
+		AuthKey: authKey,
+		Signer:  w.Address(),
+	})
+	assert.NoError(t, err)
+	assert.True(t, rw.Address().Equal(w.Address()))
+	assert.Equal(t, w.PublicKey(), rw.PublicKey())
+
+	data, ctx := newTestContext(SignKindVote, 1, 0, 0, "block", 0)
+	sigBytes, err := rw.(ContextSigner).SignWithContext(data, ctx)
+	assert.NoError(t, err)
+	sig, err := crypto.ParseSignature(sigBytes)
+	assert.NoError(t, err)
+	pk, err := crypto.ParsePublicKey(w.PublicKey())
+	assert.NoError(t, err)
+	assert.True(t, sig.Verify(data, pk))
+
+	_, err = rw.(ContextSigner).SignWithContext([]byte("short"), ctx)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+}
+
+func TestRemote_Authentication(t *testing.T) {
+	w := newTestKeyStoreWallet(t)
+	_, authPub := crypto.GenerateKeyPair()
+	s := startTestSigner(t, w, []*crypto.PublicKey{authPub}, "")
+
+	otherKey, _ := crypto.GenerateKeyPair()
+	_, err := OpenRemote(&RemoteConfig{
+		Address: s.address,
+		AuthKey: otherKey,
+		Signer:  w.Address(),
+	})
+	assert.Error(t, err)
+
+	authKey, authPub2 := crypto.GenerateKeyPair()
+	s2 := startTestSigner(t, w, []*crypto.PublicKey{authPub2}, "")
+	_, err = OpenRemote(&RemoteConfig{
+		Address: s2.address,
+		AuthKey: authKey,
+		Signer:  New().Address(),
+	})
+	assert.Error(t, err)
+}
+
+func TestRemote_CheckContext(t *testing.T) {
+	w := newTestKeyStoreWallet(t)
+	authKey, authPub := crypto.GenerateKeyPair()
+	s := startTestSigner(t, w, []*crypto.PublicKey{authPub}, "")
+
+	rw, err := OpenRemote(&RemoteConfig{
+		Address: s.address,
+		AuthKey: authKey,
+		Signer:  w.Address(),
+	})
+	assert.NoError(t, err)
+	cs := rw.(ContextSigner)
+
+	// data without context is refused
+	data, ctx := newTestContext(SignKindVote, 10, 0, 0, "block1", 0)
+	_, err = rw.Sign(data)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	_, err = cs.SignWithContext(data, ctx)
+	assert.NoError(t, err)
+
+	// data should be the hash of the message
+	data2, _ := newTestContext(SignKindVote, 10, 0, 0, "block2", 0)
+	_, err = cs.SignWithContext(data2, ctx)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	// context should match the message
+	_, ctx2 := newTestContext(SignKindVote, 10, 0, 0, "block2", 0)
+	ctx2.Value = []byte("block1")
+	_, err = cs.SignWithContext(data2, ctx2)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	_, ctx2 = newTestContext(SignKindVote, 10, 0, 0, "block2", 0)
+	ctx2.Height = 11
+	_, err = cs.SignWithContext(data2, ctx2)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	// message should be of the kind
+	_, ctx2 = newTestContext(SignKindVote, 10, 0, 0, "block2", 0)
+	ctx2.Kind = SignKindProposal
+	_, err = cs.SignWithContext(data2, ctx2)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	// refused requests are not recorded by the guard
+	data3, ctx3 := newTestContext(SignKindVote, 10, 0, 0, "block1", 1)
+	_, err = cs.SignWithContext(data3, ctx3)
+	assert.NoError(t, err)
+
+	// authentication of peers signs 32 bytes of message without guard
+	msg := crypto.SHA3Sum256([]byte("secret"))
+	actx := &SignContext{Kind: SignKindPeerAuth, Message: msg}
+	_, err = cs.SignWithContext(crypto.SHA3Sum256(msg), actx)
+	assert.NoError(t, err)
+	_, err = cs.SignWithContext(crypto.SHA3Sum256(msg), actx)
+	assert.NoError(t, err)
+
+	// a consensus message can't be signed for authentication
+	actx = &SignContext{Kind: SignKindPeerAuth, Message: ctx.Message}
+	_, err = cs.SignWithContext(data, actx)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	// decisions of network type sections may use Keccak-256
+	_, nctx := newTestContext(SignKindNTSD, 10, 0, 1, "nts1", 0)
+	h := sha3.NewLegacyKeccak256()
+	h.Write(nctx.Message)
+	_, err = cs.SignWithContext(h.Sum(nil), nctx)
+	assert.NoError(t, err)
+	_, nctx2 := newTestContext(SignKindNTSD, 10, 0, 1, "nts2", 0)
+	_, err = cs.SignWithContext(crypto.SHA3Sum256(nctx2.Message), nctx2)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+}
+
+func TestRemote_DoubleSignGuard(t *testing.T) {
+	w := newTestKeyStoreWallet(t)
+	authKey, authPub := crypto.GenerateKeyPair()
+	state := path.Join(t.TempDir(), "state.json")
+	s := startTestSigner(t, w, []*crypto.PublicKey{authPub}, state)
+
+	rw, err := OpenRemote(&RemoteConfig{
+		Address: s.address,
+		AuthKey: authKey,
+		Signer:  w.Address(),
+	})
+	assert.NoError(t, err)
+	cs := rw.(ContextSigner)
+
+	signVote := func(cs ContextSigner, h int64, r int32, step int, bid string, ts int64) error {
+		data, ctx := newTestContext(SignKindVote, h, r, step, bid, ts)
+		_, err := cs.SignWithContext(data, ctx)
+		return err
+	}
+
+	assert.NoError(t, signVote(cs, 10, 0, 0, "block1", 0))
+
+	// same vote can be signed again with different timestamp
+	assert.NoError(t, signVote(cs, 10, 0, 0, "block1", 1))
+
+	// other step of the same round
+	assert.NoError(t, signVote(cs, 10, 0, 1, "block1", 0))
+
+	err = signVote(cs, 10, 0, 0, "block2", 0)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+
+	err = signVote(cs, 9, 3, 0, "block2", 0)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+
+	assert.NoError(t, signVote(cs, 10, 1, 0, "block2", 0))
+
+	// the connection is still usable after refusal
+	assert.NoError(t, signVote(cs, 10, 1, 0, "block2", 1))
+
+	// the state is kept over restart of the signer
+	s.Close()
+	s2 := startTestSigner(t, w, []*crypto.PublicKey{authPub}, state)
+	rw2, err := OpenRemote(&RemoteConfig{
+		Address: s2.address,
+		AuthKey: authKey,
+		Signer:  w.Address(),
+	})
+	assert.NoError(t, err)
+	err = signVote(rw2.(ContextSigner), 10, 1, 0, "block3", 0)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+	assert.NoError(t, signVote(rw2.(ContextSigner), 10, 1, 0, "block2", 0))
+}
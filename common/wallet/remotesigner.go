@@ -0,0 +1,366 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+type signRecord struct {
+	Height int64           `json:"height"`
+	Round  int32           `json:"round"`
+	Value  common.HexBytes `json:"value"`
+}
+
+// signGuard keeps the last signed message for each kind and step of
+// consensus messages. It refuses to sign a different value for the same
+// height and round, and to sign for a height and round before the last one.
+type signGuard struct {
+	lock sync.Mutex
+	path string
+	last map[string]*signRecord
+}
+
+func signGuardKey(ctx *SignContext) string {
+	return fmt.Sprintf("%s/%d", ctx.Kind, ctx.Step)
+}
+
+func (g *signGuard) check(ctx *SignContext) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	key := signGuardKey(ctx)
+	if rec, ok := g.last[key]; ok {
+		if ctx.Height < rec.Height || (ctx.Height == rec.Height && ctx.Round < rec.Round) {
+			return errors.InvalidStateError.Errorf(
+				"HeightRoundRegression(kind=%s,last=%d/%d,req=%d/%d)",
+				key, rec.Height, rec.Round, ctx.Height, ctx.Round)
+		}
+		if ctx.Height == rec.Height && ctx.Round == rec.Round {
+			if !bytes.Equal(ctx.Value, rec.Value) {
+				return errors.InvalidStateError.Errorf(
+					"DoubleSign(kind=%s,height=%d,round=%d,signed=%s,req=%s)",
+					key, ctx.Height, ctx.Round, rec.Value, ctx.Value)
+			}
+			return nil
+		}
+	}
+	g.last[key] = &signRecord{
+		Height: ctx.Height,
+		Round:  ctx.Round,
+		Value:  ctx.Value,
+	}
+	return g.save()
+}
+
+func (g *signGuard) save() error {
+	if g.path == "" {
+		return nil
+	}
+	bs, err := json.Marshal(g.last)
+	if err != nil {
+		return err
+	}
+	tmp := g.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bs, 0600); err != nil {
+		return errors.CriticalIOError.Wrap(err, "FailToWriteSignState")
+	}
+	if err := os.Rename(tmp, g.path); err != nil {
+		return errors.CriticalIOError.Wrap(err, "FailToWriteSignState")
+	}
+	return nil
+}
+
+func newSignGuard(path string) (*signGuard, error) {
+	g := &signGuard{
+		path: path,
+		last: make(map[string]*signRecord),
+	}
+	if path == "" {
+		return g, nil
+	}
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return g, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &g.last); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidSignState")
+	}
+	return g, nil
+}
+
+type RemoteSignerConfig struct {
+	// Clients are public keys of allowed clients.
+	Clients []*crypto.PublicKey
+	// StatePath is the file for the state of the double sign guard. If it's
+	// empty, the state is kept only in memory.
+	StatePath string
+	// Parser decodes messages to check contexts of requests.
+	Parser SignContextParser
+}
+
+// RemoteSigner serves signing requests of remote wallets with its wallet.
+type RemoteSigner struct {
+	wallet  module.Wallet
+	clients []*crypto.PublicKey
+	guard   *signGuard
+	parser  SignContextParser
+	timeout time.Duration
+	log     log.Logger
+
+	lock      sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewRemoteSigner returns a new signer for the wallet. Only the clients
+// whose public keys are in the configuration are allowed.
+func NewRemoteSigner(w module.Wallet, cfg *RemoteSignerConfig, l log.Logger) (*RemoteSigner, error) {
+	if len(cfg.Clients) == 0 {
+		return nil, errors.IllegalArgumentError.New("NoClientKeys")
+	}
+	if cfg.Parser == nil {
+		return nil, errors.IllegalArgumentError.New("NoSignContextParser")
+	}
+	guard, err := newSignGuard(cfg.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{
+		wallet:    w,
+		clients:   cfg.Clients,
+		guard:     guard,
+		parser:    cfg.Parser,
+		timeout:   remoteDefaultTimeout,
+		log:       l,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}, nil
+}
+
+func (s *RemoteSigner) Address() module.Address {
+	return s.wallet.Address()
+}
+
+func (s *RemoteSigner) isAllowed(pk *crypto.PublicKey) error {
+	for _, c := range s.clients {
+		if c.Equal(pk) {
+			return nil
+		}
+	}
+	return errors.IllegalArgumentError.Errorf("UnknownClient(key=%s)", pk)
+}
+
+func (s *RemoteSigner) track(conn net.Conn, add bool) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if add {
+		if s.closed {
+			return false
+		}
+		s.conns[conn] = struct{}{}
+	} else {
+		delete(s.conns, conn)
+	}
+	return true
+}
+
+// Serve accepts connections from the listener and handles them until the
+// listener is closed.
+func (s *RemoteSigner) Serve(l net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return errors.ErrInvalidState
+	}
+	s.listeners[l] = struct{}{}
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.listeners, l)
+		s.lock.Unlock()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.lock.Lock()
+			closed := s.closed
+			s.lock.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		if !s.track(conn, true) {
+			conn.Close()
+			return nil
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *RemoteSigner) handleConn(conn net.Conn) {
+	defer func() {
+		s.track(conn, false)
+		conn.Close()
+	}()
+
+	_ = conn.SetDeadline(time.Now().Add(s.timeout))
+	rc, err := remoteHandshake(conn, false, s.wallet, s.isAllowed)
+	if err != nil {
+		s.log.Warnf("Fail to authenticate client remote=%s err=%v",
+			conn.RemoteAddr(), err)
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	s.log.Infof("Client connected remote=%s key=%s", conn.RemoteAddr(), rc.peer)
+
+	for {
+		var req remoteRequest
+		if err := rc.receive(&req); err != nil {
+			s.log.Debugf("Client disconnected remote=%s err=%v",
+				conn.RemoteAddr(), err)
+			return
+		}
+		res := &remoteResponse{ID: req.ID}
+		result, err := s.handleRequest(&req)
+		if err == nil {
+			res.Result, err = json.Marshal(result)
+		}
+		if err != nil {
+			res.Error = &remoteError{
+				Code:    int(errors.CodeOf(err)),
+				Message: err.Error(),
+			}
+		}
+		if err := rc.send(res); err != nil {
+			s.log.Warnf("Fail to send response remote=%s err=%v",
+				conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// digestOf returns whether the data is the digest of the message of
+// the context.
+func digestOf(ctx *SignContext, data []byte) bool {
+	if bytes.Equal(crypto.SHA3Sum256(ctx.Message), data) {
+		return true
+	}
+	if ctx.Kind == SignKindNTSD {
+		h := sha3.NewLegacyKeccak256()
+		h.Write(ctx.Message)
+		return bytes.Equal(h.Sum(nil), data)
+	}
+	return false
+}
+
+// checkContext checks that the context describes the data, then checks the
+// context with the double sign guard. The context is decoded from the
+// message of it, whose hash should be the data. Requests without context
+// are refused.
+func (s *RemoteSigner) checkContext(data []byte, ctx *SignContext) error {
+	if ctx == nil {
+		return errors.IllegalArgumentError.New("NoSignContext")
+	}
+	if !digestOf(ctx, data) {
+		return errors.IllegalArgumentError.New("MessageDigestMismatch")
+	}
+	if ctx.Kind == SignKindPeerAuth {
+		// it's not protected by the guard, but the message can't be
+		// the one for other kinds.
+		if len(ctx.Message) != PeerAuthMessageSize {
+			return errors.IllegalArgumentError.Errorf(
+				"InvalidPeerAuthMessage(len=%d)", len(ctx.Message))
+		}
+		return nil
+	}
+	mctx, err := s.parser(ctx.Kind, ctx.Message)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrapf(err,
+			"InvalidMessage(kind=%s)", ctx.Kind)
+	}
+	if mctx.Kind != ctx.Kind || mctx.Height != ctx.Height ||
+		mctx.Round != ctx.Round || mctx.Step != ctx.Step ||
+		!bytes.Equal(mctx.Value, ctx.Value) {
+		return errors.IllegalArgumentError.Errorf(
+			"ContextMismatch(kind=%s,height=%d,round=%d,step=%d,value=%s)",
+			mctx.Kind, mctx.Height, mctx.Round, mctx.Step, mctx.Value)
+	}
+	return s.guard.check(mctx)
+}
+
+func (s *RemoteSigner) handleRequest(req *remoteRequest) (interface{}, error) {
+	switch req.Method {
+	case RemoteMethodPublicKey:
+		return common.HexBytes(s.wallet.PublicKey()), nil
+	case RemoteMethodSign:
+		var params remoteSignParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+		}
+		if len(params.Data) != 32 {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"InvalidDataLength(len=%d)", len(params.Data))
+		}
+		if err := s.checkContext(params.Data, params.Context); err != nil {
+			s.log.Warnf("Refuse to sign err=%v", err)
+			return nil, err
+		}
+		sig, err := s.wallet.Sign(params.Data)
+		if err != nil {
+			return nil, err
+		}
+		return common.HexBytes(sig), nil
+	default:
+		return nil, errors.UnsupportedError.Errorf("UnknownMethod(%s)", req.Method)
+	}
+}
+
+// Close closes all listeners and connections.
+func (s *RemoteSigner) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"sync"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/module"
@@ -9,6 +11,10 @@ import (
 type softwareWallet struct {
 	skey *crypto.PrivateKey
 	pkey *crypto.PublicKey
+
+	bls    module.BaseWallet
+	edOnce sync.Once
+	ed     module.BaseWallet
 }
 
 func (w *softwareWallet) Address() module.Address {
@@ -27,18 +33,41 @@ func (w *softwareWallet) PublicKey() []byte {
 	return w.pkey.SerializeCompressed()
 }
 
+// WalletFor returns the wallet for the DSA. Keys for DSAs other than
+// secp256k1 are derived from the secret key of the wallet. BLS key is derived
+// on creation of the wallet to report the failure of the derivation.
+func (w *softwareWallet) WalletFor(dsa string) module.BaseWallet {
+	switch dsa {
+	case DSASecp256k1:
+		return w
+	case DSABLS12381:
+		return w.bls
+	case DSAEd25519:
+		w.edOnce.Do(func() {
+			w.ed = newEd25519WalletFromSecret(w.skey.Bytes())
+		})
+		return w.ed
+	}
+	return nil
+}
+
 func New() module.Wallet {
-	sk, pk := crypto.GenerateKeyPair()
-	return &softwareWallet{
-		skey: sk,
-		pkey: pk,
+	sk, _ := crypto.GenerateKeyPair()
+	w, err := NewFromPrivateKey(sk)
+	if err != nil {
+		panic(err)
 	}
+	return w
 }
 
 func NewFromPrivateKey(sk *crypto.PrivateKey) (module.Wallet, error) {
-	pk := sk.PublicKey()
+	bls, err := newBLSWalletFromSecret(sk.Bytes())
+	if err != nil {
+		return nil, err
+	}
 	return &softwareWallet{
 		skey: sk,
-		pkey: pk,
+		pkey: sk.PublicKey(),
+		bls:  bls,
 	}, nil
 }
@@ -0,0 +1,465 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+// instr is a decoded instruction. Immediates and the positions of the
+// related instructions are resolved on decoding, so that the interpreter
+// doesn't need to scan the code.
+type instr struct {
+	op  uint16
+	imm uint64
+
+	// else (or end if there is no else) of if
+	target uint32
+	// end of block, if and else
+	end uint32
+
+	// arity of block, loop and if
+	params  uint16
+	results uint16
+}
+
+type ctrlFrame struct {
+	op          uint16
+	pc          int
+	height      int
+	params      int
+	results     int
+	hasElse     bool
+	unreachable bool
+}
+
+func (c *ctrlFrame) labelArity() int {
+	if c.op == opLoop {
+		return c.params
+	}
+	return c.results
+}
+
+// compiler decodes the body of a function. It validates the structure of
+// the control instructions, the indices and the height of the operand
+// stack. Types of the operands are not validated as the interpreter
+// handles all values as 64 bits integers.
+type compiler struct {
+	m      *Module
+	f      *function
+	r      *reader
+	code   []instr
+	ctrls  []*ctrlFrame
+	height int
+	locals int
+}
+
+func (m *Module) compile(f *function, r *reader) ([]instr, error) {
+	ft := m.Types[f.typ]
+	c := &compiler{
+		m:      m,
+		f:      f,
+		r:      r,
+		locals: len(ft.Params) + len(f.locals),
+	}
+	c.ctrls = append(c.ctrls, &ctrlFrame{
+		op:      opBlock,
+		results: len(ft.Results),
+	})
+	for len(c.ctrls) > 0 {
+		if err := c.next(); err != nil {
+			return nil, err
+		}
+	}
+	if !r.eof() {
+		return nil, invalidModule("CodeAfterEnd")
+	}
+	return c.code, nil
+}
+
+func (c *compiler) top() *ctrlFrame {
+	return c.ctrls[len(c.ctrls)-1]
+}
+
+func (c *compiler) pop(n int) error {
+	frame := c.top()
+	if c.height-n < frame.height {
+		if !frame.unreachable {
+			return invalidModule("StackUnderflow(pc=%d)", len(c.code))
+		}
+		c.height = frame.height
+		return nil
+	}
+	c.height -= n
+	return nil
+}
+
+func (c *compiler) push(n int) {
+	c.height += n
+}
+
+// apply applies the effect of an instruction popping pops operands and
+// pushing pushes results.
+func (c *compiler) apply(pops, pushes int) error {
+	if err := c.pop(pops); err != nil {
+		return err
+	}
+	c.push(pushes)
+	return nil
+}
+
+func (c *compiler) setUnreachable() {
+	frame := c.top()
+	frame.unreachable = true
+	c.height = frame.height
+}
+
+func (c *compiler) label(depth uint32) (*ctrlFrame, error) {
+	if depth >= uint32(len(c.ctrls)) {
+		return nil, invalidModule("BranchDepth(depth=%d)", depth)
+	}
+	return c.ctrls[len(c.ctrls)-1-int(depth)], nil
+}
+
+func (c *compiler) blockType() (int, int, error) {
+	if c.r.eof() {
+		return 0, 0, invalidModule("UnexpectedEnd")
+	}
+	switch b := c.r.buf[c.r.pos]; b {
+	case blockEmpty:
+		c.r.pos++
+		return 0, 0, nil
+	case byte(I32), byte(I64):
+		c.r.pos++
+		return 0, 1, nil
+	default:
+		idx, err := c.r.sleb(33)
+		if err != nil {
+			return 0, 0, err
+		}
+		if idx < 0 || idx >= int64(len(c.m.Types)) {
+			return 0, 0, unsupported("UnsupportedBlockType(0x%02x)", b)
+		}
+		t := c.m.Types[idx]
+		return len(t.Params), len(t.Results), nil
+	}
+}
+
+func (c *compiler) memArg() (uint64, error) {
+	if c.m.Memory == nil {
+		return 0, invalidModule("NoMemory")
+	}
+	if _, err := c.r.u32(); err != nil {
+		return 0, err
+	}
+	offset, err := c.r.u32()
+	return uint64(offset), err
+}
+
+func (c *compiler) zeroByte() error {
+	if b, err := c.r.byte(); err != nil {
+		return err
+	} else if b != 0 {
+		return invalidModule("NonZeroReserved")
+	}
+	return nil
+}
+
+func (c *compiler) next() error {
+	b, err := c.r.byte()
+	if err != nil {
+		return err
+	}
+	pc := len(c.code)
+	in := instr{op: uint16(b)}
+	switch b {
+	case opUnreachable:
+		c.setUnreachable()
+	case opNop:
+	case opBlock, opLoop, opIf:
+		params, results, err := c.blockType()
+		if err != nil {
+			return err
+		}
+		if b == opIf {
+			if err := c.pop(1); err != nil {
+				return err
+			}
+		}
+		if err := c.pop(params); err != nil {
+			return err
+		}
+		c.ctrls = append(c.ctrls, &ctrlFrame{
+			op:      uint16(b),
+			pc:      pc,
+			height:  c.height,
+			params:  params,
+			results: results,
+		})
+		c.push(params)
+		in.params = uint16(params)
+		in.results = uint16(results)
+	case opElse:
+		frame := c.top()
+		if frame.op != opIf || frame.hasElse {
+			return invalidModule("UnexpectedElse(pc=%d)", pc)
+		}
+		if !frame.unreachable && c.height != frame.height+frame.results {
+			return invalidModule("StackHeight(pc=%d)", pc)
+		}
+		frame.hasElse = true
+		frame.unreachable = false
+		c.height = frame.height + frame.params
+		c.code[frame.pc].target = uint32(pc)
+	case opEnd:
+		frame := c.top()
+		if !frame.unreachable && c.height != frame.height+frame.results {
+			return invalidModule("StackHeight(pc=%d)", pc)
+		}
+		if frame.op == opIf && !frame.hasElse {
+			if frame.params != frame.results {
+				return invalidModule("IfWithoutElse(pc=%d)", pc)
+			}
+			c.code[frame.pc].target = uint32(pc)
+		}
+		if len(c.ctrls) > 1 {
+			c.code[frame.pc].end = uint32(pc)
+			if frame.hasElse {
+				c.code[c.code[frame.pc].target].end = uint32(pc)
+			}
+		}
+		c.ctrls = c.ctrls[:len(c.ctrls)-1]
+		c.height = frame.height + frame.results
+	case opBr, opBrIf:
+		depth, err := c.r.u32()
+		if err != nil {
+			return err
+		}
+		frame, err := c.label(depth)
+		if err != nil {
+			return err
+		}
+		if b == opBrIf {
+			if err := c.pop(1); err != nil {
+				return err
+			}
+		}
+		arity := frame.labelArity()
+		if err := c.pop(arity); err != nil {
+			return err
+		}
+		if b == opBr {
+			c.setUnreachable()
+		} else {
+			c.push(arity)
+		}
+		in.imm = uint64(depth)
+	case opBrTable:
+		n, err := c.r.count()
+		if err != nil {
+			return err
+		}
+		depths := make([]uint32, n+1)
+		arity := -1
+		for i := range depths {
+			if depths[i], err = c.r.u32(); err != nil {
+				return err
+			}
+			frame, err := c.label(depths[i])
+			if err != nil {
+				return err
+			}
+			if arity < 0 {
+				arity = frame.labelArity()
+			} else if arity != frame.labelArity() {
+				return invalidModule("BranchArity(pc=%d)", pc)
+			}
+		}
+		if err := c.pop(1 + arity); err != nil {
+			return err
+		}
+		c.setUnreachable()
+		in.imm = uint64(len(c.f.tables))
+		c.f.tables = append(c.f.tables, depths)
+	case opReturn:
+		if err := c.pop(c.ctrls[0].results); err != nil {
+			return err
+		}
+		c.setUnreachable()
+	case opCall:
+		idx, err := c.r.u32()
+		if err != nil {
+			return err
+		}
+		ft := c.m.FuncTypeOf(idx)
+		if ft == nil {
+			return invalidModule("FuncIndex(idx=%d)", idx)
+		}
+		if err := c.apply(len(ft.Params), len(ft.Results)); err != nil {
+			return err
+		}
+		in.imm = uint64(idx)
+	case opCallIndirect:
+		idx, err := c.r.u32()
+		if err != nil {
+			return err
+		}
+		if idx >= uint32(len(c.m.Types)) {
+			return invalidModule("TypeIndex(idx=%d)", idx)
+		}
+		if c.m.Table == nil {
+			return invalidModule("NoTable")
+		}
+		if err := c.zeroByte(); err != nil {
+			return err
+		}
+		ft := c.m.Types[idx]
+		if err := c.apply(1+len(ft.Params), len(ft.Results)); err != nil {
+			return err
+		}
+		in.imm = uint64(idx)
+	case opDrop:
+		err = c.pop(1)
+	case opSelect:
+		err = c.apply(3, 1)
+	case opSelectTyped:
+		if types, err := c.r.valueTypes(); err != nil {
+			return err
+		} else if len(types) != 1 {
+			return invalidModule("SelectTypes(pc=%d)", pc)
+		}
+		in.op = opSelect
+		err = c.apply(3, 1)
+	case opLocalGet, opLocalSet, opLocalTee:
+		idx, err := c.r.u32()
+		if err != nil {
+			return err
+		}
+		if idx >= uint32(c.locals) {
+			return invalidModule("LocalIndex(idx=%d)", idx)
+		}
+		switch b {
+		case opLocalGet:
+			c.push(1)
+		case opLocalSet:
+			err = c.pop(1)
+		case opLocalTee:
+			err = c.apply(1, 1)
+		}
+		if err != nil {
+			return err
+		}
+		in.imm = uint64(idx)
+	case opGlobalGet, opGlobalSet:
+		idx, err := c.r.u32()
+		if err != nil {
+			return err
+		}
+		if idx >= uint32(len(c.m.Globals)) {
+			return invalidModule("GlobalIndex(idx=%d)", idx)
+		}
+		if b == opGlobalGet {
+			c.push(1)
+		} else {
+			if !c.m.Globals[idx].Mutable {
+				return invalidModule("ImmutableGlobal(idx=%d)", idx)
+			}
+			if err := c.pop(1); err != nil {
+				return err
+			}
+		}
+		in.imm = uint64(idx)
+	case opI32Load, opI64Load, opI32Load8S, opI32Load8U, opI32Load16S,
+		opI32Load16U, opI64Load8S, opI64Load8U, opI64Load16S, opI64Load16U,
+		opI64Load32S, opI64Load32U:
+		if in.imm, err = c.memArg(); err != nil {
+			return err
+		}
+		err = c.apply(1, 1)
+	case opI32Store, opI64Store, opI32Store8, opI32Store16, opI64Store8,
+		opI64Store16, opI64Store32:
+		if in.imm, err = c.memArg(); err != nil {
+			return err
+		}
+		err = c.pop(2)
+	case opMemorySize, opMemoryGrow:
+		if c.m.Memory == nil {
+			return invalidModule("NoMemory")
+		}
+		if err := c.zeroByte(); err != nil {
+			return err
+		}
+		if b == opMemorySize {
+			c.push(1)
+		} else {
+			err = c.apply(1, 1)
+		}
+	case opI32Const:
+		v, err := c.r.s32()
+		if err != nil {
+			return err
+		}
+		in.imm = uint64(uint32(v))
+		c.push(1)
+	case opI64Const:
+		v, err := c.r.s64()
+		if err != nil {
+			return err
+		}
+		in.imm = uint64(v)
+		c.push(1)
+	case opI32Eqz, opI64Eqz, opI32Clz, opI32Ctz, opI32Popcnt, opI64Clz,
+		opI64Ctz, opI64Popcnt, opI32WrapI64, opI64ExtendI32S,
+		opI64ExtendI32U, opI32Extend8S, opI32Extend16S, opI64Extend8S,
+		opI64Extend16S, opI64Extend32S:
+		err = c.apply(1, 1)
+	case opPrefixMisc:
+		sub, err := c.r.u32()
+		if err != nil {
+			return err
+		}
+		in.op = uint16(0x100 + sub)
+		switch in.op {
+		case opMemoryCopy:
+			if err := c.zeroByte(); err != nil {
+				return err
+			}
+			fallthrough
+		case opMemoryFill:
+			if c.m.Memory == nil {
+				return invalidModule("NoMemory")
+			}
+			if err := c.zeroByte(); err != nil {
+				return err
+			}
+			if err := c.pop(3); err != nil {
+				return err
+			}
+		default:
+			return unsupported("UnsupportedInstruction(op=0xfc%02x)", sub)
+		}
+	default:
+		if (b >= opI32Eq && b <= opI64GeU && b != opI64Eqz) ||
+			(b >= opI32Add && b <= opI32Rotr) ||
+			(b >= opI64Add && b <= opI64Rotr) {
+			err = c.apply(2, 1)
+		} else {
+			return unsupported("UnsupportedInstruction(op=0x%02x)", b)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	c.code = append(c.code, in)
+	return nil
+}
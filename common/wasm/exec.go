@@ -0,0 +1,594 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+type label struct {
+	cont   uint32
+	height int
+	arity  int
+	loop   bool
+}
+
+func (inst *Instance) push(v uint64) {
+	if len(inst.stack) >= inst.config.MaxStack {
+		panic("StackOverflow")
+	}
+	inst.stack = append(inst.stack, v)
+}
+
+func (inst *Instance) pop() uint64 {
+	n := len(inst.stack) - 1
+	v := inst.stack[n]
+	inst.stack = inst.stack[:n]
+	return v
+}
+
+func (inst *Instance) top() *uint64 {
+	return &inst.stack[len(inst.stack)-1]
+}
+
+func (inst *Instance) invoke(idx uint32) error {
+	m := inst.module
+	ft := m.FuncTypeOf(idx)
+	np := len(ft.Params)
+	base := len(inst.stack) - np
+
+	if idx < uint32(len(inst.hosts)) {
+		args := make([]uint64, np)
+		copy(args, inst.stack[base:])
+		inst.stack = inst.stack[:base]
+		results, err := inst.hosts[idx].Call(inst, args)
+		if err != nil {
+			return err
+		}
+		if len(results) != len(ft.Results) {
+			return trap("InvalidHostResults(name=%s)", m.Imports[idx].Name)
+		}
+		for _, v := range results {
+			inst.push(v)
+		}
+		return nil
+	}
+
+	if inst.depth >= inst.config.MaxCallDepth {
+		return trap("CallStackExhausted")
+	}
+	inst.depth++
+	defer func() {
+		inst.depth--
+	}()
+
+	f := m.funcs[idx-uint32(len(inst.hosts))]
+	locals := make([]uint64, np+len(f.locals))
+	copy(locals, inst.stack[base:])
+	inst.stack = inst.stack[:base]
+	return inst.execute(f, locals, len(ft.Results))
+}
+
+func (inst *Instance) branch(labels []label, depth int) ([]label, uint32) {
+	l := labels[len(labels)-1-depth]
+	n := len(inst.stack)
+	copy(inst.stack[l.height:], inst.stack[n-l.arity:n])
+	inst.stack = inst.stack[:l.height+l.arity]
+	if l.loop {
+		return labels[:len(labels)-depth], l.cont
+	}
+	return labels[:len(labels)-1-depth], l.cont
+}
+
+func (inst *Instance) address(offset uint64, size uint64) (uint64, error) {
+	addr := uint64(uint32(inst.pop())) + offset
+	if err := inst.checkMemory(addr, size); err != nil {
+		return 0, err
+	}
+	return addr, nil
+}
+
+func (inst *Instance) load(offset uint64, size uint64) (uint64, error) {
+	addr, err := inst.address(offset, size)
+	if err != nil {
+		return 0, err
+	}
+	mem := inst.memory[addr:]
+	switch size {
+	case 1:
+		return uint64(mem[0]), nil
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(mem)), nil
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(mem)), nil
+	default:
+		return binary.LittleEndian.Uint64(mem), nil
+	}
+}
+
+func (inst *Instance) store(offset uint64, size uint64) error {
+	v := inst.pop()
+	addr, err := inst.address(offset, size)
+	if err != nil {
+		return err
+	}
+	mem := inst.memory[addr:]
+	switch size {
+	case 1:
+		mem[0] = byte(v)
+	case 2:
+		binary.LittleEndian.PutUint16(mem, uint16(v))
+	case 4:
+		binary.LittleEndian.PutUint32(mem, uint32(v))
+	default:
+		binary.LittleEndian.PutUint64(mem, v)
+	}
+	return nil
+}
+
+func (inst *Instance) grow(n uint32) (uint32, error) {
+	pages := uint32(len(inst.memory) / PageSize)
+	if uint64(pages)+uint64(n) > uint64(inst.maxPages) {
+		return math.MaxUint32, nil
+	}
+	if err := inst.consume(uint64(n) * StepsPerPage); err != nil {
+		return 0, err
+	}
+	inst.memory = append(inst.memory, make([]byte, int(n)*PageSize)...)
+	return pages, nil
+}
+
+func b2i(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func i32(v uint64) uint64 {
+	return uint64(uint32(v))
+}
+
+func (inst *Instance) execute(f *function, locals []uint64, results int) error {
+	code := f.code
+	labels := []label{{
+		cont:   uint32(len(code)),
+		height: len(inst.stack),
+		arity:  results,
+	}}
+	for pc := uint32(0); pc < uint32(len(code)); pc++ {
+		if err := inst.consume(1); err != nil {
+			return err
+		}
+		in := &code[pc]
+		switch in.op {
+		case opUnreachable:
+			return trap("Unreachable")
+		case opNop:
+		case opBlock:
+			labels = append(labels, label{
+				cont:   in.end + 1,
+				height: len(inst.stack) - int(in.params),
+				arity:  int(in.results),
+			})
+		case opLoop:
+			labels = append(labels, label{
+				cont:   pc + 1,
+				height: len(inst.stack) - int(in.params),
+				arity:  int(in.params),
+				loop:   true,
+			})
+		case opIf:
+			cond := inst.pop()
+			l := label{
+				cont:   in.end + 1,
+				height: len(inst.stack) - int(in.params),
+				arity:  int(in.results),
+			}
+			if cond != 0 {
+				labels = append(labels, l)
+			} else if in.target != in.end {
+				labels = append(labels, l)
+				pc = in.target
+			} else {
+				pc = in.end
+			}
+		case opElse:
+			labels = labels[:len(labels)-1]
+			pc = in.end
+		case opEnd:
+			labels = labels[:len(labels)-1]
+		case opBr:
+			labels, pc = inst.branch(labels, int(in.imm))
+			pc--
+		case opBrIf:
+			if inst.pop() != 0 {
+				labels, pc = inst.branch(labels, int(in.imm))
+				pc--
+			}
+		case opBrTable:
+			depths := f.tables[in.imm]
+			i := uint32(inst.pop())
+			if i >= uint32(len(depths)) {
+				i = uint32(len(depths) - 1)
+			}
+			labels, pc = inst.branch(labels, int(depths[i]))
+			pc--
+		case opReturn:
+			labels, pc = inst.branch(labels, len(labels)-1)
+			pc--
+		case opCall:
+			if err := inst.invoke(uint32(in.imm)); err != nil {
+				return err
+			}
+		case opCallIndirect:
+			i := uint32(inst.pop())
+			if i >= uint32(len(inst.table)) {
+				return trap("UndefinedElement(idx=%d)", i)
+			}
+			idx := inst.table[i]
+			if idx < 0 {
+				return trap("UninitializedElement(idx=%d)", i)
+			}
+			ft := inst.module.FuncTypeOf(uint32(idx))
+			if !ft.Equal(inst.module.Types[in.imm]) {
+				return trap("IndirectCallTypeMismatch(idx=%d)", i)
+			}
+			if err := inst.invoke(uint32(idx)); err != nil {
+				return err
+			}
+		case opDrop:
+			inst.pop()
+		case opSelect:
+			cond := inst.pop()
+			v2 := inst.pop()
+			if cond == 0 {
+				*inst.top() = v2
+			}
+		case opLocalGet:
+			inst.push(locals[in.imm])
+		case opLocalSet:
+			locals[in.imm] = inst.pop()
+		case opLocalTee:
+			locals[in.imm] = *inst.top()
+		case opGlobalGet:
+			inst.push(inst.globals[in.imm])
+		case opGlobalSet:
+			inst.globals[in.imm] = inst.pop()
+
+		case opI32Load, opI64Load32U:
+			v, err := inst.load(in.imm, 4)
+			if err != nil {
+				return err
+			}
+			inst.push(v)
+		case opI64Load:
+			v, err := inst.load(in.imm, 8)
+			if err != nil {
+				return err
+			}
+			inst.push(v)
+		case opI32Load8S, opI32Load8U, opI64Load8S, opI64Load8U:
+			v, err := inst.load(in.imm, 1)
+			if err != nil {
+				return err
+			}
+			switch in.op {
+			case opI32Load8S:
+				v = i32(uint64(int8(v)))
+			case opI64Load8S:
+				v = uint64(int8(v))
+			}
+			inst.push(v)
+		case opI32Load16S, opI32Load16U, opI64Load16S, opI64Load16U:
+			v, err := inst.load(in.imm, 2)
+			if err != nil {
+				return err
+			}
+			switch in.op {
+			case opI32Load16S:
+				v = i32(uint64(int16(v)))
+			case opI64Load16S:
+				v = uint64(int16(v))
+			}
+			inst.push(v)
+		case opI64Load32S:
+			v, err := inst.load(in.imm, 4)
+			if err != nil {
+				return err
+			}
+			inst.push(uint64(int32(v)))
+		case opI32Store, opI64Store32:
+			if err := inst.store(in.imm, 4); err != nil {
+				return err
+			}
+		case opI64Store:
+			if err := inst.store(in.imm, 8); err != nil {
+				return err
+			}
+		case opI32Store8, opI64Store8:
+			if err := inst.store(in.imm, 1); err != nil {
+				return err
+			}
+		case opI32Store16, opI64Store16:
+			if err := inst.store(in.imm, 2); err != nil {
+				return err
+			}
+		case opMemorySize:
+			inst.push(uint64(len(inst.memory) / PageSize))
+		case opMemoryGrow:
+			v, err := inst.grow(uint32(inst.pop()))
+			if err != nil {
+				return err
+			}
+			inst.push(uint64(v))
+		case opMemoryCopy:
+			n := uint64(uint32(inst.pop()))
+			src := uint64(uint32(inst.pop()))
+			dst := uint64(uint32(inst.pop()))
+			if err := inst.checkMemory(src, n); err != nil {
+				return err
+			}
+			if err := inst.checkMemory(dst, n); err != nil {
+				return err
+			}
+			if err := inst.consume(n / BytesPerStep); err != nil {
+				return err
+			}
+			copy(inst.memory[dst:dst+n], inst.memory[src:src+n])
+		case opMemoryFill:
+			n := uint64(uint32(inst.pop()))
+			v := byte(inst.pop())
+			dst := uint64(uint32(inst.pop()))
+			if err := inst.checkMemory(dst, n); err != nil {
+				return err
+			}
+			if err := inst.consume(n / BytesPerStep); err != nil {
+				return err
+			}
+			mem := inst.memory[dst : dst+n]
+			for i := range mem {
+				mem[i] = v
+			}
+
+		case opI32Const, opI64Const:
+			inst.push(in.imm)
+
+		default:
+			if err := inst.numeric(in.op); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (inst *Instance) numeric(op uint16) error {
+	switch op {
+	case opI32Eqz:
+		x := inst.top()
+		*x = b2i(uint32(*x) == 0)
+	case opI64Eqz:
+		x := inst.top()
+		*x = b2i(*x == 0)
+	case opI32Clz:
+		x := inst.top()
+		*x = uint64(bits.LeadingZeros32(uint32(*x)))
+	case opI32Ctz:
+		x := inst.top()
+		*x = uint64(bits.TrailingZeros32(uint32(*x)))
+	case opI32Popcnt:
+		x := inst.top()
+		*x = uint64(bits.OnesCount32(uint32(*x)))
+	case opI64Clz:
+		x := inst.top()
+		*x = uint64(bits.LeadingZeros64(*x))
+	case opI64Ctz:
+		x := inst.top()
+		*x = uint64(bits.TrailingZeros64(*x))
+	case opI64Popcnt:
+		x := inst.top()
+		*x = uint64(bits.OnesCount64(*x))
+	case opI32WrapI64:
+		x := inst.top()
+		*x = i32(*x)
+	case opI64ExtendI32S:
+		x := inst.top()
+		*x = uint64(int32(*x))
+	case opI64ExtendI32U:
+		x := inst.top()
+		*x = i32(*x)
+	case opI32Extend8S:
+		x := inst.top()
+		*x = i32(uint64(int8(*x)))
+	case opI32Extend16S:
+		x := inst.top()
+		*x = i32(uint64(int16(*x)))
+	case opI64Extend8S:
+		x := inst.top()
+		*x = uint64(int8(*x))
+	case opI64Extend16S:
+		x := inst.top()
+		*x = uint64(int16(*x))
+	case opI64Extend32S:
+		x := inst.top()
+		*x = uint64(int32(*x))
+	default:
+		b := inst.pop()
+		a := inst.top()
+		if op >= opI64Eqz && op <= opI64GeU || op >= opI64Clz && op <= opI64Rotr {
+			v, err := binary64(op, *a, b)
+			if err != nil {
+				return err
+			}
+			*a = v
+		} else {
+			v, err := binary32(op, uint32(*a), uint32(b))
+			if err != nil {
+				return err
+			}
+			*a = uint64(v)
+		}
+	}
+	return nil
+}
+
+func binary32(op uint16, a, b uint32) (uint32, error) {
+	switch op {
+	case opI32Eq:
+		return uint32(b2i(a == b)), nil
+	case opI32Ne:
+		return uint32(b2i(a != b)), nil
+	case opI32LtS:
+		return uint32(b2i(int32(a) < int32(b))), nil
+	case opI32LtU:
+		return uint32(b2i(a < b)), nil
+	case opI32GtS:
+		return uint32(b2i(int32(a) > int32(b))), nil
+	case opI32GtU:
+		return uint32(b2i(a > b)), nil
+	case opI32LeS:
+		return uint32(b2i(int32(a) <= int32(b))), nil
+	case opI32LeU:
+		return uint32(b2i(a <= b)), nil
+	case opI32GeS:
+		return uint32(b2i(int32(a) >= int32(b))), nil
+	case opI32GeU:
+		return uint32(b2i(a >= b)), nil
+	case opI32Add:
+		return a + b, nil
+	case opI32Sub:
+		return a - b, nil
+	case opI32Mul:
+		return a * b, nil
+	case opI32DivS:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			return 0, trap("IntegerOverflow")
+		}
+		return uint32(int32(a) / int32(b)), nil
+	case opI32DivU:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		return a / b, nil
+	case opI32RemS:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		return uint32(int32(a) % int32(b)), nil
+	case opI32RemU:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		return a % b, nil
+	case opI32And:
+		return a & b, nil
+	case opI32Or:
+		return a | b, nil
+	case opI32Xor:
+		return a ^ b, nil
+	case opI32Shl:
+		return a << (b & 31), nil
+	case opI32ShrS:
+		return uint32(int32(a) >> (b & 31)), nil
+	case opI32ShrU:
+		return a >> (b & 31), nil
+	case opI32Rotl:
+		return bits.RotateLeft32(a, int(b&31)), nil
+	case opI32Rotr:
+		return bits.RotateLeft32(a, -int(b&31)), nil
+	default:
+		return 0, trap("UnknownInstruction(op=0x%02x)", op)
+	}
+}
+
+func binary64(op uint16, a, b uint64) (uint64, error) {
+	switch op {
+	case opI64Eq:
+		return b2i(a == b), nil
+	case opI64Ne:
+		return b2i(a != b), nil
+	case opI64LtS:
+		return b2i(int64(a) < int64(b)), nil
+	case opI64LtU:
+		return b2i(a < b), nil
+	case opI64GtS:
+		return b2i(int64(a) > int64(b)), nil
+	case opI64GtU:
+		return b2i(a > b), nil
+	case opI64LeS:
+		return b2i(int64(a) <= int64(b)), nil
+	case opI64LeU:
+		return b2i(a <= b), nil
+	case opI64GeS:
+		return b2i(int64(a) >= int64(b)), nil
+	case opI64GeU:
+		return b2i(a >= b), nil
+	case opI64Add:
+		return a + b, nil
+	case opI64Sub:
+		return a - b, nil
+	case opI64Mul:
+		return a * b, nil
+	case opI64DivS:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			return 0, trap("IntegerOverflow")
+		}
+		return uint64(int64(a) / int64(b)), nil
+	case opI64DivU:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		return a / b, nil
+	case opI64RemS:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		return uint64(int64(a) % int64(b)), nil
+	case opI64RemU:
+		if b == 0 {
+			return 0, trap("IntegerDivideByZero")
+		}
+		return a % b, nil
+	case opI64And:
+		return a & b, nil
+	case opI64Or:
+		return a | b, nil
+	case opI64Xor:
+		return a ^ b, nil
+	case opI64Shl:
+		return a << (b & 63), nil
+	case opI64ShrS:
+		return uint64(int64(a) >> (b & 63)), nil
+	case opI64ShrU:
+		return a >> (b & 63), nil
+	case opI64Rotl:
+		return bits.RotateLeft64(a, int(b&63)), nil
+	case opI64Rotr:
+		return bits.RotateLeft64(a, -int(b&63)), nil
+	default:
+		return 0, trap("UnknownInstruction(op=0x%02x)", op)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	// StepsPerPage is the steps for allocating a page of the memory.
+	StepsPerPage = 64
+	// BytesPerStep is the bytes handled for a step by bulk memory
+	// instructions.
+	BytesPerStep = 64
+
+	maxTableSize = 65536
+)
+
+var ErrOutOfSteps = errors.NewBase(errors.ExecutionFailError, "OutOfSteps")
+
+func trap(f string, args ...interface{}) error {
+	return errors.ExecutionFailError.Errorf("Trap("+f+")", args...)
+}
+
+// HostFunc is a function provided by the host for an import of the
+// module. Call receives the arguments, and it returns the results for the
+// types of the function.
+type HostFunc struct {
+	Type *FuncType
+	Call func(inst *Instance, args []uint64) ([]uint64, error)
+}
+
+// Imports has host functions by the module name and the field name.
+type Imports map[string]map[string]*HostFunc
+
+type Config struct {
+	MaxPages     uint32
+	MaxCallDepth int
+	MaxStack     int
+}
+
+var DefaultConfig = Config{
+	MaxPages:     256,
+	MaxCallDepth: 256,
+	MaxStack:     64 * 1024,
+}
+
+// Instance is an instantiated module. It's not safe for concurrent use.
+type Instance struct {
+	module   *Module
+	config   Config
+	hosts    []*HostFunc
+	memory   []byte
+	maxPages uint32
+	globals  []uint64
+	table    []int64
+	stack    []uint64
+	depth    int
+
+	steps uint64
+	limit uint64
+}
+
+// Instantiate creates an instance of the module. Steps for initialization
+// and the start function are limited by limit.
+func Instantiate(m *Module, imports Imports, config *Config, limit uint64) (*Instance, error) {
+	if config == nil {
+		config = &DefaultConfig
+	}
+	inst := &Instance{
+		module: m,
+		config: *config,
+		limit:  limit,
+	}
+	for _, imp := range m.Imports {
+		h, ok := imports[imp.Module][imp.Name]
+		if !ok {
+			return nil, errors.NotFoundError.Errorf(
+				"UnknownImport(name=%s.%s)", imp.Module, imp.Name)
+		}
+		if !h.Type.Equal(m.Types[imp.Type]) {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"ImportTypeMismatch(name=%s.%s)", imp.Module, imp.Name)
+		}
+		inst.hosts = append(inst.hosts, h)
+	}
+	if m.Memory != nil {
+		inst.maxPages = inst.config.MaxPages
+		if m.Memory.HasMax && m.Memory.Max < inst.maxPages {
+			inst.maxPages = m.Memory.Max
+		}
+		if m.Memory.Min > inst.maxPages {
+			return nil, errors.UnsupportedError.Errorf(
+				"TooLargeMemory(pages=%d)", m.Memory.Min)
+		}
+		if err := inst.consume(uint64(m.Memory.Min) * StepsPerPage); err != nil {
+			return nil, err
+		}
+		inst.memory = make([]byte, int(m.Memory.Min)*PageSize)
+	}
+	for _, g := range m.Globals {
+		inst.globals = append(inst.globals, g.Init)
+	}
+	if m.Table != nil {
+		if m.Table.Min > maxTableSize {
+			return nil, errors.UnsupportedError.Errorf(
+				"TooLargeTable(size=%d)", m.Table.Min)
+		}
+		inst.table = make([]int64, m.Table.Min)
+		for i := range inst.table {
+			inst.table[i] = -1
+		}
+	}
+	for _, e := range m.elements {
+		if uint64(e.offset)+uint64(len(e.funcs)) > uint64(len(inst.table)) {
+			return nil, trap("ElementOutOfBounds")
+		}
+		for i, idx := range e.funcs {
+			inst.table[int(e.offset)+i] = int64(idx)
+		}
+	}
+	for _, d := range m.data {
+		if uint64(d.offset)+uint64(len(d.init)) > uint64(len(inst.memory)) {
+			return nil, trap("DataOutOfBounds")
+		}
+		copy(inst.memory[d.offset:], d.init)
+	}
+	if m.Start != nil {
+		if _, err := inst.callFunc(*m.Start, nil); err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+func (inst *Instance) Module() *Module {
+	return inst.module
+}
+
+// Steps returns the steps consumed after the last reset.
+func (inst *Instance) Steps() uint64 {
+	return inst.steps
+}
+
+// ResetSteps resets the consumed steps and sets the new limit.
+func (inst *Instance) ResetSteps(limit uint64) {
+	inst.steps = 0
+	inst.limit = limit
+}
+
+func (inst *Instance) consume(steps uint64) error {
+	if steps > inst.limit-inst.steps {
+		inst.steps = inst.limit
+		return ErrOutOfSteps
+	}
+	inst.steps += steps
+	return nil
+}
+
+// Memory returns the memory of the instance. It's valid until the memory
+// grows.
+func (inst *Instance) Memory() []byte {
+	return inst.memory
+}
+
+func (inst *Instance) checkMemory(ptr, size uint64) error {
+	if ptr+size > uint64(len(inst.memory)) {
+		return trap("MemoryOutOfBounds(ptr=%d,size=%d)", ptr, size)
+	}
+	return nil
+}
+
+// Read returns a copy of the memory at ptr.
+func (inst *Instance) Read(ptr, size uint32) ([]byte, error) {
+	if err := inst.checkMemory(uint64(ptr), uint64(size)); err != nil {
+		return nil, err
+	}
+	bs := make([]byte, size)
+	copy(bs, inst.memory[ptr:])
+	return bs, nil
+}
+
+// Write writes bs to the memory at ptr.
+func (inst *Instance) Write(ptr uint32, bs []byte) error {
+	if err := inst.checkMemory(uint64(ptr), uint64(len(bs))); err != nil {
+		return err
+	}
+	copy(inst.memory[ptr:], bs)
+	return nil
+}
+
+// Call calls the exported function with the arguments.
+func (inst *Instance) Call(name string, args ...uint64) ([]uint64, error) {
+	idx, ok := inst.module.ExportedFunc(name)
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoExportedFunc(name=%s)", name)
+	}
+	if len(args) != len(inst.module.FuncTypeOf(idx).Params) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidArguments(name=%s,args=%d)", name, len(args))
+	}
+	return inst.callFunc(idx, args)
+}
+
+func (inst *Instance) callFunc(idx uint32, args []uint64) (results []uint64, err error) {
+	base, depth := len(inst.stack), inst.depth
+	defer func() {
+		if obj := recover(); obj != nil {
+			results = nil
+			err = trap("Panic(%v)", obj)
+		}
+		inst.stack = inst.stack[:base]
+		inst.depth = depth
+	}()
+	inst.stack = append(inst.stack, args...)
+	if err := inst.invoke(idx); err != nil {
+		return nil, err
+	}
+	results = make([]uint64, len(inst.stack)-base)
+	copy(results, inst.stack[base:])
+	return results, nil
+}
@@ -0,0 +1,738 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package wasm implements an interpreter of WebAssembly modules for
+// deterministic execution. It supports the MVP instruction set except
+// floating point types and instructions, and some instructions of the
+// sign-extension and the bulk memory extension. Every instruction consumes
+// steps, so the execution can be limited.
+package wasm
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+)
+
+type ValueType byte
+
+const (
+	I32 ValueType = 0x7f
+	I64 ValueType = 0x7e
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	magic   = 0x6d736100
+	version = 1
+
+	PageSize = 65536
+)
+
+const (
+	sectionCustom = iota
+	sectionType
+	sectionImport
+	sectionFunction
+	sectionTable
+	sectionMemory
+	sectionGlobal
+	sectionExport
+	sectionStart
+	sectionElement
+	sectionCode
+	sectionData
+	sectionDataCount
+)
+
+const (
+	ExternalFunc   = 0x00
+	ExternalTable  = 0x01
+	ExternalMemory = 0x02
+	ExternalGlobal = 0x03
+)
+
+const (
+	typeFunc    = 0x60
+	typeFuncRef = 0x70
+	blockEmpty  = 0x40
+)
+
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+func (t *FuncType) Equal(t2 *FuncType) bool {
+	if len(t.Params) != len(t2.Params) || len(t.Results) != len(t2.Results) {
+		return false
+	}
+	for i, p := range t.Params {
+		if t2.Params[i] != p {
+			return false
+		}
+	}
+	for i, r := range t.Results {
+		if t2.Results[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+type Import struct {
+	Module string
+	Name   string
+	Type   uint32
+}
+
+type Export struct {
+	Name  string
+	Kind  byte
+	Index uint32
+}
+
+type Limits struct {
+	Min    uint32
+	Max    uint32
+	HasMax bool
+}
+
+type Global struct {
+	Type    ValueType
+	Mutable bool
+	Init    uint64
+}
+
+type element struct {
+	offset uint32
+	funcs  []uint32
+}
+
+type data struct {
+	offset uint32
+	init   []byte
+}
+
+type function struct {
+	typ    uint32
+	locals []ValueType
+	code   []instr
+	tables [][]uint32
+}
+
+type Custom struct {
+	Name string
+	Data []byte
+}
+
+// Module is a decoded WebAssembly module. It's immutable, so it can be
+// shared by instances.
+type Module struct {
+	Types   []*FuncType
+	Imports []*Import
+	Exports []*Export
+	Globals []*Global
+	Memory  *Limits
+	Table   *Limits
+	Start   *uint32
+	Customs []*Custom
+
+	funcs    []*function
+	elements []*element
+	data     []*data
+}
+
+// CustomSection returns the content of the first custom section with the
+// name.
+func (m *Module) CustomSection(name string) ([]byte, bool) {
+	for _, c := range m.Customs {
+		if c.Name == name {
+			return c.Data, true
+		}
+	}
+	return nil, false
+}
+
+// ExportedFunc returns the index of the exported function with the name.
+func (m *Module) ExportedFunc(name string) (uint32, bool) {
+	for _, e := range m.Exports {
+		if e.Kind == ExternalFunc && e.Name == name {
+			return e.Index, true
+		}
+	}
+	return 0, false
+}
+
+// FuncTypeOf returns the type of the function with the index including
+// imported functions.
+func (m *Module) FuncTypeOf(idx uint32) *FuncType {
+	if idx < uint32(len(m.Imports)) {
+		return m.Types[m.Imports[idx].Type]
+	}
+	idx -= uint32(len(m.Imports))
+	if idx < uint32(len(m.funcs)) {
+		return m.Types[m.funcs[idx].typ]
+	}
+	return nil
+}
+
+func (m *Module) funcCount() uint32 {
+	return uint32(len(m.Imports) + len(m.funcs))
+}
+
+func invalidModule(f string, args ...interface{}) error {
+	return errors.IllegalArgumentError.Errorf("InvalidModule("+f+")", args...)
+}
+
+func unsupported(f string, args ...interface{}) error {
+	return errors.UnsupportedError.Errorf(f, args...)
+}
+
+// Decode decodes and validates the module in the binary format.
+func Decode(bs []byte) (*Module, error) {
+	r := &reader{buf: bs}
+	if v, err := r.uint32le(); err != nil || v != magic {
+		return nil, invalidModule("magic")
+	}
+	if v, err := r.uint32le(); err != nil || v != version {
+		return nil, unsupported("UnsupportedVersion")
+	}
+
+	m := new(Module)
+	var funcTypes []uint32
+	last := sectionCustom
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		body, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		if id != sectionCustom {
+			if order(int(id)) <= order(last) {
+				return nil, invalidModule("SectionOrder(id=%d)", id)
+			}
+			last = int(id)
+		}
+		sr := &reader{buf: body}
+		switch id {
+		case sectionCustom:
+			err = m.decodeCustom(sr)
+		case sectionType:
+			err = m.decodeTypes(sr)
+		case sectionImport:
+			err = m.decodeImports(sr)
+		case sectionFunction:
+			funcTypes, err = m.decodeFunctions(sr)
+		case sectionTable:
+			err = m.decodeTable(sr)
+		case sectionMemory:
+			err = m.decodeMemory(sr)
+		case sectionGlobal:
+			err = m.decodeGlobals(sr)
+		case sectionExport:
+			err = m.decodeExports(sr)
+		case sectionStart:
+			err = m.decodeStart(sr)
+		case sectionElement:
+			err = m.decodeElements(sr)
+		case sectionCode:
+			err = m.decodeCode(sr, funcTypes)
+			funcTypes = nil
+		case sectionData:
+			err = m.decodeData(sr)
+		case sectionDataCount:
+			_, err = sr.u32()
+		default:
+			err = invalidModule("UnknownSection(id=%d)", id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !sr.eof() {
+			return nil, invalidModule("SectionSize(id=%d)", id)
+		}
+	}
+	if len(funcTypes) != 0 {
+		return nil, invalidModule("NoCode")
+	}
+	for _, e := range m.Exports {
+		if e.Kind == ExternalFunc && e.Index >= m.funcCount() {
+			return nil, invalidModule("ExportFunc(name=%s,idx=%d)", e.Name, e.Index)
+		}
+	}
+	if m.Start != nil {
+		t := m.FuncTypeOf(*m.Start)
+		if t == nil || len(t.Params) != 0 || len(t.Results) != 0 {
+			return nil, invalidModule("StartFunc")
+		}
+	}
+	for _, e := range m.elements {
+		if m.Table == nil {
+			return nil, invalidModule("NoTable")
+		}
+		for _, idx := range e.funcs {
+			if idx >= m.funcCount() {
+				return nil, invalidModule("ElementFunc(idx=%d)", idx)
+			}
+		}
+	}
+	if len(m.data) > 0 && m.Memory == nil {
+		return nil, invalidModule("NoMemory")
+	}
+	return m, nil
+}
+
+// order returns the order of the section. The data count section is placed
+// before the code section.
+func order(id int) int {
+	switch id {
+	case sectionDataCount:
+		return sectionCode*2 - 1
+	default:
+		return id * 2
+	}
+}
+
+func (m *Module) decodeCustom(r *reader) error {
+	name, err := r.name()
+	if err != nil {
+		return err
+	}
+	m.Customs = append(m.Customs, &Custom{
+		Name: name,
+		Data: r.rest(),
+	})
+	return nil
+}
+
+func (r *reader) valueType() (ValueType, error) {
+	b, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch t := ValueType(b); t {
+	case I32, I64:
+		return t, nil
+	default:
+		return 0, unsupported("UnsupportedValueType(type=0x%02x)", b)
+	}
+}
+
+func (r *reader) valueTypes() ([]ValueType, error) {
+	n, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	var types []ValueType
+	for i := 0; i < n; i++ {
+		t, err := r.valueType()
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+func (m *Module) decodeTypes(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if b, err := r.byte(); err != nil {
+			return err
+		} else if b != typeFunc {
+			return invalidModule("FuncType(0x%02x)", b)
+		}
+		ft := new(FuncType)
+		if ft.Params, err = r.valueTypes(); err != nil {
+			return err
+		}
+		if ft.Results, err = r.valueTypes(); err != nil {
+			return err
+		}
+		m.Types = append(m.Types, ft)
+	}
+	return nil
+}
+
+func (m *Module) typeIndex(r *reader) (uint32, error) {
+	idx, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+	if idx >= uint32(len(m.Types)) {
+		return 0, invalidModule("TypeIndex(idx=%d)", idx)
+	}
+	return idx, nil
+}
+
+func (m *Module) decodeImports(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		imp := new(Import)
+		if imp.Module, err = r.name(); err != nil {
+			return err
+		}
+		if imp.Name, err = r.name(); err != nil {
+			return err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if kind != ExternalFunc {
+			return unsupported("UnsupportedImport(name=%s.%s,kind=%d)",
+				imp.Module, imp.Name, kind)
+		}
+		if imp.Type, err = m.typeIndex(r); err != nil {
+			return err
+		}
+		m.Imports = append(m.Imports, imp)
+	}
+	return nil
+}
+
+func (m *Module) decodeFunctions(r *reader) ([]uint32, error) {
+	n, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	types := make([]uint32, n)
+	for i := range types {
+		if types[i], err = m.typeIndex(r); err != nil {
+			return nil, err
+		}
+	}
+	return types, nil
+}
+
+func (r *reader) limits() (*Limits, error) {
+	flag, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	l := new(Limits)
+	if l.Min, err = r.u32(); err != nil {
+		return nil, err
+	}
+	switch flag {
+	case 0x00:
+	case 0x01:
+		if l.Max, err = r.u32(); err != nil {
+			return nil, err
+		}
+		if l.Max < l.Min {
+			return nil, invalidModule("Limits(min=%d,max=%d)", l.Min, l.Max)
+		}
+		l.HasMax = true
+	default:
+		return nil, unsupported("UnsupportedLimits(flag=0x%02x)", flag)
+	}
+	return l, nil
+}
+
+func (m *Module) decodeTable(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	if n > 1 || m.Table != nil {
+		return unsupported("MultipleTables")
+	}
+	if n == 0 {
+		return nil
+	}
+	if t, err := r.byte(); err != nil {
+		return err
+	} else if t != typeFuncRef {
+		return unsupported("UnsupportedTableType(type=0x%02x)", t)
+	}
+	m.Table, err = r.limits()
+	return err
+}
+
+func (m *Module) decodeMemory(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	if n > 1 || m.Memory != nil {
+		return unsupported("MultipleMemories")
+	}
+	if n == 0 {
+		return nil
+	}
+	m.Memory, err = r.limits()
+	return err
+}
+
+// constExpr decodes a constant expression, which is a single constant
+// instruction followed by end.
+func (r *reader) constExpr(t ValueType) (uint64, error) {
+	op, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	switch {
+	case op == opI32Const && t == I32:
+		i, err := r.s32()
+		if err != nil {
+			return 0, err
+		}
+		v = uint64(uint32(i))
+	case op == opI64Const && t == I64:
+		i, err := r.s64()
+		if err != nil {
+			return 0, err
+		}
+		v = uint64(i)
+	default:
+		return 0, unsupported("UnsupportedConstExpr(op=0x%02x)", op)
+	}
+	if end, err := r.byte(); err != nil {
+		return 0, err
+	} else if end != opEnd {
+		return 0, invalidModule("ConstExprEnd")
+	}
+	return v, nil
+}
+
+func (m *Module) decodeGlobals(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		g := new(Global)
+		if g.Type, err = r.valueType(); err != nil {
+			return err
+		}
+		mut, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if mut > 1 {
+			return invalidModule("GlobalMutability(0x%02x)", mut)
+		}
+		g.Mutable = mut == 1
+		if g.Init, err = r.constExpr(g.Type); err != nil {
+			return err
+		}
+		m.Globals = append(m.Globals, g)
+	}
+	return nil
+}
+
+func (m *Module) decodeExports(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		e := new(Export)
+		if e.Name, err = r.name(); err != nil {
+			return err
+		}
+		if names[e.Name] {
+			return invalidModule("DuplicateExport(name=%s)", e.Name)
+		}
+		names[e.Name] = true
+		if e.Kind, err = r.byte(); err != nil {
+			return err
+		}
+		if e.Index, err = r.u32(); err != nil {
+			return err
+		}
+		var ok bool
+		switch e.Kind {
+		case ExternalFunc:
+			// it's checked after decoding the code section.
+			ok = true
+		case ExternalTable:
+			ok = e.Index == 0 && m.Table != nil
+		case ExternalMemory:
+			ok = e.Index == 0 && m.Memory != nil
+		case ExternalGlobal:
+			ok = e.Index < uint32(len(m.Globals))
+		}
+		if !ok {
+			return invalidModule("Export(name=%s,kind=%d,idx=%d)",
+				e.Name, e.Kind, e.Index)
+		}
+		m.Exports = append(m.Exports, e)
+	}
+	return nil
+}
+
+func (m *Module) decodeStart(r *reader) error {
+	idx, err := r.u32()
+	if err != nil {
+		return err
+	}
+	m.Start = &idx
+	return nil
+}
+
+func (m *Module) decodeElements(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		flag, err := r.u32()
+		if err != nil {
+			return err
+		}
+		if flag != 0 {
+			return unsupported("UnsupportedElement(flag=%d)", flag)
+		}
+		offset, err := r.constExpr(I32)
+		if err != nil {
+			return err
+		}
+		cnt, err := r.count()
+		if err != nil {
+			return err
+		}
+		e := &element{offset: uint32(offset), funcs: make([]uint32, cnt)}
+		for j := range e.funcs {
+			if e.funcs[j], err = r.u32(); err != nil {
+				return err
+			}
+		}
+		m.elements = append(m.elements, e)
+	}
+	return nil
+}
+
+func (m *Module) decodeCode(r *reader, types []uint32) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	if n != len(types) {
+		return invalidModule("CodeCount(funcs=%d,codes=%d)", len(types), n)
+	}
+	for i := 0; i < n; i++ {
+		m.funcs = append(m.funcs, &function{typ: types[i]})
+	}
+	for _, f := range m.funcs {
+		size, err := r.u32()
+		if err != nil {
+			return err
+		}
+		body, err := r.bytes(size)
+		if err != nil {
+			return err
+		}
+		if err := m.decodeBody(f, &reader{buf: body}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxLocals limits the number of locals of a function to prevent
+// allocation of huge memory on invocation.
+const maxLocals = 50000
+
+func (m *Module) decodeBody(f *function, r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	total := len(m.Types[f.typ].Params)
+	for i := 0; i < n; i++ {
+		cnt, err := r.u32()
+		if err != nil {
+			return err
+		}
+		t, err := r.valueType()
+		if err != nil {
+			return err
+		}
+		if uint64(total)+uint64(cnt) > maxLocals {
+			return unsupported("TooManyLocals")
+		}
+		total += int(cnt)
+		for j := uint32(0); j < cnt; j++ {
+			f.locals = append(f.locals, t)
+		}
+	}
+	f.code, err = m.compile(f, r)
+	return err
+}
+
+func (m *Module) decodeData(r *reader) error {
+	n, err := r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		flag, err := r.u32()
+		if err != nil {
+			return err
+		}
+		switch flag {
+		case 0:
+		case 2:
+			if idx, err := r.u32(); err != nil {
+				return err
+			} else if idx != 0 {
+				return invalidModule("DataMemory(idx=%d)", idx)
+			}
+		default:
+			return unsupported("UnsupportedData(flag=%d)", flag)
+		}
+		offset, err := r.constExpr(I32)
+		if err != nil {
+			return err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return err
+		}
+		init, err := r.bytes(size)
+		if err != nil {
+			return err
+		}
+		m.data = append(m.data, &data{offset: uint32(offset), init: init})
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+)
+
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func (r *reader) eof() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *reader) rest() []byte {
+	bs := r.buf[r.pos:]
+	r.pos = len(r.buf)
+	return bs
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, invalidModule("UnexpectedEnd")
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) bytes(n uint32) ([]byte, error) {
+	if uint64(n) > uint64(len(r.buf)-r.pos) {
+		return nil, invalidModule("UnexpectedEnd")
+	}
+	bs := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return bs, nil
+}
+
+func (r *reader) uint32le() (uint32, error) {
+	bs, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(bs), nil
+}
+
+// uleb decodes unsigned LEB128 integer of the bits.
+func (r *reader) uleb(bits uint) (uint64, error) {
+	var v uint64
+	var shift uint
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		if shift+7 > bits && (b&0x7f)>>(bits-shift) != 0 {
+			return 0, invalidModule("IntegerTooLarge")
+		}
+		v |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			return v, nil
+		}
+		if shift >= bits {
+			return 0, invalidModule("IntegerTooLong")
+		}
+	}
+}
+
+// sleb decodes signed LEB128 integer of the bits.
+func (r *reader) sleb(bits uint) (int64, error) {
+	var v int64
+	var shift uint
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		v |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				v |= -1 << shift
+			}
+			// unused bits should be the extension of the sign bit.
+			if shift >= 64 {
+				if b != 0 && b != 0x7f {
+					return 0, invalidModule("IntegerTooLarge")
+				}
+			} else if shift > bits {
+				if v < -(1<<(bits-1)) || v >= 1<<(bits-1) {
+					return 0, invalidModule("IntegerTooLarge")
+				}
+			}
+			return v, nil
+		}
+		if shift >= bits {
+			return 0, invalidModule("IntegerTooLong")
+		}
+	}
+}
+
+func (r *reader) u32() (uint32, error) {
+	v, err := r.uleb(32)
+	return uint32(v), err
+}
+
+func (r *reader) s32() (int32, error) {
+	v, err := r.sleb(32)
+	return int32(v), err
+}
+
+func (r *reader) s64() (int64, error) {
+	return r.sleb(64)
+}
+
+// count decodes the length of a vector. The length can't be larger than
+// the remaining bytes as each element takes at least one byte.
+func (r *reader) count() (int, error) {
+	n, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n) > uint64(len(r.buf)-r.pos) {
+		return 0, invalidModule("VectorLength(%d)", n)
+	}
+	return int(n), nil
+}
+
+func (r *reader) name() (string, error) {
+	n, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	bs, err := r.bytes(n)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(bs) {
+		return "", invalidModule("InvalidName")
+	}
+	return string(bs), nil
+}
@@ -0,0 +1,393 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+func uleb(v uint64) []byte {
+	var bs []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			bs = append(bs, b|0x80)
+		} else {
+			return append(bs, b)
+		}
+	}
+}
+
+// cat concatenates bytes, integers as bytes and byte slices.
+func cat(items ...interface{}) []byte {
+	var bs []byte
+	for _, item := range items {
+		switch v := item.(type) {
+		case byte:
+			bs = append(bs, v)
+		case int:
+			bs = append(bs, byte(v))
+		case ValueType:
+			bs = append(bs, byte(v))
+		case string:
+			bs = append(bs, uleb(uint64(len(v)))...)
+			bs = append(bs, v...)
+		case []byte:
+			bs = append(bs, v...)
+		}
+	}
+	return bs
+}
+
+func vec(items ...[]byte) []byte {
+	bs := uleb(uint64(len(items)))
+	for _, item := range items {
+		bs = append(bs, item...)
+	}
+	return bs
+}
+
+func section(id byte, items ...interface{}) []byte {
+	content := cat(items...)
+	return cat(id, uleb(uint64(len(content))), content)
+}
+
+func funcType(params, results []ValueType) []byte {
+	bs := []byte{typeFunc, byte(len(params))}
+	for _, p := range params {
+		bs = append(bs, byte(p))
+	}
+	bs = append(bs, byte(len(results)))
+	for _, r := range results {
+		bs = append(bs, byte(r))
+	}
+	return bs
+}
+
+func body(locals []byte, code ...interface{}) []byte {
+	content := cat(locals, cat(code...))
+	return cat(uleb(uint64(len(content))), content)
+}
+
+func module(sections ...[]byte) []byte {
+	return cat([]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, cat(toItems(sections)...))
+}
+
+func toItems(bss [][]byte) []interface{} {
+	items := make([]interface{}, len(bss))
+	for i, bs := range bss {
+		items[i] = bs
+	}
+	return items
+}
+
+func instantiate(t *testing.T, bin []byte, imports Imports, limit uint64) *Instance {
+	m, err := Decode(bin)
+	assert.NoError(t, err)
+	inst, err := Instantiate(m, imports, nil, limit)
+	assert.NoError(t, err)
+	return inst
+}
+
+// factorial returns a module exporting "fact" calculating the factorial
+// of the parameter in 9+12*n steps.
+func factorial() []byte {
+	return module(
+		section(sectionType, vec(funcType([]ValueType{I64}, []ValueType{I64}))),
+		section(sectionFunction, vec(uleb(0))),
+		section(sectionExport, vec(cat("fact", ExternalFunc, 0))),
+		section(sectionCode, vec(body(vec(cat(1, I64)),
+			opI64Const, 1, opLocalSet, 1,
+			opBlock, blockEmpty, opLoop, blockEmpty,
+			opLocalGet, 0, opI64Eqz, opBrIf, 1,
+			opLocalGet, 1, opLocalGet, 0, opI64Mul, opLocalSet, 1,
+			opLocalGet, 0, opI64Const, 1, opI64Sub, opLocalSet, 0,
+			opBr, 0,
+			opEnd, opEnd,
+			opLocalGet, 1, opEnd,
+		))),
+	)
+}
+
+func TestInstance_Steps(t *testing.T) {
+	inst := instantiate(t, factorial(), nil, 1000)
+
+	res, err := inst.Call("fact", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{120}, res)
+	assert.EqualValues(t, 69, inst.Steps())
+
+	inst.ResetSteps(68)
+	_, err = inst.Call("fact", 5)
+	assert.True(t, errors.Is(err, ErrOutOfSteps))
+	assert.EqualValues(t, 68, inst.Steps())
+
+	inst.ResetSteps(69)
+	res, err = inst.Call("fact", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{120}, res)
+
+	_, err = inst.Call("fact")
+	assert.Error(t, err)
+	_, err = inst.Call("none")
+	assert.True(t, errors.NotFoundError.Equals(err))
+}
+
+func TestInstance_HostFunc(t *testing.T) {
+	bin := module(
+		section(sectionType, vec(
+			funcType([]ValueType{I32, I32}, []ValueType{I32}),
+			funcType(nil, []ValueType{I32}),
+		)),
+		section(sectionImport, vec(cat("env", "log", ExternalFunc, 0))),
+		section(sectionFunction, vec(uleb(1))),
+		section(sectionMemory, vec(cat(0x00, 1))),
+		section(sectionExport, vec(cat("run", ExternalFunc, 1))),
+		section(sectionCode, vec(body(vec(),
+			opI32Const, 16, opI32Const, 5, opCall, 0, opEnd,
+		))),
+		section(sectionData, vec(cat(0, opI32Const, 16, opEnd, "hello"))),
+	)
+
+	var logged []byte
+	imports := Imports{
+		"env": {
+			"log": &HostFunc{
+				Type: &FuncType{
+					Params:  []ValueType{I32, I32},
+					Results: []ValueType{I32},
+				},
+				Call: func(inst *Instance, args []uint64) ([]uint64, error) {
+					bs, err := inst.Read(uint32(args[0]), uint32(args[1]))
+					if err != nil {
+						return nil, err
+					}
+					logged = bs
+					return []uint64{uint64(len(bs))}, nil
+				},
+			},
+		},
+	}
+	inst := instantiate(t, bin, imports, 2000)
+	res, err := inst.Call("run")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{5}, res)
+	assert.Equal(t, []byte("hello"), logged)
+	assert.EqualValues(t, StepsPerPage+4, inst.Steps())
+
+	m, err := Decode(bin)
+	assert.NoError(t, err)
+	_, err = Instantiate(m, nil, nil, 1000)
+	assert.True(t, errors.NotFoundError.Equals(err))
+
+	_, err = Instantiate(m, Imports{"env": {"log": &HostFunc{
+		Type: &FuncType{},
+	}}}, nil, 1000)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	_, err = Instantiate(m, imports, nil, StepsPerPage-1)
+	assert.True(t, errors.Is(err, ErrOutOfSteps))
+}
+
+func TestInstance_Control(t *testing.T) {
+	i32Func := funcType([]ValueType{I32}, []ValueType{I32})
+	bin := module(
+		section(sectionType, vec(i32Func, funcType(nil, []ValueType{I32}))),
+		section(sectionFunction, vec(uleb(0), uleb(0), uleb(1), uleb(1), uleb(0))),
+		section(sectionTable, vec(cat(typeFuncRef, 0x00, 3))),
+		section(sectionExport, vec(
+			cat("select", ExternalFunc, 0),
+			cat("choose", ExternalFunc, 1),
+			cat("indirect", ExternalFunc, 4),
+		)),
+		section(sectionElement, vec(cat(0, opI32Const, 0, opEnd, vec(uleb(2), uleb(3))))),
+		section(sectionCode, vec(
+			body(vec(),
+				opBlock, blockEmpty, opBlock, blockEmpty, opBlock, blockEmpty,
+				opLocalGet, 0, opBrTable, vec(uleb(0), uleb(1)), 2,
+				opEnd, opI32Const, 10, opReturn,
+				opEnd, opI32Const, 20, opReturn,
+				opEnd, opI32Const, 30, opEnd,
+			),
+			body(vec(),
+				opLocalGet, 0,
+				opIf, I32, opI32Const, 1, opElse, opI32Const, 2, opEnd,
+				opEnd,
+			),
+			body(vec(), opI32Const, 7, opEnd),
+			body(vec(), opI32Const, 9, opEnd),
+			body(vec(), opLocalGet, 0, opCallIndirect, 1, 0, opEnd),
+		)),
+	)
+	inst := instantiate(t, bin, nil, 1000)
+
+	for _, c := range [][2]uint64{{0, 10}, {1, 20}, {2, 30}, {7, 30}} {
+		res, err := inst.Call("select", c[0])
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{c[1]}, res)
+	}
+	for _, c := range [][2]uint64{{0, 2}, {3, 1}} {
+		res, err := inst.Call("choose", c[0])
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{c[1]}, res)
+	}
+	for _, c := range [][2]uint64{{0, 7}, {1, 9}} {
+		res, err := inst.Call("indirect", c[0])
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{c[1]}, res)
+	}
+	for _, idx := range []uint64{2, 3} {
+		_, err := inst.Call("indirect", idx)
+		assert.True(t, errors.ExecutionFailError.Equals(err))
+	}
+}
+
+func TestInstance_Trap(t *testing.T) {
+	i32Func := funcType([]ValueType{I32}, []ValueType{I32})
+	bin := module(
+		section(sectionType, vec(i32Func)),
+		section(sectionFunction, vec(uleb(0), uleb(0), uleb(0))),
+		section(sectionMemory, vec(cat(0x01, 1, 2))),
+		section(sectionExport, vec(
+			cat("div", ExternalFunc, 0),
+			cat("load", ExternalFunc, 1),
+			cat("grow", ExternalFunc, 2),
+		)),
+		section(sectionCode, vec(
+			body(vec(), opI32Const, 60, opLocalGet, 0, opI32DivS, opEnd),
+			body(vec(), opLocalGet, 0, opI32Load, 2, 0, opEnd),
+			body(vec(), opLocalGet, 0, opMemoryGrow, 0, opEnd),
+		)),
+	)
+	inst := instantiate(t, bin, nil, 1<<20)
+
+	res, err := inst.Call("div", 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{15}, res)
+	_, err = inst.Call("div", 0)
+	assert.True(t, errors.ExecutionFailError.Equals(err))
+
+	_, err = inst.Call("load", PageSize-4)
+	assert.NoError(t, err)
+	_, err = inst.Call("load", PageSize-3)
+	assert.True(t, errors.ExecutionFailError.Equals(err))
+
+	res, err = inst.Call("grow", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0xffffffff}, res)
+	res, err = inst.Call("grow", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, res)
+	_, err = inst.Call("load", PageSize-3)
+	assert.NoError(t, err)
+}
+
+func TestInstance_Recursion(t *testing.T) {
+	bin := module(
+		section(sectionType, vec(funcType(nil, nil))),
+		section(sectionFunction, vec(uleb(0))),
+		section(sectionExport, vec(cat("loop", ExternalFunc, 0))),
+		section(sectionCode, vec(body(vec(), opCall, 0, opEnd))),
+	)
+	inst := instantiate(t, bin, nil, 1<<20)
+	_, err := inst.Call("loop")
+	assert.True(t, errors.ExecutionFailError.Equals(err))
+	assert.False(t, errors.Is(err, ErrOutOfSteps))
+	assert.EqualValues(t, DefaultConfig.MaxCallDepth, inst.Steps())
+}
+
+func TestDecode(t *testing.T) {
+	m, err := Decode(module(
+		section(sectionCustom, "icon:api", []byte("[]")),
+	))
+	assert.NoError(t, err)
+	bs, ok := m.CustomSection("icon:api")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("[]"), bs)
+
+	cases := []struct {
+		name string
+		bin  []byte
+		code errors.Code
+	}{
+		{
+			"InvalidMagic",
+			[]byte{0x00, 0x61, 0x73, 0x6e, 0x01, 0x00, 0x00, 0x00},
+			errors.IllegalArgumentError,
+		},
+		{
+			"FloatType",
+			module(section(sectionType, vec(funcType([]ValueType{0x7d}, nil)))),
+			errors.UnsupportedError,
+		},
+		{
+			"FloatInstruction",
+			module(
+				section(sectionType, vec(funcType(nil, nil))),
+				section(sectionFunction, vec(uleb(0))),
+				section(sectionCode, vec(body(vec(), 0x43, 0, 0, 0, 0, opDrop, opEnd))),
+			),
+			errors.UnsupportedError,
+		},
+		{
+			"StackUnderflow",
+			module(
+				section(sectionType, vec(funcType(nil, []ValueType{I32}))),
+				section(sectionFunction, vec(uleb(0))),
+				section(sectionCode, vec(body(vec(), opI32Const, 1, opI32Add, opEnd))),
+			),
+			errors.IllegalArgumentError,
+		},
+		{
+			"BranchDepth",
+			module(
+				section(sectionType, vec(funcType(nil, nil))),
+				section(sectionFunction, vec(uleb(0))),
+				section(sectionCode, vec(body(vec(), opBr, 1, opEnd))),
+			),
+			errors.IllegalArgumentError,
+		},
+		{
+			"NoCode",
+			module(
+				section(sectionType, vec(funcType(nil, nil))),
+				section(sectionFunction, vec(uleb(0))),
+			),
+			errors.IllegalArgumentError,
+		},
+		{
+			"SectionOrder",
+			module(
+				section(sectionFunction, vec()),
+				section(sectionType, vec()),
+			),
+			errors.IllegalArgumentError,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Decode(c.bin)
+			assert.Error(t, err)
+			assert.Equal(t, c.code, errors.CodeOf(err))
+		})
+	}
+}
@@ -85,6 +85,7 @@ type consensus struct {
 	nid         []byte
 	bpp         fastsync.BlockProofProvider
 	srcUID      []byte
+	clock       common.Clock
 
 	lastBlock          module.Block
 	validators         module.ValidatorList
@@ -108,7 +109,7 @@ type consensus struct {
 	pcmForLastBlock    module.BTPProofContextMap
 	nextPCM            module.BTPProofContextMap
 
-	timer *time.Timer
+	timer *common.Timer
 
 	// commit cache
 	commitCache *commitCache
@@ -128,7 +129,7 @@ func NewConsensus(
 	timestamper module.Timestamper,
 	bpp fastsync.BlockProofProvider,
 ) module.Consensus {
-	cs := New(c, walDir, nil, timestamper, bpp, nil)
+	cs := New(c, walDir, nil, timestamper, bpp, nil, nil)
 	cs.log.Debugf("NewConsensus\n")
 	return cs
 }
@@ -140,10 +141,14 @@ func New(
 	timestamper module.Timestamper,
 	bpp fastsync.BlockProofProvider,
 	lastVoteData *LastVoteData,
+	clock common.Clock,
 ) *consensus {
 	if wm == nil {
 		wm = defaultWALManager
 	}
+	if clock == nil {
+		clock = &common.GoTimeClock{}
+	}
 	cs := &consensus{
 		c:            c,
 		walDir:       walDir,
@@ -155,6 +160,7 @@ func New(
 		bpp:          bpp,
 		srcUID:       module.GetSourceNetworkUID(c),
 		lastVoteData: lastVoteData,
+		clock:        clock,
 	}
 	cs.log = c.Logger().WithFields(log.Fields{
 		log.FieldKeyModule: "CS",
@@ -238,13 +244,18 @@ func (cs *consensus) resetForNewRound(round int32) {
 func (cs *consensus) resetForNewStep(step step) {
 	cs.endStep()
 	if cs.step < stepPropose && step > stepPropose {
-		now := time.Now()
+		now := cs.clock.Now()
 		cs.nextProposeTime = now
 		cs.c.Regulator().OnPropose(now)
 	}
 	cs.beginStep(step)
 }
 
+func (cs *consensus) afterFunc(d time.Duration, f func()) *common.Timer {
+	timer := cs.clock.AfterFunc(d, f)
+	return &timer
+}
+
 func (cs *consensus) endStep() {
 	if (cs.step == stepPropose || cs.step == stepCommit) && cs.cancelBlockRequest != nil {
 		cs.cancelBlockRequest.Cancel()
@@ -528,7 +539,7 @@ func (cs *consensus) processPrefetchItems() {
 func (cs *consensus) enterPropose() {
 	cs.resetForNewStep(stepPropose)
 
-	now := time.Now()
+	now := cs.clock.Now()
 	if int(cs.round) > cs.validators.Len()*configRoundTimeoutThresholdFactor {
 		cs.nextProposeTime = now.Add(timeoutNewRound)
 	} else {
@@ -537,7 +548,7 @@ func (cs *consensus) enterPropose() {
 	cs.c.Regulator().OnPropose(now)
 
 	hrs := cs.hrs
-	cs.timer = time.AfterFunc(timeoutPropose, func() {
+	cs.timer = cs.afterFunc(timeoutPropose, func() {
 		cs.mutex.Lock()
 		defer cs.mutex.Unlock()
 
@@ -723,7 +734,7 @@ func (cs *consensus) enterPrevoteWait() {
 		cs.enterPrecommit()
 	} else {
 		hrs := cs.hrs
-		cs.timer = time.AfterFunc(timeoutPrevote, func() {
+		cs.timer = cs.afterFunc(timeoutPrevote, func() {
 			cs.mutex.Lock()
 			defer cs.mutex.Unlock()
 
@@ -823,7 +834,7 @@ func (cs *consensus) enterPrecommitWait() {
 	} else {
 		cs.log.Traceln("enterPrecommitWait: start timer")
 		hrs := cs.hrs
-		cs.timer = time.AfterFunc(timeoutPrecommit, func() {
+		cs.timer = cs.afterFunc(timeoutPrecommit, func() {
 			cs.mutex.Lock()
 			defer cs.mutex.Unlock()
 
@@ -892,7 +903,7 @@ func (cs *consensus) enterCommit(precommits *voteSet, partSetID *PartSetID, roun
 		cs.log.Errorf("fail to sync WAL: cs.enterCommit: %+v\n", err)
 	}
 
-	cs.nextProposeTime = time.Now()
+	cs.nextProposeTime = cs.clock.Now()
 	if cs.consumedNonunicast || cs.validators.Len() == 1 {
 		if cs.timestamper == nil {
 			cs.nextProposeTime = cs.nextProposeTime.Add(cs.c.Regulator().CommitTimeout())
@@ -912,10 +923,10 @@ func (cs *consensus) enterNewRound() {
 	cs.resetForNewRound(cs.round + 1)
 	cs.notifySyncer()
 
-	now := time.Now()
+	now := cs.clock.Now()
 	if cs.nextProposeTime.After(now) {
 		hrs := cs.hrs
-		cs.timer = time.AfterFunc(cs.nextProposeTime.Sub(now), func() {
+		cs.timer = cs.afterFunc(cs.nextProposeTime.Sub(now), func() {
 			cs.mutex.Lock()
 			defer cs.mutex.Unlock()
 
@@ -964,10 +975,10 @@ func (cs *consensus) enterNewHeight() {
 	cs.resetForNewHeight(cs.currentBlockParts.validatedBlock, votes)
 	cs.notifySyncer()
 
-	now := time.Now()
+	now := cs.clock.Now()
 	if cs.nextProposeTime.After(now) {
 		hrs := cs.hrs
-		cs.timer = time.AfterFunc(cs.nextProposeTime.Sub(now), func() {
+		cs.timer = cs.afterFunc(cs.nextProposeTime.Sub(now), func() {
 			cs.mutex.Lock()
 			defer cs.mutex.Unlock()
 
@@ -1069,7 +1080,7 @@ func (cs *consensus) voteTimestamp() int64 {
 	} else if cs.currentBlockParts.HasBlockData() {
 		timestamp = cs.currentBlockParts.block.Timestamp() + blockIota
 	}
-	now := common.UnixMicroFromTime(time.Now())
+	now := common.UnixMicroFromTime(cs.clock.Now())
 	if now > timestamp {
 		timestamp = now
 	}
@@ -1110,7 +1121,11 @@ func (cs *consensus) ntsVoteBaseAndDecisionProofParts(
 			cs.round,
 			ntsHashEntry.NetworkTypeSectionHash,
 		)
-		pp, err := pc.NewProofPart(ntsd.Hash(), cs.c)
+		wp, err := newNTSDWalletProvider(cs.c, ntsd)
+		if err != nil {
+			return nil, nil, err
+		}
+		pp, err := pc.NewProofPart(ntsd.Hash(), wp)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1707,7 +1722,7 @@ func (cs *consensus) Start() error {
 
 	cs.started = true
 	cs.log.Infof("Start consensus wallet:%v", common.HexPre(cs.c.Wallet().Address().ID()))
-	cs.syncer, err = newSyncer(cs, cs.log, cs.c.NetworkManager(), cs.c.BlockManager(), &cs.mutex, cs.c.Wallet().Address())
+	cs.syncer, err = newSyncer(cs, cs.log, cs.c.NetworkManager(), cs.c.BlockManager(), &cs.mutex, cs.c.Wallet().Address(), cs.clock)
 	if err != nil {
 		return err
 	}
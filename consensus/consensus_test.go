@@ -248,8 +248,19 @@ type btpTest struct {
 }
 
 func newBTPTest(t *testing.T) *btpTest {
-	const dsa = "ecdsa/secp256k1"
-	const uid = "eth"
+	return newBTPTestFor(t, "ecdsa/secp256k1", "eth")
+}
+
+// btpRegistrationKey returns the key for setBTPPublicKey. It includes the
+// proof of possession if the DSA requires it.
+func btpRegistrationKey(bw module.BaseWallet) []byte {
+	if pw, ok := bw.(interface{ PublicKeyWithProof() []byte }); ok {
+		return pw.PublicKeyWithProof()
+	}
+	return bw.PublicKey()
+}
+
+func newBTPTestFor(t *testing.T, dsa string, uid string) *btpTest {
 	assert := assert.New(t)
 	f := test.NewFixture(t, test.AddDefaultNode(false), test.AddValidatorNodes(4))
 
@@ -261,14 +272,12 @@ func newBTPTest(t *testing.T) *btpTest {
 	for i, v := range f.Validators {
 		tx.CallFrom(v.CommonAddress(), "setBTPPublicKey", map[string]string{
 			"name":   dsa,
-			"pubKey": fmt.Sprintf("0x%x", v.Chain.WalletFor(dsa).PublicKey()),
+			"pubKey": fmt.Sprintf("0x%x", btpRegistrationKey(v.Chain.WalletFor(dsa))),
 		})
 		pk := v.Chain.WalletFor(dsa).PublicKey()
-		iconAddr, err := ntm.NewIconAddressFromPubKey(pk)
+		addr, err := ntm.ForUID(uid).AddressFromPubKey(pk)
 		assert.NoError(err)
-		ethAddr, err := ntm.ForUID("eth").AddressFromPubKey(pk)
-		assert.NoError(err)
-		t.Logf("register key index=%d %s=%x icon=%x eth=%x", i, dsa, v.Chain.WalletFor(dsa).PublicKey(), iconAddr, ethAddr)
+		t.Logf("register key index=%d %s=%x %s=%x", i, dsa, pk, uid, addr)
 	}
 	tx.Call("openBTPNetwork", map[string]string{
 		"networkTypeName": uid,
@@ -373,6 +382,57 @@ func TestConsensus_BTPBasic(t *testing.T) {
 	assert.NotNil(bb.MessagesRoot())
 }
 
+func TestConsensus_BTPBLS12381(t *testing.T) {
+	testBTPProofFor(t, "bls/bls12381", "bls12381")
+}
+
+func TestConsensus_BTPEd25519(t *testing.T) {
+	testBTPProofFor(t, "eddsa/ed25519", "ed25519")
+}
+
+func testBTPProofFor(t *testing.T, dsa string, uid string) {
+	tst := newBTPTestFor(t, dsa, uid)
+	defer tst.Close()
+	f := tst.Fixture
+	assert := tst.Assertions
+
+	f.WaitForBlock(2)
+	blk := f.SendTXToAllAndWaitForResultBlock(
+		f.NewTx().CallFrom(f.CommonAddress(), "sendBTPMessage", map[string]string{
+			"networkId": "0x1",
+			"message":   fmt.Sprintf("0x%x", []byte("test message")),
+		}),
+	)
+	bd, err := blk.BTPDigest()
+	assert.NoError(err)
+	bbh, pfBytes, err := f.CS.GetBTPBlockHeaderAndProof(
+		blk, 1,
+		module.FlagBTPBlockHeader|module.FlagBTPBlockProof,
+	)
+	assert.NoError(err)
+	prevBlk, err := f.BM.GetBlockByHeight(blk.Height() - 1)
+	assert.NoError(err)
+	pcm, err := prevBlk.NextProofContextMap()
+	assert.NoError(err)
+	pc, err := pcm.ProofContextFor(1)
+	assert.NoError(err)
+	assert.EqualValues(dsa, pc.DSA())
+	pf, err := pc.NewProofFromBytes(pfBytes)
+	assert.NoError(err)
+	ntsd := pc.NewDecision(module.SourceNetworkUID(1), 1, blk.Height(), bbh.Round(), bd.NetworkTypeDigestFor(1).NetworkTypeSectionHash())
+	assert.NoError(pc.Verify(ntsd.Hash(), pf))
+	assert.EqualValues(4, pf.ValidatorCount())
+
+	// each vote can be restored from the proof
+	for i := 0; i < pf.ValidatorCount(); i++ {
+		if pp := pf.ProofPartAt(i); pp != nil {
+			idx, err := pc.VerifyPart(ntsd.Hash(), pp)
+			assert.NoError(err)
+			assert.EqualValues(i, idx)
+		}
+	}
+}
+
 func TestConsensus_BTPBlockBasic(t_ *testing.T) {
 	assert := assert.New(t_)
 	f := test.NewFixture(t_, test.AddDefaultNode(false), test.AddValidatorNodes(4))
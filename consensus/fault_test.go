@@ -0,0 +1,157 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/test"
+)
+
+func newFaultFixture(t *testing.T, fi *test.FaultInjector) (*test.Fixture, []*test.Node) {
+	f := test.NewFixture(t, test.AddValidatorNodes(4), test.UseFaultInjector(fi))
+	tx := test.NewTx().Call("setRevision", map[string]string{
+		"code": fmt.Sprintf("0x%x", basic.MaxRevision),
+	})
+	f.SendTransactionToProposer(tx)
+
+	validators := f.Nodes[:4]
+	test.NodeInterconnect(validators)
+	return f, validators
+}
+
+func startNodes(t *testing.T, nodes []*test.Node) {
+	for _, n := range nodes {
+		assert.NoError(t, n.CS.Start())
+	}
+}
+
+func lastHeight(nodes []*test.Node) int64 {
+	var height int64
+	for _, n := range nodes {
+		if h := n.GetLastBlock().Height(); h > height {
+			height = h
+		}
+	}
+	return height
+}
+
+// assertSameBlocks checks that the nodes finalized same blocks up to h.
+func assertSameBlocks(t *testing.T, nodes []*test.Node, h int64) {
+	for height := int64(1); height <= h; height++ {
+		blk, err := nodes[0].BM.GetBlockByHeight(height)
+		assert.NoError(t, err)
+		for _, n := range nodes[1:] {
+			blk2, err := n.BM.GetBlockByHeight(height)
+			assert.NoError(t, err)
+			assert.Equal(t, blk.ID(), blk2.ID(), "height=%d", height)
+		}
+	}
+}
+
+func TestConsensus_LossyNetwork(t *testing.T) {
+	fi := test.NewFaultInjector(1)
+	f, validators := newFaultFixture(t, fi)
+	defer f.Close()
+
+	fi.SetDefaultFault(test.LinkFault{
+		Latency:       time.Millisecond,
+		Jitter:        10 * time.Millisecond,
+		DropRate:      0.1,
+		DuplicateRate: 0.1,
+	})
+	startNodes(t, validators)
+
+	blk := test.NodeWaitForBlock(validators, 4)
+	assert.EqualValues(t, 4, blk.Height())
+	assertSameBlocks(t, validators, 4)
+
+	stats := fi.Stats()
+	assert.True(t, stats.Dropped > 0)
+	assert.True(t, stats.Duplicated > 0)
+}
+
+func TestConsensus_PartitionAndHeal(t *testing.T) {
+	fi := test.NewFaultInjector(2)
+	f, validators := newFaultFixture(t, fi)
+	defer f.Close()
+
+	startNodes(t, validators)
+	_ = test.NodeWaitForBlock(validators, 2)
+
+	// no partition has more than 2/3 of validators.
+	fi.Partition(
+		test.NodePeerIDs(validators[:2]...),
+		test.NodePeerIDs(validators[2:]...),
+	)
+	time.Sleep(500 * time.Millisecond)
+	height := lastHeight(validators)
+	time.Sleep(time.Second)
+	assert.Equal(t, height, lastHeight(validators))
+	assert.True(t, fi.Stats().Blocked > 0)
+
+	fi.Heal()
+	blk := test.NodeWaitForBlock(validators, height+2)
+	assert.EqualValues(t, height+2, blk.Height())
+	assertSameBlocks(t, validators, height+2)
+}
+
+func TestConsensus_IsolatedValidator(t *testing.T) {
+	fi := test.NewFaultInjector(3)
+	f, validators := newFaultFixture(t, fi)
+	defer f.Close()
+
+	// other validators have more than 2/3 of validators.
+	fi.Partition(
+		test.NodePeerIDs(validators[:3]...),
+		test.NodePeerIDs(validators[3]),
+	)
+	startNodes(t, validators)
+	_ = test.NodeWaitForBlock(validators[:3], 4)
+
+	fi.Heal()
+	blk := validators[3].WaitForBlock(4)
+	assert.EqualValues(t, 4, blk.Height())
+	assertSameBlocks(t, validators, 4)
+}
+
+func TestConsensus_ByzantineValidator(t *testing.T) {
+	fi := test.NewFaultInjector(4)
+	f, validators := newFaultFixture(t, fi)
+	defer f.Close()
+
+	byzantine := validators[3]
+	honest := validators[:3]
+	// the byzantine validator sends conflicting messages to one of
+	// honest validators, so others still have more than 2/3 of votes.
+	fi.SetTamperer(byzantine.NM.ID(), test.NewEquivocator(
+		byzantine.Chain.Wallet(),
+		honest[2].NM.ID(),
+	))
+	startNodes(t, validators)
+
+	// honest validators keep making blocks and never finalize
+	// different blocks.
+	blk := test.NodeWaitForBlock(honest, 5)
+	assert.EqualValues(t, 5, blk.Height())
+	assertSameBlocks(t, honest, 5)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -100,6 +101,18 @@ func NewProposalMessage() *ProposalMessage {
 	return msg
 }
 
+func (msg *ProposalMessage) signContext() *wallet.SignContext {
+	ctx := &wallet.SignContext{
+		Kind:   wallet.SignKindProposal,
+		Height: msg.Height,
+		Round:  msg.Round,
+	}
+	if msg.BlockPartSetID != nil {
+		ctx.Value = msg.BlockPartSetID.Hash
+	}
+	return ctx
+}
+
 func (msg *ProposalMessage) Verify() error {
 	if err := msg._HR.verify(); err != nil {
 		return err
@@ -164,6 +177,113 @@ func (v *blockVoteByteser) bytes() []byte {
 	return msgCodec.MustMarshalToBytes(&bv)
 }
 
+func (v *blockVoteByteser) signContext() *wallet.SignContext {
+	return &wallet.SignContext{
+		Kind:   wallet.SignKindVote,
+		Height: v.msg.Height,
+		Round:  v.msg.Round,
+		Step:   int(v.msg.Type),
+		Value:  v.msg.BlockID,
+	}
+}
+
+// ntsDecision is the decision of a network type section signed for proof
+// parts in votes. It's encoded in the same way as the decision made by
+// BTPProofContext.NewDecision.
+type ntsDecision struct {
+	SrcNetworkID           []byte
+	DstType                int64
+	Height                 int64
+	Round                  int32
+	NetworkTypeSectionHash []byte
+}
+
+func (d *ntsDecision) signContext() *wallet.SignContext {
+	return &wallet.SignContext{
+		Kind:   wallet.SignKindNTSD,
+		Height: d.Height,
+		Round:  d.Round,
+		Step:   int(d.DstType),
+		Value:  d.NetworkTypeSectionHash,
+	}
+}
+
+// ntsdWallet signs with the context of the decision if the wallet can take
+// it.
+type ntsdWallet struct {
+	module.BaseWallet
+	ctx *wallet.SignContext
+}
+
+func (w *ntsdWallet) Sign(data []byte) ([]byte, error) {
+	if cs, ok := w.BaseWallet.(wallet.ContextSigner); ok {
+		return cs.SignWithContext(data, w.ctx)
+	}
+	return w.BaseWallet.Sign(data)
+}
+
+// ntsdWalletProvider provides wallets signing the decision with its context,
+// so the remote signer can protect it from double signing.
+type ntsdWalletProvider struct {
+	wp  module.WalletProvider
+	ctx *wallet.SignContext
+}
+
+func (p *ntsdWalletProvider) WalletFor(dsa string) module.BaseWallet {
+	w := p.wp.WalletFor(dsa)
+	if w == nil {
+		return nil
+	}
+	return &ntsdWallet{w, p.ctx}
+}
+
+func newNTSDWalletProvider(wp module.WalletProvider, ntsd module.BytesHasher) (module.WalletProvider, error) {
+	ctx, err := ParseSignContext(wallet.SignKindNTSD, ntsd.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	ctx.Message = ntsd.Bytes()
+	return &ntsdWalletProvider{wp, ctx}, nil
+}
+
+// ParseSignContext decodes the bytes of the message to be signed, and
+// returns the context of it. It's used by the remote signer to check
+// the context given by the node.
+func ParseSignContext(kind string, bs []byte) (*wallet.SignContext, error) {
+	var remain []byte
+	var err error
+	var ctx *wallet.SignContext
+	switch kind {
+	case wallet.SignKindProposal:
+		msg := NewProposalMessage()
+		remain, err = msgCodec.UnmarshalFromBytes(bs, &msg.proposal)
+		ctx = msg.signContext()
+	case wallet.SignKindVote:
+		var bv struct {
+			blockVoteBase
+			Timestamp int64
+		}
+		remain, err = msgCodec.UnmarshalFromBytes(bs, &bv)
+		msg := newVoteMessage()
+		msg.blockVoteBase = bv.blockVoteBase
+		ctx = (&blockVoteByteser{msg: msg}).signContext()
+	case wallet.SignKindNTSD:
+		var d ntsDecision
+		remain, err = msgCodec.UnmarshalFromBytes(bs, &d)
+		ctx = d.signContext()
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownKind(%s)", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(remain) > 0 {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"TrailingBytes(kind=%s,len=%d)", kind, len(remain))
+	}
+	return ctx, nil
+}
+
 type VoteMessage struct {
 	signedBase
 	voteBase
@@ -226,7 +346,11 @@ func NewVoteMessageFromBlock(
 			round,
 			ntd.NetworkTypeSectionHash(),
 		)
-		pp, err := pc.NewProofPart(ntsd.Hash(), wp)
+		nwp, err := newNTSDWalletProvider(wp, ntsd)
+		if err != nil {
+			return nil, err
+		}
+		pp, err := pc.NewProofPart(ntsd.Hash(), nwp)
 		if err != nil {
 			return nil, err
 		}
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 )
@@ -174,3 +175,87 @@ func TestVoteMessage_VerifyMismatchBetweenNTSVoteBasesAndNTSDProofParts(t *testi
 	_ = msg.Sign(w)
 	assert.Error(msg.Verify())
 }
+
+type contextWallet struct {
+	module.Wallet
+	data []byte
+	ctx  *wallet.SignContext
+}
+
+func (w *contextWallet) SignWithContext(data []byte, ctx *wallet.SignContext) ([]byte, error) {
+	w.data, w.ctx = data, ctx
+	return w.Wallet.Sign(data)
+}
+
+func TestParseSignContext(t *testing.T) {
+	w := &contextWallet{Wallet: wallet.New()}
+
+	vm := newVoteMessage()
+	vm.Height = 10
+	vm.Round = 2
+	vm.Type = VoteTypePrecommit
+	vm.BlockID = []byte("block id")
+	vm.Timestamp = 1000
+	assert.NoError(t, vm.Sign(w))
+	assert.Equal(t, vm.hash(), w.data)
+	assert.Equal(t, w.data, crypto.SHA3Sum256(w.ctx.Message))
+	ctx, err := ParseSignContext(wallet.SignKindVote, w.ctx.Message)
+	assert.NoError(t, err)
+	assert.Equal(t, &wallet.SignContext{
+		Kind:   wallet.SignKindVote,
+		Height: 10,
+		Round:  2,
+		Step:   int(VoteTypePrecommit),
+		Value:  []byte("block id"),
+	}, ctx)
+
+	pm := NewProposalMessage()
+	pm.Height = 10
+	pm.Round = 2
+	pm.BlockPartSetID = &PartSetID{Count: 1, Hash: []byte("part set hash")}
+	pm.POLRound = -1
+	assert.NoError(t, pm.Sign(w))
+	assert.Equal(t, pm.hash(), w.data)
+	ctx, err = ParseSignContext(wallet.SignKindProposal, w.ctx.Message)
+	assert.NoError(t, err)
+	assert.Equal(t, &wallet.SignContext{
+		Kind:   wallet.SignKindProposal,
+		Height: 10,
+		Round:  2,
+		Value:  []byte("part set hash"),
+	}, ctx)
+
+	// message of other kind
+	_, err = ParseSignContext(wallet.SignKindVote, w.ctx.Message)
+	assert.Error(t, err)
+	_, err = ParseSignContext("unknown", w.ctx.Message)
+	assert.Error(t, err)
+	_, err = ParseSignContext(wallet.SignKindProposal, append(w.ctx.Message, 0))
+	assert.Error(t, err)
+}
+
+func TestNTSDWalletProvider(t *testing.T) {
+	w := &contextWallet{Wallet: wallet.New()}
+	wp := &walletProvider{w}
+
+	pc, err := ntm.ForUID("eth").NewProofContext([][]byte{w.PublicKey()})
+	assert.NoError(t, err)
+	ntsd := pc.NewDecision([]byte("src"), 1, 10, 2, []byte("nts hash"))
+	nwp, err := newNTSDWalletProvider(wp, ntsd)
+	assert.NoError(t, err)
+	assert.Nil(t, nwp.WalletFor("ecdsa/unknown"))
+
+	_, err = pc.NewProofPart(ntsd.Hash(), nwp)
+	assert.NoError(t, err)
+	assert.Equal(t, ntsd.Hash(), w.data)
+	assert.Equal(t, ntsd.Bytes(), []byte(w.ctx.Message))
+	ctx, err := ParseSignContext(wallet.SignKindNTSD, w.ctx.Message)
+	assert.NoError(t, err)
+	assert.Equal(t, &wallet.SignContext{
+		Kind:   wallet.SignKindNTSD,
+		Height: 10,
+		Round:  2,
+		Step:   1,
+		Value:  []byte("nts hash"),
+	}, ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -11,6 +12,12 @@ type byteser interface {
 	bytes() []byte
 }
 
+// signContexter is implemented by byteser of consensus messages which
+// shall be protected from double signing.
+type signContexter interface {
+	signContext() *wallet.SignContext
+}
+
 // base class for signed data
 type signedBase struct {
 	// shall be initialized
@@ -54,10 +61,20 @@ func (s *signedBase) verify() error {
 	return nil
 }
 
-func (s *signedBase) Sign(wallet module.Wallet) error {
+func (s *signedBase) Sign(w module.Wallet) error {
 	s._hash = nil
 	s._publicKey = nil
-	sigBS, err := wallet.Sign(s.hash())
+	var sigBS []byte
+	var err error
+	cs, ok1 := w.(wallet.ContextSigner)
+	sc, ok2 := s._byteser.(signContexter)
+	if ok1 && ok2 {
+		ctx := sc.signContext()
+		ctx.Message = s._byteser.bytes()
+		sigBS, err = cs.SignWithContext(s.hash(), ctx)
+	} else {
+		sigBS, err = w.Sign(s.hash())
+	}
 	if err != nil {
 		return errors.Errorf("sendVote : %v", err)
 	}
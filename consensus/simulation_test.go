@@ -0,0 +1,205 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/test"
+)
+
+func blockIDs(t *testing.T, n *test.Node, h int64) [][]byte {
+	var ids [][]byte
+	for height := int64(1); height <= h; height++ {
+		blk, err := n.BM.GetBlockByHeight(height)
+		assert.NoError(t, err)
+		ids = append(ids, blk.ID())
+	}
+	return ids
+}
+
+func runSimulation(t *testing.T, seed int64, h int64) [][]byte {
+	s := test.NewSimulation(t, seed, 4)
+	defer s.Close()
+
+	s.Start()
+	s.WaitForHeight(h)
+	return blockIDs(t, s.Validators[0], h)
+}
+
+func TestSimulation_Reproducible(t *testing.T) {
+	ids := runSimulation(t, 1, 5)
+	assert.Equal(t, ids, runSimulation(t, 1, 5))
+	assert.NotEqual(t, ids, runSimulation(t, 2, 5))
+}
+
+func hasValidator(vl module.ValidatorList, n *test.Node) bool {
+	return vl.IndexOf(n.Address()) >= 0
+}
+
+func TestSimulation_ValidatorRotation(t *testing.T) {
+	s := test.NewSimulation(t, 1, 4)
+	defer s.Close()
+
+	v := s.Validators
+	newcomer := s.AddNode()
+	s.Start()
+	s.WaitForHeight(2)
+
+	blk := s.SendTXAndWaitForBlock(
+		s.NewTx().SetValidatorsNode(v[1], v[2], v[3], newcomer),
+	)
+	blk = s.WaitForHeight(blk.Height() + 1)
+	assert.True(t, hasValidator(blk.NextValidators(), newcomer))
+	assert.False(t, hasValidator(blk.NextValidators(), v[0]))
+
+	// the removed validator is not necessary to make blocks anymore.
+	s.Faults.Partition(
+		test.NodePeerIDs(v[0]),
+		test.NodePeerIDs(v[1], v[2], v[3], newcomer),
+	)
+	blk = s.WaitForHeight(blk.Height()+3, v[1], v[2], v[3], newcomer)
+	assert.NotNil(t, blk)
+	assert.True(t, hasValidator(blk.NextValidators(), newcomer))
+}
+
+func TestSimulation_RevisionUpgrade(t *testing.T) {
+	s := test.NewSimulation(t, 1, 4)
+	defer s.Close()
+
+	s.Start()
+	s.WaitForHeight(1)
+	assert.NotEqual(t, basic.MaxRevision, s.Revision(s.Node))
+
+	blk := s.SendTXAndWaitForBlock(s.NewTx().Call("setRevision", map[string]string{
+		"code": fmt.Sprintf("0x%x", basic.MaxRevision),
+	}))
+	s.WaitForHeight(blk.Height() + 1)
+	for _, n := range s.Running() {
+		assert.Equal(t, basic.MaxRevision, s.Revision(n))
+	}
+}
+
+func TestSimulation_BTPNetworkOpenClose(t *testing.T) {
+	const (
+		dsa = "ecdsa/secp256k1"
+		uid = "eth"
+	)
+	s := test.NewSimulation(t, 1, 4)
+	defer s.Close()
+
+	s.Start()
+	tx := s.NewTx().Call("setRevision", map[string]string{
+		"code": fmt.Sprintf("0x%x", basic.MaxRevision),
+	})
+	for _, v := range s.Validators {
+		tx.CallFrom(v.CommonAddress(), "setBTPPublicKey", map[string]string{
+			"name":   dsa,
+			"pubKey": fmt.Sprintf("0x%x", btpRegistrationKey(v.Chain.WalletFor(dsa))),
+		})
+	}
+	tx.Call("openBTPNetwork", map[string]string{
+		"networkTypeName": uid,
+		"name":            fmt.Sprintf("%s-test", uid),
+		"owner":           s.CommonAddress().String(),
+	})
+	blk := s.SendTXAndWaitForBlock(tx)
+	blk = s.WaitForHeight(blk.Height() + 1)
+	bd, err := blk.BTPDigest()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(bd.NetworkTypeDigests()))
+	nw, err := s.SM.BTPNetworkFromResult(blk.Result(), 1)
+	assert.NoError(t, err)
+	assert.True(t, nw.Open())
+
+	blk = s.SendTXAndWaitForBlock(s.NewTx().Call("closeBTPNetwork", map[string]string{
+		"id": "0x1",
+	}))
+	blk = s.WaitForHeight(blk.Height() + 1)
+	nw, err = s.SM.BTPNetworkFromResult(blk.Result(), 1)
+	assert.NoError(t, err)
+	assert.False(t, nw.Open())
+	s.WaitForHeight(blk.Height() + 2)
+}
+
+func TestSimulation_FastSyncCatchUp(t *testing.T) {
+	s := test.NewSimulation(t, 1, 4)
+	defer s.Close()
+
+	s.Start()
+	s.WaitForHeight(10)
+
+	late := s.AddNode()
+	s.Start(late)
+	blk := s.WaitForHeight(12)
+	assert.NotNil(t, blk)
+	assert.Equal(t, blockIDs(t, s.Validators[0], 12), blockIDs(t, late, 12))
+}
+
+func nextProposer(s *test.Simulation) (*test.Node, []*test.Node) {
+	blk := s.GetLastBlock()
+	vl := blk.NextValidators()
+	v, _ := vl.Get(int((blk.Height() + 1) % int64(vl.Len())))
+	var others []*test.Node
+	var proposer *test.Node
+	for _, n := range s.Validators {
+		if n.Address().Equal(v.Address()) {
+			proposer = n
+		} else {
+			others = append(others, n)
+		}
+	}
+	return proposer, others
+}
+
+func TestSimulation_TimeoutsInVirtualTime(t *testing.T) {
+	s := test.NewSimulation(t, 1, 4)
+	defer s.Close()
+
+	s.Start()
+	s.WaitForHeight(2)
+
+	// no partition has more than 2/3 of validators, so no block is made.
+	v := s.Validators
+	s.Faults.Partition(test.NodePeerIDs(v[:2]...), test.NodePeerIDs(v[2:]...))
+	height := s.GetLastBlock().Height()
+	start := time.Now()
+	s.RunFor(time.Minute)
+	assert.True(t, time.Since(start) < time.Minute)
+	for _, n := range s.Running() {
+		assert.True(t, n.GetLastBlock().Height() <= height+1)
+	}
+	s.Faults.Heal()
+	blk := s.WaitForHeight(height + 2)
+
+	// others make the block after timeout for the isolated proposer.
+	proposer, others := nextProposer(s)
+	s.Faults.Partition(test.NodePeerIDs(proposer), test.NodePeerIDs(others...))
+	now := s.Clock.Now()
+	blk = s.WaitForHeight(blk.Height()+1, others...)
+	assert.False(t, blk.Proposer().Equal(proposer.Address()))
+	assert.True(t, s.Clock.Now().Sub(now) >= time.Second)
+
+	s.Faults.Heal()
+	s.WaitForHeight(blk.Height() + 1)
+}
@@ -2,6 +2,7 @@ module github.com/icon-project/goloop
 
 require (
 	contrib.go.opencensus.io/exporter/prometheus v0.4.2
+	filippo.io/edwards25519 v1.0.0
 	github.com/biter777/countries v1.3.4
 	github.com/bshuster-repo/logrus-logstash-hook v0.4.1
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.1.0
@@ -10,6 +11,7 @@ require (
 	github.com/gorilla/websocket v1.4.1
 	github.com/gosuri/uitable v0.0.0-20160404203958-36ee7e946282
 	github.com/jroimartin/gocui v0.4.0
+	github.com/kilic/bls12-381 v0.1.0
 	github.com/labstack/echo/v4 v4.9.0
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/pkg/errors v0.9.1
@@ -26,6 +28,7 @@ require (
 	golang.org/x/tools v0.1.12
 	gopkg.in/go-playground/validator.v9 v9.28.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -81,7 +84,6 @@ require (
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 go 1.18
@@ -32,13 +32,16 @@ import (
 )
 
 var (
-	treasury = common.MustNewAddressFromString("hx1000000000000000000000000000000000000000")
+	treasury   = common.MustNewAddressFromString("hx1000000000000000000000000000000000000000")
+	governance = common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
 )
 
 type WorldContext interface {
 	icmodule.WorldContext
 	GetExtensionState() state.ExtensionState
 	BlockTimeStamp() int64
+	TransactionInfo() *state.TransactionInfo
+	SetTransactionInfo(ti *state.TransactionInfo)
 }
 
 type worldContext struct {
@@ -50,6 +53,7 @@ type worldContext struct {
 	revision       module.Revision
 	stepPrice      *big.Int
 	txId           []byte
+	txInfo         state.TransactionInfo
 }
 
 func (ctx *worldContext) addBalance(address module.Address, amount *big.Int) error {
@@ -82,6 +86,15 @@ func (ctx *worldContext) ConsensusInfo() module.ConsensusInfo {
 	return ctx.csi
 }
 
+func (ctx *worldContext) TransactionInfo() *state.TransactionInfo {
+	ti := ctx.txInfo
+	return &ti
+}
+
+func (ctx *worldContext) SetTransactionInfo(ti *state.TransactionInfo) {
+	ctx.txInfo = *ti
+}
+
 func (ctx *worldContext) GetBalance(address module.Address) *big.Int {
 	account := ctx.GetAccountState(address.ID())
 	return account.GetBalance()
@@ -204,7 +217,22 @@ func (ctx *worldContext) SetScoreOwner(from module.Address, score module.Address
 
 func (ctx *worldContext) GetBTPContext() state.BTPContext {
 	as := ctx.GetAccountState(state.SystemID)
-	return state.NewBTPContext(nil, as)
+	return state.NewBTPContext(&btpWorldContext{wc: ctx}, as)
+}
+
+// btpWorldContext is state.WorldContext for BTPContext, which uses only
+// BlockHeight and GetValidatorState of it.
+type btpWorldContext struct {
+	state.WorldContext
+	wc *worldContext
+}
+
+func (ctx *btpWorldContext) BlockHeight() int64 {
+	return ctx.wc.BlockHeight()
+}
+
+func (ctx *btpWorldContext) GetValidatorState() state.ValidatorState {
+	return ctx.wc.GetValidatorState()
 }
 
 func NewWorldContext(
@@ -278,17 +306,13 @@ func (ctx *callContext) CallOnTimer(to module.Address, params []byte) error {
 }
 
 func (ctx *callContext) Governance() module.Address {
-	return ctx.Governance()
+	return governance
 }
 
 func (ctx *callContext) FrameLogger() *trace.Logger {
 	return trace.LoggerOf(log.GlobalLogger())
 }
 
-func (ctx *callContext) TransactionInfo() *state.TransactionInfo {
-	panic("implement me")
-}
-
 func NewCallContext(wc WorldContext, from module.Address) icmodule.CallContext {
 	return &callContext{
 		WorldContext: wc,
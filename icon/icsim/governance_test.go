@@ -0,0 +1,170 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package icsim
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+)
+
+func newGovernanceTestEnv(t *testing.T) *Env {
+	c := NewConfig()
+	c.MainPRepCount = 4
+	c.SubPRepCount = 2
+	c.TermPeriod = 100
+	c.BondedPRepCount = 1
+	return initEnv(t, c, icmodule.Revision13)
+}
+
+func TestSimulator_InjectValidationFailure(t *testing.T) {
+	env := newGovernanceTestEnv(t)
+	sim := env.sim
+
+	vl := sim.ValidatorList()
+	csi, err := sim.NewConsensusInfo(vl[1].Address())
+	assert.NoError(t, err)
+	assert.NoError(t, sim.Go(3, csi))
+
+	blockHeight := sim.BlockHeight()
+	prep := sim.GetPRep(env.preps[1])
+	assert.Equal(t, int64(3), prep.GetVFail(blockHeight))
+	assert.Equal(t, int64(3), prep.GetVFailCont(blockHeight))
+	prep = sim.GetPRep(env.preps[0])
+	assert.Zero(t, prep.GetVFail(blockHeight))
+}
+
+func TestSimulator_PenalizeNonvoters(t *testing.T) {
+	const slashRate = 10
+	env := newGovernanceTestEnv(t)
+	sim := env.sim
+	target := env.preps[0]
+
+	rcpts, err := sim.GoByTransaction(sim.SetNonVoteSlashingRate(slashRate), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+	assert.Equal(t, slashRate, sim.GetNetworkInfo()["proposalNonVotePenaltySlashRatio"])
+
+	rcpts, err = sim.GoByTransaction(sim.SetNonVoteSlashingRate(101), nil)
+	assert.NoError(t, err)
+	assert.False(t, checkReceipts(rcpts))
+
+	oldBonded := sim.GetPRep(target).Bonded()
+	assert.True(t, oldBonded.Sign() > 0)
+	oldTotalBond := sim.TotalBond()
+
+	rcpts, err = sim.GoByTransaction(sim.PenalizeNonvoters([]module.Address{target}), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+
+	slashed := estimateSlashed(100/slashRate, oldBonded)
+	bonded := sim.GetPRep(target).Bonded()
+	assert.Zero(t, new(big.Int).Sub(oldBonded, slashed).Cmp(bonded))
+	assert.Zero(t, new(big.Int).Sub(oldTotalBond, slashed).Cmp(sim.TotalBond()))
+
+	rcpts, err = sim.GoByTransaction(sim.PenalizeNonvoters([]module.Address{env.users[0]}), nil)
+	assert.NoError(t, err)
+	assert.False(t, checkReceipts(rcpts))
+}
+
+func TestSimulator_SetRewardFundAllocation(t *testing.T) {
+	env := newGovernanceTestEnv(t)
+	sim := env.sim
+
+	tx := sim.SetRewardFundAllocation(big.NewInt(40), big.NewInt(10), big.NewInt(10), big.NewInt(40))
+	rcpts, err := sim.GoByTransaction(tx, nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+
+	rf := sim.GetNetworkInfo()["rewardFund"].(map[string]interface{})
+	assert.Equal(t, int64(40), rf["Iprep"].(*big.Int).Int64())
+	assert.Equal(t, int64(10), rf["Icps"].(*big.Int).Int64())
+	assert.Equal(t, int64(10), rf["Irelay"].(*big.Int).Int64())
+	assert.Equal(t, int64(40), rf["Ivoter"].(*big.Int).Int64())
+
+	// New allocation is applied to the next term
+	assert.NoError(t, sim.GoToTermEnd(nil))
+	assert.NoError(t, sim.Go(1, nil))
+	rf = sim.TermSnapshot().RewardFund().ToJSON()
+	assert.Equal(t, int64(10), rf["Irelay"].(*big.Int).Int64())
+}
+
+func TestSimulator_SetNetworkScore(t *testing.T) {
+	env := newGovernanceTestEnv(t)
+	sim := env.sim
+	cps := common.MustNewAddressFromString("cx0000000000000000000000000000000000000100")
+
+	rcpts, err := sim.GoByTransaction(sim.SetNetworkScore(icstate.CPSKey, cps), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+	scores := sim.GetNetworkScores()
+	assert.True(t, cps.Equal(scores[icstate.CPSKey].(module.Address)))
+	assert.True(t, governance.Equal(scores[icstate.GovernanceKey].(module.Address)))
+
+	rcpts, err = sim.GoByTransaction(sim.SetNetworkScore(icstate.GovernanceKey, cps), nil)
+	assert.NoError(t, err)
+	assert.False(t, checkReceipts(rcpts))
+
+	rcpts, err = sim.GoByTransaction(sim.SetNetworkScore(icstate.CPSKey, nil), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+	_, ok := sim.GetNetworkScores()[icstate.CPSKey]
+	assert.False(t, ok)
+}
+
+func TestSimulator_OpenBTPNetwork(t *testing.T) {
+	const networkType = "eth"
+	env := newGovernanceTestEnv(t)
+	sim := env.sim
+	owner := env.users[0]
+
+	// Validators don't have public keys yet
+	rcpts, err := sim.GoByTransaction(sim.OpenBTPNetwork(networkType, "test", owner), nil)
+	assert.NoError(t, err)
+	assert.False(t, checkReceipts(rcpts))
+	assert.Zero(t, sim.GetBTPNetworkTypeID(networkType))
+
+	// Register public keys with new node addresses
+	block := NewBlock()
+	pubKeys := make(map[string][]byte)
+	for _, prep := range env.preps {
+		_, pub := crypto.GenerateKeyPair()
+		pubKeys[string(prep.Bytes())] = pub.SerializeCompressed()
+		block.AddTransaction(sim.SetPRepNodePublicKey(prep, pub.SerializeCompressed()))
+	}
+	rcpts, err = sim.GoByBlock(block, nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+	for _, prep := range env.preps {
+		assert.Equal(t, pubKeys[string(prep.Bytes())], sim.GetPRepNodePublicKey(prep))
+	}
+
+	// New node addresses become validators in the next term
+	assert.NoError(t, sim.GoToTermEnd(nil))
+	assert.NoError(t, sim.Go(1, nil))
+
+	rcpts, err = sim.GoByTransaction(sim.OpenBTPNetwork(networkType, "test", owner), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(rcpts))
+	assert.True(t, sim.GetBTPNetworkTypeID(networkType) > 0)
+}
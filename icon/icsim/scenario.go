@@ -0,0 +1,796 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package icsim
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// ScenarioValue is a value in a scenario. It may be written as a string,
+// a number or a boolean. Amounts are in loop unless they have "icx" suffix.
+type ScenarioValue string
+
+func (v *ScenarioValue) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*v = ScenarioValue(s)
+	} else {
+		*v = ScenarioValue(b)
+	}
+	return nil
+}
+
+func (v ScenarioValue) BigInt() (*big.Int, error) {
+	s := strings.TrimSpace(string(v))
+	unit := big.NewInt(1)
+	if strings.HasSuffix(strings.ToLower(s), "icx") {
+		s = strings.TrimSpace(s[:len(s)-3])
+		unit = icmodule.BigIntICX
+	}
+	value, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", string(v))
+	}
+	return value.Mul(value, unit), nil
+}
+
+type ScenarioAccount struct {
+	Name    string        `json:"name"`
+	Address string        `json:"address,omitempty"`
+	Balance ScenarioValue `json:"balance,omitempty"`
+}
+
+type ScenarioVote struct {
+	Address string        `json:"address"`
+	Value   ScenarioValue `json:"value"`
+}
+
+type ScenarioPRepInfo struct {
+	Name        string `json:"name,omitempty"`
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Website     string `json:"website,omitempty"`
+	Details     string `json:"details,omitempty"`
+	P2PEndpoint string `json:"p2pEndpoint,omitempty"`
+	Node        string `json:"node,omitempty"`
+}
+
+type ScenarioRewardFund struct {
+	Iprep  ScenarioValue `json:"iprep"`
+	Icps   ScenarioValue `json:"icps"`
+	Irelay ScenarioValue `json:"irelay"`
+	Ivoter ScenarioValue `json:"ivoter"`
+}
+
+type ScenarioTx struct {
+	Type        string              `json:"type"`
+	From        string              `json:"from,omitempty"`
+	Amount      ScenarioValue       `json:"amount,omitempty"`
+	Address     string              `json:"address,omitempty"`
+	Addresses   []string            `json:"addresses,omitempty"`
+	Revision    int                 `json:"revision,omitempty"`
+	Rate        int                 `json:"rate,omitempty"`
+	Delegations []ScenarioVote      `json:"delegations,omitempty"`
+	Bonds       []ScenarioVote      `json:"bonds,omitempty"`
+	BonderList  []string            `json:"bonderList,omitempty"`
+	Info        *ScenarioPRepInfo   `json:"info,omitempty"`
+	RewardFund  *ScenarioRewardFund `json:"rewardFund,omitempty"`
+	Role        string              `json:"role,omitempty"`
+	PubKey      string              `json:"pubKey,omitempty"`
+	NetworkType string              `json:"networkType,omitempty"`
+	Name        string              `json:"name,omitempty"`
+
+	// Expect is the expected result of the transaction, "success" (default)
+	// or "failure".
+	Expect string `json:"expect,omitempty"`
+}
+
+// ScenarioAssert has expected values keyed by account names (or key of
+// term for Term, and key of network information for Network). An expected value may start with one of the operators,
+// "=", "!=", "<", "<=", ">" and ">=".
+type ScenarioAssert struct {
+	BlockHeight ScenarioValue            `json:"blockHeight,omitempty"`
+	Balance     map[string]ScenarioValue `json:"balance,omitempty"`
+	Stake       map[string]ScenarioValue `json:"stake,omitempty"`
+	IScore      map[string]ScenarioValue `json:"iscore,omitempty"`
+	Term        map[string]ScenarioValue `json:"term,omitempty"`
+	Network     map[string]ScenarioValue `json:"network,omitempty"`
+}
+
+// ScenarioStep is a step of a scenario. Only one of Block, Go, GoTo and
+// Terms is used for a step, and Assert is checked after it.
+type ScenarioStep struct {
+	Name   string          `json:"name,omitempty"`
+	Block  []*ScenarioTx   `json:"block,omitempty"`
+	Go     int64           `json:"go,omitempty"`
+	GoTo   int64           `json:"goTo,omitempty"`
+	Terms  int64           `json:"terms,omitempty"`
+	Absent []string        `json:"absent,omitempty"`
+	Assert *ScenarioAssert `json:"assert,omitempty"`
+}
+
+type Scenario struct {
+	Revision   int               `json:"revision"`
+	Config     json.RawMessage   `json:"config,omitempty"`
+	Accounts   []ScenarioAccount `json:"accounts"`
+	Validators []string          `json:"validators,omitempty"`
+	Steps      []*ScenarioStep   `json:"steps"`
+}
+
+// LoadScenario loads a scenario from JSON or YAML file. Files with
+// ".json" extension are parsed as JSON, and others are parsed as YAML.
+func LoadScenario(file string) (*Scenario, error) {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(filepath.Ext(file)) != ".json" {
+		if bs, err = yamlToJSON(bs); err != nil {
+			return nil, err
+		}
+	}
+	sc := new(Scenario)
+	if err := json.Unmarshal(bs, sc); err != nil {
+		return nil, errors.Wrapf(err, "InvalidScenario(file=%s)", file)
+	}
+	return sc, nil
+}
+
+func yamlToJSON(bs []byte) ([]byte, error) {
+	var obj interface{}
+	if err := yaml.Unmarshal(bs, &obj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+type AccountReward struct {
+	Name    string         `json:"name"`
+	Address module.Address `json:"address"`
+	IScore  *common.HexInt `json:"iscore"`
+	Delta   *common.HexInt `json:"delta"`
+}
+
+type PRepPenalty struct {
+	Name      string         `json:"name"`
+	Address   module.Address `json:"address"`
+	Grade     int            `json:"grade"`
+	Status    int            `json:"status"`
+	Penalties int            `json:"penalties"`
+	Delta     int            `json:"delta"`
+}
+
+// TermReport has rewards of accounts and penalties of P-Reps in the
+// scenario at the end of a term. I-Scores are ones calculated by the end
+// of the term, so rewards of a term are reported at the end of the next
+// term.
+type TermReport struct {
+	Sequence    int                    `json:"sequence"`
+	StartHeight int64                  `json:"startBlockHeight"`
+	EndHeight   int64                  `json:"endBlockHeight"`
+	RewardFund  map[string]interface{} `json:"rewardFund"`
+	Rewards     []*AccountReward       `json:"rewards,omitempty"`
+	Penalties   []*PRepPenalty         `json:"penalties,omitempty"`
+}
+
+func (r *TermReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "Term #%d [%d, %d] rewardFund=%v\n",
+		r.Sequence, r.StartHeight, r.EndHeight, r.RewardFund)
+	for _, rw := range r.Rewards {
+		fmt.Fprintf(w, "  reward  %s(%s) iscore=%s (+%s)\n",
+			rw.Name, rw.Address, rw.IScore.String(), rw.Delta.String())
+	}
+	for _, p := range r.Penalties {
+		fmt.Fprintf(w, "  penalty %s(%s) penalties=%d (+%d) grade=%d status=%d\n",
+			p.Name, p.Address, p.Penalties, p.Delta, p.Grade, p.Status)
+	}
+}
+
+type scenarioAccount struct {
+	name    string
+	address module.Address
+}
+
+type scenarioRunner struct {
+	sc       *Scenario
+	sim      Simulator
+	accounts []*scenarioAccount
+	names    map[string]module.Address
+	out      io.Writer
+	reports  []*TermReport
+
+	iscores   map[string]*big.Int
+	penalties map[string]int
+}
+
+// addressOf returns the address of the account named by name, or the
+// address in the name.
+func (r *scenarioRunner) addressOf(name string) (module.Address, error) {
+	if addr, ok := r.names[name]; ok {
+		return addr, nil
+	}
+	addr, err := common.NewAddressFromString(name)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Errorf("UnknownAccount(%s)", name)
+	}
+	return addr, nil
+}
+
+func addressForName(name string) module.Address {
+	return common.NewAccountAddress(crypto.SHA3Sum256([]byte(name))[:common.AddressIDBytes])
+}
+
+func (r *scenarioRunner) init() error {
+	cfg := NewConfig()
+	if len(r.sc.Config) > 0 {
+		if err := json.Unmarshal(r.sc.Config, cfg); err != nil {
+			return errors.Wrap(err, "InvalidConfig")
+		}
+	}
+
+	r.names = make(map[string]module.Address)
+	balances := make(map[string]*big.Int)
+	for _, a := range r.sc.Accounts {
+		var addr module.Address
+		if a.Address != "" {
+			var err error
+			if addr, err = common.NewAddressFromString(a.Address); err != nil {
+				return errors.IllegalArgumentError.Wrapf(err, "InvalidAddress(%s)", a.Address)
+			}
+		} else {
+			addr = addressForName(a.Name)
+		}
+		if _, ok := r.names[a.Name]; ok {
+			return errors.IllegalArgumentError.Errorf("DuplicateAccount(%s)", a.Name)
+		}
+		r.names[a.Name] = addr
+		r.accounts = append(r.accounts, &scenarioAccount{a.Name, addr})
+		if a.Balance != "" {
+			balance, err := a.Balance.BigInt()
+			if err != nil {
+				return err
+			}
+			balances[icutils.ToKey(addr)] = balance
+		}
+	}
+
+	var validators []module.Validator
+	for _, name := range r.sc.Validators {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return err
+		}
+		v, err := state.ValidatorFromAddress(addr)
+		if err != nil {
+			return err
+		}
+		validators = append(validators, v)
+	}
+	if len(validators) == 0 {
+		v, _ := state.ValidatorFromAddress(addressForName("validator"))
+		validators = append(validators, v)
+	}
+
+	r.sim = NewSimulator(icmodule.ValueToRevision(r.sc.Revision), validators, balances, cfg)
+	if r.sim == nil {
+		return errors.InvalidStateError.New("FailToCreateSimulator")
+	}
+	r.iscores = make(map[string]*big.Int)
+	r.penalties = make(map[string]int)
+	return nil
+}
+
+func (r *scenarioRunner) newTransaction(stx *ScenarioTx) (Transaction, error) {
+	txType, err := ParseTxType(stx.Type)
+	if err != nil {
+		return nil, err
+	}
+	var from module.Address
+	switch txType {
+	case TypeSetRevision, TypePenalizeNonvoters, TypeSetNonVoteSlashingRate,
+		TypeSetRewardFundAllocation, TypeSetNetworkScore:
+		// transactions of governance
+	default:
+		// the sender, or the owner of the network for openBTPNetwork
+		if from, err = r.addressOf(stx.From); err != nil {
+			return nil, err
+		}
+	}
+	sim := r.sim
+	switch txType {
+	case TypeSetStake:
+		amount, err := stx.Amount.BigInt()
+		if err != nil {
+			return nil, err
+		}
+		return sim.SetStake(from, amount), nil
+	case TypeSetDelegation:
+		var ds icstate.Delegations
+		for _, v := range stx.Delegations {
+			addr, value, err := r.voteOf(v)
+			if err != nil {
+				return nil, err
+			}
+			ds = append(ds, icstate.NewDelegation(addr, value))
+		}
+		return sim.SetDelegation(from, ds), nil
+	case TypeSetBond:
+		var bonds icstate.Bonds
+		for _, v := range stx.Bonds {
+			addr, value, err := r.voteOf(v)
+			if err != nil {
+				return nil, err
+			}
+			bonds = append(bonds, icstate.NewBond(addr, value))
+		}
+		return sim.SetBond(from, bonds), nil
+	case TypeSetBonderList:
+		var bl icstate.BonderList
+		for _, name := range stx.BonderList {
+			addr, err := r.addressOf(name)
+			if err != nil {
+				return nil, err
+			}
+			bl = append(bl, common.AddressToPtr(addr))
+		}
+		return sim.SetBonderList(from, bl), nil
+	case TypeRegisterPRep, TypeSetPRep:
+		info, err := r.prepInfoOf(stx.From, stx.Info)
+		if err != nil {
+			return nil, err
+		}
+		if txType == TypeRegisterPRep {
+			return sim.RegisterPRep(from, info), nil
+		}
+		return sim.SetPRep(from, info), nil
+	case TypeUnregisterPRep:
+		return sim.UnregisterPRep(from), nil
+	case TypeDisqualifyPRep:
+		addr, err := r.addressOf(stx.Address)
+		if err != nil {
+			return nil, err
+		}
+		return sim.DisqualifyPRep(from, addr), nil
+	case TypeSetRevision:
+		return sim.SetRevision(icmodule.ValueToRevision(stx.Revision)), nil
+	case TypeClaimIScore:
+		return sim.ClaimIScore(from), nil
+	case TypePenalizeNonvoters:
+		var preps []module.Address
+		for _, name := range stx.Addresses {
+			addr, err := r.addressOf(name)
+			if err != nil {
+				return nil, err
+			}
+			preps = append(preps, addr)
+		}
+		return sim.PenalizeNonvoters(preps), nil
+	case TypeSetNonVoteSlashingRate:
+		return sim.SetNonVoteSlashingRate(stx.Rate), nil
+	case TypeSetRewardFundAllocation:
+		if stx.RewardFund == nil {
+			return nil, errors.IllegalArgumentError.New("NoRewardFund")
+		}
+		var values [4]*big.Int
+		for i, v := range []ScenarioValue{
+			stx.RewardFund.Iprep, stx.RewardFund.Icps, stx.RewardFund.Irelay, stx.RewardFund.Ivoter,
+		} {
+			if values[i], err = v.BigInt(); err != nil {
+				return nil, err
+			}
+		}
+		return sim.SetRewardFundAllocation(values[0], values[1], values[2], values[3]), nil
+	case TypeSetNetworkScore:
+		var addr module.Address
+		if stx.Address != "" {
+			if addr, err = r.addressOf(stx.Address); err != nil {
+				return nil, err
+			}
+		}
+		return sim.SetNetworkScore(stx.Role, addr), nil
+	case TypeSetPRepNodePublicKey:
+		pubKey, err := r.pubKeyOf(stx)
+		if err != nil {
+			return nil, err
+		}
+		return sim.SetPRepNodePublicKey(from, pubKey), nil
+	case TypeOpenBTPNetwork:
+		return sim.OpenBTPNetwork(stx.NetworkType, stx.Name, from), nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnsupportedTxType(%s)", stx.Type)
+	}
+}
+
+// pubKeyOf returns the public key in the transaction, or the public key
+// derived from the name of the sender if it's not specified.
+func (r *scenarioRunner) pubKeyOf(stx *ScenarioTx) ([]byte, error) {
+	if stx.PubKey != "" {
+		pubKey, err := hex.DecodeString(strings.TrimPrefix(stx.PubKey, "0x"))
+		if err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidPubKey(%s)", stx.PubKey)
+		}
+		return pubKey, nil
+	}
+	sk, err := crypto.ParsePrivateKey(crypto.SHA3Sum256([]byte("node:" + stx.From)))
+	if err != nil {
+		return nil, err
+	}
+	return sk.PublicKey().SerializeCompressed(), nil
+}
+
+func (r *scenarioRunner) voteOf(v ScenarioVote) (*common.Address, *big.Int, error) {
+	addr, err := r.addressOf(v.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+	value, err := v.Value.BigInt()
+	if err != nil {
+		return nil, nil, err
+	}
+	return common.AddressToPtr(addr), value, nil
+}
+
+func stringOr(s, def string) *string {
+	if s == "" {
+		return &def
+	}
+	return &s
+}
+
+func (r *scenarioRunner) prepInfoOf(name string, si *ScenarioPRepInfo) (*icstate.PRepInfo, error) {
+	if si == nil {
+		si = &ScenarioPRepInfo{}
+	}
+	website := *stringOr(si.Website, fmt.Sprintf("https://%s.example.com/", name))
+	info := &icstate.PRepInfo{
+		Name:        stringOr(si.Name, name),
+		Country:     stringOr(si.Country, "KOR"),
+		City:        stringOr(si.City, "Seoul"),
+		Email:       stringOr(si.Email, fmt.Sprintf("%s@example.com", name)),
+		WebSite:     &website,
+		Details:     stringOr(si.Details, website+"details/"),
+		P2PEndpoint: stringOr(si.P2PEndpoint, fmt.Sprintf("%s.example.com:7100", name)),
+	}
+	if si.Node != "" {
+		node, err := r.addressOf(si.Node)
+		if err != nil {
+			return nil, err
+		}
+		info.Node = node
+	}
+	return info, nil
+}
+
+func (r *scenarioRunner) consensusInfo(absent []string) (module.ConsensusInfo, error) {
+	if len(absent) == 0 {
+		return nil, nil
+	}
+	absentees := make([]module.Address, 0, len(absent))
+	for _, name := range absent {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return nil, err
+		}
+		absentees = append(absentees, addr)
+	}
+	return r.sim.NewConsensusInfo(absentees...)
+}
+
+// advance generates blocks, and reports at the end of each term.
+func (r *scenarioRunner) advance(blocks int64, absent []string) error {
+	for blocks > 0 {
+		n := blocks
+		var term *icstate.TermSnapshot
+		if r.sim.Revision().Value() >= icmodule.RevisionIISS {
+			term = r.sim.TermSnapshot()
+		}
+		if term != nil {
+			if remain := term.GetEndHeight() - r.sim.BlockHeight(); remain > 0 && remain < n {
+				n = remain
+			}
+		}
+		csi, err := r.consensusInfo(absent)
+		if err != nil {
+			return err
+		}
+		if err := r.sim.Go(n, csi); err != nil {
+			return err
+		}
+		blocks -= n
+		if term != nil && r.sim.BlockHeight() == term.GetEndHeight() {
+			r.reportTerm(term)
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) terms(count int64, absent []string) error {
+	if r.sim.Revision().Value() < icmodule.RevisionIISS {
+		return errors.InvalidStateError.Errorf(
+			"NoTermBeforeIISS(rev=%d)", r.sim.Revision().Value())
+	}
+	for i := int64(0); i < count; i++ {
+		blocks := r.sim.TermSnapshot().GetEndHeight() - r.sim.BlockHeight()
+		if err := r.advance(blocks, absent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) reportTerm(term *icstate.TermSnapshot) {
+	report := &TermReport{
+		Sequence:    term.Sequence(),
+		StartHeight: term.StartHeight(),
+		EndHeight:   term.GetEndHeight(),
+		RewardFund:  term.RewardFund().ToJSON(),
+	}
+	for _, a := range r.accounts {
+		key := icutils.ToKey(a.address)
+		if iscore := r.sim.QueryIScore(a.address); iscore != nil && iscore.Sign() > 0 {
+			last, ok := r.iscores[key]
+			if !ok {
+				last = new(big.Int)
+			}
+			report.Rewards = append(report.Rewards, &AccountReward{
+				Name:    a.name,
+				Address: a.address,
+				IScore:  common.NewHexInt(0).SetValue(iscore),
+				Delta:   common.NewHexInt(0).SetValue(new(big.Int).Sub(iscore, last)),
+			})
+			r.iscores[key] = iscore
+		}
+		if r.sim.GetPRep(a.address) == nil {
+			continue
+		}
+		stats := r.sim.GetPRepStats(a.address)
+		penalties, _ := stats["penalties"].(int)
+		if delta := penalties - r.penalties[key]; delta > 0 {
+			grade, _ := stats["grade"].(int)
+			status, _ := stats["status"].(int)
+			report.Penalties = append(report.Penalties, &PRepPenalty{
+				Name:      a.name,
+				Address:   a.address,
+				Grade:     grade,
+				Status:    status,
+				Penalties: penalties,
+				Delta:     delta,
+			})
+		}
+		r.penalties[key] = penalties
+	}
+	r.reports = append(r.reports, report)
+	if r.out != nil {
+		report.Print(r.out)
+	}
+}
+
+func (r *scenarioRunner) runBlock(txs []*ScenarioTx, absent []string) error {
+	block := NewBlock()
+	for _, stx := range txs {
+		tx, err := r.newTransaction(stx)
+		if err != nil {
+			return err
+		}
+		block.AddTransaction(tx)
+	}
+	csi, err := r.consensusInfo(absent)
+	if err != nil {
+		return err
+	}
+	receipts, err := r.sim.GoByBlock(block, csi)
+	if err != nil {
+		return err
+	}
+	for i, rct := range receipts {
+		stx := txs[i]
+		switch stx.Expect {
+		case "", "success":
+			if rct.Status() != Success {
+				return errors.Errorf("TxFailed(idx=%d,type=%s,err=%v)", i, stx.Type, rct.Error())
+			}
+		case "failure":
+			if rct.Status() != Failure {
+				return errors.Errorf("TxNotFailed(idx=%d,type=%s)", i, stx.Type)
+			}
+		default:
+			return errors.IllegalArgumentError.Errorf("InvalidExpect(%s)", stx.Expect)
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) runStep(step *ScenarioStep) error {
+	var err error
+	switch {
+	case len(step.Block) > 0:
+		err = r.runBlock(step.Block, step.Absent)
+	case step.Go > 0:
+		err = r.advance(step.Go, step.Absent)
+	case step.GoTo > 0:
+		if step.GoTo <= r.sim.BlockHeight() {
+			return errors.IllegalArgumentError.Errorf(
+				"InvalidGoTo(cur=%d,new=%d)", r.sim.BlockHeight(), step.GoTo)
+		}
+		err = r.advance(step.GoTo-r.sim.BlockHeight(), step.Absent)
+	case step.Terms > 0:
+		err = r.terms(step.Terms, step.Absent)
+	}
+	if err != nil {
+		return err
+	}
+	if step.Assert != nil {
+		return r.check(step.Assert)
+	}
+	return nil
+}
+
+func (r *scenarioRunner) check(a *ScenarioAssert) error {
+	if a.BlockHeight != "" {
+		if err := compareValue("blockHeight", r.sim.BlockHeight(), a.BlockHeight); err != nil {
+			return err
+		}
+	}
+	checkAccounts := func(kind string, values map[string]ScenarioValue, get func(addr module.Address) interface{}) error {
+		for name, expected := range values {
+			addr, err := r.addressOf(name)
+			if err != nil {
+				return err
+			}
+			if err := compareValue(kind+"."+name, get(addr), expected); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	sim := r.sim
+	if err := checkAccounts("balance", a.Balance, func(addr module.Address) interface{} {
+		return sim.GetBalance(addr)
+	}); err != nil {
+		return err
+	}
+	if err := checkAccounts("stake", a.Stake, func(addr module.Address) interface{} {
+		return sim.GetStake(addr)["stake"]
+	}); err != nil {
+		return err
+	}
+	if err := checkAccounts("iscore", a.IScore, func(addr module.Address) interface{} {
+		return sim.QueryIScore(addr)
+	}); err != nil {
+		return err
+	}
+	if len(a.Term) > 0 {
+		term := sim.GetPRepTerm()
+		for key, expected := range a.Term {
+			if err := compareValue("term."+key, term[key], expected); err != nil {
+				return err
+			}
+		}
+	}
+	if len(a.Network) > 0 {
+		info := sim.GetNetworkInfo()
+		for key, expected := range a.Network {
+			if err := compareValue("network."+key, info[key], expected); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var compareOperators = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+// compareValue compares the value with the expression. Values are compared
+// as integers if both can be parsed as integers, otherwise as strings.
+func compareValue(name string, value interface{}, expr ScenarioValue) error {
+	s := strings.TrimSpace(string(expr))
+	op := "="
+	for _, o := range compareOperators {
+		if strings.HasPrefix(s, o) {
+			op, s = o, strings.TrimSpace(s[len(o):])
+			break
+		}
+	}
+	var actual string
+	switch v := value.(type) {
+	case nil:
+		actual = "0"
+	case *big.Int:
+		if v == nil {
+			actual = "0"
+		} else {
+			actual = v.String()
+		}
+	default:
+		actual = fmt.Sprint(v)
+	}
+
+	var cmp int
+	x, ok1 := new(big.Int).SetString(actual, 0)
+	y, err := ScenarioValue(s).BigInt()
+	if ok1 && err == nil {
+		cmp = x.Cmp(y)
+	} else if op == "=" || op == "!=" {
+		cmp = strings.Compare(actual, s)
+		if cmp != 0 {
+			cmp = 1
+		}
+	} else {
+		return errors.IllegalArgumentError.Errorf(
+			"NotComparable(name=%s,value=%s,expected=%s)", name, actual, expr)
+	}
+
+	var ok bool
+	switch op {
+	case "=":
+		ok = cmp == 0
+	case "!=":
+		ok = cmp != 0
+	case "<":
+		ok = cmp < 0
+	case "<=":
+		ok = cmp <= 0
+	case ">":
+		ok = cmp > 0
+	case ">=":
+		ok = cmp >= 0
+	}
+	if !ok {
+		return errors.Errorf("AssertionFailed(name=%s,value=%s,expected=%s)", name, actual, expr)
+	}
+	return nil
+}
+
+// Run runs the scenario and returns the reports of terms. If out is not
+// nil, reports are printed to out at the end of each term.
+func (sc *Scenario) Run(out io.Writer) ([]*TermReport, error) {
+	r := &scenarioRunner{sc: sc, out: out}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+	for i, step := range sc.Steps {
+		if err := r.runStep(step); err != nil {
+			name := step.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return r.reports, errors.Wrapf(err, "FailInStep(step=%s,height=%d)", name, r.sim.BlockHeight())
+		}
+	}
+	return r.reports, nil
+}
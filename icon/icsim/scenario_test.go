@@ -0,0 +1,180 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package icsim
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScenario = `
+revision: 14
+config:
+  termPeriod: 10
+  mainPRepCount: 2
+  subPRepCount: 0
+  validationPenaltyCondition: 5
+accounts:
+  - { name: prep1, balance: 2000icx }
+  - { name: prep2, balance: 2000icx }
+  - { name: user1, balance: 10000icx }
+  - { name: user2, balance: 10000icx }
+steps:
+  - name: register
+    block:
+      - { type: registerPRep, from: prep1 }
+      - { type: registerPRep, from: prep2 }
+      - { type: setStake, from: user1, amount: 5000icx }
+      - { type: setStake, from: user2, amount: 5000icx }
+      - { type: unregisterPRep, from: user1, expect: failure }
+    assert:
+      stake: { user1: 5000icx, user2: 5000icx }
+      balance: { prep1: "<2000icx" }
+  - name: bond
+    block:
+      - { type: setStake, from: prep1, amount: 1000icx }
+      - { type: setStake, from: prep2, amount: 1000icx }
+      - { type: setBonderList, from: prep1, bonderList: [ prep1 ] }
+      - { type: setBonderList, from: prep2, bonderList: [ prep2 ] }
+  - name: delegate
+    block:
+      - type: setBond
+        from: prep1
+        bonds: [ { address: prep1, value: 1000icx } ]
+      - type: setBond
+        from: prep2
+        bonds: [ { address: prep2, value: 1000icx } ]
+      - type: setDelegation
+        from: user1
+        delegations: [ { address: prep1, value: 5000icx } ]
+      - type: setDelegation
+        from: user2
+        delegations: [ { address: prep2, value: 5000icx } ]
+  - name: decentralize
+    terms: 2
+  - name: penalty
+    terms: 2
+    absent: [ prep1 ]
+    assert:
+      blockHeight: 40
+      term: { sequence: 3 }
+`
+
+func TestScenario_Run(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scenario.yml")
+	assert.NoError(t, os.WriteFile(file, []byte(testScenario), 0644))
+
+	sc, err := LoadScenario(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(sc.Accounts))
+	assert.Equal(t, 5, len(sc.Steps))
+
+	out := new(bytes.Buffer)
+	reports, err := sc.Run(out)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(reports))
+	assert.Equal(t, int64(40), reports[3].EndHeight)
+	assert.Contains(t, out.String(), "Term #2 [31, 40]")
+	assert.Contains(t, out.String(), "penalty prep1")
+}
+
+const testGovernanceScenario = `
+revision: 13
+config:
+  termPeriod: 10
+  mainPRepCount: 2
+  subPRepCount: 0
+accounts:
+  - { name: prep1, balance: 2000icx }
+  - { name: prep2, balance: 2000icx }
+  - { name: user1, balance: 10000icx }
+  - { name: cps, address: cx0000000000000000000000000000000000000100 }
+steps:
+  - block:
+      - { type: registerPRep, from: prep1 }
+      - { type: registerPRep, from: prep2 }
+      - { type: setStake, from: user1, amount: 5000icx }
+  - block:
+      - type: setDelegation
+        from: user1
+        delegations: [ { address: prep1, value: 3000icx }, { address: prep2, value: 2000icx } ]
+  - terms: 2
+  - name: governance
+    block:
+      - { type: setNonVoteSlashingRate, rate: 10 }
+      - { type: setNonVoteSlashingRate, rate: 200, expect: failure }
+      - { type: setRewardFundAllocation, rewardFund: { iprep: 40, icps: 10, irelay: 10, ivoter: 40 } }
+      - { type: setNetworkScore, role: cps, address: cps }
+      - { type: penalizeNonvoters, addresses: [ prep2 ] }
+      - { type: penalizeNonvoters, addresses: [ user1 ], expect: failure }
+    assert:
+      network: { proposalNonVotePenaltySlashRatio: 10 }
+  - name: btp
+    block:
+      - { type: setPRepNodePublicKey, from: prep1 }
+      - { type: setPRepNodePublicKey, from: prep2 }
+      - { type: openBTPNetwork, from: user1, networkType: eth, name: test, expect: failure }
+  - terms: 1
+  - block:
+      - { type: openBTPNetwork, from: user1, networkType: eth, name: test }
+`
+
+func TestScenario_GovernanceTxs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scenario.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(testGovernanceScenario), 0644))
+
+	sc, err := LoadScenario(file)
+	assert.NoError(t, err)
+	_, err = sc.Run(nil)
+	assert.NoError(t, err)
+}
+
+func TestScenario_RunFailure(t *testing.T) {
+	sc := &Scenario{
+		Revision: 13,
+		Accounts: []ScenarioAccount{{Name: "user1", Balance: "100icx"}},
+		Steps: []*ScenarioStep{
+			{
+				Name:  "stake",
+				Block: []*ScenarioTx{{Type: "setStake", From: "user1", Amount: "50icx"}},
+				Assert: &ScenarioAssert{
+					Stake: map[string]ScenarioValue{"user1": "60icx"},
+				},
+			},
+		},
+	}
+	_, err := sc.Run(nil)
+	assert.Error(t, err)
+
+	sc.Steps[0].Block[0].Type = "unknownTx"
+	_, err = sc.Run(nil)
+	assert.Error(t, err)
+}
+
+func TestCompareValue(t *testing.T) {
+	assert.NoError(t, compareValue("v", 10, "10"))
+	assert.NoError(t, compareValue("v", 10, ">= 10"))
+	assert.NoError(t, compareValue("v", 10, "!=11"))
+	assert.NoError(t, compareValue("v", "1000000000000000000", "1icx"))
+	assert.NoError(t, compareValue("v", nil, "0"))
+	assert.NoError(t, compareValue("v", true, "true"))
+	assert.Error(t, compareValue("v", 10, "<10"))
+	assert.Error(t, compareValue("v", "abc", ">1"))
+}
@@ -17,9 +17,11 @@
 package icsim
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/module"
 )
@@ -36,8 +38,50 @@ const (
 	TypeSetPRep
 	TypeSetRevision
 	TypeClaimIScore
+	TypePenalizeNonvoters
+	TypeSetNonVoteSlashingRate
+	TypeSetRewardFundAllocation
+	TypeSetNetworkScore
+	TypeSetPRepNodePublicKey
+	TypeOpenBTPNetwork
 )
 
+var txTypeNames = []string{
+	"setStake",
+	"setDelegation",
+	"setBond",
+	"setBonderList",
+	"registerPRep",
+	"unregisterPRep",
+	"disqualifyPRep",
+	"setPRep",
+	"setRevision",
+	"claimIScore",
+	"penalizeNonvoters",
+	"setNonVoteSlashingRate",
+	"setRewardFundAllocation",
+	"setNetworkScore",
+	"setPRepNodePublicKey",
+	"openBTPNetwork",
+}
+
+func (t TxType) String() string {
+	if t >= 0 && int(t) < len(txTypeNames) {
+		return txTypeNames[t]
+	}
+	return fmt.Sprintf("TxType(%d)", int(t))
+}
+
+// ParseTxType returns TxType for the name returned by TxType.String().
+func ParseTxType(name string) (TxType, error) {
+	for i, n := range txTypeNames {
+		if n == name {
+			return TxType(i), nil
+		}
+	}
+	return 0, errors.IllegalArgumentError.Errorf("UnknownTxType(%s)", name)
+}
+
 type Transaction interface {
 	Type() TxType
 	Args() []interface{}
@@ -113,4 +157,23 @@ type Simulator interface {
 	RegisterPRep(from module.Address, info *icstate.PRepInfo) Transaction
 	UnregisterPRep(from module.Address) Transaction
 	DisqualifyPRep(from module.Address, address module.Address) Transaction
+
+	// NewConsensusInfo returns ConsensusInfo of the current validators.
+	// Validators in absentees are regarded as ones which failed to vote for
+	// the previous block, so it can be used to inject validation failures.
+	NewConsensusInfo(absentees ...module.Address) (module.ConsensusInfo, error)
+
+	// PenalizeNonvoters imposes non-vote penalties on preps which missed
+	// votes for network proposals.
+	PenalizeNonvoters(preps []module.Address) Transaction
+	SetNonVoteSlashingRate(rate int) Transaction
+	SetRewardFundAllocation(iprep, icps, irelay, ivoter *big.Int) Transaction
+
+	SetNetworkScore(role string, address module.Address) Transaction
+	GetNetworkScores() map[string]interface{}
+
+	SetPRepNodePublicKey(from module.Address, pubKey []byte) Transaction
+	GetPRepNodePublicKey(address module.Address) []byte
+	OpenBTPNetwork(networkTypeName, name string, owner module.Address) Transaction
+	GetBTPNetworkTypeID(name string) int64
 }
@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -31,6 +32,8 @@ import (
 	"github.com/icon-project/goloop/service/state"
 )
 
+const iconDSA = "ecdsa/secp256k1"
+
 func newWorldState(wss state.WorldSnapshot, readonly bool) state.WorldState {
 	if readonly {
 		return state.NewReadOnlyWorldState(wss)
@@ -311,7 +314,7 @@ func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Re
 
 	for i, tx := range block.Txs() {
 		wss = ws.GetSnapshot()
-		err = sim.executeTx(csi, ws, tx)
+		err = sim.executeTx(csi, ws, i, tx)
 		receipts[i] = NewReceipt(blockHeight, err)
 
 		if err != nil {
@@ -338,10 +341,11 @@ func (sim *simulatorImpl) GoByTransaction(tx Transaction, csi module.ConsensusIn
 	return sim.GoByBlock(block, csi)
 }
 
-func (sim *simulatorImpl) executeTx(csi module.ConsensusInfo, ws state.WorldState, tx Transaction) error {
+func (sim *simulatorImpl) executeTx(csi module.ConsensusInfo, ws state.WorldState, index int, tx Transaction) error {
 	var err error
 	revision := sim.revision
 	wc := NewWorldContext(ws, sim.blockHeight+1, revision, csi, sim.stepPrice)
+	wc.SetTransactionInfo(&state.TransactionInfo{Index: int32(index)})
 	es := wc.GetExtensionState().(*iiss.ExtensionStateImpl)
 
 	switch tx.Type() {
@@ -365,6 +369,18 @@ func (sim *simulatorImpl) executeTx(csi module.ConsensusInfo, ws state.WorldStat
 		err = sim.setRevision(wc, tx)
 	case TypeClaimIScore:
 		err = sim.claimIScore(es, wc, tx)
+	case TypePenalizeNonvoters:
+		err = sim.penalizeNonvoters(es, wc, tx)
+	case TypeSetNonVoteSlashingRate:
+		err = sim.setNonVoteSlashingRate(es, tx)
+	case TypeSetRewardFundAllocation:
+		err = sim.setRewardFundAllocation(es, tx)
+	case TypeSetNetworkScore:
+		err = sim.setNetworkScore(es, tx)
+	case TypeSetPRepNodePublicKey:
+		err = sim.setPRepNodePublicKey(es, wc, tx)
+	case TypeOpenBTPNetwork:
+		err = sim.openBTPNetwork(es, wc, tx)
 	default:
 		return errors.Errorf("Unexpected transaction: %v", tx.Type())
 	}
@@ -541,6 +557,164 @@ func (sim *simulatorImpl) GetNetworkInfo() map[string]interface{} {
 	return jso
 }
 
+func (sim *simulatorImpl) NewConsensusInfo(absentees ...module.Address) (module.ConsensusInfo, error) {
+	vl := sim.ValidatorList()
+	if len(vl) == 0 {
+		return nil, errors.InvalidStateError.New("NoValidators")
+	}
+	voted := make([]bool, len(vl))
+	for i, v := range vl {
+		voted[i] = true
+		for _, absentee := range absentees {
+			if v.Address().Equal(absentee) {
+				voted[i] = false
+				break
+			}
+		}
+	}
+	vss, err := state.ValidatorSnapshotFromSlice(sim.Database(), vl)
+	if err != nil {
+		return nil, err
+	}
+	proposer, _ := vss.Get(vss.Len() - 1)
+	return common.NewConsensusInfo(proposer.Address(), vss, voted), nil
+}
+
+func (sim *simulatorImpl) PenalizeNonvoters(preps []module.Address) Transaction {
+	return NewTransaction(TypePenalizeNonvoters, []interface{}{preps})
+}
+
+func (sim *simulatorImpl) penalizeNonvoters(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	preps := args[0].([]module.Address)
+	cc := NewCallContext(wc, governance)
+	for _, prep := range preps {
+		if es.State.GetPRepStatusByOwner(prep, false) == nil {
+			return errors.IllegalArgumentError.Errorf("NotPRep(%s)", prep)
+		}
+		if err := es.PenalizeNonVoters(cc, prep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sim *simulatorImpl) SetNonVoteSlashingRate(rate int) Transaction {
+	return NewTransaction(TypeSetNonVoteSlashingRate, []interface{}{rate})
+}
+
+func (sim *simulatorImpl) setNonVoteSlashingRate(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	rate := args[0].(int)
+	return es.State.SetNonVotePenaltySlashRatio(rate)
+}
+
+func (sim *simulatorImpl) SetRewardFundAllocation(iprep, icps, irelay, ivoter *big.Int) Transaction {
+	return NewTransaction(TypeSetRewardFundAllocation, []interface{}{iprep, icps, irelay, ivoter})
+}
+
+func (sim *simulatorImpl) setRewardFundAllocation(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	rf := es.State.GetRewardFund()
+	rf.Iprep = args[0].(*big.Int)
+	rf.Icps = args[1].(*big.Int)
+	rf.Irelay = args[2].(*big.Int)
+	rf.Ivoter = args[3].(*big.Int)
+	return es.State.SetRewardFund(rf)
+}
+
+func (sim *simulatorImpl) SetNetworkScore(role string, address module.Address) Transaction {
+	return NewTransaction(TypeSetNetworkScore, []interface{}{role, address})
+}
+
+func (sim *simulatorImpl) setNetworkScore(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	role := args[0].(string)
+	address, _ := args[1].(module.Address)
+	return es.State.SetNetworkScore(role, address)
+}
+
+func (sim *simulatorImpl) GetNetworkScores() map[string]interface{} {
+	es := sim.getExtensionState(true)
+	jso := make(map[string]interface{})
+	for k, v := range es.State.GetNetworkScores(sim.newCallContext()) {
+		jso[k] = v
+	}
+	return jso
+}
+
+func (sim *simulatorImpl) SetPRepNodePublicKey(from module.Address, pubKey []byte) Transaction {
+	return NewTransaction(TypeSetPRepNodePublicKey, []interface{}{from, pubKey})
+}
+
+func (sim *simulatorImpl) setPRepNodePublicKey(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	pubKey := args[1].([]byte)
+
+	prep := es.GetPRep(from)
+	if prep == nil {
+		return errors.IllegalArgumentError.Errorf("NotPRep(%s)", from)
+	}
+	pk, err := crypto.ParsePublicKey(pubKey)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "InvalidPublicKey")
+	}
+	pubKeyAddr := common.NewAccountAddressFromPublicKey(pk)
+
+	cc := NewCallContext(wc, from)
+	bc := wc.GetBTPContext()
+	bs := wc.(state.WorldState).GetBTPState().(*state.BTPStateImpl)
+	if nodeAddress := prep.NodeAddress(); !pubKeyAddr.Equal(nodeAddress) {
+		if err = bs.SetPublicKey(bc, nodeAddress, iconDSA, []byte{}); err != nil {
+			return err
+		}
+		if err = es.SetPRep(cc, &icstate.PRepInfo{Node: pubKeyAddr}, true); err != nil {
+			return err
+		}
+	}
+	if err = bs.SetPublicKey(bc, pubKeyAddr, iconDSA, pubKey); err != nil {
+		return err
+	}
+	prep.SetDSAMask(bc.GetPublicKeyMask(pubKeyAddr))
+	return es.OnSetPublicKey(cc, prep.Owner(), bc.GetDSAIndex(iconDSA))
+}
+
+func (sim *simulatorImpl) GetPRepNodePublicKey(address module.Address) []byte {
+	es := sim.getExtensionState(true)
+	prep := es.GetPRep(address)
+	if prep == nil {
+		return nil
+	}
+	return sim.newCallContext().GetBTPContext().GetPublicKey(prep.NodeAddress(), iconDSA)
+}
+
+func (sim *simulatorImpl) OpenBTPNetwork(networkTypeName, name string, owner module.Address) Transaction {
+	return NewTransaction(TypeOpenBTPNetwork, []interface{}{networkTypeName, name, owner})
+}
+
+func (sim *simulatorImpl) openBTPNetwork(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	networkTypeName := args[0].(string)
+	name := args[1].(string)
+	owner := args[2].(module.Address)
+
+	bc := wc.GetBTPContext()
+	bs := wc.(state.WorldState).GetBTPState().(*state.BTPStateImpl)
+	ntActivated := bc.GetNetworkTypeIDByName(networkTypeName) <= 0
+	if _, _, err := bs.OpenNetwork(bc, networkTypeName, name, owner); err != nil {
+		return err
+	}
+	if ntActivated {
+		return es.OnOpenBTPNetwork(NewCallContext(wc, governance), bc, networkTypeName)
+	}
+	return nil
+}
+
+func (sim *simulatorImpl) GetBTPNetworkTypeID(name string) int64 {
+	return sim.newCallContext().GetBTPContext().GetNetworkTypeIDByName(name)
+}
+
 func (sim *simulatorImpl) TermSnapshot() *icstate.TermSnapshot {
 	es := sim.getExtensionState(true)
 	return es.State.GetTermSnapshot()
@@ -377,6 +377,22 @@ func (sm *ServiceManager) GetSCOREStatus(result []byte, addr module.Address) (mo
 	return nil, common.ErrInvalidState
 }
 
+func (sm *ServiceManager) GetProof(result []byte, addr module.Address, keys [][]byte) (module.AccountProof, error) {
+	return nil, common.ErrInvalidState
+}
+
+func (sm *ServiceManager) GetStateDiff(result1, result2 []byte, addrs []module.Address) (module.StateDiff, error) {
+	return nil, common.ErrInvalidState
+}
+
+func (sm *ServiceManager) GetAccounts(orderBy string, skip, limit int) (module.AccountList, error) {
+	return nil, common.ErrInvalidState
+}
+
+func (sm *ServiceManager) GetDelegators(addr module.Address, skip, limit int) (module.AccountList, error) {
+	return nil, common.ErrInvalidState
+}
+
 func NewServiceManagerWithExecutor(chain module.Chain, ex *Executor, ps BlockV1ProofStorage, vs []*common.Address, cb ImportCallback) (*ServiceManager, error) {
 	logger := chain.Logger()
 	dbase := chain.Database()
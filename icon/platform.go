@@ -119,6 +119,30 @@ func (p *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot,
 	p.calculator.Start(ess, logger)
 }
 
+func (p *platform) GetAccountStatus(ess state.ExtensionSnapshot, addr module.Address) (*base.AccountStatus, error) {
+	esi, ok := ess.(*iiss.ExtensionSnapshotImpl)
+	if !ok {
+		return nil, nil
+	}
+	es := esi.NewState(true).(*iiss.ExtensionStateImpl)
+	as := es.State.GetAccountSnapshot(addr)
+	if as == nil {
+		return nil, nil
+	}
+	status := &base.AccountStatus{
+		Stake:      as.Stake(),
+		Delegation: as.Delegating(),
+		Bond:       as.Bond(),
+	}
+	for _, d := range as.Delegations() {
+		status.Delegations = append(status.Delegations, base.AccountVote{
+			Address: d.To(),
+			Value:   d.Amount(),
+		})
+	}
+	return status, nil
+}
+
 func checkBaseTX(txs module.TransactionList) bool {
 	tx, err := txs.Get(0)
 	if err == nil {
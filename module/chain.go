@@ -38,6 +38,10 @@ type Chain interface {
 	ChildrenLimit() int
 	NephewsLimit() int
 	ValidateTxOnSend() bool
+	AccountIndex() bool
+	// StateRetention returns the number of recent blocks whose states are
+	// kept. States of all blocks are kept if it's zero.
+	StateRetention() int64
 	Genesis() []byte
 	GenesisStorage() GenesisStorage
 	CommitVoteSetDecoder() CommitVoteSetDecoder
@@ -56,6 +60,8 @@ type Chain interface {
 	Import(src string, height int64) error
 	Prune(gs string, dbt string, height int64) error
 	Backup(file string, extra []string) error
+	OnlineBackup(file string, extra []string) error
+	IncrementalBackup(file string, base int64) error
 	RunTask(task string, params json.RawMessage) error
 	Term() error
 	State() (string, int64, error)
@@ -36,6 +36,10 @@ const (
 	PurgeEnumCache
 	ContractSetEvent
 	FixMapValues
+	MultiCallTransaction
+	SponsoredTransaction
+	GoExecutionEngine
+	WasmExecutionEngine
 	LastRevisionBit
 )
 
@@ -242,6 +242,23 @@ type SCOREStatus interface {
 	ToJSON(height int64, version JSONVersion) (interface{}, error)
 }
 
+// AccountProof has proofs of the account and its storage values against
+// the state hash of the result.
+type AccountProof interface {
+	ToJSON(version JSONVersion) (interface{}, error)
+}
+
+// StateDiff has differences of accounts, balances and storage values
+// between two world states.
+type StateDiff interface {
+	ToJSON(version JSONVersion) (interface{}, error)
+}
+
+// AccountList is a page of accounts in the account index.
+type AccountList interface {
+	ToJSON(version JSONVersion) (interface{}, error)
+}
+
 // Options for finalize
 const (
 	FinalizeNormalTransaction = 1 << iota
@@ -353,6 +370,22 @@ type ServiceManager interface {
 	// GetSCOREStatus returns status of the contract
 	GetSCOREStatus(result []byte, addr Address) (SCOREStatus, error)
 
+	// GetProof returns proofs of the account and its storage values for
+	// the keys
+	GetProof(result []byte, addr Address, keys [][]byte) (AccountProof, error)
+
+	// GetStateDiff returns differences of accounts between world states of
+	// two results. If addrs is not empty, only the accounts are compared.
+	GetStateDiff(result1, result2 []byte, addrs []Address) (StateDiff, error)
+
+	// GetAccounts returns a page of accounts in the account index ordered
+	// by orderBy ("address", "balance", "stake", "delegation" or "bond").
+	GetAccounts(orderBy string, skip, limit int) (AccountList, error)
+
+	// GetDelegators returns a page of accounts delegating to the address
+	// in the account index.
+	GetDelegators(addr Address, skip, limit int) (AccountList, error)
+
 	// GetMembers returns network member list
 	GetMembers(result []byte) (MemberList, error)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -94,12 +95,19 @@ func (a *Authenticator) onPacket(pkt *Packet, p *Peer) {
 	}
 }
 
-func (a *Authenticator) Signature(content []byte) []byte {
+// Signature signs the content for authentication. The wallet gets the
+// context of it if it signs with contexts.
+func (a *Authenticator) Signature(content []byte) ([]byte, error) {
 	defer a.mtx.Unlock()
 	a.mtx.Lock()
 	h := crypto.SHA3Sum256(content)
-	sb, _ := a.wallet.Sign(h)
-	return sb
+	if cs, ok := a.wallet.(wallet.ContextSigner); ok {
+		return cs.SignWithContext(h, &wallet.SignContext{
+			Kind:    wallet.SignKindPeerAuth,
+			Message: content,
+		})
+	}
+	return a.wallet.Sign(h)
 }
 
 func (a *Authenticator) VerifySignature(publicKey []byte, signature []byte, content []byte) (module.PeerID, error) {
@@ -383,9 +391,15 @@ func (a *Authenticator) handleSecureResponse(pkt *Packet, p *Peer) {
 		return
 	}
 
+	sig, err := a.Signature(p.secureKey.extra)
+	if err != nil {
+		a.logger.Warnln("handleSecureResponse", p.ConnString(), "failed Signature", err)
+		p.CloseByError(err)
+		return
+	}
 	m := &SignatureRequest{
 		PublicKey: a.wallet.PublicKey(),
-		Signature: a.Signature(p.secureKey.extra),
+		Signature: sig,
 		Rtt:       rttLast,
 	}
 	a.setWaitInfo(p2pProtoAuthSignatureResponse, p)
@@ -409,9 +423,15 @@ func (a *Authenticator) handleSignatureRequest(pkt *Packet, p *Peer) {
 		a.logger.Debugln("handleSignatureRequest", df, "DefaultRttAccuracy", DefaultRttAccuracy)
 	}
 
+	sig, err := a.Signature(p.secureKey.extra)
+	if err != nil {
+		a.logger.Warnln("handleSignatureRequest", p.ConnString(), "failed Signature", err)
+		p.CloseByError(err)
+		return
+	}
 	m := &SignatureResponse{
 		PublicKey: a.wallet.PublicKey(),
-		Signature: a.Signature(p.secureKey.extra),
+		Signature: sig,
 		Rtt:       rttLast,
 	}
 
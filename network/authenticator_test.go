@@ -6,6 +6,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -14,6 +16,45 @@ const (
 	testSecureAeadSuite = SecureAeadSuiteAes256Gcm + 1
 )
 
+type contextWallet struct {
+	module.Wallet
+	ctx *wallet.SignContext
+	err error
+}
+
+func (w *contextWallet) SignWithContext(data []byte, ctx *wallet.SignContext) ([]byte, error) {
+	w.ctx = ctx
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.Wallet.Sign(data)
+}
+
+func mustSignature(t *testing.T, a *Authenticator, content []byte) []byte {
+	sig, err := a.Signature(content)
+	assert.NoError(t, err)
+	return sig
+}
+
+func Test_AuthenticatorSignWithContext(t *testing.T) {
+	w := &contextWallet{Wallet: walletFromGeneratedPrivateKey()}
+	a := newAuthenticator(w, testLogger())
+
+	b := make([]byte, wallet.PeerAuthMessageSize)
+	s, err := a.Signature(b)
+	assert.NoError(t, err)
+	assert.Equal(t, &wallet.SignContext{
+		Kind:    wallet.SignKindPeerAuth,
+		Message: b,
+	}, w.ctx)
+	_, err = a.VerifySignature(w.PublicKey(), s, b)
+	assert.NoError(t, err)
+
+	w.err = errors.InvalidStateError.New("Refused")
+	_, err = a.Signature(b)
+	assert.Error(t, err)
+}
+
 func Test_Authenticator(t *testing.T) {
 	w := walletFromGeneratedPrivateKey()
 	a := newAuthenticator(w, testLogger())
@@ -24,7 +65,8 @@ func Test_Authenticator(t *testing.T) {
 
 	p := w.PublicKey()
 	b := []byte("test")
-	s := a.Signature(b)
+	s, err := a.Signature(b)
+	assert.NoError(t, err)
 	pid, err := a.VerifySignature(p, s, b)
 	assert.NoError(t, err)
 	assert.Equal(t, NewPeerIDFromAddress(w.Address()), pid)
@@ -36,7 +78,9 @@ func Test_Authenticator(t *testing.T) {
 	_, err = a.VerifySignature(p, s[:0], b)
 	assert.Error(t, err)
 	//fail to verify signature
-	_, err = a.VerifySignature(p, a.Signature(b[:0]), b)
+	s2, err := a.Signature(b[:0])
+	assert.NoError(t, err)
+	_, err = a.VerifySignature(p, s2, b)
 	assert.Error(t, err)
 
 	sss := []SecureSuite{
@@ -172,7 +216,7 @@ func Test_Authenticator_Request(t *testing.T) {
 		{
 			givenSignatureRequest: &SignatureRequest{
 				PublicKey: w.PublicKey(),
-				Signature: a.Signature([]byte{0x00}),
+				Signature: mustSignature(t, a, []byte{0x00}),
 				Rtt:       0,
 			},
 			expectSignatureResponse: &SignatureResponse{
@@ -288,7 +332,7 @@ func Test_Authenticator_Response(t *testing.T) {
 		{
 			givenSignatureResponse: &SignatureResponse{
 				PublicKey: []byte{0x00},
-				Signature: a.Signature(sk.extra),
+				Signature: mustSignature(t, a, sk.extra),
 				Rtt:       0,
 			},
 		},
@@ -302,7 +346,7 @@ func Test_Authenticator_Response(t *testing.T) {
 		{
 			givenSignatureResponse: &SignatureResponse{
 				PublicKey: w.PublicKey(),
-				Signature: a.Signature(sk.extra),
+				Signature: mustSignature(t, a, sk.extra),
 				Rtt:       0,
 			},
 		},
@@ -346,7 +390,7 @@ func Test_Authenticator_Response(t *testing.T) {
 				assert.FailNow(t, err.Error())
 			}
 			assert.Equal(t, w.PublicKey(), actualSignatureRequest.PublicKey)
-			assert.Equal(t, a.Signature(p.secureKey.extra), actualSignatureRequest.Signature)
+			assert.Equal(t, mustSignature(t, a, p.secureKey.extra), actualSignatureRequest.Signature)
 			last, _ := p.rtt.Value()
 			assert.Equal(t, last, actualSignatureRequest.Rtt)
 
@@ -103,11 +103,13 @@ func (c *StaticConfig) AbsBaseDir() string {
 }
 
 const (
-	DefaultEEInstances = 1
+	DefaultEEInstances       = 1
+	DefaultEEWatchdogTimeout = "0s"
 )
 
 type RuntimeConfig struct {
 	EEInstances       int    `json:"eeInstances"`
+	EEWatchdogTimeout string `json:"eeWatchdogTimeout"`
 	RPCDefaultChannel string `json:"rpcDefaultChannel"`
 	RPCIncludeDebug   bool   `json:"rpcIncludeDebug"`
 	RPCRosetta        bool   `json:"rpcRosetta"`
@@ -146,10 +148,11 @@ func (c *RuntimeConfig) save() error {
 
 func loadRuntimeConfig(baseDir string) (*RuntimeConfig, error) {
 	cfg := &RuntimeConfig{
-		EEInstances:   DefaultEEInstances,
-		RPCBatchLimit: jsonrpc.DefaultBatchLimit,
-		FilePath:      path.Join(baseDir, "rconfig.json"),
-		WSMaxSession:  server.DefaultWSMaxSession,
+		EEInstances:       DefaultEEInstances,
+		EEWatchdogTimeout: DefaultEEWatchdogTimeout,
+		RPCBatchLimit:     jsonrpc.DefaultBatchLimit,
+		FilePath:          path.Join(baseDir, "rconfig.json"),
+		WSMaxSession:      server.DefaultWSMaxSession,
 	}
 	if err := cfg.load(); err != nil {
 		if os.IsNotExist(err) {
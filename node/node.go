@@ -1,6 +1,7 @@
 package node
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -35,6 +36,7 @@ type Node struct {
 	srv  *server.Manager
 	pm   eeproxy.Manager
 	rsm  RestoreManager
+	sch  *Scheduler
 	cfg  StaticConfig
 	rcfg *RuntimeConfig
 
@@ -52,6 +54,24 @@ type Chain struct {
 	module.Chain
 	cfg     *chain.Config
 	refresh bool
+
+	// lock protects schedules in cfg, which are read by the scheduler
+	// while they are configured.
+	lock sync.Mutex
+}
+
+// Schedules returns schedules of the chain. The slice is replaced on
+// configuration, so it's safe to be used without the lock.
+func (c *Chain) Schedules() []chain.Schedule {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cfg.Schedules
+}
+
+func (c *Chain) setSchedules(schedules []chain.Schedule) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cfg.Schedules = schedules
 }
 
 func (n *Node) loadChainConfig(chainDir string) (*chain.Config, error) {
@@ -229,7 +249,10 @@ func (n *Node) _add(cfg *chain.Config) (module.Chain, error) {
 		return nil, err
 	}
 
-	c := &Chain{chain.NewChain(n.w, n.nt, n.srv, n.pm, n.logger, cfg), cfg, false}
+	c := &Chain{
+		Chain: chain.NewChain(n.w, n.nt, n.srv, n.pm, n.logger, cfg),
+		cfg:   cfg,
+	}
 	if err := c.Init(); err != nil {
 		return nil, err
 	}
@@ -335,6 +358,8 @@ func (n *Node) Start() {
 		}
 	}()
 
+	n.sch.Start()
+
 	if err := n.cliSrv.Start(); err != nil {
 		log.Panicf("fail to cli server start err=%+v", err)
 	}
@@ -342,6 +367,7 @@ func (n *Node) Start() {
 }
 
 func (n *Node) Stop() {
+	n.sch.Stop()
 	if err := n.nt.Close(); err != nil {
 		log.Panicf("fail to P2P close err=%+v", err)
 	}
@@ -381,6 +407,9 @@ func (n *Node) JoinChain(
 	if err := n._canAdd(cid, nid, channel, false); err != nil {
 		return nil, err
 	}
+	if err := chain.ValidateSchedules(p.Schedules); err != nil {
+		return nil, err
+	}
 
 	chainDir, err := n._mkChainDir(cid)
 	if err != nil {
@@ -414,6 +443,10 @@ func (n *Node) JoinChain(
 		ChildrenLimit:    p.ChildrenLimit,
 		NephewsLimit:     p.NephewsLimit,
 		ValidateTxOnSend: p.ValidateTxOnSend,
+		StateRetention:   p.StateRetention,
+		StatePinned:      p.StatePinned,
+		AccountIndex:     p.AccountIndex,
+		Schedules:        p.Schedules,
 	}
 
 	if err := cfg.Save(); err != nil {
@@ -530,7 +563,7 @@ func (n *Node) PruneChain(cid int, dbt string, height int64) error {
 	return c.Prune(gs, dbt, height)
 }
 
-func (n *Node) BackupChain(cid int, manual bool) (string, error) {
+func (n *Node) BackupChain(cid int, manual, online bool, base string) (string, error) {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
 
@@ -540,9 +573,31 @@ func (n *Node) BackupChain(cid int, manual bool) (string, error) {
 	}
 
 	if manual {
+		if online || len(base) > 0 {
+			return "", errors.IllegalArgumentError.New(
+				"ManualBackupCanNotBeOnlineOrIncremental")
+		}
 		return "manual", c.Backup("", nil)
 	}
 	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	var baseInfo *chain.BackupInfo
+	if len(base) > 0 {
+		if online {
+			return "", errors.IllegalArgumentError.New(
+				"IncrementalBackupCanNotBeOnline")
+		}
+		baseInfo, err = chain.GetBackupInfoOf(path.Join(backupDir, path.Base(base)))
+		if err != nil {
+			return "", errors.IllegalArgumentError.Wrapf(err,
+				"InvalidBaseBackup(name=%s)", base)
+		}
+		if int(baseInfo.CID.Value) != c.CID() || int(baseInfo.NID.Value) != c.NID() ||
+			baseInfo.Channel != c.Channel() {
+			return "", errors.IllegalArgumentError.Errorf(
+				"BaseBackupForOtherChain(name=%s,cid=%#x,channel=%s)",
+				base, baseInfo.CID.Value, baseInfo.Channel)
+		}
+	}
 	if err := os.MkdirAll(backupDir, 0700); err != nil {
 		return "", errors.InvalidStateError.Wrapf(err,
 			"Fail to make backup directory=%s", backupDir)
@@ -551,20 +606,27 @@ func (n *Node) BackupChain(cid int, manual bool) (string, error) {
 	name := fmt.Sprintf("%#x_%#x_%s_%s.zip", c.CID(), c.NID(), c.Channel(),
 		now.Format("20060102-150405"))
 	file := path.Join(backupDir, name)
-	return name, c.Backup(file, []string{ChainGenesisZipFileName, ChainConfigFileName})
+	if baseInfo != nil {
+		return name, c.IncrementalBackup(file, baseInfo.Height)
+	}
+	extra := []string{ChainGenesisZipFileName, ChainConfigFileName}
+	if online {
+		return name, c.OnlineBackup(file, extra)
+	}
+	return name, c.Backup(file, extra)
 }
 
 type BackupInfo struct {
 	Name string `json:"name"`
 	Size int64  `json:"size"`
 	chain.BackupInfo
-}
 
-func (n *Node) GetBackups() ([]BackupInfo, error) {
-	n.mtx.Lock()
-	defer n.mtx.Unlock()
+	// Chain is names of backups to restore the incremental backup,
+	// starting with a full backup.
+	Chain []string `json:"chain,omitempty"`
+}
 
-	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+func listBackups(backupDir string) ([]BackupInfo, error) {
 	fis, err := ioutil.ReadDir(backupDir)
 	if err != nil {
 		return nil, err
@@ -589,6 +651,99 @@ func (n *Node) GetBackups() ([]BackupInfo, error) {
 	return infos, nil
 }
 
+// backupChainOf returns names of backups to restore the backup in order.
+// The base of an incremental backup is a backup of the same chain ending
+// with the base block. Full backups are preferred to shorten the chain.
+func backupChainOf(infos []BackupInfo, name string) ([]string, error) {
+	var target *BackupInfo
+	for i := range infos {
+		if infos[i].Name == name {
+			target = &infos[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, errors.NotFoundError.Errorf("BackupNotFound(name=%s)", name)
+	}
+	names := []string{target.Name}
+	for target.IsIncremental() {
+		var base *BackupInfo
+		for i := range infos {
+			info := &infos[i]
+			if info.Height != target.Base || info.CID != target.CID ||
+				info.NID != target.NID || info.Channel != target.Channel ||
+				!bytes.Equal(info.BlockID, target.BaseID) {
+				continue
+			}
+			if base == nil || (base.IsIncremental() && !info.IsIncremental()) {
+				base = info
+			}
+		}
+		if base == nil {
+			return nil, errors.NotFoundError.Errorf(
+				"BaseBackupNotFound(name=%s,base=%d)", target.Name, target.Base)
+		}
+		names = append([]string{base.Name}, names...)
+		target = base
+	}
+	return names, nil
+}
+
+func (n *Node) GetBackups() ([]BackupInfo, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	infos, err := listBackups(backupDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		if infos[i].IsIncremental() {
+			infos[i].Chain, _ = backupChainOf(infos, infos[i].Name)
+		}
+	}
+	return infos, nil
+}
+
+// latestBackupOf returns the name of the backup of the chain with
+// the highest block height. It returns empty string if there is none.
+func (n *Node) latestBackupOf(c *Chain) string {
+	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	infos, err := listBackups(backupDir)
+	if err != nil {
+		return ""
+	}
+	var latest *BackupInfo
+	for i := range infos {
+		info := &infos[i]
+		if int(info.CID.Value) != c.CID() || int(info.NID.Value) != c.NID() ||
+			info.Channel != c.Channel() {
+			continue
+		}
+		if latest == nil || info.Height > latest.Height {
+			latest = info
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Name
+}
+
+// GetSchedules returns schedules of the chain with their run history.
+func (n *Node) GetSchedules(cid int) (*SchedulesView, error) {
+	c, err := func() (*Chain, error) {
+		n.mtx.RLock()
+		defer n.mtx.RUnlock()
+		return n._get(cid)
+	}()
+	if err != nil {
+		return nil, err
+	}
+	return n.sch.GetSchedules(c), nil
+}
+
 type RestoreView struct {
 	State     string `json:"state"`
 	Name      string `json:"name,omitempty"`
@@ -606,9 +761,19 @@ func (n *Node) StartRestore(name string, overwrite bool) (ret error) {
 			n.cfg.ResolveAbsolute(n.cfg.BackupDir)
 	}()
 
-	backupFile := path.Join(backupDir, name)
-
-	return n.rsm.Start(n, backupFile, baseDir, overwrite)
+	infos, err := listBackups(backupDir)
+	if err != nil {
+		return err
+	}
+	names, err := backupChainOf(infos, path.Base(name))
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "InvalidBackup")
+	}
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = path.Join(backupDir, name)
+	}
+	return n.rsm.Start(n, files, baseDir, overwrite)
 }
 
 // GetRestore returns state of latest restore operations.
@@ -633,6 +798,17 @@ func (n *Node) StopRestore() error {
 	return n.rsm.Stop()
 }
 
+func parseSchedules(value string) ([]chain.Schedule, error) {
+	var schedules []chain.Schedule
+	if err := json.Unmarshal([]byte(value), &schedules); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidSchedules(%s)", value)
+	}
+	if err := chain.ValidateSchedules(schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
 func (n *Node) ConfigureChain(cid int, key string, value string) error {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
@@ -663,6 +839,12 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.AutoStart = as
 			}
+		case "schedules":
+			if schedules, err := parseSchedules(value); err != nil {
+				return err
+			} else {
+				c.setSchedules(schedules)
+			}
 		default:
 			return errors.ErrInvalidState
 		}
@@ -767,6 +949,32 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.ValidateTxOnSend = bc
 			}
+		case "stateRetention":
+			if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
+				return errors.Wrapf(err, "invalid value type")
+			} else if intVal < 0 {
+				return errors.IllegalArgumentError.Errorf("InvalidStateRetention(%d)", intVal)
+			} else {
+				c.cfg.StateRetention = intVal
+			}
+		case "statePinned":
+			if heights, err := parseHeights(value); err != nil {
+				return err
+			} else {
+				c.cfg.StatePinned = heights
+			}
+		case "accountIndex":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.AccountIndex = bc
+			}
+		case "schedules":
+			if schedules, err := parseSchedules(value); err != nil {
+				return err
+			} else {
+				c.setSchedules(schedules)
+			}
 		default:
 			return errors.Errorf("not found key %s", key)
 		}
@@ -829,6 +1037,23 @@ func (n *Node) GetChainByChannel(channel string) *Chain {
 	return n.chains[channel]
 }
 
+// parseHeights parses comma separated heights. Empty string means no heights.
+func parseHeights(s string) ([]int64, error) {
+	var heights []int64
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if len(v) == 0 {
+			continue
+		}
+		h, err := strconv.ParseInt(v, 0, 64)
+		if err != nil || h < 0 {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidHeight(%s)", v)
+		}
+		heights = append(heights, h)
+	}
+	return heights, nil
+}
+
 func cidOfSelector(s string) (int, bool) {
 	if cid, err := strconv.ParseInt(s, 0, 32); err == nil {
 		return int(cid), true
@@ -848,6 +1073,23 @@ func (n *Node) GetChainBySelector(s string) *Chain {
 	return n.chains[s]
 }
 
+// parseWatchdogTimeout parses timeout for the executors like "30s".
+// Zero disables the watchdog.
+func parseWatchdogTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.IllegalArgumentError.Wrapf(err, "InvalidTimeout(%s)", s)
+	}
+	if d < 0 {
+		return 0, errors.IllegalArgumentError.Errorf("NegativeTimeout(%s)", s)
+	}
+	return d, nil
+}
+
+func (n *Node) InspectEE() map[string]interface{} {
+	return n.pm.Inspect()
+}
+
 func (n *Node) Configure(key string, value string) error {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
@@ -862,6 +1104,13 @@ func (n *Node) Configure(key string, value string) error {
 		if err := n.pm.SetInstances(n.rcfg.EEInstances, n.rcfg.EEInstances, n.rcfg.EEInstances); err != nil {
 			return err
 		}
+	case "eeWatchdogTimeout":
+		d, err := parseWatchdogTimeout(value)
+		if err != nil {
+			return err
+		}
+		n.rcfg.EEWatchdogTimeout = value
+		n.pm.SetWatchdogTimeout(d)
 	case "rpcDefaultChannel":
 		n.rcfg.RPCDefaultChannel = value
 		n.srv.SetDefaultChannel(n.rcfg.RPCDefaultChannel)
@@ -948,6 +1197,11 @@ func NewNode(
 	if err := pm.SetInstances(rcfg.EEInstances, rcfg.EEInstances, rcfg.EEInstances); err != nil {
 		log.Panicf("fail to EEManager.SetInstances err=%+v", err)
 	}
+	if d, err := parseWatchdogTimeout(rcfg.EEWatchdogTimeout); err != nil {
+		log.Panicf("fail to EEManager.SetWatchdogTimeout err=%+v", err)
+	} else {
+		pm.SetWatchdogTimeout(d)
+	}
 	go func() {
 		if err := pm.Loop(); err != nil {
 			log.Panic(err)
@@ -969,6 +1223,7 @@ func NewNode(
 		channels: make(map[int]string),
 		cliSrv:   cliSrv,
 	}
+	n.sch = NewScheduler(n, l)
 
 	// Load chains
 	fs, err := ioutil.ReadDir(nodeDir)
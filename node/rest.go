@@ -61,6 +61,10 @@ type SystemView struct {
 	Config interface{} `json:"config"`
 }
 
+type SystemInspectView struct {
+	EE map[string]interface{} `json:"ee"`
+}
+
 type StatsView struct {
 	Chains    []map[string]interface{} `json:"chains"`
 	Timestamp time.Time                `json:"timestamp"`
@@ -84,25 +88,30 @@ type ChainInspectView struct {
 }
 
 type ChainConfig struct {
-	DBType           string `json:"dbType"`
-	Platform         string `json:"platform"`
-	SeedAddr         string `json:"seedAddress"`
-	Role             uint   `json:"role"`
-	ConcurrencyLevel int    `json:"concurrencyLevel,omitempty"`
-	NormalTxPoolSize int    `json:"normalTxPool,omitempty"`
-	PatchTxPoolSize  int    `json:"patchTxPool,omitempty"`
-	MaxBlockTxBytes  int    `json:"maxBlockTxBytes,omitempty"`
-	NodeCache        string `json:"nodeCache,omitempty"`
-	Channel          string `json:"channel"`
-	SecureSuites     string `json:"secureSuites"`
-	SecureAeads      string `json:"secureAeads"`
-	DefWaitTimeout   int64  `json:"defaultWaitTimeout"`
-	MaxWaitTimeout   int64  `json:"maxWaitTimeout"`
-	TxTimeout        int64  `json:"txTimeout"`
-	AutoStart        bool   `json:"autoStart"`
-	ChildrenLimit    *int   `json:"childrenLimit,omitempty"`
-	NephewsLimit     *int   `json:"nephewsLimit,omitempty"`
-	ValidateTxOnSend bool   `json:"validateTxOnSend,omitempty"`
+	DBType           string  `json:"dbType"`
+	Platform         string  `json:"platform"`
+	SeedAddr         string  `json:"seedAddress"`
+	Role             uint    `json:"role"`
+	ConcurrencyLevel int     `json:"concurrencyLevel,omitempty"`
+	NormalTxPoolSize int     `json:"normalTxPool,omitempty"`
+	PatchTxPoolSize  int     `json:"patchTxPool,omitempty"`
+	MaxBlockTxBytes  int     `json:"maxBlockTxBytes,omitempty"`
+	NodeCache        string  `json:"nodeCache,omitempty"`
+	Channel          string  `json:"channel"`
+	SecureSuites     string  `json:"secureSuites"`
+	SecureAeads      string  `json:"secureAeads"`
+	DefWaitTimeout   int64   `json:"defaultWaitTimeout"`
+	MaxWaitTimeout   int64   `json:"maxWaitTimeout"`
+	TxTimeout        int64   `json:"txTimeout"`
+	AutoStart        bool    `json:"autoStart"`
+	ChildrenLimit    *int    `json:"childrenLimit,omitempty"`
+	NephewsLimit     *int    `json:"nephewsLimit,omitempty"`
+	ValidateTxOnSend bool    `json:"validateTxOnSend,omitempty"`
+	StateRetention   int64   `json:"stateRetention,omitempty"`
+	StatePinned      []int64 `json:"statePinned,omitempty"`
+	AccountIndex     bool    `json:"accountIndex,omitempty"`
+
+	Schedules []chain.Schedule `json:"schedules,omitempty"`
 }
 
 type ChainResetParam struct {
@@ -121,7 +130,9 @@ type ChainPruneParam struct {
 }
 
 type ChainBackupParam struct {
-	Manual bool `json:"manual,omitempty"`
+	Manual bool   `json:"manual,omitempty"`
+	Online bool   `json:"online,omitempty"`
+	Base   string `json:"base,omitempty"`
 }
 
 type ConfigureParam struct {
@@ -161,6 +172,7 @@ func NewChainInspectView(c *Chain) *ChainInspectView {
 		GenesisTx: c.Genesis(),
 		Config:    NewChainConfig(c.cfg),
 	}
+	v.Config.Schedules = c.Schedules()
 	return v
 }
 
@@ -185,6 +197,10 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 		ChildrenLimit:    cfg.ChildrenLimit,
 		NephewsLimit:     cfg.NephewsLimit,
 		ValidateTxOnSend: cfg.ValidateTxOnSend,
+		StateRetention:   cfg.StateRetention,
+		StatePinned:      cfg.StatePinned,
+		AccountIndex:     cfg.AccountIndex,
+		Schedules:        cfg.Schedules,
 	}
 	return v
 }
@@ -237,6 +253,7 @@ func (r *Rest) RegisterChainHandlers(g *echo.Group) {
 	g.POST(UrlChainRes+"/import", r.ImportChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/prune", r.PruneChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/backup", r.BackupChain, r.ChainInjector)
+	g.GET(UrlChainRes+"/schedule", r.GetChainSchedules, r.ChainInjector)
 	route := g.GET(UrlChainRes+"/genesis", r.GetChainGenesis, r.ChainInjector)
 	if r.a != nil {
 		r.a.SetSkip(route, false)
@@ -421,13 +438,22 @@ func (r *Rest) BackupChain(ctx echo.Context) error {
 	if err := ctx.Bind(param); err != nil {
 		return echo.ErrBadRequest
 	}
-	if name, err := r.n.BackupChain(c.CID(), param.Manual); err != nil {
+	if name, err := r.n.BackupChain(c.CID(), param.Manual, param.Online, param.Base); err != nil {
 		return err
 	} else {
 		return ctx.String(http.StatusOK, name)
 	}
 }
 
+func (r *Rest) GetChainSchedules(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	v, err := r.n.GetSchedules(c.CID())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, v)
+}
+
 func (r *Rest) GetChainGenesis(ctx echo.Context) error {
 	c := ctx.Get("chain").(*Chain)
 	gsFile := path.Join(c.cfg.AbsBaseDir(), ChainGenesisZipFileName)
@@ -470,6 +496,7 @@ func (r *Rest) RunChainTask(ctx echo.Context) error {
 
 func (r *Rest) RegisterSystemHandlers(g *echo.Group) {
 	g.GET("", r.GetSystem)
+	g.GET("/inspect", r.InspectSystem)
 	g.GET("/configure", r.GetSystemConfig)
 	g.POST("/configure", r.ConfigureSystem)
 	r.RegistryBackupHandlers(g.Group("/backup"))
@@ -495,6 +522,18 @@ func (r *Rest) GetSystem(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, v)
 }
 
+func (r *Rest) InspectSystem(ctx echo.Context) error {
+	v := &SystemInspectView{
+		EE: r.n.InspectEE(),
+	}
+
+	format := ctx.QueryParam("format")
+	if format != "" {
+		return defaultJsonTemplate.Response(format, v, ctx.Response())
+	}
+	return ctx.JSON(http.StatusOK, v)
+}
+
 func (r *Rest) GetSystemConfig(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, r.n.rcfg)
 }
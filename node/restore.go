@@ -18,15 +18,18 @@ package node
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"strconv"
 	"sync"
 
 	"github.com/icon-project/goloop/chain"
 	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
 )
 
@@ -80,7 +83,10 @@ type RestoreManager struct {
 	lastErr error
 }
 
-func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrite bool) (ret error) {
+// Start starts to restore the chain with the files. The first one should
+// be a full backup, and others should be incremental backups on top of
+// the previous one.
+func (m *RestoreManager) Start(node *Node, files []string, baseDir string, overwrite bool) (ret error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
@@ -93,6 +99,11 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 			"StillRestoring(%s)", path.Base(m.file))
 	}
 
+	if len(files) == 0 {
+		return errors.IllegalArgumentError.New("NoBackupFile")
+	}
+	file := files[len(files)-1]
+
 	tmpDir, err := ioutil.TempDir(baseDir, RestoreDirectoryPrefix)
 	if err != nil {
 		return err
@@ -103,27 +114,43 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 		}
 	}()
 
-	zr, err := zip.OpenReader(file)
-	if err != nil {
-		return errors.IllegalArgumentError.Wrapf(err,
-			"ZipOpenFailure(backup=%s)", file)
-	}
+	zrs := make([]*zip.ReadCloser, 0, len(files))
 	defer func() {
 		if ret != nil {
-			zr.Close()
+			for _, zr := range zrs {
+				zr.Close()
+			}
 		}
 	}()
+	var info *chain.BackupInfo
+	total := 0
+	for idx, f := range files {
+		zr, err := zip.OpenReader(f)
+		if err != nil {
+			return errors.IllegalArgumentError.Wrapf(err,
+				"ZipOpenFailure(backup=%s)", f)
+		}
+		zrs = append(zrs, zr)
 
-	info, err := chain.ReadBackupInfo(&zr.Reader)
-	if err != nil {
-		return errors.IllegalArgumentError.Wrap(err,
-			"InvalidBackupInfo")
-	}
-
-	if info.Codec != codec.BC.Name() {
-		return errors.IllegalArgumentError.Errorf(
-			"IncompatibleCodec(backup=%s,system=%s)",
-			info.Codec, codec.BC.Name())
+		info, err = chain.ReadBackupInfo(&zr.Reader)
+		if err != nil {
+			return errors.IllegalArgumentError.Wrapf(err,
+				"InvalidBackupInfo(backup=%s)", f)
+		}
+		if info.Codec != codec.BC.Name() {
+			return errors.IllegalArgumentError.Errorf(
+				"IncompatibleCodec(backup=%s,system=%s)",
+				info.Codec, codec.BC.Name())
+		}
+		if info.IsIncremental() != (idx > 0) {
+			return errors.IllegalArgumentError.Errorf(
+				"InvalidBackupChain(backup=%s,base=%d)", f, info.Base)
+		}
+		if idx == 0 {
+			total += len(zr.File)
+		} else {
+			total += 1
+		}
 	}
 
 	if err := node.CanAdd(int(info.CID.Value), int(info.NID.Value), info.Channel, overwrite); err != nil {
@@ -131,7 +158,7 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 	}
 
 	go func() {
-		if err := m._restore(node, zr, tmpDir, overwrite); err != nil {
+		if err := m._restore(node, zrs, tmpDir, overwrite); err != nil {
 			node.logger.Debugf("Restore failed err=%+v", err)
 			if errors.InterruptedError.Equals(err) {
 				m._setState(RestoreNone, nil)
@@ -147,7 +174,7 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 	m.overwrite = overwrite
 	m.state = RestoreStarted
 	m.current = 0
-	m.total = len(zr.File)
+	m.total = total
 	return nil
 }
 
@@ -235,21 +262,59 @@ func zipExtract(file *zip.File, tmpDir string) (ret error) {
 	return err
 }
 
-func (m *RestoreManager) _restore(node *Node, zr *zip.ReadCloser, tmpDir string, overwrite bool) (ret error) {
+func applyIncrementalBackup(zr *zip.Reader, tmpDir string) error {
+	cfgFile := path.Join(tmpDir, ChainConfigFileName)
+	bs, err := ioutil.ReadFile(cfgFile)
+	if err != nil {
+		return err
+	}
+	cfg := new(chain.Config)
+	if err := json.Unmarshal(bs, cfg); err != nil {
+		return err
+	}
+	dbase, err := db.Open(path.Join(tmpDir, chain.DefaultDBDir), cfg.DBType,
+		strconv.FormatInt(int64(cfg.NID), 16))
+	if err != nil {
+		return err
+	}
+	defer dbase.Close()
+	if err := chain.ApplyIncrementalBackup(zr, dbase); err != nil {
+		return err
+	}
+	// WAL of the base is for the old height.
+	return os.RemoveAll(path.Join(tmpDir, chain.DefaultWALDir))
+}
+
+func (m *RestoreManager) _restore(node *Node, zrs []*zip.ReadCloser, tmpDir string, overwrite bool) (ret error) {
 	defer func() {
 		if ret != nil {
 			os.RemoveAll(tmpDir)
 		}
 	}()
-	defer zr.Close()
+	defer func() {
+		for _, zr := range zrs {
+			zr.Close()
+		}
+	}()
 
-	for idx, file := range zr.File {
+	idx := 0
+	for _, file := range zrs[0].File {
 		if err := zipExtract(file, tmpDir); err != nil {
 			return err
 		}
 		if err := m._onRestored(idx); err != nil {
 			return err
 		}
+		idx += 1
+	}
+	for _, zr := range zrs[1:] {
+		if err := applyIncrementalBackup(&zr.Reader, tmpDir); err != nil {
+			return err
+		}
+		if err := m._onRestored(idx); err != nil {
+			return err
+		}
+		idx += 1
 	}
 
 	return node.restoreChain(tmpDir, overwrite)
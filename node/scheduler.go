@@ -0,0 +1,359 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package node
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+)
+
+const (
+	scheduleCheckInterval = time.Second
+	scheduleWaitInterval  = 500 * time.Millisecond
+	scheduleHistoryLimit  = 32
+)
+
+// ScheduleBackupParam is the parameter of scheduled backups. Backups are
+// online by default, so validators keep running consensus. Offline backups
+// stop the chain while they are written, and only they can be incremental.
+// Online backups are recorded when they are started.
+type ScheduleBackupParam struct {
+	Offline     bool `json:"offline,omitempty"`
+	Incremental bool `json:"incremental,omitempty"`
+}
+
+type ScheduleRun struct {
+	Name   string     `json:"name"`
+	Task   string     `json:"task"`
+	Height int64      `json:"height"`
+	Start  time.Time  `json:"start"`
+	End    *time.Time `json:"end,omitempty"`
+	Result string     `json:"result,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type ScheduleView struct {
+	chain.Schedule
+	Next *time.Time `json:"next,omitempty"`
+}
+
+type SchedulesView struct {
+	Schedules []ScheduleView `json:"schedules"`
+	Running   string         `json:"running,omitempty"`
+	History   []ScheduleRun  `json:"history"`
+}
+
+type scheduleState struct {
+	cron   string
+	blocks int64
+	spec   *chain.Cron
+	next   time.Time
+	height int64
+}
+
+type chainSchedules struct {
+	states  map[string]*scheduleState
+	running string
+	history []ScheduleRun
+}
+
+// Scheduler runs maintenance tasks of chains on their schedules.
+type Scheduler struct {
+	lock   sync.Mutex
+	node   *Node
+	chains map[int]*chainSchedules
+	stop   chan struct{}
+	logger log.Logger
+}
+
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go s.loop(s.stop)
+}
+
+func (s *Scheduler) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *Scheduler) loop(stop <-chan struct{}) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			for _, c := range s.node.GetChains() {
+				s.check(c, now)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) _chainSchedulesOf(cid int) *chainSchedules {
+	cs, ok := s.chains[cid]
+	if !ok {
+		cs = &chainSchedules{
+			states: make(map[string]*scheduleState),
+		}
+		s.chains[cid] = cs
+	}
+	return cs
+}
+
+func (s *Scheduler) check(c *Chain, now time.Time) {
+	_, height, _ := c.State()
+	schedules := c.Schedules()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cs := s._chainSchedulesOf(c.CID())
+	names := make(map[string]bool)
+	for i := range schedules {
+		sc := &schedules[i]
+		names[sc.Name] = true
+		st, ok := cs.states[sc.Name]
+		if !ok || st.cron != sc.Cron || st.blocks != sc.Blocks {
+			st = &scheduleState{
+				cron:   sc.Cron,
+				blocks: sc.Blocks,
+				height: height,
+			}
+			if len(sc.Cron) > 0 {
+				if spec, err := chain.ParseCron(sc.Cron); err == nil {
+					st.spec = spec
+					st.next = spec.Next(now)
+				}
+			}
+			cs.states[sc.Name] = st
+			continue
+		}
+		var due bool
+		if st.spec != nil {
+			if !st.next.IsZero() && !now.Before(st.next) {
+				due = true
+				st.next = st.spec.Next(now)
+			}
+		} else if st.blocks > 0 {
+			if height/st.blocks > st.height/st.blocks {
+				due = true
+			}
+			st.height = height
+		}
+		if due {
+			if len(cs.running) > 0 {
+				cs._record(ScheduleRun{
+					Name:   sc.Name,
+					Task:   sc.Task,
+					Height: height,
+					Start:  now,
+					Error:  "skipped while running " + cs.running,
+				})
+				continue
+			}
+			cs.running = sc.Name
+			go s.run(c, *sc, height)
+		}
+	}
+	for name := range cs.states {
+		if !names[name] {
+			delete(cs.states, name)
+		}
+	}
+}
+
+func (cs *chainSchedules) _record(r ScheduleRun) {
+	cs.history = append(cs.history, r)
+	if len(cs.history) > scheduleHistoryLimit {
+		cs.history = cs.history[len(cs.history)-scheduleHistoryLimit:]
+	}
+}
+
+func (s *Scheduler) run(c *Chain, sc chain.Schedule, height int64) {
+	r := ScheduleRun{
+		Name:   sc.Name,
+		Task:   sc.Task,
+		Height: height,
+		Start:  time.Now(),
+	}
+	s.logger.Infof("Run scheduled task name=%s task=%s cid=%#x height=%d",
+		sc.Name, sc.Task, c.CID(), height)
+	result, err := s.runTask(c, &sc)
+	end := time.Now()
+	r.End = &end
+	r.Result = result
+	if err != nil {
+		s.logger.Warnf("Fail to run scheduled task name=%s task=%s cid=%#x err=%+v",
+			sc.Name, sc.Task, c.CID(), err)
+		r.Error = err.Error()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cs := s._chainSchedulesOf(c.CID())
+	cs.running = ""
+	cs._record(r)
+}
+
+func (s *Scheduler) runTask(c *Chain, sc *chain.Schedule) (string, error) {
+	cid := c.CID()
+	switch sc.Task {
+	case chain.ScheduleTaskBackup:
+		var p ScheduleBackupParam
+		if len(sc.Params) > 0 {
+			if err := json.Unmarshal(sc.Params, &p); err != nil {
+				return "", errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+			}
+		}
+		if !p.Offline {
+			if p.Incremental {
+				return "", errors.IllegalArgumentError.New(
+					"IncrementalBackupCanNotBeOnline")
+			}
+			return s.node.BackupChain(cid, false, true, "")
+		}
+		var base string
+		if p.Incremental {
+			base = s.node.latestBackupOf(c)
+		}
+		var name string
+		err := s.withStoppedChain(c, func() (err error) {
+			name, err = s.node.BackupChain(cid, false, false, base)
+			return err
+		})
+		return name, err
+	case chain.ScheduleTaskPrune:
+		var p ChainPruneParam
+		if len(sc.Params) > 0 {
+			if err := json.Unmarshal(sc.Params, &p); err != nil {
+				return "", errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+			}
+		}
+		return "", s.withStoppedChain(c, func() error {
+			height := p.Height
+			// zero or negative height is relative to the last block
+			if height <= 0 {
+				_, last, _ := c.State()
+				height += last
+			}
+			return s.node.PruneChain(cid, p.DBType, height)
+		})
+	default:
+		return "", s.node.RunChainTask(cid, sc.Task, sc.Params)
+	}
+}
+
+// withStoppedChain stops the chain if it's running, then it runs the task
+// and waits for the task to finish. The chain is started again after the
+// task if it was running.
+func (s *Scheduler) withStoppedChain(c *Chain, task func() error) error {
+	started := c.IsStarted()
+	if started {
+		if err := c.Stop(); err != nil {
+			return err
+		}
+		if err := s.waitStopped(c); err != nil {
+			return err
+		}
+	}
+	err := task()
+	if err == nil {
+		if err = s.waitStopped(c); err == nil {
+			_, _, err = c.State()
+		}
+	}
+	if started {
+		if serr := s.node.StartChain(c.CID()); serr != nil {
+			if err == nil {
+				err = serr
+			}
+			s.logger.Warnf("Fail to start chain after scheduled task cid=%#x err=%+v",
+				c.CID(), serr)
+		}
+	}
+	return err
+}
+
+func (s *Scheduler) waitStopped(c *Chain) error {
+	for !c.IsStopped() {
+		s.lock.Lock()
+		stop := s.stop
+		s.lock.Unlock()
+		if stop == nil {
+			return errors.ErrInterrupted
+		}
+		select {
+		case <-stop:
+			return errors.ErrInterrupted
+		case <-time.After(scheduleWaitInterval):
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) GetSchedules(c *Chain) *SchedulesView {
+	schedules := c.Schedules()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v := &SchedulesView{
+		Schedules: make([]ScheduleView, 0, len(schedules)),
+		History:   []ScheduleRun{},
+	}
+	cs, ok := s.chains[c.CID()]
+	for _, sc := range schedules {
+		sv := ScheduleView{Schedule: sc}
+		if ok {
+			if st, ok := cs.states[sc.Name]; ok && !st.next.IsZero() {
+				next := st.next
+				sv.Next = &next
+			}
+		}
+		v.Schedules = append(v.Schedules, sv)
+	}
+	if ok {
+		v.Running = cs.running
+		v.History = append(v.History, cs.history...)
+	}
+	return v
+}
+
+func NewScheduler(n *Node, logger log.Logger) *Scheduler {
+	return &Scheduler{
+		node:   n,
+		chains: make(map[int]*chainSchedules),
+		logger: logger,
+	}
+}
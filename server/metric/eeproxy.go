@@ -0,0 +1,76 @@
+package metric
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	MetricKeyEngine   = NewMetricKey("engine")
+	MetricKeyPriority = NewMetricKey("priority")
+
+	msEEExecutors     = stats.Int64("ee_executors", "Live Executors", stats.UnitDimensionless)
+	msEERestarts      = stats.Int64("ee_restarts", "Restarted Executors", stats.UnitDimensionless)
+	msEEWatchdogKills = stats.Int64("ee_watchdog_kills", "Executors Killed by Watchdog", stats.UnitDimensionless)
+	msEECallLatency   = stats.Int64("ee_call_latency", "Call Latency", "us")
+	msEEWaiting       = stats.Int64("ee_waiting", "Waiting Executor Requests", stats.UnitDimensionless)
+	eeEngineMks       = []tag.Key{MetricKeyEngine}
+	eeQueueMks        = []tag.Key{MetricKeyPriority}
+)
+
+func RegisterEEProxy() {
+	RegisterMetricView(msEEExecutors, view.LastValue(), eeEngineMks)
+	RegisterMetricView(msEERestarts, view.Sum(), eeEngineMks)
+	RegisterMetricView(msEEWatchdogKills, view.Sum(), eeEngineMks)
+	RegisterMetricView(msEECallLatency, view.Count(), eeEngineMks)
+	RegisterMetricView(msEECallLatency, view.Sum(), eeEngineMks)
+	RegisterMetricView(msEEWaiting, view.LastValue(), eeQueueMks)
+}
+
+// EEMetric records the status of executors of an execution engine.
+// Executors are shared by chains, so it's not tagged with the chain.
+type EEMetric struct {
+	ctx context.Context
+}
+
+func (m *EEMetric) OnExecutors(n int) {
+	stats.Record(m.ctx, msEEExecutors.M(int64(n)))
+}
+
+func (m *EEMetric) OnRestart() {
+	stats.Record(m.ctx, msEERestarts.M(1))
+}
+
+func (m *EEMetric) OnWatchdogKill() {
+	stats.Record(m.ctx, msEEWatchdogKills.M(1))
+}
+
+func (m *EEMetric) OnCall(d time.Duration) {
+	stats.Record(m.ctx, msEECallLatency.M(int64(d/time.Microsecond)))
+}
+
+func NewEEMetric(engine string) *EEMetric {
+	return &EEMetric{
+		ctx: GetMetricContext(rootMetricCtx, &MetricKeyEngine, engine),
+	}
+}
+
+// EEQueueMetric records the number of requests waiting for an executor
+// with the priority.
+type EEQueueMetric struct {
+	ctx context.Context
+}
+
+func (m *EEQueueMetric) OnWaiting(n int) {
+	stats.Record(m.ctx, msEEWaiting.M(int64(n)))
+}
+
+func NewEEQueueMetric(priority string) *EEQueueMetric {
+	return &EEQueueMetric{
+		ctx: GetMetricContext(rootMetricCtx, &MetricKeyPriority, priority),
+	}
+}
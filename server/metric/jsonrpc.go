@@ -57,6 +57,7 @@ var (
 		"icx_getVotesByHeight":       msRetrieve,
 		"icx_getProofForResult":      msRetrieve,
 		"icx_getProofForEvents":      msRetrieve,
+		"icx_getProof":               msRetrieve,
 		"icx_getScoreStatus":         msRetrieve,
 		"icx_getNetworkInfo":         msRetrieve,
 		"btp_getNetworkInfo":         msRetrieve,
@@ -75,6 +76,21 @@ var (
 			stats.Int64("jsonrpc_estimate_step_avg", "moving average of jsonrpc debug_estimateStep method", "ns"),
 			emptyMks,
 		},
+		"debug_getStateDiff": {
+			stats.Int64("jsonrpc_get_state_diff", "jsonrpc debug_getStateDiff method", "ns"),
+			stats.Int64("jsonrpc_get_state_diff_avg", "moving average of jsonrpc debug_getStateDiff method", "ns"),
+			emptyMks,
+		},
+		"debug_getAccounts": {
+			stats.Int64("jsonrpc_get_accounts", "jsonrpc debug_getAccounts method", "ns"),
+			stats.Int64("jsonrpc_get_accounts_avg", "moving average of jsonrpc debug_getAccounts method", "ns"),
+			emptyMks,
+		},
+		"debug_getDelegators": {
+			stats.Int64("jsonrpc_get_delegators", "jsonrpc debug_getDelegators method", "ns"),
+			stats.Int64("jsonrpc_get_delegators_avg", "moving average of jsonrpc debug_getDelegators method", "ns"),
+			emptyMks,
+		},
 		"rosetta_getTrace": {
 			stats.Int64("jsonrpc_rosetta_trace_", "jsonrpc rosetta_getTrace method", "ns"),
 			stats.Int64("jsonrpc_rosetta_trace_avg", "moving average of jsonrpc rosetta_getTTrace method", "ns"),
@@ -0,0 +1,42 @@
+package metric
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	msPruneDeleted   = stats.Int64("state_prune_deleted", "Deleted State Nodes", stats.UnitDimensionless)
+	msPruneReclaimed = stats.Int64("state_prune_reclaimed", "Reclaimed State Bytes", stats.UnitBytes)
+	msPruneHeight    = stats.Int64("state_prune_height", "Pruned State Height", stats.UnitDimensionless)
+	pruneMks         = []tag.Key{}
+)
+
+func RegisterPruning() {
+	RegisterMetricView(msPruneDeleted, view.Sum(), pruneMks)
+	RegisterMetricView(msPruneReclaimed, view.Sum(), pruneMks)
+	RegisterMetricView(msPruneHeight, view.LastValue(), pruneMks)
+}
+
+type PruningMetric struct {
+	ctx context.Context
+}
+
+// OnPrune records the result of a pruning cycle which removed the states
+// below the height.
+func (m *PruningMetric) OnPrune(height int64, deleted, reclaimed int64) {
+	stats.Record(m.ctx,
+		msPruneDeleted.M(deleted),
+		msPruneReclaimed.M(reclaimed),
+		msPruneHeight.M(height),
+	)
+}
+
+func NewPruningMetric(ctx context.Context) *PruningMetric {
+	return &PruningMetric{
+		ctx: ctx,
+	}
+}
@@ -51,6 +51,7 @@ func MethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
 	mr.RegisterMethod("icx_getVotesByHeight", getVotesByHeight)
 	mr.RegisterMethod("icx_getProofForResult", getProofForResult)
 	mr.RegisterMethod("icx_getProofForEvents", getProofForEvents)
+	mr.RegisterMethod("icx_getProof", getProof)
 	mr.RegisterMethod("icx_getScoreStatus", getScoreStatus)
 	mr.RegisterMethod("icx_getNetworkInfo", getNetworkInfo)
 
@@ -414,10 +415,11 @@ func getTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 		return nil, err
 	}
 
-	var param TransactionHashParam
+	var param TransactionResultParam
 	if err := params.Convert(&param); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
 	}
+	decode, _ := param.DecodeLogs.Bool()
 
 	txInfo, err := c.bm.GetTransactionInfo(param.Hash.Bytes())
 	if errors.NotFoundError.Equals(err) {
@@ -439,7 +441,11 @@ func getTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
-	res, err := receipt.ToJSON(module.JSONVersion3)
+	var dec txresult.EventLogDecoder
+	if decode {
+		dec = NewEventLogDecoder(c.sm, resultOfNextBlock(&c.contextWithBM, blk))
+	}
+	res, err := txresult.ReceiptToJSON(receipt, module.JSONVersion3, dec)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
@@ -704,6 +710,41 @@ func getProofForEvents(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{
 	return proofs, nil
 }
 
+func getProof(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param ProofAccountParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	b, err := c.GetBlockByHeight(param.Height)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([][]byte, len(param.Keys))
+	for i, k := range param.Keys {
+		if keys[i], err = hex.DecodeString(string(k[2:])); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("InvalidKey(key=%s)", k)
+		}
+	}
+	p, err := c.sm.GetProof(b.Result(), param.Address.Address(), keys)
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	jso, err := p.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	}
+	if m, ok := jso.(map[string]interface{}); ok {
+		m["height"] = intconv.FormatInt(b.Height())
+		m["blockHash"] = common.HexBytes(b.ID())
+	}
+	return jso, nil
+}
+
 func getScoreStatus(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	var c contextWithSM
 	if err := c.Init(ctx); err != nil {
@@ -1042,7 +1083,7 @@ func sendTransactionAndWait(ctx *jsonrpc.Context, params *jsonrpc.Params) (inter
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
 
-	return waitTransactionResultOnChannel(&c, hash, timeout, maxLimit, fc)
+	return waitTransactionResultOnChannel(&c, hash, timeout, maxLimit, fc, false)
 }
 
 func waitTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
@@ -1068,10 +1109,11 @@ func waitTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interf
 		maxLimit = true
 	}
 
-	var param TransactionHashParam
+	var param TransactionResultParam
 	if err := params.Convert(&param); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
 	}
+	decode, _ := param.DecodeLogs.Bool()
 
 	hash := param.Hash.Bytes()
 	fc, err := c.bm.WaitTransactionResult(hash)
@@ -1079,10 +1121,10 @@ func waitTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interf
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
 
-	return waitTransactionResultOnChannel(&c, hash, timeout, maxLimit, fc)
+	return waitTransactionResultOnChannel(&c, hash, timeout, maxLimit, fc, decode)
 }
 
-func waitTransactionResultOnChannel(c *contextWithBM, id []byte, timeout time.Duration, maxLimit bool, fc <-chan interface{}) (interface{}, error) {
+func waitTransactionResultOnChannel(c *contextWithBM, id []byte, timeout time.Duration, maxLimit bool, fc <-chan interface{}, decode bool) (interface{}, error) {
 	tc := time.After(timeout)
 
 	var err error
@@ -1127,7 +1169,11 @@ func waitTransactionResultOnChannel(c *contextWithBM, id []byte, timeout time.Du
 	if err = c.CheckBaseHeight(blk.Height()); err != nil {
 		return nil, err
 	}
-	res, err := receipt.ToJSON(module.JSONVersion3)
+	var dec txresult.EventLogDecoder
+	if sm := c.chain.ServiceManager(); decode && sm != nil {
+		dec = NewEventLogDecoder(sm, resultOfNextBlock(c, blk))
+	}
+	res, err := txresult.ReceiptToJSON(receipt, module.JSONVersion3, dec)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
@@ -1146,10 +1192,96 @@ func DebugMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository
 
 	mr.RegisterMethod("debug_getTrace", getTrace)
 	mr.RegisterMethod("debug_estimateStep", estimateStep)
+	mr.RegisterMethod("debug_getStateDiff", getStateDiff)
+	mr.RegisterMethod("debug_getAccounts", getAccounts)
+	mr.RegisterMethod("debug_getDelegators", getDelegators)
 
 	return mr
 }
 
+func getStateDiff(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var param StateDiffParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	blk1, err := c.GetBlockByHeight(param.Height1)
+	if err != nil {
+		return nil, err
+	}
+	blk2, err := c.GetBlockByHeight(param.Height2)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]module.Address, len(param.Addresses))
+	for i, addr := range param.Addresses {
+		addrs[i] = addr.Address()
+	}
+	diff, err := c.sm.GetStateDiff(blk1.Result(), blk2.Result(), addrs)
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	jso, err := diff.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	}
+	if m, ok := jso.(map[string]interface{}); ok {
+		m["height1"] = intconv.FormatInt(blk1.Height())
+		m["height2"] = intconv.FormatInt(blk2.Height())
+	}
+	return jso, nil
+}
+
+func accountListToJSON(c *contextWithSM, l module.AccountList, err error) (interface{}, error) {
+	if errors.IllegalArgumentError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	} else if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	jso, err := l.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	}
+	return jso, nil
+}
+
+func getAccounts(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var param AccountsParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	l, err := c.sm.GetAccounts(param.OrderBy,
+		int(param.Skip.Value()), int(param.Limit.Value()))
+	return accountListToJSON(&c, l, err)
+}
+
+func getDelegators(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var param DelegatorsParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	l, err := c.sm.GetDelegators(param.Address.Address(),
+		int(param.Skip.Value()), int(param.Limit.Value()))
+	return accountListToJSON(&c, l, err)
+}
+
 func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	var c contextWithSM
 	if err := c.Init(ctx); err != nil {
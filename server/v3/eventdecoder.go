@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package v3
+
+import (
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// NewEventLogDecoder returns a decoder of event logs using event
+// definitions of contracts in the state of the result.
+func NewEventLogDecoder(sm module.ServiceManager, result []byte) txresult.EventLogDecoder {
+	infos := make(map[string]*scoreapi.Info)
+	return func(el module.EventLog) interface{} {
+		key := string(el.Address().Bytes())
+		info, ok := infos[key]
+		if !ok {
+			if ai, err := sm.GetAPIInfo(result, el.Address()); err == nil {
+				info, _ = ai.(*scoreapi.Info)
+			}
+			infos[key] = info
+		}
+		if info == nil {
+			return nil
+		}
+		ev, err := info.DecodeEvent(el.Indexed(), el.Data())
+		if err != nil {
+			return nil
+		}
+		return ev
+	}
+}
+
+// resultOfNextBlock returns the result in the next block of the block,
+// which is the state after the execution of the block. It returns the
+// result of the last block if there is no next block.
+func resultOfNextBlock(c *contextWithBM, blk module.Block) []byte {
+	nblk, err := c.bm.GetBlockByHeight(blk.Height() + 1)
+	if err != nil {
+		if nblk, err = c.bm.GetLastBlock(); err != nil {
+			return nil
+		}
+	}
+	return nblk.Result()
+}
@@ -43,10 +43,39 @@ type ScoreAddressParam struct {
 	Height  jsonrpc.HexInt  `json:"height,omitempty" validate:"optional,t_int"`
 }
 
+type ProofAccountParam struct {
+	Address jsonrpc.Address    `json:"address" validate:"required,t_addr"`
+	Keys    []jsonrpc.HexBytes `json:"keys,omitempty" validate:"dive,startswith=0x"`
+	Height  jsonrpc.HexInt     `json:"height,omitempty" validate:"optional,t_int"`
+}
+
+type StateDiffParam struct {
+	Height1   jsonrpc.HexInt    `json:"height1" validate:"required,t_int"`
+	Height2   jsonrpc.HexInt    `json:"height2,omitempty" validate:"optional,t_int"`
+	Addresses []jsonrpc.Address `json:"addresses,omitempty" validate:"dive,t_addr"`
+}
+
+type AccountsParam struct {
+	OrderBy string         `json:"orderBy,omitempty"`
+	Skip    jsonrpc.HexInt `json:"skip,omitempty" validate:"optional,t_int"`
+	Limit   jsonrpc.HexInt `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+type DelegatorsParam struct {
+	Address jsonrpc.Address `json:"address" validate:"required,t_addr"`
+	Skip    jsonrpc.HexInt  `json:"skip,omitempty" validate:"optional,t_int"`
+	Limit   jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
 type TransactionHashParam struct {
 	Hash jsonrpc.HexBytes `json:"txHash" validate:"required,t_hash"`
 }
 
+type TransactionResultParam struct {
+	Hash       jsonrpc.HexBytes `json:"txHash" validate:"required,t_hash"`
+	DecodeLogs jsonrpc.HexBool  `json:"decodeLogs,omitempty" validate:"optional,t_bool"`
+}
+
 type TransactionParamForEstimate struct {
 	Version     jsonrpc.HexInt  `json:"version" validate:"required,t_int"`
 	FromAddress jsonrpc.Address `json:"from" validate:"required,t_addr_eoa"`
@@ -55,7 +84,7 @@ type TransactionParamForEstimate struct {
 	Timestamp   jsonrpc.HexInt  `json:"timestamp" validate:"required,t_int"`
 	NetworkID   jsonrpc.HexInt  `json:"nid" validate:"required,t_int"`
 	Nonce       jsonrpc.HexInt  `json:"nonce,omitempty" validate:"optional,t_int"`
-	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
+	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit|multicall"`
 	Data        interface{}     `json:"data,omitempty"`
 }
 
@@ -69,8 +98,11 @@ type TransactionParam struct {
 	NetworkID   jsonrpc.HexInt  `json:"nid" validate:"required,t_int"`
 	Nonce       jsonrpc.HexInt  `json:"nonce,omitempty" validate:"optional,t_int"`
 	Signature   string          `json:"signature" validate:"required,t_sig"`
-	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
+	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit|multicall"`
 	Data        interface{}     `json:"data,omitempty"`
+
+	FeePayer          jsonrpc.Address `json:"feePayer,omitempty" validate:"optional,t_addr_eoa"`
+	FeePayerSignature string          `json:"feePayerSignature,omitempty" validate:"optional,t_sig"`
 }
 
 type DataHashParam struct {
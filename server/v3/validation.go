@@ -21,6 +21,7 @@ func RegisterValidationRule(v *jsonrpc.Validator) {
 	v.RegisterValidation("deploy", isDeploy)
 	v.RegisterValidation("message", isMessage)
 	v.RegisterValidation("deposit", isDeposit)
+	v.RegisterValidation("multicall", isMultiCall)
 
 	// validate : CallParam.Data, TransactionParam.Data
 	v.RegisterStructValidation(DataParamValidation, CallParam{}, TransactionParam{})
@@ -43,6 +44,10 @@ func isDeposit(fl validator.FieldLevel) bool {
 	return fl.Field().String() == contract.DataTypeDeposit
 }
 
+func isMultiCall(fl validator.FieldLevel) bool {
+	return fl.Field().String() == contract.DataTypeMultiCall
+}
+
 func DataParamValidation(sl validator.StructLevel) {
 	switch sl.Current().Interface().(type) {
 	case CallParam:
@@ -82,6 +87,12 @@ func DataParamValidation(sl validator.StructLevel) {
 				} else {
 					sl.ReportError(txParam.Data, "Data", "", "data", "")
 				}
+			case contract.DataTypeMultiCall:
+				if data, ok := txParam.Data.([]interface{}); ok {
+					validateMultiCallDataParam(sl, txParam.Data, data)
+				} else {
+					sl.ReportError(txParam.Data, "Data", "", "data", "")
+				}
 			}
 		}
 	}
@@ -201,3 +212,36 @@ func validateDepositDataParam(sl validator.StructLevel, field interface{}, data
 		sl.ReportError(field, "Data", "", "data.action", "")
 	}
 }
+
+func validateMultiCallDataParam(sl validator.StructLevel, field interface{}, data []interface{}) {
+	if len(data) == 0 || len(data) > contract.MultiCallMaxCalls {
+		sl.ReportError(field, "Data", "data", "data", "InvalidNumberOfCalls")
+		return
+	}
+	for i, item := range data {
+		name := fmt.Sprintf("data[%d]", i)
+		call, ok := item.(map[string]interface{})
+		if !ok {
+			sl.ReportError(field, "Data", "", name, "")
+			continue
+		}
+		if to, ok := call["to"].(string); !ok || len(to) == 0 {
+			sl.ReportError(field, "Data", "to", name+".to", "")
+		}
+		if value, ok := call["value"]; ok && !isHexString(value) {
+			sl.ReportError(field, "Data", "value", name+".value", "Invalid T_INT format")
+		}
+		if params, ok := call["params"]; ok {
+			if _, ok := call["method"]; !ok {
+				sl.ReportError(field, "Data", "method", name+".method", "")
+			}
+			if paramsMap, ok := params.(map[string]interface{}); ok {
+				for k, pv := range paramsMap {
+					validateRPCData(sl, name+".params."+k, pv)
+				}
+			} else {
+				sl.ReportError(field, "Data", "", name+".params", "")
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 
 	"github.com/labstack/echo/v4"
@@ -10,6 +11,7 @@ import (
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/v3"
 	"github.com/icon-project/goloop/service/scoreapi"
 	"github.com/icon-project/goloop/service/txresult"
 )
@@ -18,6 +20,7 @@ type EventRequest struct {
 	EventFilter
 	Height           common.HexInt64 `json:"height"`
 	Logs             common.HexBool  `json:"logs,omitempty"`
+	Decode           common.HexBool  `json:"decode,omitempty"`
 	ProgressInterval common.HexInt64 `json:"progressInterval,omitempty"`
 
 	Filters EventFilters `json:"eventFilters,omitempty"`
@@ -45,6 +48,24 @@ type EventNotification struct {
 	Logs   []module.EventLog `json:"logs,omitempty"`
 }
 
+type decodedEventLog struct {
+	module.EventLog
+	dec txresult.EventLogDecoder
+}
+
+func (el *decodedEventLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(txresult.EventLogToJSON(el.EventLog, module.JSONVersionLast, el.dec))
+}
+
+// decodedEventLogs wraps event logs to be marshaled with decoded events.
+func decodedEventLogs(logs []module.EventLog, dec txresult.EventLogDecoder) []module.EventLog {
+	res := make([]module.EventLog, len(logs))
+	for i, el := range logs {
+		res[i] = &decodedEventLog{el, dec}
+	}
+	return res
+}
+
 // FilteredByLogBloom returns applicable event filters.
 // If there is no event filters, then it returns false along with filters.
 func (fs EventFilters) FilteredByLogBloom(lb module.LogsBloom) (EventFilters, bool) {
@@ -157,13 +178,20 @@ loop:
 			if err != nil {
 				break loop
 			}
+			var dec txresult.EventLogDecoder
+			if er.Decode.Value {
+				dec = v3.NewEventLogDecoder(sm, blk.Result())
+			}
 			index := int32(0)
 			for rit := rl.Iterator(); rit.Has(); rit.Next() {
 				r, err := rit.Get()
 				if err != nil {
 					break loop
 				}
-				if es, el, err := filters2.MatchEvents(r, er.Logs.Value); err == nil && len(es) > 0 {
+				if es, el, err := filters2.MatchEvents(r, er.Logs.Value || er.Decode.Value); err == nil && len(es) > 0 {
+					if dec != nil {
+						el = decodedEventLogs(el, dec)
+					}
 					var en EventNotification
 					en.Height.Value = h
 					en.Hash = blk.ID()
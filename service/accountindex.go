@@ -0,0 +1,731 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import (
+	"bytes"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+)
+
+const (
+	keyAccountIndexHeight = "accountIndex.height"
+
+	// maxAccountListSize is the maximum number of accounts in a page of
+	// the account index.
+	maxAccountListSize = 1000
+
+	// accountIndexRetryDelay is the delay before retrying to index a block
+	// after a failure.
+	accountIndexRetryDelay = 5 * time.Second
+)
+
+const (
+	AccountOrderByAddress    = "address"
+	AccountOrderByBalance    = "balance"
+	AccountOrderByStake      = "stake"
+	AccountOrderByDelegation = "delegation"
+	AccountOrderByBond       = "bond"
+)
+
+var accountOrders = []string{
+	AccountOrderByAddress,
+	AccountOrderByBalance,
+	AccountOrderByStake,
+	AccountOrderByDelegation,
+	AccountOrderByBond,
+}
+
+type accountVote struct {
+	Address *common.Address
+	Value   *big.Int
+}
+
+type accountEntry struct {
+	Address     *common.Address
+	Balance     *big.Int
+	Stake       *big.Int
+	Delegation  *big.Int
+	Bond        *big.Int
+	Delegations []accountVote
+}
+
+func (e *accountEntry) isEmpty() bool {
+	return e.Balance.Sign() == 0 && e.Stake.Sign() == 0 &&
+		e.Delegation.Sign() == 0 && e.Bond.Sign() == 0 &&
+		len(e.Delegations) == 0
+}
+
+func (e *accountEntry) valueOf(orderBy string) *big.Int {
+	switch orderBy {
+	case AccountOrderByBalance:
+		return e.Balance
+	case AccountOrderByStake:
+		return e.Stake
+	case AccountOrderByDelegation:
+		return e.Delegation
+	case AccountOrderByBond:
+		return e.Bond
+	default:
+		return nil
+	}
+}
+
+// lessAccount returns whether e1 is ahead of e2 in the order. Accounts are
+// ordered by the value in descending order, and ones with same value are
+// ordered by the address.
+func lessAccount(orderBy string, e1, e2 *accountEntry) bool {
+	if orderBy != AccountOrderByAddress {
+		if c := e1.valueOf(orderBy).Cmp(e2.valueOf(orderBy)); c != 0 {
+			return c > 0
+		}
+	}
+	return bytes.Compare(e1.Address.Bytes(), e2.Address.Bytes()) < 0
+}
+
+func lessVote(v1, v2 *accountVote) bool {
+	if c := v1.Value.Cmp(v2.Value); c != 0 {
+		return c > 0
+	}
+	return bytes.Compare(v1.Address.Bytes(), v2.Address.Bytes()) < 0
+}
+
+func (e *accountEntry) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"address":    e.Address,
+		"balance":    intconv.FormatBigInt(e.Balance),
+		"stake":      intconv.FormatBigInt(e.Stake),
+		"delegation": intconv.FormatBigInt(e.Delegation),
+		"bond":       intconv.FormatBigInt(e.Bond),
+	}
+}
+
+type accountList struct {
+	height   int64
+	total    int
+	accounts []interface{}
+	err      error
+}
+
+func (l *accountList) ToJSON(version module.JSONVersion) (interface{}, error) {
+	jso := map[string]interface{}{
+		"height":   intconv.FormatInt(l.height),
+		"total":    intconv.FormatInt(int64(l.total)),
+		"accounts": l.accounts,
+	}
+	if l.err != nil {
+		jso["error"] = l.err.Error()
+	}
+	return jso, nil
+}
+
+// accountIndex keeps balances and platform specific status of accounts
+// for the debug APIs. It follows finalized blocks, and updates accounts
+// appearing in transactions, receipts and event logs of each block
+// and accounts changed in the world state.
+type accountIndex struct {
+	lock sync.Mutex
+	m    *manager
+	log  log.Logger
+
+	bucket   db.Bucket
+	property db.Bucket
+
+	// height of the last indexed block (-1 for none).
+	height   int64
+	accounts map[string]*accountEntry
+	keys     map[string]string
+
+	// sorted keeps accounts ordered by each order, and delegators keeps
+	// delegations to each address ordered by the amount. They are updated
+	// incrementally on each block.
+	sorted     map[string]*orderedList
+	delegators map[string]*orderedList
+
+	// lastErr is the error of the last attempt to index a block. The index
+	// stalls at the height until it succeeds.
+	lastErr error
+
+	notifyCh chan struct{}
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newAccountIndex(m *manager, logger log.Logger) (*accountIndex, error) {
+	bk, err := m.db.GetBucket(db.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	pbk, err := m.db.GetBucket(db.ChainProperty)
+	if err != nil {
+		return nil, err
+	}
+	ai := &accountIndex{
+		m:          m,
+		log:        logger,
+		bucket:     bk,
+		property:   pbk,
+		height:     -1,
+		accounts:   make(map[string]*accountEntry),
+		keys:       make(map[string]string),
+		sorted:     make(map[string]*orderedList),
+		delegators: make(map[string]*orderedList),
+	}
+	for _, orderBy := range accountOrders {
+		orderBy := orderBy
+		ai.sorted[orderBy] = newOrderedList(func(v1, v2 interface{}) bool {
+			return lessAccount(orderBy, v1.(*accountEntry), v2.(*accountEntry))
+		})
+	}
+	if err := ai.load(); err != nil {
+		return nil, err
+	}
+	return ai, nil
+}
+
+func (ai *accountIndex) load() error {
+	bs, err := ai.property.Get([]byte(keyAccountIndexHeight))
+	if err != nil || bs == nil {
+		return err
+	}
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &ai.height); err != nil {
+		return err
+	}
+	itr, ok := ai.bucket.(db.Iterable)
+	if !ok {
+		return errors.UnsupportedError.New("AccountIndexNotIterable")
+	}
+	var derr error
+	err = itr.Iterate(func(key, value []byte) bool {
+		e := new(accountEntry)
+		if _, derr = codec.BC.UnmarshalFromBytes(value, e); derr != nil {
+			return false
+		}
+		ai.setEntry(e)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return derr
+}
+
+func lessVoteValue(v1, v2 interface{}) bool {
+	return lessVote(v1.(*accountVote), v2.(*accountVote))
+}
+
+func (ai *accountIndex) setEntry(e *accountEntry) {
+	key := string(e.Address.Bytes())
+	if old, ok := ai.accounts[key]; ok {
+		ai.unlinkEntry(old)
+	}
+	ai.accounts[key] = e
+	ai.keys[string(state.AccountKeyOf(e.Address.ID()))] = key
+	for _, orderBy := range accountOrders {
+		ai.sorted[orderBy].Add(e)
+	}
+	for _, d := range e.Delegations {
+		key := string(d.Address.Bytes())
+		votes, ok := ai.delegators[key]
+		if !ok {
+			votes = newOrderedList(lessVoteValue)
+			ai.delegators[key] = votes
+		}
+		votes.Add(&accountVote{Address: e.Address, Value: d.Value})
+	}
+}
+
+func (ai *accountIndex) removeEntry(addr module.Address) {
+	key := string(addr.Bytes())
+	if old, ok := ai.accounts[key]; ok {
+		ai.unlinkEntry(old)
+	}
+	delete(ai.accounts, key)
+	delete(ai.keys, string(state.AccountKeyOf(addr.ID())))
+}
+
+// unlinkEntry removes the entry from sorted accounts and delegators.
+func (ai *accountIndex) unlinkEntry(e *accountEntry) {
+	for _, orderBy := range accountOrders {
+		ai.sorted[orderBy].Remove(e)
+	}
+	for _, d := range e.Delegations {
+		key := string(d.Address.Bytes())
+		votes, ok := ai.delegators[key]
+		if !ok {
+			continue
+		}
+		votes.Remove(&accountVote{Address: e.Address, Value: d.Value})
+		if votes.Len() == 0 {
+			delete(ai.delegators, key)
+		}
+	}
+}
+
+func (ai *accountIndex) Start() {
+	if ai.stopCh != nil {
+		return
+	}
+	ai.notifyCh = make(chan struct{}, 1)
+	ai.stopCh = make(chan struct{})
+	ai.wg.Add(1)
+	go ai.run(ai.notifyCh, ai.stopCh)
+	ai.Notify()
+}
+
+func (ai *accountIndex) Stop() {
+	if ai.stopCh != nil {
+		close(ai.stopCh)
+		ai.wg.Wait()
+		ai.stopCh = nil
+	}
+}
+
+// Notify wakes up the index to follow newly finalized blocks.
+func (ai *accountIndex) Notify() {
+	select {
+	case ai.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (ai *accountIndex) setError(err error) {
+	ai.lock.Lock()
+	defer ai.lock.Unlock()
+	ai.lastErr = err
+}
+
+func (ai *accountIndex) run(notifyCh, stopCh chan struct{}) {
+	defer ai.wg.Done()
+	var retryCh <-chan time.Time
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-notifyCh:
+		case <-retryCh:
+		}
+		retryCh = nil
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			ok, err := ai.indexNext()
+			ai.setError(err)
+			if err != nil {
+				ai.log.Warnf("AccountIndex fails to index height=%d err=%+v (retry after %v)",
+					ai.height+1, err, accountIndexRetryDelay)
+				retryCh = time.After(accountIndexRetryDelay)
+				break
+			}
+			if !ok {
+				break
+			}
+		}
+	}
+}
+
+func (ai *accountIndex) getBlock(bm module.BlockManager, height int64) (module.Block, error) {
+	blk, err := bm.GetBlockByHeight(height)
+	if errors.NotFoundError.Equals(err) {
+		return nil, nil
+	}
+	return blk, err
+}
+
+// needsBootstrap returns whether the index should be built from the world
+// state of the last block instead of following blocks from the indexed
+// height. States before the genesis don't exist for the chain started from
+// a pruned genesis or an imported snapshot, and states of old blocks are
+// deleted with the state retention.
+func needsBootstrap(height, genesis, retention, last int64) bool {
+	if retention > 0 && last-height >= retention {
+		return true
+	}
+	return height+1 < genesis || (height < 0 && retention > 0)
+}
+
+// bootstrap indexes accounts in the world state after the block before the
+// last block, so states of old blocks are not required. The world state
+// keeps accounts with hashes of their addresses, so accounts of already
+// indexed addresses and addresses in the genesis are indexed here, and
+// others are indexed when they appear in the following blocks.
+func (ai *accountIndex) bootstrap(bm module.BlockManager, last module.Block) (bool, error) {
+	genesis := ai.m.chain.GenesisStorage().Height()
+	height := last.Height() - 1
+	if height < genesis || height <= ai.height {
+		return false, nil
+	}
+	ai.log.Infof("AccountIndex bootstraps from the state height=%d indexed=%d",
+		height, ai.height)
+
+	addrs := make(map[string]module.Address)
+	add := func(addr module.Address) {
+		if addr != nil {
+			addrs[string(addr.Bytes())] = addr
+		}
+	}
+	ai.lock.Lock()
+	for _, e := range ai.accounts {
+		add(e.Address)
+	}
+	ai.lock.Unlock()
+	if blk, err := ai.getBlock(bm, genesis); err != nil {
+		return false, err
+	} else if blk != nil {
+		if err := addressesOfTransactions(blk.NormalTransactions(), add); err != nil {
+			return false, err
+		}
+	}
+
+	wss, err := ai.m.trc.GetWorldSnapshot(last.Result(), nil)
+	if err != nil {
+		return false, err
+	}
+	entries := make([]*accountEntry, 0, len(addrs))
+	for _, addr := range addrs {
+		e, err := ai.entryOf(wss, addr)
+		if err != nil {
+			return false, err
+		}
+		entries = append(entries, e)
+	}
+	return true, ai.apply(height, entries)
+}
+
+// indexNext indexes the block next to the last indexed block. It returns
+// false if the block or its result is not finalized yet.
+func (ai *accountIndex) indexNext() (bool, error) {
+	bm := ai.m.chain.BlockManager()
+	if bm == nil {
+		return false, nil
+	}
+	last, err := bm.GetLastBlock()
+	if last == nil || err != nil {
+		if errors.NotFoundError.Equals(err) {
+			err = nil
+		}
+		return false, err
+	}
+	if needsBootstrap(ai.height, ai.m.chain.GenesisStorage().Height(),
+		ai.m.chain.StateRetention(), last.Height()) {
+		return ai.bootstrap(bm, last)
+	}
+	height := ai.height + 1
+	blk, err := ai.getBlock(bm, height)
+	if blk == nil || err != nil {
+		return false, err
+	}
+	next, err := ai.getBlock(bm, height+1)
+	if next == nil || err != nil {
+		return false, err
+	}
+
+	addrs := make(map[string]module.Address)
+	add := func(addr module.Address) {
+		if addr != nil {
+			addrs[string(addr.Bytes())] = addr
+		}
+	}
+	for _, txs := range []module.TransactionList{blk.PatchTransactions(), blk.NormalTransactions()} {
+		if err := addressesOfTransactions(txs, add); err != nil {
+			return false, err
+		}
+	}
+	for _, g := range []module.TransactionGroup{module.TransactionGroupPatch, module.TransactionGroupNormal} {
+		rl, err := ai.m.trc.GetReceipts(next.Result(), g)
+		if err != nil {
+			return false, err
+		}
+		if err := addressesOfReceipts(rl, add); err != nil {
+			return false, err
+		}
+	}
+
+	wss1, err := ai.m.trc.GetWorldSnapshot(blk.Result(), nil)
+	if err != nil {
+		return false, err
+	}
+	wss2, err := ai.m.trc.GetWorldSnapshot(next.Result(), nil)
+	if err != nil {
+		return false, err
+	}
+	err = state.DiffAccounts(wss1, wss2,
+		func(op int, key []byte, ass1, ass2 state.AccountSnapshot) error {
+			if addr, ok := ai.keys[string(key)]; ok {
+				add(common.MustNewAddress([]byte(addr)))
+			}
+			return nil
+		})
+	if err != nil {
+		return false, err
+	}
+
+	entries := make([]*accountEntry, 0, len(addrs))
+	for _, addr := range addrs {
+		e, err := ai.entryOf(wss2, addr)
+		if err != nil {
+			return false, err
+		}
+		entries = append(entries, e)
+	}
+	return true, ai.apply(height, entries)
+}
+
+func addressesOfTransactions(txs module.TransactionList, add func(addr module.Address)) error {
+	for itr := txs.Iterator(); itr.Has(); itr.Next() {
+		tx, _, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		add(tx.From())
+		if t, ok := tx.(transaction.Transaction); ok {
+			add(t.To())
+		}
+		if tx.From() == nil {
+			addressesOfGenesis(tx, add)
+		}
+	}
+	return nil
+}
+
+// addressesOfGenesis adds addresses of accounts in the genesis transaction.
+func addressesOfGenesis(tx module.Transaction, add func(addr module.Address)) {
+	jso, err := tx.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return
+	}
+	m, _ := jso.(map[string]interface{})
+	accounts, _ := m["accounts"].([]interface{})
+	for _, account := range accounts {
+		info, _ := account.(map[string]interface{})
+		if s, ok := info["address"].(string); ok {
+			if addr, err := common.NewAddressFromString(s); err == nil {
+				add(addr)
+			}
+		}
+	}
+}
+
+func addressesOfReceipts(rl module.ReceiptList, add func(addr module.Address)) error {
+	for itr := rl.Iterator(); itr.Has(); itr.Next() {
+		rct, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		add(rct.To())
+		add(rct.SCOREAddress())
+		for fitr := rct.FeePaymentIterator(); fitr.Has(); fitr.Next() {
+			fp, err := fitr.Get()
+			if err != nil {
+				return err
+			}
+			add(fp.Payer())
+		}
+		for eitr := rct.EventLogIterator(); eitr.Has(); eitr.Next() {
+			ev, err := eitr.Get()
+			if err != nil {
+				return err
+			}
+			add(ev.Address())
+			for _, params := range [][][]byte{ev.Indexed(), ev.Data()} {
+				for _, param := range params {
+					if len(param) != common.AddressBytes {
+						continue
+					}
+					if addr, err := common.NewAddress(param); err == nil {
+						add(addr)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (ai *accountIndex) entryOf(wss state.WorldSnapshot, addr module.Address) (*accountEntry, error) {
+	e := &accountEntry{
+		Address:    common.AddressToPtr(addr),
+		Balance:    new(big.Int),
+		Stake:      new(big.Int),
+		Delegation: new(big.Int),
+		Bond:       new(big.Int),
+	}
+	if ass := wss.GetAccountSnapshot(addr.ID()); ass != nil {
+		e.Balance = ass.GetBalance()
+	}
+	if r, ok := ai.m.plt.(base.AccountStatusReader); ok {
+		status, err := r.GetAccountStatus(wss.GetExtensionSnapshot(), addr)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			setNonNil(&e.Stake, status.Stake)
+			setNonNil(&e.Delegation, status.Delegation)
+			setNonNil(&e.Bond, status.Bond)
+			for _, d := range status.Delegations {
+				e.Delegations = append(e.Delegations, accountVote{
+					Address: common.AddressToPtr(d.Address),
+					Value:   d.Value,
+				})
+			}
+		}
+	}
+	return e, nil
+}
+
+func setNonNil(dst **big.Int, v *big.Int) {
+	if v != nil {
+		*dst = v
+	}
+}
+
+func (ai *accountIndex) apply(height int64, entries []*accountEntry) error {
+	ai.lock.Lock()
+	defer ai.lock.Unlock()
+
+	for _, e := range entries {
+		key := e.Address.Bytes()
+		if e.isEmpty() {
+			if err := ai.bucket.Delete(key); err != nil {
+				return err
+			}
+			ai.removeEntry(e.Address)
+		} else {
+			if err := ai.bucket.Set(key, codec.BC.MustMarshalToBytes(e)); err != nil {
+				return err
+			}
+			ai.setEntry(e)
+		}
+	}
+	err := ai.property.Set([]byte(keyAccountIndexHeight), codec.BC.MustMarshalToBytes(height))
+	if err != nil {
+		return err
+	}
+	ai.height = height
+	return nil
+}
+
+func pageOf(total, skip, limit int) (int, int, error) {
+	if skip < 0 || limit < 0 || limit > maxAccountListSize {
+		return 0, 0, errors.IllegalArgumentError.Errorf(
+			"InvalidPage(skip=%d,limit=%d)", skip, limit)
+	}
+	if limit == 0 {
+		limit = maxAccountListSize
+	}
+	if skip > total {
+		skip = total
+	}
+	end := skip + limit
+	if end > total {
+		end = total
+	}
+	return skip, end, nil
+}
+
+// GetAccounts returns accounts ordered by orderBy as lessAccount.
+func (ai *accountIndex) GetAccounts(orderBy string, skip, limit int) (module.AccountList, error) {
+	switch orderBy {
+	case "":
+		orderBy = AccountOrderByAddress
+	case AccountOrderByAddress, AccountOrderByBalance, AccountOrderByStake,
+		AccountOrderByDelegation, AccountOrderByBond:
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("InvalidOrderBy(%s)", orderBy)
+	}
+
+	ai.lock.Lock()
+	defer ai.lock.Unlock()
+
+	entries := ai.sorted[orderBy]
+	start, end, err := pageOf(entries.Len(), skip, limit)
+	if err != nil {
+		return nil, err
+	}
+	l := &accountList{
+		height:   ai.height,
+		total:    entries.Len(),
+		accounts: make([]interface{}, 0, end-start),
+		err:      ai.lastErr,
+	}
+	for _, e := range entries.Slice(start, end) {
+		l.accounts = append(l.accounts, e.(*accountEntry).ToJSON())
+	}
+	return l, nil
+}
+
+// GetDelegators returns accounts delegating to the address with amounts of
+// delegation in descending order.
+func (ai *accountIndex) GetDelegators(addr module.Address, skip, limit int) (module.AccountList, error) {
+	ai.lock.Lock()
+	defer ai.lock.Unlock()
+
+	var total int
+	delegators, ok := ai.delegators[string(addr.Bytes())]
+	if ok {
+		total = delegators.Len()
+	}
+	start, end, err := pageOf(total, skip, limit)
+	if err != nil {
+		return nil, err
+	}
+	l := &accountList{
+		height:   ai.height,
+		total:    total,
+		accounts: make([]interface{}, 0, end-start),
+		err:      ai.lastErr,
+	}
+	if !ok {
+		return l, nil
+	}
+	for _, v := range delegators.Slice(start, end) {
+		d := v.(*accountVote)
+		l.accounts = append(l.accounts, map[string]interface{}{
+			"address": d.Address,
+			"value":   intconv.FormatBigInt(d.Value),
+		})
+	}
+	return l, nil
+}
+
+func (m *manager) GetAccounts(orderBy string, skip, limit int) (module.AccountList, error) {
+	if m.ai == nil {
+		return nil, errors.UnsupportedError.New("AccountIndexDisabled")
+	}
+	return m.ai.GetAccounts(orderBy, skip, limit)
+}
+
+func (m *manager) GetDelegators(addr module.Address, skip, limit int) (module.AccountList, error) {
+	if m.ai == nil {
+		return nil, errors.UnsupportedError.New("AccountIndexDisabled")
+	}
+	return m.ai.GetDelegators(addr, skip, limit)
+}
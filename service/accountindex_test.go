@@ -0,0 +1,233 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+func newTestAccountEntry(addr string, balance, delegation int64, preps ...string) *accountEntry {
+	e := &accountEntry{
+		Address:    common.MustNewAddressFromString(addr),
+		Balance:    big.NewInt(balance),
+		Stake:      big.NewInt(delegation),
+		Delegation: big.NewInt(delegation),
+		Bond:       new(big.Int),
+	}
+	for _, prep := range preps {
+		e.Delegations = append(e.Delegations, accountVote{
+			Address: common.MustNewAddressFromString(prep),
+			Value:   big.NewInt(delegation),
+		})
+	}
+	return e
+}
+
+func accountsOf(t *testing.T, l module.AccountList) []interface{} {
+	jso, err := l.ToJSON(module.JSONVersion3)
+	assert.NoError(t, err)
+	return jso.(map[string]interface{})["accounts"].([]interface{})
+}
+
+func TestAccountIndex_GetAccounts(t *testing.T) {
+	m := &manager{db: db.NewMapDB()}
+	ai, err := newAccountIndex(m, log.New())
+	assert.NoError(t, err)
+
+	prep := "hx3f01840a599da07b0f620eeae7aa9c574169a4be"
+	err = ai.apply(10, []*accountEntry{
+		newTestAccountEntry("hx0000000000000000000000000000000000000001", 100, 0),
+		newTestAccountEntry("hx0000000000000000000000000000000000000002", 300, 20, prep),
+		newTestAccountEntry("hx0000000000000000000000000000000000000003", 200, 30, prep),
+		newTestAccountEntry("hx0000000000000000000000000000000000000004", 0, 0),
+	})
+	assert.NoError(t, err)
+
+	l, err := ai.GetAccounts(AccountOrderByBalance, 0, 2)
+	assert.NoError(t, err)
+	jso, _ := l.ToJSON(module.JSONVersion3)
+	assert.Equal(t, "0xa", jso.(map[string]interface{})["height"])
+	assert.Equal(t, "0x3", jso.(map[string]interface{})["total"])
+	accounts := accountsOf(t, l)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, "0x12c", accounts[0].(map[string]interface{})["balance"])
+	assert.Equal(t, "0xc8", accounts[1].(map[string]interface{})["balance"])
+
+	l, err = ai.GetAccounts(AccountOrderByBalance, 2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, accountsOf(t, l), 1)
+
+	l, err = ai.GetAccounts("", 0, 0)
+	assert.NoError(t, err)
+	accounts = accountsOf(t, l)
+	assert.Len(t, accounts, 3)
+	assert.Equal(t, "hx0000000000000000000000000000000000000001",
+		accounts[0].(map[string]interface{})["address"].(*common.Address).String())
+
+	_, err = ai.GetAccounts("nonce", 0, 0)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+	_, err = ai.GetAccounts(AccountOrderByStake, 0, maxAccountListSize+1)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	l, err = ai.GetDelegators(common.MustNewAddressFromString(prep), 0, 0)
+	assert.NoError(t, err)
+	accounts = accountsOf(t, l)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, "0x1e", accounts[0].(map[string]interface{})["value"])
+
+	// removing empty account
+	err = ai.apply(11, []*accountEntry{
+		newTestAccountEntry("hx0000000000000000000000000000000000000001", 0, 0),
+	})
+	assert.NoError(t, err)
+
+	// reload the index
+	ai2, err := newAccountIndex(m, log.New())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, ai2.height)
+	l, err = ai2.GetAccounts(AccountOrderByDelegation, 0, 0)
+	assert.NoError(t, err)
+	accounts = accountsOf(t, l)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, "hx0000000000000000000000000000000000000003",
+		accounts[0].(map[string]interface{})["address"].(*common.Address).String())
+}
+
+func addressesOf(t *testing.T, l module.AccountList) []string {
+	var addrs []string
+	for _, account := range accountsOf(t, l) {
+		addrs = append(addrs,
+			account.(map[string]interface{})["address"].(*common.Address).String())
+	}
+	return addrs
+}
+
+func TestAccountIndex_Update(t *testing.T) {
+	m := &manager{db: db.NewMapDB()}
+	ai, err := newAccountIndex(m, log.New())
+	assert.NoError(t, err)
+
+	prep1 := "hx3f01840a599da07b0f620eeae7aa9c574169a4be"
+	prep2 := "hx4f01840a599da07b0f620eeae7aa9c574169a4be"
+	addr1 := "hx0000000000000000000000000000000000000001"
+	addr2 := "hx0000000000000000000000000000000000000002"
+	addr3 := "hx0000000000000000000000000000000000000003"
+	err = ai.apply(1, []*accountEntry{
+		newTestAccountEntry(addr1, 100, 10, prep1),
+		newTestAccountEntry(addr2, 200, 20, prep1),
+		newTestAccountEntry(addr3, 300, 30, prep1),
+	})
+	assert.NoError(t, err)
+
+	// move addr1 to the top, and addr3 to the other prep
+	err = ai.apply(2, []*accountEntry{
+		newTestAccountEntry(addr1, 400, 40, prep1),
+		newTestAccountEntry(addr3, 300, 30, prep2),
+	})
+	assert.NoError(t, err)
+
+	l, err := ai.GetAccounts(AccountOrderByBalance, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{addr1, addr3, addr2}, addressesOf(t, l))
+	l, err = ai.GetAccounts(AccountOrderByAddress, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{addr1, addr2, addr3}, addressesOf(t, l))
+
+	l, err = ai.GetDelegators(common.MustNewAddressFromString(prep1), 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{addr1, addr2}, addressesOf(t, l))
+	l, err = ai.GetDelegators(common.MustNewAddressFromString(prep2), 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{addr3}, addressesOf(t, l))
+
+	// remove addr1
+	err = ai.apply(3, []*accountEntry{
+		newTestAccountEntry(addr1, 0, 0),
+	})
+	assert.NoError(t, err)
+	for _, orderBy := range accountOrders {
+		l, err = ai.GetAccounts(orderBy, 0, 0)
+		assert.NoError(t, err)
+		assert.Len(t, accountsOf(t, l), 2, orderBy)
+	}
+	l, err = ai.GetDelegators(common.MustNewAddressFromString(prep1), 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{addr2}, addressesOf(t, l))
+	assert.Equal(t, 2, ai.sorted[AccountOrderByBond].Len())
+
+	// the index loaded from the database has same orders
+	ai2, err := newAccountIndex(m, log.New())
+	assert.NoError(t, err)
+	for _, orderBy := range accountOrders {
+		l, err = ai.GetAccounts(orderBy, 0, 0)
+		assert.NoError(t, err)
+		l2, err := ai2.GetAccounts(orderBy, 0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, addressesOf(t, l), addressesOf(t, l2), orderBy)
+	}
+	l, err = ai2.GetDelegators(common.MustNewAddressFromString(prep2), 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{addr3}, addressesOf(t, l))
+}
+
+func TestAccountIndex_Error(t *testing.T) {
+	m := &manager{db: db.NewMapDB()}
+	ai, err := newAccountIndex(m, log.New())
+	assert.NoError(t, err)
+
+	ai.setError(errors.InvalidStateError.New("NoResult"))
+	l, err := ai.GetAccounts("", 0, 0)
+	assert.NoError(t, err)
+	jso, _ := l.ToJSON(module.JSONVersion3)
+	assert.Contains(t, jso.(map[string]interface{})["error"], "NoResult")
+
+	ai.setError(nil)
+	l, err = ai.GetDelegators(common.MustNewAddressFromString(
+		"hx3f01840a599da07b0f620eeae7aa9c574169a4be"), 0, 0)
+	assert.NoError(t, err)
+	jso, _ = l.ToJSON(module.JSONVersion3)
+	assert.NotContains(t, jso.(map[string]interface{}), "error")
+}
+
+func TestAccountIndex_NeedsBootstrap(t *testing.T) {
+	cases := []struct {
+		name                             string
+		height, genesis, retention, last int64
+		exp                              bool
+	}{
+		{"FromGenesis", -1, 0, 0, 100, false},
+		{"Following", 50, 0, 0, 100, false},
+		{"PrunedGenesis", -1, 30, 0, 100, true},
+		{"AfterPrunedGenesis", 50, 30, 0, 100, false},
+		{"Retention", -1, 0, 1000, 100, true},
+		{"InRetention", 50, 0, 1000, 100, false},
+		{"OutOfRetention", 50, 0, 10, 100, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.exp, needsBootstrap(c.height, c.genesis, c.retention, c.last))
+		})
+	}
+}
@@ -244,6 +244,9 @@ func (h *CallHandler) DoExecuteAsync(cc CallContext, ch eeproxy.CallContext, sto
 func (h *CallHandler) invokeEEMethod(cc CallContext, c state.ContractState) error {
 	h.conn = cc.GetProxy(h.EEType())
 	if h.conn == nil {
+		if h.EEType().IsOptional() {
+			return errNoEngine(h.EEType())
+		}
 		return errors.ExecutionFailError.Errorf(
 			"FAIL to get connection for (%s)", h.EEType())
 	}
@@ -74,11 +74,12 @@ type (
 )
 
 const (
-	DataTypeCall    = "call"
-	DataTypeMessage = "message"
-	DataTypeDeploy  = "deploy"
-	DataTypeDeposit = "deposit"
-	DataTypePatch   = "patch"
+	DataTypeCall      = "call"
+	DataTypeMessage   = "message"
+	DataTypeDeploy    = "deploy"
+	DataTypeDeposit   = "deposit"
+	DataTypePatch     = "patch"
+	DataTypeMultiCall = "multicall"
 )
 
 func IsCallableDataType(dt *string) bool {
@@ -200,6 +201,8 @@ func (cm *contractManager) GetHandler(from, to module.Address, value *big.Int, c
 		return newPatchHandler(ch, data)
 	case CTypeDeposit:
 		return newDepositHandler(ch, data)
+	case CTypeMultiCall:
+		return newMultiCallHandler(ch, data), nil
 	}
 	return handler, nil
 }
@@ -347,6 +350,8 @@ func NewContractManager(db db.Database, contractDir string, log log.Logger) (Con
 
 const (
 	javaCode               = "code.jar"
+	goCode                 = "score.id"
+	wasmCode               = "score.wasm"
 	tmpRoot                = "tmp"
 	tmpPattern             = "tmp-*"
 	contractPythonRootFile = "package.json"
@@ -443,6 +448,36 @@ func storeJava(path string, code []byte, log log.Logger) error {
 	return nil
 }
 
+func storeGo(path string, code []byte, log log.Logger) error {
+	if len(code) == 0 {
+		return scoreresult.IllegalFormatError.New("NoScoreID")
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.WithCode(err, errors.CriticalIOError)
+	}
+	sPath := filepath.Join(path, goCode)
+	if err := ioutil.WriteFile(sPath, code, 0644); err != nil {
+		_ = os.RemoveAll(sPath)
+		return errors.WithCode(err, errors.CriticalIOError)
+	}
+	return nil
+}
+
+func storeWasm(path string, code []byte, log log.Logger) error {
+	if len(code) == 0 {
+		return scoreresult.IllegalFormatError.New("NoCode")
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.WithCode(err, errors.CriticalIOError)
+	}
+	sPath := filepath.Join(path, wasmCode)
+	if err := ioutil.WriteFile(sPath, code, 0644); err != nil {
+		_ = os.RemoveAll(sPath)
+		return errors.WithCode(err, errors.CriticalIOError)
+	}
+	return nil
+}
+
 func storeByEEType(e state.EEType, path string, code []byte, log log.Logger) error {
 	var err error
 	switch e {
@@ -450,6 +485,10 @@ func storeByEEType(e state.EEType, path string, code []byte, log log.Logger) err
 		err = storePython(path, code, log)
 	case state.JavaEE:
 		err = storeJava(path, code, log)
+	case state.GoEE:
+		err = storeGo(path, code, log)
+	case state.WasmEE:
+		err = storeWasm(path, code, log)
 	default:
 		err = scoreresult.Errorf(module.StatusInvalidParameter,
 			"UnexpectedEEType(%v)\n", e)
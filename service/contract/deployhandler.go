@@ -320,7 +320,7 @@ func (h *DeployHandler) DoExecuteSync(cc CallContext) (error, *codec.TypedObj, m
 		return scoreresult.ErrAccessDenied, nil, nil
 	}
 
-	if !state.ValidateEEType(h.eeType) {
+	if !state.ValidateEEType(h.eeType) || !h.eeType.IsEnabledBy(cc.Revision()) {
 		return scoreresult.InvalidParameterError.Errorf("InvalidContentType(ct=%s)",
 			h.contentType), nil, nil
 	}
@@ -526,6 +526,9 @@ func (h *callGetAPIHandler) ExecuteAsync(cc CallContext) (ret error) {
 
 	conn := h.cc.GetProxy(h.EEType())
 	if conn == nil {
+		if h.EEType().IsOptional() {
+			return errNoEngine(h.EEType())
+		}
 		return NoAvailableProxy.Errorf(
 			"FAIL to get connection of (%s)", h.EEType())
 	}
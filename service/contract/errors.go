@@ -2,9 +2,18 @@ package contract
 
 import (
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
 )
 
 const (
 	PreparingContractError = iota + errors.CodeService + 200
 	NoAvailableProxy
 )
+
+// errNoEngine returns an error for the optional type whose engine is not
+// running on the node. It fails the call instead of rerunning the
+// transaction, so the node doesn't stop on the block.
+func errNoEngine(et state.EEType) error {
+	return scoreresult.UnknownFailureError.Errorf("NoEngine(type=%s)", et)
+}
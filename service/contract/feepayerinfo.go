@@ -35,10 +35,20 @@ type FeePayer struct {
 	paidSteps *big.Int
 	feeSteps  *big.Int
 	parent    *FeePayer
+	balance   bool
+	fee       *big.Int
+}
+
+// NewSponsor returns FeePayer paying all steps with the balance of the payer.
+// It's used for the fee payer of sponsored transaction.
+func NewSponsor(payer module.Address) *FeePayer {
+	return &FeePayer{payer: payer, portion: 100, balance: true}
 }
 
 func (p *FeePayer) PaySteps(ctx CallContext, steps *big.Int) (*big.Int, error) {
-	if bytes.Equal(p.payer.ID(), state.SystemID) {
+	if p.balance {
+		return p.payWithBalance(ctx, steps)
+	} else if bytes.Equal(p.payer.ID(), state.SystemID) {
 		return p.payWithSystemDeposit(ctx, steps)
 	} else {
 		return p.payWithAccountDeposit(ctx, steps)
@@ -77,6 +87,53 @@ func (p *FeePayer) payWithAccountDeposit(ctx CallContext, steps *big.Int) (*big.
 	}
 }
 
+// payWithBalance pays whole steps with the balance of the payer.
+// If the payer doesn't have enough balance, then it pays nothing.
+func (p *FeePayer) payWithBalance(ctx CallContext, steps *big.Int) (*big.Int, error) {
+	as := ctx.GetAccountState(p.payer.ID())
+	p.ensureStepsToPay(steps)
+	fee := new(big.Int).Mul(p.steps, ctx.StepPrice())
+	balance := as.GetBalance()
+	if balance.Cmp(fee) < 0 {
+		p.paidSteps = new(big.Int)
+		p.feeSteps = nil
+		return p.paidSteps, nil
+	}
+	as.SetBalance(new(big.Int).Sub(balance, fee))
+	p.paidSteps = p.steps
+	p.feeSteps = p.steps
+	p.fee = fee
+	return p.paidSteps, nil
+}
+
+// Refund returns the fee paid with the balance to the payer, then clears
+// logs of the payment.
+func (p *FeePayer) Refund(ctx CallContext) {
+	if p.fee != nil {
+		as := ctx.GetAccountState(p.payer.ID())
+		as.SetBalance(new(big.Int).Add(as.GetBalance(), p.fee))
+	}
+	p.ClearLogs()
+}
+
+// GetLogs adds the payment of the payer to the receipt.
+// It returns true if the payer paid some steps.
+func (p *FeePayer) GetLogs(r txresult.Receipt) bool {
+	if p.paidSteps == nil || p.paidSteps.Sign() == 0 {
+		return false
+	}
+	r.AddPayment(p.payer, p.paidSteps, p.feeSteps)
+	return true
+}
+
+func (p *FeePayer) ClearLogs() {
+	p.steps = nil
+	p.baseSteps = nil
+	p.paidSteps = nil
+	p.feeSteps = nil
+	p.fee = nil
+}
+
 var portionBase = big.NewInt(100)
 
 func (p *FeePayer) calcStepsToPay(s *big.Int) *big.Int {
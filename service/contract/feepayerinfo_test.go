@@ -34,6 +34,18 @@ type payAccountState struct {
 	state.AccountState
 	store   map[string][]byte
 	deposit *big.Int
+	balance *big.Int
+}
+
+func (as *payAccountState) GetBalance() *big.Int {
+	if as.balance == nil {
+		return new(big.Int)
+	}
+	return as.balance
+}
+
+func (as *payAccountState) SetBalance(v *big.Int) {
+	as.balance = v
 }
 
 func (as *payAccountState) ensureStore() {
@@ -370,4 +382,82 @@ func TestFeePayerInfo_GetLogs(t *testing.T) {
 		}
 		assert.Equal(t, expectedPayments, rct.payments)
 	});
-}
\ No newline at end of file
+}
+
+func TestFeePayer_PayWithBalance(t *testing.T) {
+	payer := common.MustNewAddressFromString("hx12")
+	payerIDStr := string(payer.ID())
+
+	t.Run("enough_balance", func(t *testing.T) {
+		cc := &payCallContext{
+			accounts: map[string]*payAccountState{
+				payerIDStr: {
+					balance: big.NewInt(10000),
+				},
+			},
+			stepPrice: big.NewInt(10),
+		}
+		sponsor := NewSponsor(payer)
+		steps := big.NewInt(700)
+		paid, err := sponsor.PaySteps(cc, steps)
+		assert.NoError(t, err)
+		assert.Equal(t, steps, paid)
+		assert.Equal(t, big.NewInt(3000), cc.accounts[payerIDStr].GetBalance())
+
+		rct := new(payReceipt)
+		assert.True(t, sponsor.GetLogs(rct))
+		expectedPayments := []*feePayment{
+			{payer, steps, steps},
+		}
+		assert.Equal(t, expectedPayments, rct.payments)
+
+		sponsor.ClearLogs()
+		rct = new(payReceipt)
+		assert.False(t, sponsor.GetLogs(rct))
+		assert.Empty(t, rct.payments)
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		cc := &payCallContext{
+			accounts: map[string]*payAccountState{
+				payerIDStr: {
+					balance: big.NewInt(10000),
+				},
+			},
+			stepPrice: big.NewInt(10),
+		}
+		sponsor := NewSponsor(payer)
+		paid, err := sponsor.PaySteps(cc, big.NewInt(700))
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(700), paid)
+		assert.Equal(t, big.NewInt(3000), cc.accounts[payerIDStr].GetBalance())
+
+		sponsor.Refund(cc)
+		assert.Equal(t, big.NewInt(10000), cc.accounts[payerIDStr].GetBalance())
+		rct := new(payReceipt)
+		assert.False(t, sponsor.GetLogs(rct))
+
+		// nothing to refund after it's refunded
+		sponsor.Refund(cc)
+		assert.Equal(t, big.NewInt(10000), cc.accounts[payerIDStr].GetBalance())
+	})
+
+	t.Run("not_enough_balance", func(t *testing.T) {
+		cc := &payCallContext{
+			accounts: map[string]*payAccountState{
+				payerIDStr: {
+					balance: big.NewInt(6999),
+				},
+			},
+			stepPrice: big.NewInt(10),
+		}
+		sponsor := NewSponsor(payer)
+		paid, err := sponsor.PaySteps(cc, big.NewInt(700))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, paid.Sign())
+		assert.Equal(t, big.NewInt(6999), cc.accounts[payerIDStr].GetBalance())
+
+		rct := new(payReceipt)
+		assert.False(t, sponsor.GetLogs(rct))
+	})
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	MultiCallMaxCalls = 32
+)
+
+type MultiCallJSON struct {
+	To     common.Address  `json:"to"`
+	Value  *common.HexInt  `json:"value,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// ParseMultiCallData parses data of multicall transaction, which is the
+// list of calls.
+func ParseMultiCallData(data []byte) ([]*MultiCallJSON, error) {
+	var calls []*MultiCallJSON
+	jd := json.NewDecoder(bytes.NewBuffer(data))
+	jd.DisallowUnknownFields()
+	if err := jd.Decode(&calls); err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrapf(err,
+			"InvalidJSON(json=%s)", data)
+	}
+	if len(calls) == 0 || len(calls) > MultiCallMaxCalls {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidNumberOfCalls(calls=%d)", len(calls))
+	}
+	for idx, call := range calls {
+		if call == nil {
+			return nil, scoreresult.InvalidParameterError.Errorf(
+				"NullCall(idx=%d)", idx)
+		}
+		if call.Value != nil && call.Value.Sign() < 0 {
+			return nil, scoreresult.InvalidParameterError.Errorf(
+				"InvalidValue(idx=%d,value=%s)", idx, call.Value)
+		}
+		if call.Method == "" && len(call.Params) > 0 {
+			return nil, scoreresult.InvalidParameterError.Errorf(
+				"NoMethod(idx=%d)", idx)
+		}
+	}
+	return calls, nil
+}
+
+// MultiCallResult is the result of a call in multicall transaction.
+type MultiCallResult struct {
+	Status   error
+	StepUsed *big.Int
+}
+
+// MultiCallHandler runs the calls in order in its frame. If one of them
+// fails, then all changes of the calls are rolled back.
+type MultiCallHandler struct {
+	*CommonHandler
+	calls   []*MultiCallJSON
+	results []MultiCallResult
+}
+
+func newMultiCallHandler(ch *CommonHandler, data []byte) *MultiCallHandler {
+	h := &MultiCallHandler{CommonHandler: ch}
+	if calls, err := ParseMultiCallData(data); err == nil {
+		h.calls = calls
+	} else {
+		ch.Log.Debugf("FAIL to parse multicall data err=%+v", err)
+	}
+	return h
+}
+
+func (h *MultiCallHandler) Prepare(ctx Context) (state.WorldContext, error) {
+	lq := []state.LockRequest{
+		{state.WorldIDStr, state.AccountWriteLock},
+	}
+	return ctx.GetFuture(lq), nil
+}
+
+// Results returns the results of the calls executed. A failed call is
+// the last one.
+func (h *MultiCallHandler) Results() []MultiCallResult {
+	return h.results
+}
+
+func (h *MultiCallHandler) handlerFor(cc CallContext, call *MultiCallJSON) (ContractHandler, error) {
+	value := new(big.Int)
+	if call.Value != nil {
+		value.Set(call.Value.Value())
+	}
+	if call.Method == "" {
+		return cc.ContractManager().GetHandler(h.From, &call.To, value, CTypeTransfer, nil)
+	}
+	data, err := json.Marshal(&DataCallJSON{
+		Method: call.Method,
+		Params: call.Params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc.ContractManager().GetHandler(h.From, &call.To, value, CTypeCall, data)
+}
+
+func (h *MultiCallHandler) ExecuteSync(cc CallContext) (err error, ro *codec.TypedObj, addr module.Address) {
+	h.Log.TSystemf("MULTICALL start from=%s calls=%d", h.From, len(h.calls))
+	defer func() {
+		if err != nil {
+			h.Log.TSystemf("MULTICALL done status=%s msg=%v", err.Error(), err)
+		}
+	}()
+
+	if !cc.Revision().Has(module.MultiCallTransaction) {
+		return scoreresult.InvalidRequestError.New("MultiCallNotEnabled"), nil, nil
+	}
+	if cc.ReadOnlyMode() {
+		return scoreresult.AccessDeniedError.New("MultiCallIsNotAllowed"), nil, nil
+	}
+	if h.calls == nil {
+		return scoreresult.InvalidParameterError.New("InvalidMultiCallData"), nil, nil
+	}
+
+	for idx, call := range h.calls {
+		if err := cc.ApplyCallSteps(); err != nil {
+			return err, nil, nil
+		}
+		handler, err := h.handlerFor(cc, call)
+		if err != nil {
+			return scoreresult.InvalidParameterError.Wrapf(err,
+				"InvalidCall(idx=%d)", idx), nil, nil
+		}
+		h.Log.TSystemf("MULTICALL call idx=%d to=%s method=%s", idx, &call.To, call.Method)
+		status, used, _, _ := cc.Call(handler, cc.StepAvailable())
+		cc.DeductSteps(used)
+		h.results = append(h.results, MultiCallResult{
+			Status:   status,
+			StepUsed: used,
+		})
+		if status != nil {
+			return errors.Wrapf(status, "CallFailed(idx=%d)", idx), nil, nil
+		}
+	}
+	h.Log.TSystem("MULTICALL done")
+	return nil, nil, nil
+}
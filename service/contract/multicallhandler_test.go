@@ -0,0 +1,220 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/trace"
+)
+
+func TestParseMultiCallData(t *testing.T) {
+	calls, err := ParseMultiCallData([]byte(`[
+		{"to":"cx0000000000000000000000000000000000000001","method":"approve","params":{"spender":"hx0000000000000000000000000000000000000001","amount":"0x10"}},
+		{"to":"cx0000000000000000000000000000000000000002","value":"0x1","method":"swap"},
+		{"to":"hx0000000000000000000000000000000000000003","value":"0x2"}
+	]`))
+	assert.NoError(t, err)
+	assert.Len(t, calls, 3)
+	assert.Equal(t, "approve", calls[0].Method)
+	assert.Nil(t, calls[0].Value)
+	assert.Equal(t, int64(1), calls[1].Value.Int64())
+	assert.Equal(t, "", calls[2].Method)
+	assert.False(t, calls[2].To.IsContract())
+
+	for _, data := range []string{
+		`[]`,
+		`{"to":"cx0000000000000000000000000000000000000001","method":"f"}`,
+		`[null]`,
+		`[{"to":"cx0000000000000000000000000000000000000001","method":"f","extra":"0x1"}]`,
+		`[{"to":"cx0000000000000000000000000000000000000001","value":"-0x1"}]`,
+		`[{"to":"cx0000000000000000000000000000000000000001","params":{}}]`,
+	} {
+		_, err := ParseMultiCallData([]byte(data))
+		assert.Error(t, err, data)
+	}
+
+	var many []byte
+	many = append(many, '[')
+	for i := 0; i <= MultiCallMaxCalls; i++ {
+		if i > 0 {
+			many = append(many, ',')
+		}
+		many = append(many, `{"to":"hx0000000000000000000000000000000000000001"}`...)
+	}
+	many = append(many, ']')
+	_, err = ParseMultiCallData(many)
+	assert.Error(t, err)
+}
+
+var multiCallTestAccount = []byte("multicall")
+
+// setValueHandler sets the value of the method name to the test account,
+// and fails if the method is "fail".
+type setValueHandler struct {
+	method string
+}
+
+func (h *setValueHandler) Prepare(ctx Context) (state.WorldContext, error) {
+	return ctx, nil
+}
+
+func (h *setValueHandler) SetTraceLogger(logger *trace.Logger) {}
+
+func (h *setValueHandler) TraceLogger() *trace.Logger {
+	return nil
+}
+
+func (h *setValueHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, module.Address) {
+	as := cc.GetAccountState(multiCallTestAccount)
+	if _, err := as.SetValue([]byte(h.method), []byte{1}); err != nil {
+		return err, nil, nil
+	}
+	if h.method == "fail" {
+		return scoreresult.RevertedError.New("Fail"), nil, nil
+	}
+	return nil, nil, nil
+}
+
+type multiCallTestContractManager struct {
+	ContractManager
+}
+
+func (cm *multiCallTestContractManager) GetHandler(from, to module.Address, value *big.Int, ctype int, data []byte) (ContractHandler, error) {
+	if ctype != CTypeCall {
+		return nil, scoreresult.InvalidParameterError.Errorf("InvalidType(%d)", ctype)
+	}
+	var call DataCallJSON
+	if err := json.Unmarshal(data, &call); err != nil {
+		return nil, err
+	}
+	return &setValueHandler{method: call.Method}, nil
+}
+
+type multiCallTestPlatform struct {
+	revision module.Revision
+}
+
+func (p multiCallTestPlatform) ToRevision(value int) module.Revision {
+	return p.revision
+}
+
+func newMultiCallTestContext(rev module.Revision) CallContext {
+	dbase := db.NewMapDB()
+	return NewCallContext(
+		NewContext(
+			state.NewWorldContext(
+				state.NewWorldState(dbase, nil, nil, nil, nil),
+				common.NewBlockInfo(1, 0),
+				nil,
+				multiCallTestPlatform{rev},
+			),
+			&multiCallTestContractManager{},
+			nil,
+			newDummyChain(),
+			log.New(),
+			nil,
+			eeproxy.ForTransaction,
+		),
+		big.NewInt(1_000_000),
+		false,
+	)
+}
+
+func newMultiCallTestHandler(methods ...string) *MultiCallHandler {
+	var calls []string
+	for _, m := range methods {
+		calls = append(calls, fmt.Sprintf(
+			`{"to":"cx0000000000000000000000000000000000000001","method":"%s"}`, m))
+	}
+	data := fmt.Sprintf("[%s]", strings.Join(calls, ","))
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	ch := NewCommonHandler(from, from, big.NewInt(0), false, log.New())
+	return newMultiCallHandler(ch, []byte(data))
+}
+
+func hasMultiCallTestValue(cc CallContext, method string) bool {
+	as := cc.GetAccountState(multiCallTestAccount)
+	v, err := as.GetValue([]byte(method))
+	return err == nil && v != nil
+}
+
+func TestMultiCallHandler_ExecuteSync(t *testing.T) {
+	cc := newMultiCallTestContext(module.LatestRevision)
+	h := newMultiCallTestHandler("first", "second")
+
+	status, _, _, _ := cc.Call(h, cc.StepAvailable())
+	assert.NoError(t, status)
+	assert.True(t, hasMultiCallTestValue(cc, "first"))
+	assert.True(t, hasMultiCallTestValue(cc, "second"))
+
+	results := h.Results()
+	assert.Len(t, results, 2)
+	for _, r := range results {
+		assert.NoError(t, r.Status)
+		assert.NotNil(t, r.StepUsed)
+	}
+}
+
+func TestMultiCallHandler_ExecuteSyncRollback(t *testing.T) {
+	cc := newMultiCallTestContext(module.LatestRevision)
+	h := newMultiCallTestHandler("first", "fail", "third")
+
+	status, _, _, _ := cc.Call(h, cc.StepAvailable())
+	assert.Error(t, status)
+	code, _ := scoreresult.StatusOf(status)
+	assert.Equal(t, module.StatusReverted, code)
+
+	// changes of the calls before the failed one are rolled back
+	assert.False(t, hasMultiCallTestValue(cc, "first"))
+	assert.False(t, hasMultiCallTestValue(cc, "fail"))
+	assert.False(t, hasMultiCallTestValue(cc, "third"))
+
+	// the failed call is the last one of the results
+	results := h.Results()
+	assert.Len(t, results, 2)
+	assert.NoError(t, results[0].Status)
+	code, _ = scoreresult.StatusOf(results[1].Status)
+	assert.Equal(t, module.StatusReverted, code)
+}
+
+func TestMultiCallHandler_ExecuteSyncBeforeRevision(t *testing.T) {
+	rev := module.LatestRevision &^ module.MultiCallTransaction
+	cc := newMultiCallTestContext(rev)
+	h := newMultiCallTestHandler("first")
+
+	status, _, _, _ := cc.Call(h, cc.StepAvailable())
+	assert.Error(t, status)
+	code, _ := scoreresult.StatusOf(status)
+	assert.Equal(t, module.StatusIllegalFormat, code)
+	assert.False(t, hasMultiCallTestValue(cc, "first"))
+	assert.Empty(t, h.Results())
+}
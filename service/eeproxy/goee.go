@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/ipc"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	GoEE = "goee"
+)
+
+type goInstance struct {
+	uid    string
+	status InstanceStatus
+	ex     *goExecutor
+}
+
+// goExecutionEngine runs instances of goExecutor in the process. Instances
+// are connected to the manager through the socket like other engines, so
+// they are managed in the same way. Contracts of the type are loaded as
+// GoScore with load.
+//
+// Note that it can't stop a GoScore running without using GoScoreContext
+// on Kill, but it replaces the instance with new one.
+type goExecutionEngine struct {
+	eeType    state.EEType
+	load      func(code string) (GoScore, error)
+	lock      sync.Mutex
+	target    int
+	instances map[string]*goInstance
+	net, addr string
+	logger    log.Logger
+}
+
+func (e *goExecutionEngine) Type() string {
+	return string(e.eeType)
+}
+
+func (e *goExecutionEngine) Init(net, addr string) error {
+	e.net = net
+	e.addr = addr
+	return nil
+}
+
+func (e *goExecutionEngine) SetInstances(n int) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if n < 0 {
+		return errors.ErrIllegalArgument
+	}
+
+	e.target = n
+	for e.target > len(e.instances) {
+		if err := e.startNew(); err != nil {
+			e.logger.Errorf("Fail to start execution engine err=%+v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *goExecutionEngine) OnAttach(uid string) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if is, ok := e.instances[uid]; ok {
+		is.status = instanceOnline
+		return true
+	}
+	return false
+}
+
+func (e *goExecutionEngine) OnEnd(uid string) bool {
+	return true
+}
+
+func (e *goExecutionEngine) Kill(uid string) (bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if is, ok := e.instances[uid]; ok {
+		return true, is.ex.close()
+	} else {
+		return false, nil
+	}
+}
+
+func (e *goExecutionEngine) run(is *goInstance) {
+	for {
+		err := is.ex.loop()
+		e.logger.Tracef("Loop result uid=%s err=%+v", is.uid, err)
+
+		e.lock.Lock()
+		if is.status != instanceOnline {
+			e.logger.Warnf("It's not correctly started status=%s err=%+v",
+				is.status, err)
+			e.term(is)
+			e.lock.Unlock()
+			return
+		}
+		if len(e.instances) > e.target {
+			e.logger.Tracef("End the instance uid=%s", is.uid)
+			e.term(is)
+			e.lock.Unlock()
+			return
+		}
+		e.logger.Warnf("Instance uid=%s is closed err=%+v", is.uid, err)
+		e.term(is)
+
+		e.init(is)
+		if err := e.start(is); err != nil {
+			e.logger.Errorf("Fail to start instance err=%+v", err)
+			e.term(is)
+			e.lock.Unlock()
+			return
+		}
+		e.lock.Unlock()
+	}
+}
+
+func (e *goExecutionEngine) init(i *goInstance) {
+	i.uid = newUID()
+	i.ex = newGoExecutor(i.uid, e.eeType, e.load, e.logger)
+	i.status = instanceStopped
+	e.instances[i.uid] = i
+}
+
+func (e *goExecutionEngine) start(i *goInstance) error {
+	e.logger.Infof("start instance uid=%s", i.uid)
+	if err := i.ex.connect(e.net, e.addr); err != nil {
+		return err
+	}
+	i.status = instanceStarted
+	return nil
+}
+
+func (e *goExecutionEngine) term(i *goInstance) {
+	delete(e.instances, i.uid)
+}
+
+func (e *goExecutionEngine) startNew() error {
+	i := new(goInstance)
+	e.init(i)
+	if err := e.start(i); err != nil {
+		e.term(i)
+		return err
+	}
+	go e.run(i)
+	return nil
+}
+
+func (e *goExecutionEngine) OnConnect(conn ipc.Connection, version uint16) error {
+	return common.ErrUnsupported
+}
+
+func (e *goExecutionEngine) OnClose(conn ipc.Connection) bool {
+	return false
+}
+
+func newGoEngine(t state.EEType, load func(string) (GoScore, error), logger log.Logger) *goExecutionEngine {
+	return &goExecutionEngine{
+		eeType:    t,
+		load:      load,
+		instances: make(map[string]*goInstance),
+		logger:    logger,
+	}
+}
+
+func NewGoEE(logger log.Logger) (Engine, error) {
+	return newGoEngine(state.GoEE, loadGoScore,
+		logger.WithFields(log.Fields{log.FieldKeyModule: GoEE})), nil
+}
@@ -0,0 +1,340 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const testCounterID = "test.counter"
+
+type testCounter struct{}
+
+func (s *testCounter) GetAPI() *scoreapi.Info {
+	return scoreapi.NewInfo([]*scoreapi.Method{
+		{
+			Type: scoreapi.Function,
+			Name: "<init>",
+		},
+		{
+			Type:    scoreapi.Function,
+			Name:    "increase",
+			Flags:   scoreapi.FlagExternal,
+			Inputs:  []scoreapi.Parameter{{Name: "delta", Type: scoreapi.Integer}},
+			Outputs: []scoreapi.DataType{scoreapi.Integer},
+		},
+		{
+			Type:    scoreapi.Function,
+			Name:    "increaseOf",
+			Flags:   scoreapi.FlagExternal,
+			Inputs:  []scoreapi.Parameter{{Name: "addr", Type: scoreapi.Address}},
+			Outputs: []scoreapi.DataType{scoreapi.Integer},
+		},
+		{
+			Type:    scoreapi.Event,
+			Name:    "Increased",
+			Inputs:  []scoreapi.Parameter{{Name: "value", Type: scoreapi.Integer}},
+			Indexed: 0,
+		},
+	})
+}
+
+func (s *testCounter) Invoke(ctx GoScoreContext, method string, params []interface{}) (interface{}, error) {
+	count := scoredb.NewVarDB(ctx, "count")
+	switch method {
+	case "<init>":
+		return nil, nil
+	case "increase":
+		value := big.NewInt(count.Int64())
+		value.Add(value, params[0].(*common.HexInt).Value())
+		if err := count.Set(value); err != nil {
+			return nil, err
+		}
+		if err := ctx.Event([]interface{}{"Increased(int)"}, []interface{}{value}); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "increaseOf":
+		return ctx.Call(params[0].(module.Address), nil, "increase", 1)
+	}
+	return nil, scoreresult.ErrMethodNotFound
+}
+
+func init() {
+	RegisterGoScore(testCounterID, new(testCounter))
+}
+
+type testResult struct {
+	status error
+	steps  *big.Int
+	result *codec.TypedObj
+	info   *scoreapi.Info
+}
+
+type testCallContext struct {
+	proxy  Proxy
+	code   string
+	store  map[string][]byte
+	events [][][]byte
+	ch     chan *testResult
+	parent *testCallContext
+}
+
+func (cc *testCallContext) GetValue(key []byte) ([]byte, error) {
+	return cc.store[string(key)], nil
+}
+
+func (cc *testCallContext) SetValue(key []byte, value []byte) ([]byte, error) {
+	old := cc.store[string(key)]
+	cc.store[string(key)] = value
+	return old, nil
+}
+
+func (cc *testCallContext) DeleteValue(key []byte) ([]byte, error) {
+	old := cc.store[string(key)]
+	delete(cc.store, string(key))
+	return old, nil
+}
+
+func (cc *testCallContext) ArrayDBContains(prefix, value []byte, limit int64) (bool, int, int, error) {
+	return false, 0, 0, nil
+}
+
+func (cc *testCallContext) GetInfo() *codec.TypedObj {
+	return common.MustEncodeAny(map[string]interface{}{
+		state.InfoBlockHeight: 1,
+		state.InfoStepCosts: map[string]interface{}{
+			state.StepTypeGet:        1,
+			state.StepTypeGetBase:    10,
+			state.StepTypeSet:        2,
+			state.StepTypeSetBase:    20,
+			state.StepTypeDelete:     1,
+			state.StepTypeDeleteBase: 14,
+			state.StepTypeLog:        3,
+			state.StepTypeLogBase:    50,
+		},
+	})
+}
+
+func (cc *testCallContext) GetBalance(addr module.Address) *big.Int {
+	return big.NewInt(0)
+}
+
+func (cc *testCallContext) OnEvent(addr module.Address, indexed, data [][]byte) error {
+	cc.events = append(cc.events, append(indexed, data...))
+	return nil
+}
+
+func (cc *testCallContext) OnResult(status error, flag int, steps *big.Int, result *codec.TypedObj) {
+	if cc.parent != nil {
+		_ = cc.proxy.SendResult(cc.parent, status, steps, result, 0, 0)
+		return
+	}
+	cc.ch <- &testResult{status: status, steps: steps, result: result}
+}
+
+func (cc *testCallContext) OnCall(from, to module.Address, value, limit *big.Int, dataType string, dataObj *codec.TypedObj) {
+	data := common.MustDecodeAny(dataObj).(map[string]interface{})
+	params := common.MustEncodeAny(data["params"])
+	sub := &testCallContext{
+		proxy:  cc.proxy,
+		store:  make(map[string][]byte),
+		parent: cc,
+	}
+	go func() {
+		_ = cc.proxy.Invoke(sub, cc.code, false, from, to, value, limit,
+			data["method"].(string), params, nil, 0, nil)
+	}()
+}
+
+func (cc *testCallContext) OnAPI(status error, info *scoreapi.Info) {
+	cc.ch <- &testResult{status: status, info: info}
+}
+
+func (cc *testCallContext) OnSetFeeProportion(portion int) {}
+
+func (cc *testCallContext) SetCode(code []byte) error {
+	return nil
+}
+
+func (cc *testCallContext) GetObjGraph(bool) (int, []byte, []byte, error) {
+	return 0, nil, nil, nil
+}
+
+func (cc *testCallContext) SetObjGraph(flags bool, nextHash int, objGraph []byte) error {
+	return nil
+}
+
+func (cc *testCallContext) Logger() log.Logger {
+	return log.GlobalLogger()
+}
+
+func (cc *testCallContext) wait(t *testing.T) *testResult {
+	select {
+	case r := <-cc.ch:
+		return r
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "Timeout waiting for the result")
+		return nil
+	}
+}
+
+func newGoEEManager(t *testing.T) Manager {
+	engine, err := NewGoEE(log.GlobalLogger())
+	assert.NoError(t, err)
+	return newTestManager(t, engine)
+}
+
+func newTestManager(t *testing.T, engine Engine) Manager {
+	dir := t.TempDir()
+	mgr, err := NewManager("unix", filepath.Join(dir, "ee.sock"), log.GlobalLogger(), engine)
+	assert.NoError(t, err)
+	go mgr.Loop()
+	t.Cleanup(func() {
+		_ = mgr.Close()
+	})
+	assert.NoError(t, mgr.SetInstances(1, 1, 1))
+	return mgr
+}
+
+func writeGoScore(t *testing.T, id string) string {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, goCodeFile), []byte(id), 0644)
+	assert.NoError(t, err)
+	return dir
+}
+
+func TestGoEE_GetAPI(t *testing.T) {
+	mgr := newGoEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("go")
+	assert.NotNil(t, proxy)
+
+	cc := &testCallContext{ch: make(chan *testResult, 1)}
+	assert.NoError(t, proxy.GetAPI(cc, writeGoScore(t, testCounterID)))
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+	assert.True(t, r.info.Equal(new(testCounter).GetAPI()))
+
+	assert.NoError(t, proxy.GetAPI(cc, writeGoScore(t, "unknown")))
+	r = cc.wait(t)
+	assert.Error(t, r.status)
+	assert.Nil(t, r.info)
+}
+
+func TestGoEE_Invoke(t *testing.T) {
+	mgr := newGoEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("go")
+
+	code := writeGoScore(t, testCounterID)
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	cc := &testCallContext{
+		proxy: proxy,
+		code:  code,
+		store: make(map[string][]byte),
+		ch:    make(chan *testResult, 1),
+	}
+	params := common.MustEncodeAny([]interface{}{5})
+
+	// getBase(10) + setBase(20) + 1*set(2) + logBase(50) + 15*log(3)
+	err := proxy.Invoke(cc, code, false, from, score, big.NewInt(0),
+		big.NewInt(1000), "increase", params, nil, 0, nil)
+	assert.NoError(t, err)
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+	assert.Equal(t, int64(127), r.steps.Int64())
+	assert.EqualValues(t, 5, common.MustDecodeAny(r.result).(*common.HexInt).Int64())
+	assert.Len(t, cc.events, 1)
+	assert.Equal(t, [][]byte{[]byte("Increased(int)"), {5}}, cc.events[0])
+
+	// getBase(10) + 1*get(1) + (setBase(20)+deleteBase(14))/2 + 1*delete(1) + 1*set(2)
+	// + logBase(50) + 15*log(3) -> out of step
+	err = proxy.Invoke(cc, code, false, from, score, big.NewInt(0),
+		big.NewInt(100), "increase", params, nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.True(t, scoreresult.OutOfStepError.Equals(r.status))
+	assert.Equal(t, int64(100), r.steps.Int64())
+
+	err = proxy.Invoke(cc, code, true, from, score, big.NewInt(0),
+		big.NewInt(1000), "increase", params, nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.True(t, scoreresult.AccessDeniedError.Equals(r.status))
+	assert.Len(t, cc.events, 1)
+}
+
+func TestGoEE_InvokeWithCall(t *testing.T) {
+	mgr := newGoEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("go")
+
+	code := writeGoScore(t, testCounterID)
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	score1 := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	score2 := common.MustNewAddressFromString("cx0000000000000000000000000000000000000002")
+	cc := &testCallContext{
+		proxy: proxy,
+		code:  code,
+		store: make(map[string][]byte),
+		ch:    make(chan *testResult, 1),
+	}
+	params := common.MustEncodeAny([]interface{}{score2})
+
+	err := proxy.Invoke(cc, code, false, from, score1, big.NewInt(0),
+		big.NewInt(1000), "increaseOf", params, nil, 0, nil)
+	assert.NoError(t, err)
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+	assert.EqualValues(t, 1, common.MustDecodeAny(r.result).(*common.HexInt).Int64())
+	// steps used by the inner call are included
+	assert.Equal(t, int64(127), r.steps.Int64())
+}
+
+func TestGoEE_Kill(t *testing.T) {
+	mgr := newGoEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	ex.Kill()
+
+	ex = mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("go")
+	cc := &testCallContext{ch: make(chan *testResult, 1)}
+	assert.NoError(t, proxy.GetAPI(cc, writeGoScore(t, testCounterID)))
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/ipc"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	goExecutorVersion = 0
+	goCodeFile        = "score.id"
+	dataTypeCall      = "call"
+)
+
+// goExecutor is the execution environment side of the proxy protocol for
+// GoScore. It's connected to the executor manager like other execution
+// environments, but it runs in the same process. GoScore for the code is
+// loaded by load, so it may be used for other types of contracts.
+type goExecutor struct {
+	uid    string
+	eeType state.EEType
+	load   func(code string) (GoScore, error)
+	conn   ipc.Connection
+	log    log.Logger
+
+	frame  *goFrame
+	result *resultMessage
+}
+
+func (e *goExecutor) connect(net, addr string) error {
+	conn, err := ipc.Dial(net, addr)
+	if err != nil {
+		return err
+	}
+	e.conn = conn
+	conn.SetHandler(msgINVOKE, e)
+	conn.SetHandler(msgGETAPI, e)
+	conn.SetHandler(msgRESULT, e)
+	conn.SetHandler(msgCLOSE, e)
+	return conn.Send(msgVERSION, &versionMessage{
+		Version: goExecutorVersion,
+		UID:     e.uid,
+		Type:    string(e.eeType),
+	})
+}
+
+func (e *goExecutor) loop() error {
+	for {
+		if err := e.conn.HandleMessage(); err != nil {
+			return err
+		}
+	}
+}
+
+func (e *goExecutor) close() error {
+	return e.conn.Close()
+}
+
+func (e *goExecutor) HandleMessage(c ipc.Connection, msg uint, data []byte) error {
+	switch msg {
+	case msgINVOKE:
+		var m invokeMessage
+		if _, err := codec.MP.UnmarshalFromBytes(data, &m); err != nil {
+			return err
+		}
+		return e.conn.Send(msgRESULT, e.invoke(&m))
+
+	case msgGETAPI:
+		var code string
+		if _, err := codec.MP.UnmarshalFromBytes(data, &code); err != nil {
+			return err
+		}
+		var m getAPIMessage
+		if score, err := e.load(code); err != nil {
+			e.log.Warnf("FailToLoadScore(code=%s,err=%+v)", code, err)
+			s, _ := scoreresult.StatusOf(err)
+			m.Status = errors.Code(s)
+		} else {
+			m.Status = errors.Success
+			m.Info = score.GetAPI()
+		}
+		return e.conn.Send(msgGETAPI, &m)
+
+	case msgRESULT:
+		var m resultMessage
+		if _, err := codec.MP.UnmarshalFromBytes(data, &m); err != nil {
+			return err
+		}
+		if e.frame == nil || e.result != nil {
+			return errors.InvalidStateError.New("UnexpectedResult")
+		}
+		e.result = &m
+		return nil
+
+	case msgCLOSE:
+		e.log.Debugf("goExecutor[%s] closed by manager", e.uid)
+		return e.close()
+
+	default:
+		return errors.IllegalArgumentError.Errorf("UnknownMessage(msg=%d)", msg)
+	}
+}
+
+func loadGoScore(code string) (GoScore, error) {
+	id, err := os.ReadFile(filepath.Join(code, goCodeFile))
+	if err != nil {
+		return nil, scoreresult.ContractNotFoundError.Wrapf(err,
+			"FailToReadScoreID(code=%s)", code)
+	}
+	score, ok := getGoScore(string(id))
+	if !ok {
+		return nil, scoreresult.ContractNotFoundError.Errorf(
+			"UnknownGoScore(id=%s)", id)
+	}
+	return score, nil
+}
+
+func (e *goExecutor) invoke(m *invokeMessage) *resultMessage {
+	f := newGoFrame(e, m)
+	e.frame = f
+	defer func() {
+		e.frame = f.prev
+	}()
+
+	result, err := f.run(m.Code, m.Method, m.Params)
+	if f.outOfStep {
+		err = scoreresult.OutOfStepError.New("OutOfStep")
+	}
+
+	var r resultMessage
+	r.StepUsed.Set(f.used)
+	if err != nil {
+		f.log.Debugf("Invoke method=%s err=%+v", m.Method, err)
+		s, _ := scoreresult.StatusOf(err)
+		r.Status = errors.Code(s)
+		r.Result = common.MustEncodeAny(err.Error())
+		return &r
+	}
+	if obj, err := common.EncodeAny(result); err != nil {
+		r.Status = errors.Code(module.StatusUnknownFailure)
+		r.Result = common.MustEncodeAny(fmt.Sprintf("InvalidResult(%T)", result))
+	} else {
+		r.Status = errors.Success
+		r.Result = obj
+	}
+	return &r
+}
+
+// waitResult handles messages from the manager until it receives the result
+// of the last call. It may handle INVOKE for the call in the middle.
+func (e *goExecutor) waitResult() (*resultMessage, error) {
+	for e.result == nil {
+		if err := e.conn.HandleMessage(); err != nil {
+			return nil, err
+		}
+	}
+	r := e.result
+	e.result = nil
+	return r, nil
+}
+
+func newGoExecutor(uid string, t state.EEType, load func(string) (GoScore, error), logger log.Logger) *goExecutor {
+	return &goExecutor{
+		uid:    uid,
+		eeType: t,
+		load:   load,
+		log:    logger.WithFields(log.Fields{log.FieldKeyEID: uid}),
+	}
+}
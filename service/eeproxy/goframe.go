@@ -0,0 +1,409 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// goFrame is GoScoreContext for an invocation. Steps are charged with the
+// step costs in the invocation info like the Java execution environment.
+type goFrame struct {
+	executor *goExecutor
+	prev     *goFrame
+
+	readOnly  bool
+	from      module.Address
+	to        module.Address
+	value     *big.Int
+	limit     *big.Int
+	used      *big.Int
+	outOfStep bool
+	info      map[string]interface{}
+	costs     map[string]int64
+	log       log.Logger
+}
+
+func newGoFrame(e *goExecutor, m *invokeMessage) *goFrame {
+	f := &goFrame{
+		executor: e,
+		prev:     e.frame,
+		readOnly: (m.Flag & InvokeFlagReadOnly) != 0,
+		to:       &m.To,
+		value:    new(big.Int).Set(&m.Value.Int),
+		limit:    new(big.Int).Set(&m.Limit.Int),
+		used:     new(big.Int),
+		costs:    make(map[string]int64),
+		log:      e.log.WithFields(log.Fields{"score": m.To.String()}),
+	}
+	if m.From != nil {
+		f.from = m.From
+	}
+	if info, err := common.DecodeAny(m.Info); err == nil {
+		if mi, ok := info.(map[string]interface{}); ok {
+			f.info = mi
+		}
+	}
+	if costs, ok := f.info[state.InfoStepCosts].(map[string]interface{}); ok {
+		for k, v := range costs {
+			if cost, ok := v.(*common.HexInt); ok {
+				f.costs[k] = cost.Int64()
+			}
+		}
+	}
+	return f
+}
+
+func (f *goFrame) run(code, method string, params *codec.TypedObj) (result interface{}, err error) {
+	defer func() {
+		if obj := recover(); obj != nil {
+			f.log.Warnf("Panic in method=%s obj=%+v", method, obj)
+			result = nil
+			err = scoreresult.UnknownFailureError.Errorf("Recover obj=%+v", obj)
+		}
+	}()
+	score, err := f.executor.load(code)
+	if err != nil {
+		return nil, err
+	}
+	var args []interface{}
+	if params != nil {
+		if obj, err := common.DecodeAny(params); err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidParams")
+		} else if obj != nil {
+			if l, ok := obj.([]interface{}); ok {
+				args = l
+			} else {
+				return nil, scoreresult.InvalidParameterError.Errorf(
+					"InvalidParamsType(%T)", obj)
+			}
+		}
+	}
+	return score.Invoke(f, method, args)
+}
+
+func (f *goFrame) From() module.Address {
+	return f.from
+}
+
+func (f *goFrame) Address() module.Address {
+	return f.to
+}
+
+func (f *goFrame) Value() *big.Int {
+	return f.value
+}
+
+func (f *goFrame) ReadOnly() bool {
+	return f.readOnly
+}
+
+func (f *goFrame) Info() map[string]interface{} {
+	return f.info
+}
+
+func (f *goFrame) StepLimit() *big.Int {
+	return f.limit
+}
+
+func (f *goFrame) StepUsed() *big.Int {
+	return new(big.Int).Set(f.used)
+}
+
+func (f *goFrame) stepAvailable() *big.Int {
+	return new(big.Int).Sub(f.limit, f.used)
+}
+
+func (f *goFrame) applySteps(steps *big.Int) error {
+	if f.outOfStep {
+		return scoreresult.ErrOutOfStep
+	}
+	f.used.Add(f.used, steps)
+	if f.used.Cmp(f.limit) > 0 {
+		f.used.Set(f.limit)
+		f.outOfStep = true
+		return scoreresult.ErrOutOfStep
+	}
+	return nil
+}
+
+func (f *goFrame) Charge(steps int64) error {
+	if steps < 0 {
+		return scoreresult.InvalidParameterError.Errorf("NegativeSteps(%d)", steps)
+	}
+	return f.applySteps(big.NewInt(steps))
+}
+
+func (f *goFrame) cost(t string) int64 {
+	return f.costs[t]
+}
+
+func (f *goFrame) checkWritable(op string) error {
+	if f.readOnly {
+		return scoreresult.AccessDeniedError.Errorf("%sInReadOnly", op)
+	}
+	return nil
+}
+
+func (f *goFrame) getValue(key []byte) ([]byte, error) {
+	var m getValueMessage
+	if err := f.executor.conn.SendAndReceive(msgGETVALUE, key, &m); err != nil {
+		return nil, errors.ExecutionFailError.Wrap(err, "FailToGetValue")
+	}
+	if !m.Success {
+		return nil, nil
+	}
+	return m.Value, nil
+}
+
+func (f *goFrame) GetValue(key []byte) ([]byte, error) {
+	if f.outOfStep {
+		return nil, scoreresult.ErrOutOfStep
+	}
+	value, err := f.getValue(key)
+	if err != nil {
+		return nil, err
+	}
+	cost := f.cost(state.StepTypeGetBase) + int64(len(value))*f.cost(state.StepTypeGet)
+	if err := f.Charge(cost); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (f *goFrame) SetValue(key []byte, value []byte) ([]byte, error) {
+	if value == nil {
+		return f.DeleteValue(key)
+	}
+	if err := f.checkWritable("SetValue"); err != nil {
+		return nil, err
+	}
+	if f.outOfStep {
+		return nil, scoreresult.ErrOutOfStep
+	}
+	old, err := f.getValue(key)
+	if err != nil {
+		return nil, err
+	}
+	var cost int64
+	if old == nil {
+		cost = f.cost(state.StepTypeSetBase) +
+			int64(len(value))*f.cost(state.StepTypeSet)
+	} else {
+		cost = (f.cost(state.StepTypeSetBase)+f.cost(state.StepTypeDeleteBase))/2 +
+			int64(len(old))*f.cost(state.StepTypeDelete) +
+			int64(len(value))*f.cost(state.StepTypeSet)
+	}
+	if err := f.Charge(cost); err != nil {
+		return nil, err
+	}
+	if err := f.executor.conn.Send(msgSETVALUE, &setValueMessage{
+		Key:   key,
+		Value: value,
+	}); err != nil {
+		return nil, errors.ExecutionFailError.Wrap(err, "FailToSetValue")
+	}
+	return old, nil
+}
+
+func (f *goFrame) DeleteValue(key []byte) ([]byte, error) {
+	if err := f.checkWritable("DeleteValue"); err != nil {
+		return nil, err
+	}
+	if f.outOfStep {
+		return nil, scoreresult.ErrOutOfStep
+	}
+	old, err := f.getValue(key)
+	if err != nil {
+		return nil, err
+	}
+	cost := f.cost(state.StepTypeDeleteBase) + int64(len(old))*f.cost(state.StepTypeDelete)
+	if err := f.Charge(cost); err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return nil, nil
+	}
+	if err := f.executor.conn.Send(msgSETVALUE, &setValueMessage{
+		Key:  key,
+		Flag: flagDELETE,
+	}); err != nil {
+		return nil, errors.ExecutionFailError.Wrap(err, "FailToDeleteValue")
+	}
+	return old, nil
+}
+
+func (f *goFrame) GetBalance(addr module.Address) (*big.Int, error) {
+	if f.outOfStep {
+		return nil, scoreresult.ErrOutOfStep
+	}
+	var balance common.HexInt
+	if err := f.executor.conn.SendAndReceive(msgGETBALANCE,
+		common.AddressToPtr(addr), &balance); err != nil {
+		return nil, errors.ExecutionFailError.Wrap(err, "FailToGetBalance")
+	}
+	return balance.Value(), nil
+}
+
+func encodeEventValue(v interface{}) ([]byte, error) {
+	switch obj := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return obj, nil
+	case string:
+		return []byte(obj), nil
+	case bool:
+		if obj {
+			return codec.TrueBytes, nil
+		}
+		return codec.FalseBytes, nil
+	default:
+		_, bs, err := common.TypeCodec.Encode(obj)
+		if err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrapf(err,
+				"InvalidEventValue(%T)", v)
+		}
+		return bs, nil
+	}
+}
+
+func encodeEventValues(values []interface{}) ([][]byte, int, error) {
+	bss := make([][]byte, len(values))
+	size := 0
+	for i, v := range values {
+		bs, err := encodeEventValue(v)
+		if err != nil {
+			return nil, 0, err
+		}
+		bss[i] = bs
+		size += len(bs)
+	}
+	return bss, size, nil
+}
+
+func (f *goFrame) Event(indexed []interface{}, data []interface{}) error {
+	if err := f.checkWritable("Event"); err != nil {
+		return err
+	}
+	if len(indexed) < 1 {
+		return scoreresult.InvalidParameterError.New("NoEventSignature")
+	}
+	var m eventMessage
+	var isz, dsz int
+	var err error
+	if m.Indexed, isz, err = encodeEventValues(indexed); err != nil {
+		return err
+	}
+	if m.Data, dsz, err = encodeEventValues(data); err != nil {
+		return err
+	}
+	cost := f.cost(state.StepTypeLogBase) + int64(isz+dsz)*f.cost(state.StepTypeLog)
+	if err := f.Charge(cost); err != nil {
+		return err
+	}
+	if err := f.executor.conn.Send(msgEVENT, &m); err != nil {
+		return errors.ExecutionFailError.Wrap(err, "FailToSendEvent")
+	}
+	return nil
+}
+
+func (f *goFrame) call(to module.Address, value *big.Int, data map[string]interface{}) (interface{}, error) {
+	if f.outOfStep {
+		return nil, scoreresult.ErrOutOfStep
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	if value.Sign() > 0 {
+		if err := f.checkWritable("Transfer"); err != nil {
+			return nil, err
+		}
+	}
+	dataObj, err := common.EncodeAny(data)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidCallData")
+	}
+	var m callMessage
+	m.To.Set(to)
+	m.Value.Set(value)
+	m.Limit.Set(f.stepAvailable())
+	m.DataType = dataTypeCall
+	m.Data = dataObj
+	if err := f.executor.conn.Send(msgCALL, &m); err != nil {
+		return nil, errors.ExecutionFailError.Wrap(err, "FailToCall")
+	}
+	r, err := f.executor.waitResult()
+	if err != nil {
+		return nil, errors.ExecutionFailError.Wrap(err, "FailToGetResult")
+	}
+	if err := f.applySteps(&r.StepUsed.Int); err != nil {
+		return nil, err
+	}
+	status, _ := StatusToCodeAndFlag(r.Status)
+	if status != errors.Success {
+		return nil, status.New(common.DecodeAsString(r.Result, ""))
+	}
+	if r.Result == nil {
+		return nil, nil
+	}
+	return common.DecodeAny(r.Result)
+}
+
+func (f *goFrame) Call(to module.Address, value *big.Int, method string, params ...interface{}) (interface{}, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	return f.call(to, value, map[string]interface{}{
+		"method": method,
+		"params": params,
+	})
+}
+
+func (f *goFrame) Transfer(to module.Address, value *big.Int) error {
+	_, err := f.call(to, value, map[string]interface{}{
+		"method": scoreapi.FallbackMethodName,
+	})
+	return err
+}
+
+func (f *goFrame) SetFeeProportion(portion int) error {
+	if err := f.checkWritable("SetFeeProportion"); err != nil {
+		return err
+	}
+	if portion < 0 || portion > 100 {
+		return scoreresult.InvalidParameterError.Errorf(
+			"InvalidProportion(%d)", portion)
+	}
+	if err := f.executor.conn.Send(msgSETFEEPCT, portion); err != nil {
+		return errors.ExecutionFailError.Wrap(err, "FailToSetFeeProportion")
+	}
+	return nil
+}
+
+func (f *goFrame) Logger() log.Logger {
+	return f.log
+}
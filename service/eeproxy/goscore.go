@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+)
+
+// GoScore is a contract written in Go, executed by the Go execution engine.
+// A GoScore is registered with an ID, and it's deployed with the ID as its
+// content using content type state.CTAppGo, which is available only if the
+// revision has module.GoExecutionEngine. Its API should include the method
+// "<init>", which is called on install and update.
+type GoScore interface {
+	GetAPI() *scoreapi.Info
+	Invoke(ctx GoScoreContext, method string, params []interface{}) (interface{}, error)
+}
+
+// GoScoreContext is given to GoScore on invocation. Operations on the
+// context are delivered through the execution engine protocol, and the
+// steps for them are charged in the same way as other engines.
+type GoScoreContext interface {
+	From() module.Address
+	Address() module.Address
+	Value() *big.Int
+	ReadOnly() bool
+	Info() map[string]interface{}
+	StepLimit() *big.Int
+	StepUsed() *big.Int
+
+	// Charge consumes steps. It returns scoreresult.OutOfStepError if there
+	// is not enough steps.
+	Charge(steps int64) error
+
+	// GetValue, SetValue and DeleteValue implements
+	// containerdb.BytesStoreState for the storage of the contract.
+	GetValue(key []byte) ([]byte, error)
+	SetValue(key []byte, value []byte) ([]byte, error)
+	DeleteValue(key []byte) ([]byte, error)
+
+	GetBalance(addr module.Address) (*big.Int, error)
+
+	// Event emits an event log. The first element of indexed should be the
+	// signature of the event.
+	Event(indexed []interface{}, data []interface{}) error
+	Call(to module.Address, value *big.Int, method string, params ...interface{}) (interface{}, error)
+	Transfer(to module.Address, value *big.Int) error
+	SetFeeProportion(portion int) error
+
+	Logger() log.Logger
+}
+
+var goScores = struct {
+	lock   sync.Mutex
+	scores map[string]GoScore
+}{
+	scores: make(map[string]GoScore),
+}
+
+// RegisterGoScore registers score with the id, so that it can be deployed
+// with the id as its content.
+func RegisterGoScore(id string, score GoScore) {
+	goScores.lock.Lock()
+	defer goScores.lock.Unlock()
+	goScores.scores[id] = score
+}
+
+func getGoScore(id string) (GoScore, bool) {
+	goScores.lock.Lock()
+	defer goScores.lock.Unlock()
+	score, ok := goScores.scores[id]
+	return score, ok
+}
@@ -2,11 +2,13 @@ package eeproxy
 
 import (
 	"sync"
+	"time"
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/ipc"
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/server/metric"
 )
 
 type RequestPriority int
@@ -19,6 +21,17 @@ const (
 	numberOfPriorities = 2
 )
 
+func (pr RequestPriority) String() string {
+	switch pr {
+	case ForTransaction:
+		return "transaction"
+	case ForQuery:
+		return "query"
+	default:
+		return "unknown"
+	}
+}
+
 const (
 	errorBase                  = errors.CodeService + 300
 	ScaleDownError errors.Code = iota + errorBase
@@ -29,6 +42,15 @@ const (
 type Manager interface {
 	GetExecutor(pr RequestPriority) *Executor
 	SetInstances(total, tx, query int) error
+
+	// SetWatchdogTimeout sets timeout for executors. If an execution
+	// environment doesn't respond for the timeout while the proxy is
+	// waiting for it, then it kills the executor to be replaced.
+	// Zero disables the watchdog.
+	SetWatchdogTimeout(d time.Duration)
+
+	// Inspect returns status of engines and queues of requests.
+	Inspect() map[string]interface{}
 	Loop() error
 	Close() error
 }
@@ -77,6 +99,41 @@ type engine struct {
 	active int
 	ready  *proxy
 	using  *proxy
+
+	restarts int64
+	kills    int64
+	calls    int64
+	latency  time.Duration
+	metric   *metric.EEMetric
+}
+
+func (e *engine) addActive(delta int) {
+	e.active += delta
+	e.metric.OnExecutors(e.active)
+}
+
+func (e *engine) inspect() map[string]interface{} {
+	var avg time.Duration
+	if e.calls > 0 {
+		avg = e.latency / time.Duration(e.calls)
+	}
+	return map[string]interface{}{
+		"executors":     e.active,
+		"ready":         countProxies(e.ready),
+		"using":         countProxies(e.using),
+		"restarts":      e.restarts,
+		"watchdogKills": e.kills,
+		"calls":         e.calls,
+		"avgLatency":    avg.String(),
+	}
+}
+
+func countProxies(p *proxy) int {
+	cnt := 0
+	for ; p != nil; p = p.next {
+		cnt += 1
+	}
+	return cnt
 }
 
 type executorState struct {
@@ -84,6 +141,12 @@ type executorState struct {
 	assigned int
 	waiter   *sync.Cond
 	waiting  int
+	metric   *metric.EEQueueMetric
+}
+
+func (s *executorState) addWaiting(delta int) {
+	s.waiting += delta
+	s.metric.OnWaiting(s.waiting)
 }
 
 type executorManager struct {
@@ -96,6 +159,9 @@ type executorManager struct {
 	executorLimit  int
 	executorStates [numberOfPriorities]executorState
 
+	watchdogTimeout time.Duration
+	watchdog        *time.Timer
+
 	log log.Logger
 }
 
@@ -111,7 +177,7 @@ func (em *executorManager) onReady(p *proxy) error {
 
 	if p.detach() {
 		if e.active > em.executorLimit {
-			e.active -= 1
+			e.addActive(-1)
 			em.log.Infof("Stop proxy=%s-%s (target=%d,active=%d)",
 				p.scoreType, p.uid, em.executorLimit, e.active)
 			return ScaleDownError.Errorf("ScalingDown(target=%d,active=%d)",
@@ -122,7 +188,7 @@ func (em *executorManager) onReady(p *proxy) error {
 			em.log.Warnf("InvalidUUID(uid=%s)", p.uid)
 			return InvalidUUIDError.Errorf("InvalidUID(uid=%s)", p.uid)
 		}
-		e.active += 1
+		e.addActive(1)
 	}
 	p.attachTo(&e.ready)
 
@@ -136,6 +202,17 @@ func (em *executorManager) onReady(p *proxy) error {
 	return nil
 }
 
+func (em *executorManager) onCall(p *proxy, d time.Duration) {
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	if e, ok := em.engines[p.scoreType]; ok {
+		e.calls += 1
+		e.latency += d
+		e.metric.OnCall(d)
+	}
+}
+
 func (em *executorManager) kill(u string) error {
 	for _, e := range em.engines {
 		if ok, err := e.engine.Kill(u); ok {
@@ -157,18 +234,24 @@ func (em *executorManager) OnClose(c ipc.Connection) {
 	for _, e := range em.engines {
 		for p := e.ready; p != nil; p = p.next {
 			if p.conn == c {
+				em.log.Warnf("Executor closed type=%s uid=%s (ready)",
+					p.scoreType, p.uid)
 				p.detach()
-				e.active -= 1
+				e.addActive(-1)
+				em.onRestartInLock(e)
 				return
 			}
 		}
 		for p := e.using; p != nil; p = p.next {
 			if p.conn == c {
+				em.log.Warnf("Executor closed type=%s uid=%s (using)",
+					p.scoreType, p.uid)
 				p.detach()
 				l.CallAfterUnlock(func() {
 					p.OnClose()
 				})
-				e.active -= 1
+				e.addActive(-1)
+				em.onRestartInLock(e)
 				return
 			}
 		}
@@ -178,7 +261,18 @@ func (em *executorManager) OnClose(c ipc.Connection) {
 	}
 }
 
+// onRestartInLock is called when an executor is closed without request of
+// the manager. The engine is responsible for replacing it.
+func (em *executorManager) onRestartInLock(e *engine) {
+	e.restarts += 1
+	e.metric.OnRestart()
+}
+
 func (em *executorManager) Close() error {
+	em.lock.Lock()
+	em.stopWatchdogInLock()
+	em.lock.Unlock()
+
 	if err := em.server.Close(); err != nil {
 		return err
 	}
@@ -217,13 +311,13 @@ func (em *executorManager) GetExecutor(pr RequestPriority) *Executor {
 	defer em.lock.Unlock()
 
 	es := &em.executorStates[pr]
-	es.waiting += 1
+	es.addWaiting(1)
 	for {
 		if es.assigned < es.limit {
 			e := em.createExecutorInLock(pr)
 			if e != nil {
 				es.assigned += 1
-				es.waiting -= 1
+				es.addWaiting(-1)
 				return e
 			}
 		}
@@ -253,12 +347,97 @@ func (em *executorManager) SetInstances(total, tx, query int) error {
 			item := e.ready
 			item.detach()
 			item.close()
-			e.active -= 1
+			e.addActive(-1)
 		}
 	}
 	return nil
 }
 
+// watchdogInterval returns the interval for checking executors with the
+// timeout. So stuck executors are killed in 1.25 times of the timeout.
+func watchdogInterval(timeout time.Duration) time.Duration {
+	return timeout / 4
+}
+
+func (em *executorManager) SetWatchdogTimeout(d time.Duration) {
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	if em.watchdogTimeout == d {
+		return
+	}
+	em.log.Infof("Set watchdog timeout=%s (prev=%s)", d, em.watchdogTimeout)
+	em.watchdogTimeout = d
+	em.stopWatchdogInLock()
+	if d > 0 {
+		em.watchdog = time.AfterFunc(watchdogInterval(d), em.checkWatchdog)
+	}
+}
+
+func (em *executorManager) stopWatchdogInLock() {
+	if em.watchdog != nil {
+		em.watchdog.Stop()
+		em.watchdog = nil
+	}
+}
+
+func (em *executorManager) checkWatchdog() {
+	em.lock.Lock()
+	if em.watchdog == nil {
+		em.lock.Unlock()
+		return
+	}
+	var stuck []*proxy
+	now := time.Now()
+	for _, e := range em.engines {
+		for p := e.using; p != nil; p = p.next {
+			if d := p.waitingFor(now); d > em.watchdogTimeout {
+				em.log.Warnf("Kill stuck executor type=%s uid=%s wait=%s (timeout=%s)",
+					p.scoreType, p.uid, d, em.watchdogTimeout)
+				e.kills += 1
+				e.metric.OnWatchdogKill()
+				p.setWaiting(false)
+				stuck = append(stuck, p)
+			}
+		}
+	}
+	em.watchdog.Reset(watchdogInterval(em.watchdogTimeout))
+	em.lock.Unlock()
+
+	// It doesn't use proxy.Kill() for keeping the state, so that the caller
+	// waiting for the result gets the failure on close.
+	for _, p := range stuck {
+		if err := em.kill(p.uid); err != nil {
+			em.log.Warnf("Fail to kill executor type=%s uid=%s err=%+v",
+				p.scoreType, p.uid, err)
+		}
+	}
+}
+
+func (em *executorManager) Inspect() map[string]interface{} {
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	engines := make(map[string]interface{})
+	for name, e := range em.engines {
+		engines[name] = e.inspect()
+	}
+	queues := make(map[string]interface{})
+	for i := range em.executorStates {
+		s := &em.executorStates[i]
+		queues[RequestPriority(i).String()] = map[string]interface{}{
+			"limit":    s.limit,
+			"assigned": s.assigned,
+			"waiting":  s.waiting,
+		}
+	}
+	return map[string]interface{}{
+		"engines":         engines,
+		"queues":          queues,
+		"watchdogTimeout": em.watchdogTimeout.String(),
+	}
+}
+
 func (em *executorManager) Loop() error {
 	return em.server.Loop()
 }
@@ -300,6 +479,7 @@ func NewManager(net, addr string, l log.Logger, engines ...Engine) (Manager, err
 
 	for i := 0; i < len(em.executorStates); i++ {
 		em.executorStates[i].waiter = sync.NewCond(&em.lock)
+		em.executorStates[i].metric = metric.NewEEQueueMetric(RequestPriority(i).String())
 	}
 
 	em.engines = make(map[string]*engine)
@@ -307,7 +487,10 @@ func NewManager(net, addr string, l log.Logger, engines ...Engine) (Manager, err
 		if err := e.Init(net, addr); err != nil {
 			return nil, err
 		}
-		em.engines[e.Type()] = &engine{engine: e}
+		em.engines[e.Type()] = &engine{
+			engine: e,
+			metric: metric.NewEEMetric(e.Type()),
+		}
 	}
 	return em, nil
 }
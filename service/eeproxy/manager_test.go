@@ -0,0 +1,126 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/service/scoreapi"
+)
+
+const testStuckID = "test.stuck"
+
+// testStuck blocks on invocation until ch is closed.
+type testStuck struct {
+	ch chan struct{}
+}
+
+func (s *testStuck) GetAPI() *scoreapi.Info {
+	return scoreapi.NewInfo([]*scoreapi.Method{
+		{
+			Type: scoreapi.Function,
+			Name: "<init>",
+		},
+		{
+			Type:  scoreapi.Function,
+			Name:  "run",
+			Flags: scoreapi.FlagExternal,
+		},
+	})
+}
+
+func (s *testStuck) Invoke(ctx GoScoreContext, method string, params []interface{}) (interface{}, error) {
+	<-s.ch
+	return nil, nil
+}
+
+func inspectEngine(mgr Manager, name string) map[string]interface{} {
+	return mgr.Inspect()["engines"].(map[string]interface{})[name].(map[string]interface{})
+}
+
+func TestManager_Inspect(t *testing.T) {
+	mgr := newGoEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("go")
+
+	cc := &testCallContext{ch: make(chan *testResult, 1)}
+	assert.NoError(t, proxy.GetAPI(cc, writeGoScore(t, testCounterID)))
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+
+	info := mgr.Inspect()
+	assert.Equal(t, "0s", info["watchdogTimeout"])
+	queue := info["queues"].(map[string]interface{})[ForTransaction.String()].(map[string]interface{})
+	assert.Equal(t, 1, queue["limit"])
+	assert.Equal(t, 1, queue["assigned"])
+	assert.Equal(t, 0, queue["waiting"])
+
+	engine := inspectEngine(mgr, "go")
+	assert.Equal(t, 1, engine["executors"])
+	assert.Equal(t, 0, engine["ready"])
+	assert.Equal(t, 1, engine["using"])
+	assert.EqualValues(t, 1, engine["calls"])
+	assert.EqualValues(t, 0, engine["restarts"])
+}
+
+func TestManager_Watchdog(t *testing.T) {
+	score := &testStuck{ch: make(chan struct{})}
+	RegisterGoScore(testStuckID, score)
+
+	mgr := newGoEEManager(t)
+	mgr.SetWatchdogTimeout(200 * time.Millisecond)
+	assert.Equal(t, "200ms", mgr.Inspect()["watchdogTimeout"])
+
+	ex := mgr.GetExecutor(ForTransaction)
+	proxy := ex.Get("go")
+	code := writeGoScore(t, testStuckID)
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	addr := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	cc := &testCallContext{
+		proxy: proxy,
+		code:  code,
+		store: make(map[string][]byte),
+		ch:    make(chan *testResult, 1),
+	}
+	err := proxy.Invoke(cc, code, false, from, addr, big.NewInt(0),
+		big.NewInt(1000), "run", common.MustEncodeAny([]interface{}{}), nil, 0, nil)
+	assert.NoError(t, err)
+	r := cc.wait(t)
+	assert.True(t, errors.ExecutionFailError.Equals(r.status))
+	ex.Release()
+
+	engine := inspectEngine(mgr, "go")
+	assert.EqualValues(t, 1, engine["watchdogKills"])
+	assert.EqualValues(t, 1, engine["restarts"])
+	assert.Equal(t, 0, engine["executors"])
+
+	// the instance is replaced after the score returns
+	close(score.ch)
+	ex = mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	cc = &testCallContext{ch: make(chan *testResult, 1)}
+	assert.NoError(t, ex.Get("go").GetAPI(cc, writeGoScore(t, testCounterID)))
+	r = cc.wait(t)
+	assert.NoError(t, r.status)
+}
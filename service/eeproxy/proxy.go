@@ -3,6 +3,7 @@ package eeproxy
 import (
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 
@@ -89,13 +90,15 @@ type Proxy interface {
 
 type proxyManager interface {
 	onReady(p *proxy) error
+	onCall(p *proxy, d time.Duration)
 	kill(u string) error
 }
 
 type callFrame struct {
-	addr module.Address
-	ctx  CallContext
-	log  *trace.Logger
+	addr  module.Address
+	ctx   CallContext
+	log   *trace.Logger
+	start time.Time
 
 	prev *callFrame
 }
@@ -115,6 +118,10 @@ type proxy struct {
 
 	frame *callFrame
 
+	// waitSince is the time when the proxy started to wait for the
+	// execution environment. It's zero if the proxy is not waiting.
+	waitSince time.Time
+
 	next  *proxy
 	pprev **proxy
 }
@@ -271,12 +278,14 @@ func (p *proxy) Invoke(
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	p.frame = &callFrame{
-		addr: to,
-		ctx:  ctx,
-		log:  p.log,
-		prev: p.frame,
+		addr:  to,
+		ctx:   ctx,
+		log:   p.log,
+		start: time.Now(),
+		prev:  p.frame,
 	}
 	p.log = logger
+	p.waitSince = p.frame.start
 	return p.conn.Send(msgINVOKE, &m)
 }
 
@@ -288,12 +297,14 @@ func (p *proxy) GetAPI(ctx CallContext, code string) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	p.frame = &callFrame{
-		addr: nil,
-		ctx:  ctx,
-		log:  p.log,
-		prev: p.frame,
+		addr:  nil,
+		ctx:   ctx,
+		log:   p.log,
+		start: time.Now(),
+		prev:  p.frame,
 	}
 	p.log = logger
+	p.waitSince = p.frame.start
 	return p.conn.Send(msgGETAPI, code)
 }
 
@@ -355,9 +366,36 @@ func (p *proxy) SendResult(ctx CallContext, status error, steps *big.Int, result
 	}
 	m.EID = eid
 	m.PrevEID = last
+	p.setWaiting(true)
 	return p.conn.Send(msgRESULT, &m)
 }
 
+// setWaiting updates the time when the proxy started to wait for the
+// execution environment. It's also refreshed on requests from the
+// execution environment, so it measures inactivity of the environment.
+func (p *proxy) setWaiting(yn bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if yn {
+		p.waitSince = time.Now()
+	} else {
+		p.waitSince = time.Time{}
+	}
+}
+
+// waitingFor returns how long the proxy has been waiting for the execution
+// environment without any message. It returns zero if it's not waiting.
+func (p *proxy) waitingFor(now time.Time) time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.waitSince.IsZero() || p.state >= stateStopped {
+		return 0
+	}
+	return now.Sub(p.waitSince)
+}
+
 func (p *proxy) popFrame() *callFrame {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -393,6 +431,13 @@ func (p *proxy) tryToBeReadyInLock() bool {
 }
 
 func (p *proxy) HandleMessage(c ipc.Connection, msg uint, data []byte) error {
+	switch msg {
+	case msgRESULT, msgGETAPI, msgCALL:
+		p.setWaiting(false)
+	default:
+		p.setWaiting(true)
+	}
+
 	switch msg {
 	case msgRESULT:
 		var m resultMessage
@@ -405,6 +450,7 @@ func (p *proxy) HandleMessage(c ipc.Connection, msg uint, data []byte) error {
 		if frame == nil {
 			return errors.InvalidStateError.New("Empty frame")
 		}
+		p.mgr.onCall(p, time.Since(frame.start))
 
 		var status error
 		var result *codec.TypedObj
@@ -511,6 +557,7 @@ func (p *proxy) HandleMessage(c ipc.Connection, msg uint, data []byte) error {
 		if frame == nil {
 			return errors.InvalidStateError.New("Empty frame")
 		}
+		p.mgr.onCall(p, time.Since(frame.start))
 
 		var status error
 		if m.Status != errors.Success {
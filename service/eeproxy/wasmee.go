@@ -0,0 +1,184 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/cache"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wasm"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	WasmEE = "wasmee"
+
+	wasmCodeFile       = "score.wasm"
+	wasmAPISection     = "icon:api"
+	wasmAllocFunc      = "alloc"
+	wasmFallbackFunc   = "fallback"
+	wasmScoreCacheSize = 64
+)
+
+var (
+	wasmMethodType = &wasm.FuncType{
+		Params: []wasm.ValueType{wasm.I32, wasm.I32},
+	}
+	wasmAllocType = &wasm.FuncType{
+		Params:  []wasm.ValueType{wasm.I32},
+		Results: []wasm.ValueType{wasm.I32},
+	}
+)
+
+// wasmScore is GoScore for a contract in WebAssembly deployed with content
+// type state.CTAppWasm, which is available only if the revision has
+// module.WasmExecutionEngine.
+//
+// The module has the API in the custom section "icon:api" in the format of
+// icx_getScoreApi, and it exports the memory, "alloc" allocating memory for
+// the parameters and a function for each method in the API. The function
+// for the fallback is "fallback", and the install and update methods are
+// "on_install" and "on_update", which may be omitted.
+//
+// Exported functions for the methods receive the pointer and the length of
+// the parameters in JSON array, and it may set the result with the host
+// function "set_result". Refer wasmHost for the host functions.
+type wasmScore struct {
+	module *wasm.Module
+	info   *scoreapi.Info
+	events map[string]*scoreapi.Method
+}
+
+func wasmFuncName(method string) string {
+	if method == scoreapi.FallbackMethodName {
+		return wasmFallbackFunc
+	}
+	return method
+}
+
+func checkWasmExport(m *wasm.Module, name string, t *wasm.FuncType) error {
+	idx, ok := m.ExportedFunc(name)
+	if !ok {
+		return scoreresult.IllegalFormatError.Errorf("NoExportedFunc(name=%s)", name)
+	}
+	if !m.FuncTypeOf(idx).Equal(t) {
+		return scoreresult.IllegalFormatError.Errorf("InvalidFuncType(name=%s)", name)
+	}
+	return nil
+}
+
+func newWasmScore(code string) (*wasmScore, error) {
+	bs, err := os.ReadFile(filepath.Join(code, wasmCodeFile))
+	if err != nil {
+		return nil, scoreresult.ContractNotFoundError.Wrapf(err,
+			"FailToReadCode(code=%s)", code)
+	}
+	m, err := wasm.Decode(bs)
+	if err != nil {
+		return nil, scoreresult.IllegalFormatError.Wrap(err, "InvalidModule")
+	}
+	if m.Memory == nil {
+		return nil, scoreresult.IllegalFormatError.New("NoMemory")
+	}
+	if err := checkWasmExport(m, wasmAllocFunc, wasmAllocType); err != nil {
+		return nil, err
+	}
+	api, ok := m.CustomSection(wasmAPISection)
+	if !ok {
+		return nil, scoreresult.IllegalFormatError.New("NoAPISection")
+	}
+	methods, err := scoreapi.MethodsFromJSON(api)
+	if err != nil {
+		return nil, scoreresult.IllegalFormatError.Wrap(err, "InvalidAPI")
+	}
+	events := make(map[string]*scoreapi.Method)
+	for _, method := range methods {
+		if method.IsEvent() {
+			events[method.Name] = method
+			continue
+		}
+		if state.WasmEE.IsInternalMethod(method.Name) {
+			method.Flags &^= scoreapi.FlagExternal
+		}
+		if err := checkWasmExport(m, wasmFuncName(method.Name), wasmMethodType); err != nil {
+			return nil, err
+		}
+	}
+	return &wasmScore{
+		module: m,
+		info:   scoreapi.NewInfo(methods),
+		events: events,
+	}, nil
+}
+
+// wasmScores caches decoded modules by the path of the code, which is
+// named with the hash of the code.
+var wasmScores = cache.NewLRUCache(wasmScoreCacheSize, func(code string) (interface{}, error) {
+	return newWasmScore(code)
+})
+
+func loadWasmScore(code string) (GoScore, error) {
+	score, err := wasmScores.Get(code)
+	if err != nil {
+		return nil, err
+	}
+	return score.(*wasmScore), nil
+}
+
+func (s *wasmScore) GetAPI() *scoreapi.Info {
+	return s.info
+}
+
+func (s *wasmScore) Invoke(ctx GoScoreContext, method string, params []interface{}) (interface{}, error) {
+	m := s.info.GetMethod(method)
+	if m == nil || m.IsEvent() {
+		if state.WasmEE.IsInternalMethod(method) {
+			return nil, nil
+		}
+		return nil, scoreresult.MethodNotFoundError.Errorf("MethodNotFound(name=%s)", method)
+	}
+	if params == nil {
+		params = []interface{}{}
+	}
+	jso, err := common.AnyForJSON(params)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidParams")
+	}
+	args, err := json.Marshal(jso)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidParams")
+	}
+	result, err := newWasmHost(ctx, s).invoke(wasmFuncName(method), args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || len(m.Outputs) == 0 {
+		return nil, nil
+	}
+	return m.Outputs[0].ConvertJSONToTypedObj(result, nil, true)
+}
+
+func NewWasmEE(logger log.Logger) (Engine, error) {
+	return newGoEngine(state.WasmEE, loadWasmScore,
+		logger.WithFields(log.Fields{log.FieldKeyModule: WasmEE})), nil
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+// wasmBytes concatenates bytes, integers as bytes, strings with the length
+// and byte slices.
+func wasmBytes(items ...interface{}) []byte {
+	var bs []byte
+	for _, item := range items {
+		switch v := item.(type) {
+		case int:
+			bs = append(bs, byte(v))
+		case string:
+			bs = append(bs, wasmLen(len(v))...)
+			bs = append(bs, v...)
+		case []byte:
+			bs = append(bs, v...)
+		}
+	}
+	return bs
+}
+
+func wasmVec(items ...[]byte) []byte {
+	bs := []byte{byte(len(items))}
+	for _, item := range items {
+		bs = append(bs, item...)
+	}
+	return bs
+}
+
+func wasmSection(id int, items ...interface{}) []byte {
+	content := wasmBytes(items...)
+	return wasmBytes(id, wasmLen(len(content)), content)
+}
+
+func wasmLen(n int) []byte {
+	var bs []byte
+	for ; n >= 0x80; n >>= 7 {
+		bs = append(bs, byte(n&0x7f)|0x80)
+	}
+	return append(bs, byte(n))
+}
+
+func wasmBody(locals []byte, code ...interface{}) []byte {
+	content := wasmBytes(locals, wasmBytes(code...))
+	return wasmBytes(wasmLen(len(content)), content)
+}
+
+const (
+	testWasmAPI = `[
+{"type":"function","name":"set","inputs":[{"name":"value","type":"str"}],"outputs":[]},
+{"type":"function","name":"get","inputs":[],"outputs":[{"type":"str"}],"readonly":"0x1"},
+{"type":"function","name":"burn","inputs":[],"outputs":[]},
+{"type":"eventlog","name":"Changed","inputs":[{"name":"value","type":"int","indexed":"0x1"}]}
+]`
+	testWasmEvent = `{"name":"Changed","params":["0x1"]}`
+)
+
+// testWasmStore returns a module storing the parameters of set in JSON,
+// and get returns the string in the stored parameters.
+func testWasmStore(api string) []byte {
+	const (
+		i32       = 0x7f
+		i32Const  = 0x41
+		localGet  = 0x20
+		localSet  = 0x21
+		call      = 0x10
+		end       = 0x0b
+		i32Sub    = 0x6b
+		loop      = 0x03
+		br        = 0x0c
+		blockVoid = 0x40
+	)
+	return wasmBytes(
+		[]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+		wasmSection(1, wasmVec(
+			wasmBytes(0x60, 2, i32, i32, 0),
+			wasmBytes(0x60, 1, i32, 1, i32),
+			wasmBytes(0x60, 4, i32, i32, i32, i32, 0),
+			wasmBytes(0x60, 2, i32, i32, 1, i32),
+			wasmBytes(0x60, 1, i32, 0),
+		)),
+		wasmSection(2, wasmVec(
+			wasmBytes("env", "set_value", 0, 2),
+			wasmBytes("env", "get_value", 0, 3),
+			wasmBytes("env", "read_return", 0, 4),
+			wasmBytes("env", "set_result", 0, 0),
+			wasmBytes("env", "event", 0, 0),
+		)),
+		wasmSection(3, wasmVec([]byte{1}, []byte{0}, []byte{0}, []byte{0})),
+		wasmSection(5, wasmVec(wasmBytes(0, 1))),
+		wasmSection(7, wasmVec(
+			wasmBytes("memory", 2, 0),
+			wasmBytes("alloc", 0, 5),
+			wasmBytes("set", 0, 6),
+			wasmBytes("get", 0, 7),
+			wasmBytes("burn", 0, 8),
+		)),
+		wasmSection(10, wasmVec(
+			// alloc returns fixed address
+			wasmBody(wasmVec(), i32Const, []byte{0x80, 0x08}, end),
+			// set stores the parameters and emits the event
+			wasmBody(wasmVec(),
+				i32Const, 0, i32Const, 5, localGet, 0, localGet, 1, call, 0,
+				i32Const, 16, i32Const, len(testWasmEvent), call, 4,
+				end),
+			// get returns the stored string without brackets
+			wasmBody(wasmVec(wasmBytes(1, i32)),
+				i32Const, 0, i32Const, 5, call, 1, localSet, 2,
+				i32Const, []byte{0x80, 0x10}, call, 2,
+				i32Const, []byte{0x81, 0x10}, localGet, 2, i32Const, 2, i32Sub, call, 3,
+				end),
+			// burn consumes all steps
+			wasmBody(wasmVec(), loop, blockVoid, br, 0, end, end),
+		)),
+		wasmSection(11, wasmVec(
+			wasmBytes(0, i32Const, 0, end, "count"),
+			wasmBytes(0, i32Const, 16, end, testWasmEvent),
+		)),
+		wasmSection(0, "icon:api", []byte(api)),
+	)
+}
+
+func writeWasmScore(t *testing.T, code []byte) string {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, wasmCodeFile), code, 0644)
+	assert.NoError(t, err)
+	return dir
+}
+
+func newWasmEEManager(t *testing.T) Manager {
+	engine, err := NewWasmEE(log.GlobalLogger())
+	assert.NoError(t, err)
+	return newTestManager(t, engine)
+}
+
+func TestWasmEE_GetAPI(t *testing.T) {
+	mgr := newWasmEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("wasm")
+	assert.NotNil(t, proxy)
+
+	cc := &testCallContext{ch: make(chan *testResult, 1)}
+	assert.NoError(t, proxy.GetAPI(cc, writeWasmScore(t, testWasmStore(testWasmAPI))))
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+	assert.True(t, r.info.GetMethod("set").IsExternal())
+	assert.True(t, r.info.GetMethod("get").IsReadOnly())
+	assert.True(t, r.info.GetMethod("Changed(int)").IsEvent())
+
+	for _, code := range [][]byte{
+		[]byte("invalid"),
+		testWasmStore(`[{"type":"function","name":"none","inputs":[]}]`),
+	} {
+		assert.NoError(t, proxy.GetAPI(cc, writeWasmScore(t, code)))
+		r = cc.wait(t)
+		assert.True(t, scoreresult.IllegalFormatError.Equals(r.status))
+		assert.Nil(t, r.info)
+	}
+}
+
+func TestWasmEE_Invoke(t *testing.T) {
+	mgr := newWasmEEManager(t)
+	ex := mgr.GetExecutor(ForTransaction)
+	defer ex.Release()
+	proxy := ex.Get("wasm")
+
+	code := writeWasmScore(t, testWasmStore(testWasmAPI))
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	cc := &testCallContext{
+		proxy: proxy,
+		code:  code,
+		store: make(map[string][]byte),
+		ch:    make(chan *testResult, 1),
+	}
+
+	// memory(64) + instructions(11) + setBase(20) + 7*set(2)
+	// + logBase(50) + 13*log(3)
+	params := common.MustEncodeAny([]interface{}{"abc"})
+	err := proxy.Invoke(cc, code, false, from, score, big.NewInt(0),
+		big.NewInt(1000), "set", params, nil, 0, nil)
+	assert.NoError(t, err)
+	r := cc.wait(t)
+	assert.NoError(t, r.status)
+	assert.Equal(t, int64(198), r.steps.Int64())
+	assert.Equal(t, []byte(`["abc"]`), cc.store["count"])
+	assert.Equal(t, [][][]byte{{[]byte("Changed(int)"), {1}}}, cc.events)
+
+	// memory(64) + instructions(14) + getBase(10) + 7*get(1)
+	err = proxy.Invoke(cc, code, true, from, score, big.NewInt(0),
+		big.NewInt(1000), "get", common.MustEncodeAny([]interface{}{}), nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.NoError(t, r.status)
+	assert.Equal(t, int64(95), r.steps.Int64())
+	assert.Equal(t, "abc", common.MustDecodeAny(r.result))
+
+	err = proxy.Invoke(cc, code, true, from, score, big.NewInt(0),
+		big.NewInt(1000), "set", params, nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.True(t, scoreresult.AccessDeniedError.Equals(r.status))
+
+	err = proxy.Invoke(cc, code, false, from, score, big.NewInt(0),
+		big.NewInt(500), "burn", common.MustEncodeAny([]interface{}{}), nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.True(t, scoreresult.OutOfStepError.Equals(r.status))
+	assert.Equal(t, int64(500), r.steps.Int64())
+
+	err = proxy.Invoke(cc, code, false, from, score, big.NewInt(0),
+		big.NewInt(1000), "on_install", common.MustEncodeAny([]interface{}{}), nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.NoError(t, r.status)
+
+	err = proxy.Invoke(cc, code, false, from, score, big.NewInt(0),
+		big.NewInt(1000), "unknown", common.MustEncodeAny([]interface{}{}), nil, 0, nil)
+	assert.NoError(t, err)
+	r = cc.wait(t)
+	assert.True(t, scoreresult.MethodNotFoundError.Equals(r.status))
+}
@@ -0,0 +1,399 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wasm"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+const wasmHostModule = "env"
+
+// wasmHost provides host functions of the module "env" for an invocation
+// of wasmScore. Data are passed with the pointer and the length in the
+// memory, and structured data are in JSON.
+//
+//	get_value(key, key_len) -> len       -1 if there is no value
+//	set_value(key, key_len, value, value_len)
+//	delete_value(key, key_len)
+//	get_balance(addr, addr_len) -> len   balance in JSON
+//	get_info() -> len                    {"from","to","value","info"}
+//	event(ev, ev_len)                    {"name","params"}
+//	call(req, req_len) -> len            {"to","value","method","params","types"}
+//	transfer(req, req_len)               {"to","value"}
+//	set_fee_proportion(portion)
+//	set_result(result, result_len)       result in JSON
+//	revert(code, msg, msg_len)
+//	log(msg, msg_len)
+//	read_return(ptr)
+//
+// Functions returning len keep the returned data, and read_return copies
+// it to the memory. Steps for the instructions executed so far are charged
+// before each host function.
+type wasmHost struct {
+	ctx   GoScoreContext
+	score *wasmScore
+	inst  *wasm.Instance
+
+	ret    []byte
+	result []byte
+}
+
+type wasmCallRequest struct {
+	To     common.Address    `json:"to"`
+	Value  *common.HexInt    `json:"value"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Types  []string          `json:"types"`
+}
+
+type wasmEvent struct {
+	Name   string            `json:"name"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func newWasmHost(ctx GoScoreContext, score *wasmScore) *wasmHost {
+	return &wasmHost{
+		ctx:   ctx,
+		score: score,
+	}
+}
+
+func (h *wasmHost) available() uint64 {
+	steps := new(big.Int).Sub(h.ctx.StepLimit(), h.ctx.StepUsed())
+	if steps.Sign() <= 0 {
+		return 0
+	}
+	if !steps.IsInt64() {
+		return math.MaxInt64
+	}
+	return steps.Uint64()
+}
+
+// charge charges steps consumed by the instance, then it limits steps of
+// the instance with the steps available.
+func (h *wasmHost) charge() error {
+	if err := h.ctx.Charge(int64(h.inst.Steps())); err != nil {
+		return err
+	}
+	h.inst.ResetSteps(h.available())
+	return nil
+}
+
+func wasmError(err error) error {
+	if errors.Is(err, wasm.ErrOutOfSteps) {
+		return scoreresult.OutOfStepError.Wrap(err, "OutOfStep")
+	}
+	if scoreresult.IsValid(err) {
+		return err
+	}
+	return scoreresult.UnknownFailureError.Wrap(err, "ExecutionFailure")
+}
+
+func (h *wasmHost) invoke(fn string, args []byte) ([]byte, error) {
+	inst, err := wasm.Instantiate(h.score.module, h.imports(), nil, h.available())
+	if err != nil {
+		if errors.Is(err, wasm.ErrOutOfSteps) {
+			_ = h.ctx.Charge(int64(h.available()))
+		}
+		return nil, wasmError(err)
+	}
+	h.inst = inst
+	err = h.invokeFunc(fn, args)
+	if err2 := h.charge(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return nil, wasmError(err)
+	}
+	return h.result, nil
+}
+
+func (h *wasmHost) invokeFunc(fn string, args []byte) error {
+	res, err := h.inst.Call(wasmAllocFunc, uint64(len(args)))
+	if err != nil {
+		return err
+	}
+	ptr := uint32(res[0])
+	if err := h.inst.Write(ptr, args); err != nil {
+		return err
+	}
+	_, err = h.inst.Call(fn, uint64(ptr), uint64(len(args)))
+	return err
+}
+
+func (h *wasmHost) read(ptr, size uint64) ([]byte, error) {
+	return h.inst.Read(uint32(ptr), uint32(size))
+}
+
+func (h *wasmHost) readJSON(ptr, size uint64, v interface{}) error {
+	bs, err := h.read(ptr, size)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bs, v); err != nil {
+		return scoreresult.InvalidParameterError.Wrap(err, "InvalidJSON")
+	}
+	return nil
+}
+
+func (h *wasmHost) returnJSON(v interface{}) ([]uint64, error) {
+	jso, err := common.AnyForJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := json.Marshal(jso)
+	if err != nil {
+		return nil, err
+	}
+	h.ret = bs
+	return []uint64{uint64(len(bs))}, nil
+}
+
+func (h *wasmHost) hostFunc(params, results int, call func(args []uint64) ([]uint64, error)) *wasm.HostFunc {
+	t := new(wasm.FuncType)
+	for i := 0; i < params; i++ {
+		t.Params = append(t.Params, wasm.I32)
+	}
+	for i := 0; i < results; i++ {
+		t.Results = append(t.Results, wasm.I32)
+	}
+	return &wasm.HostFunc{
+		Type: t,
+		Call: func(inst *wasm.Instance, args []uint64) ([]uint64, error) {
+			// it may be called by the start function on instantiation.
+			h.inst = inst
+			if err := h.charge(); err != nil {
+				return nil, err
+			}
+			res, err := call(args)
+			if err != nil {
+				return nil, err
+			}
+			// steps are used by the operation.
+			inst.ResetSteps(h.available())
+			return res, nil
+		},
+	}
+}
+
+func (h *wasmHost) imports() wasm.Imports {
+	return wasm.Imports{
+		wasmHostModule: {
+			"get_value":          h.hostFunc(2, 1, h.getValue),
+			"set_value":          h.hostFunc(4, 0, h.setValue),
+			"delete_value":       h.hostFunc(2, 0, h.deleteValue),
+			"get_balance":        h.hostFunc(2, 1, h.getBalance),
+			"get_info":           h.hostFunc(0, 1, h.getInfo),
+			"event":              h.hostFunc(2, 0, h.event),
+			"call":               h.hostFunc(2, 1, h.call),
+			"transfer":           h.hostFunc(2, 0, h.transfer),
+			"set_fee_proportion": h.hostFunc(1, 0, h.setFeeProportion),
+			"set_result":         h.hostFunc(2, 0, h.setResult),
+			"revert":             h.hostFunc(3, 0, h.revert),
+			"log":                h.hostFunc(2, 0, h.log),
+			"read_return":        h.hostFunc(1, 0, h.readReturn),
+		},
+	}
+}
+
+func (h *wasmHost) getValue(args []uint64) ([]uint64, error) {
+	key, err := h.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := h.ctx.GetValue(key)
+	if err != nil {
+		return nil, err
+	}
+	h.ret = value
+	if value == nil {
+		return []uint64{math.MaxUint32}, nil
+	}
+	return []uint64{uint64(len(value))}, nil
+}
+
+func (h *wasmHost) setValue(args []uint64) ([]uint64, error) {
+	key, err := h.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := h.read(args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	_, err = h.ctx.SetValue(key, value)
+	return nil, err
+}
+
+func (h *wasmHost) deleteValue(args []uint64) ([]uint64, error) {
+	key, err := h.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	_, err = h.ctx.DeleteValue(key)
+	return nil, err
+}
+
+func (h *wasmHost) readAddress(ptr, size uint64) (*common.Address, error) {
+	bs, err := h.read(ptr, size)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := common.NewAddressFromString(string(bs))
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrapf(err,
+			"InvalidAddress(%q)", bs)
+	}
+	return addr, nil
+}
+
+func (h *wasmHost) getBalance(args []uint64) ([]uint64, error) {
+	addr, err := h.readAddress(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	balance, err := h.ctx.GetBalance(addr)
+	if err != nil {
+		return nil, err
+	}
+	return h.returnJSON(new(common.HexInt).SetValue(balance))
+}
+
+func (h *wasmHost) getInfo(args []uint64) ([]uint64, error) {
+	return h.returnJSON(map[string]interface{}{
+		"from":  h.ctx.From(),
+		"to":    h.ctx.Address(),
+		"value": new(common.HexInt).SetValue(h.ctx.Value()),
+		"info":  h.ctx.Info(),
+	})
+}
+
+func (h *wasmHost) event(args []uint64) ([]uint64, error) {
+	var ev wasmEvent
+	if err := h.readJSON(args[0], args[1], &ev); err != nil {
+		return nil, err
+	}
+	m, ok := h.score.events[ev.Name]
+	if !ok {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"EventNotFound(name=%s)", ev.Name)
+	}
+	if len(ev.Params) != len(m.Inputs) {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidEventParams(name=%s,params=%d)", ev.Name, len(ev.Params))
+	}
+	values := make([]interface{}, len(ev.Params))
+	for i, input := range m.Inputs {
+		obj, err := input.Type.ConvertJSONToTypedObj(ev.Params[i], input.Fields, i >= m.Indexed)
+		if err != nil {
+			return nil, err
+		}
+		if values[i], err = common.DecodeAny(obj); err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidEventParam")
+		}
+	}
+	indexed := append([]interface{}{m.Signature()}, values[:m.Indexed]...)
+	return nil, h.ctx.Event(indexed, values[m.Indexed:])
+}
+
+func (h *wasmHost) call(args []uint64) ([]uint64, error) {
+	var req wasmCallRequest
+	if err := h.readJSON(args[0], args[1], &req); err != nil {
+		return nil, err
+	}
+	if len(req.Types) != len(req.Params) {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidCallParams(params=%d,types=%d)", len(req.Params), len(req.Types))
+	}
+	params := make([]interface{}, len(req.Params))
+	for i, p := range req.Params {
+		t := scoreapi.DataTypeOf(req.Types[i])
+		if t == scoreapi.Unknown {
+			return nil, scoreresult.InvalidParameterError.Errorf(
+				"UnknownType(%s)", req.Types[i])
+		}
+		obj, err := t.ConvertJSONToTypedObj(p, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		params[i] = obj
+	}
+	result, err := h.ctx.Call(&req.To, req.Value.Value(), req.Method, params...)
+	if err != nil {
+		return nil, err
+	}
+	return h.returnJSON(result)
+}
+
+func (h *wasmHost) transfer(args []uint64) ([]uint64, error) {
+	var req wasmCallRequest
+	if err := h.readJSON(args[0], args[1], &req); err != nil {
+		return nil, err
+	}
+	return nil, h.ctx.Transfer(&req.To, req.Value.Value())
+}
+
+func (h *wasmHost) setFeeProportion(args []uint64) ([]uint64, error) {
+	return nil, h.ctx.SetFeeProportion(int(int32(args[0])))
+}
+
+func (h *wasmHost) setResult(args []uint64) ([]uint64, error) {
+	result, err := h.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(result) {
+		return nil, scoreresult.InvalidParameterError.New("InvalidResultJSON")
+	}
+	h.result = result
+	return nil, nil
+}
+
+func (h *wasmHost) revert(args []uint64) ([]uint64, error) {
+	msg, err := h.read(args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	code := int32(args[0])
+	if code < 0 || code > int32(module.StatusLimitRev5-module.StatusReverted) {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidRevertCode(%d)", code)
+	}
+	return nil, scoreresult.Errorf(module.StatusReverted+module.Status(code), string(msg))
+}
+
+func (h *wasmHost) log(args []uint64) ([]uint64, error) {
+	msg, err := h.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	h.ctx.Logger().Debugf("%s", msg)
+	return nil, nil
+}
+
+func (h *wasmHost) readReturn(args []uint64) ([]uint64, error) {
+	return nil, h.inst.Write(uint32(args[0]), h.ret)
+}
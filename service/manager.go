@@ -23,6 +23,7 @@ import (
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/contract"
@@ -55,6 +56,7 @@ type manager struct {
 	trc       *transitionResultCache
 	tsc       *TxTimestampChecker
 	syncer    *ssync.Manager
+	ai        *accountIndex
 
 	log log.Logger
 
@@ -112,6 +114,12 @@ func NewManager(chain module.Chain, nm module.NetworkManager,
 	if nm != nil {
 		mgr.txReactor = NewTransactionReactor(nm, tm)
 	}
+	if chain.AccountIndex() {
+		if mgr.ai, err = newAccountIndex(mgr, logger); err != nil {
+			logger.Warnf("FAIL to create accountIndex : %v\n", err)
+			return nil, err
+		}
+	}
 	return mgr, nil
 }
 
@@ -120,9 +128,15 @@ func (m *manager) Start() {
 		m.txReactor.Start(m.chain.Wallet())
 		m.syncer.Start()
 	}
+	if m.ai != nil {
+		m.ai.Start()
+	}
 }
 
 func (m *manager) Term() {
+	if m.ai != nil {
+		m.ai.Stop()
+	}
 	if m.txReactor != nil {
 		m.txReactor.Stop()
 		m.syncer.Term()
@@ -300,6 +314,9 @@ func (m *manager) Finalize(t module.Transition, opt int) error {
 			now := time.Now()
 			m.patchMetric.OnFinalize(tst.patchTransactions.Hash(), now)
 			m.normalMetric.OnFinalize(tst.normalTransactions.Hash(), now)
+			if m.ai != nil {
+				m.ai.Notify()
+			}
 		}
 	} else {
 		panic("FAIL type assertion. Not transition pointer type")
@@ -664,6 +681,101 @@ func (m *manager) GetSCOREStatus(result []byte, addr module.Address) (module.SCO
 	}, nil
 }
 
+type storageProof struct {
+	key   []byte
+	value []byte
+	proof [][]byte
+}
+
+type accountProof struct {
+	addr      module.Address
+	stateHash []byte
+	ass       state.AccountSnapshot
+	proof     [][]byte
+	storage   []storageProof
+}
+
+func proofToJSON(proof [][]byte) interface{} {
+	if proof == nil {
+		return nil
+	}
+	res := make([]interface{}, len(proof))
+	for i, p := range proof {
+		res[i] = common.HexBytes(p)
+	}
+	return res
+}
+
+func (p *accountProof) ToJSON(version module.JSONVersion) (interface{}, error) {
+	ret := make(map[string]interface{})
+	ret["address"] = p.addr
+	ret["stateHash"] = common.HexBytes(p.stateHash)
+	ret["accountProof"] = proofToJSON(p.proof)
+	ret["account"] = common.HexBytes(p.ass.Bytes())
+	ret["balance"] = intconv.FormatBigInt(p.ass.GetBalance())
+	if hash := p.ass.StorageHash(); hash != nil {
+		ret["storageHash"] = common.HexBytes(hash)
+	}
+	storage := make([]interface{}, len(p.storage))
+	for i, sp := range p.storage {
+		jso := make(map[string]interface{})
+		jso["key"] = common.HexBytes(sp.key)
+		jso["value"] = common.HexBytes(sp.value)
+		jso["proof"] = proofToJSON(sp.proof)
+		storage[i] = jso
+	}
+	ret["storageProofs"] = storage
+	return ret, nil
+}
+
+// newAccountProof returns proofs of the account and its storage values for
+// the keys. The trie doesn't make proofs for absent keys, so it returns
+// NotFoundError if the account or any of the values doesn't exist.
+func newAccountProof(wss state.WorldSnapshot, addr module.Address, keys [][]byte) (*accountProof, error) {
+	ass := wss.GetAccountSnapshot(addr.ID())
+	if ass == nil {
+		return nil, errors.NotFoundError.Errorf("NoAccount(addr=%s)", addr)
+	}
+	p := &accountProof{
+		addr:      addr,
+		stateHash: wss.StateHash(),
+		ass:       ass,
+		proof:     wss.GetProofOfAccount(addr.ID()),
+		storage:   make([]storageProof, len(keys)),
+	}
+	if p.proof == nil {
+		return nil, errors.InvalidStateError.Errorf("NoProofForAccount(addr=%s)", addr)
+	}
+	for i, k := range keys {
+		value, err := ass.GetValue(k)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, errors.NotFoundError.Errorf("NoValue(addr=%s,key=%#x)", addr, k)
+		}
+		proof := ass.GetProofOfValue(k)
+		if proof == nil {
+			return nil, errors.InvalidStateError.Errorf(
+				"NoProofForValue(addr=%s,key=%#x)", addr, k)
+		}
+		p.storage[i] = storageProof{key: k, value: value, proof: proof}
+	}
+	return p, nil
+}
+
+func (m *manager) GetProof(result []byte, addr module.Address, keys [][]byte) (module.AccountProof, error) {
+	wss, err := m.trc.GetWorldSnapshot(result, nil)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newAccountProof(wss, addr, keys)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 func (m *manager) GetMembers(result []byte) (module.MemberList, error) {
 	wss, err := m.trc.GetWorldSnapshot(result, nil)
 	if err != nil {
@@ -838,6 +950,20 @@ func (m *manager) ImportResult(result []byte, vh []byte, src db.Database) error
 	return e.Run()
 }
 
+// ExportState exports entries of the world state related with the result.
+// Unlike ExportResult, it doesn't export receipts.
+func ExportState(plt base.Platform, src db.Database, result []byte, vh []byte, dst db.Database, cb module.ProgressCallback) error {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return err
+	}
+	e := merkle.NewCopyContext(src, dst)
+	e.SetProgressCallback(cb)
+	ess := plt.NewExtensionWithBuilder(e.Builder(), r.ExtensionData)
+	state.NewWorldSnapshotWithBuilder(e.Builder(), r.StateHash, vh, ess, r.BTPData)
+	return e.Run()
+}
+
 func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo) (module.Receipt, error) {
 	tx, err := transaction.NewTransactionFromJSON(js)
 	if err != nil {
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie/ompt"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func TestNewAccountProof(t *testing.T) {
+	ws := state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	missing := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+	as := ws.GetAccountState(score.ID())
+	as.SetBalance(big.NewInt(100))
+	for i := 0; i < 10; i++ {
+		_, err := as.SetValue([]byte{byte(i)}, []byte{byte(i), byte(i)})
+		assert.NoError(t, err)
+	}
+	wss := ws.GetSnapshot()
+	assert.NoError(t, wss.Flush())
+
+	keys := [][]byte{{1}, {5}}
+	p, err := newAccountProof(wss, score, keys)
+	assert.NoError(t, err)
+	value, err := ompt.NewMPTForBytes(db.NewMapDB(), wss.StateHash()).
+		Prove(crypto.SHA3Sum256(score.ID()), p.proof)
+	assert.NoError(t, err)
+	assert.Equal(t, p.ass.Bytes(), value)
+	storageHash := p.ass.StorageHash()
+	for i, sp := range p.storage {
+		assert.Equal(t, keys[i], sp.key)
+		value, err := ompt.NewMPTForBytes(db.NewMapDB(), storageHash).
+			Prove(sp.key, sp.proof)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{keys[i][0], keys[i][0]}, value)
+		assert.Equal(t, value, sp.value)
+	}
+
+	// absent account and values can't be proved
+	_, err = newAccountProof(wss, missing, nil)
+	assert.True(t, errors.NotFoundError.Equals(err))
+	_, err = newAccountProof(wss, score, [][]byte{{1}, {100}})
+	assert.True(t, errors.NotFoundError.Equals(err))
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import "math/rand"
+
+const (
+	orderedListMaxLevel = 32
+
+	// orderedListBranch is the inverse of the probability that a node has
+	// one more level.
+	orderedListBranch = 4
+)
+
+type orderedNode struct {
+	value interface{}
+	next  []*orderedNode
+
+	// width is the number of nodes passed by following next of the level.
+	width []int
+}
+
+// orderedList is an indexable skip list. It keeps values ordered by less,
+// and it adds, removes and finds the value at a position in O(log n). less
+// must be a total order of values in the list.
+type orderedList struct {
+	less  func(v1, v2 interface{}) bool
+	head  *orderedNode
+	level int
+	size  int
+}
+
+func newOrderedList(less func(v1, v2 interface{}) bool) *orderedList {
+	return &orderedList{
+		less: less,
+		head: &orderedNode{
+			next:  make([]*orderedNode, orderedListMaxLevel),
+			width: make([]int, orderedListMaxLevel),
+		},
+		level: 1,
+	}
+}
+
+func (l *orderedList) Len() int {
+	return l.size
+}
+
+func randomOrderedLevel() int {
+	level := 1
+	for level < orderedListMaxLevel && rand.Intn(orderedListBranch) == 0 {
+		level++
+	}
+	return level
+}
+
+// Add adds the value. It doesn't check whether an equal value exists.
+func (l *orderedList) Add(v interface{}) {
+	var update [orderedListMaxLevel]*orderedNode
+	var rank [orderedListMaxLevel]int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i] != nil && l.less(x.next[i].value, v) {
+			rank[i] += x.width[i]
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	level := randomOrderedLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			update[i] = l.head
+			l.head.width[i] = l.size
+		}
+		l.level = level
+	}
+	n := &orderedNode{
+		value: v,
+		next:  make([]*orderedNode, level),
+		width: make([]int, level),
+	}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+		n.width[i] = update[i].width[i] - (rank[0] - rank[i])
+		update[i].width[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < l.level; i++ {
+		update[i].width[i]++
+	}
+	l.size++
+}
+
+// Remove removes the value equal to v in the order. It returns false if
+// there is no such value.
+func (l *orderedList) Remove(v interface{}) bool {
+	var update [orderedListMaxLevel]*orderedNode
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.less(x.next[i].value, v) {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	x = x.next[0]
+	if x == nil || l.less(v, x.value) {
+		return false
+	}
+	for i := 0; i < l.level; i++ {
+		if update[i].next[i] == x {
+			update[i].width[i] += x.width[i] - 1
+			update[i].next[i] = x.next[i]
+		} else {
+			update[i].width[i]--
+		}
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.size--
+	return true
+}
+
+// Slice returns values in [start, end) of the list.
+func (l *orderedList) Slice(start, end int) []interface{} {
+	if start < 0 {
+		start = 0
+	}
+	if end > l.size {
+		end = l.size
+	}
+	if start >= end {
+		return nil
+	}
+	// find the node at start, whose rank is start+1 from the head.
+	x := l.head
+	traversed := 0
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && traversed+x.width[i] <= start+1 {
+			traversed += x.width[i]
+			x = x.next[i]
+		}
+	}
+	values := make([]interface{}, 0, end-start)
+	for ; x != nil && len(values) < end-start; x = x.next[0] {
+		values = append(values, x.value)
+	}
+	return values
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lessInt(v1, v2 interface{}) bool {
+	return v1.(int) < v2.(int)
+}
+
+func intsOf(values []interface{}) []int {
+	res := make([]int, len(values))
+	for i, v := range values {
+		res[i] = v.(int)
+	}
+	return res
+}
+
+func TestOrderedList_Basic(t *testing.T) {
+	l := newOrderedList(lessInt)
+	assert.Equal(t, 0, l.Len())
+	assert.Nil(t, l.Slice(0, 10))
+	assert.False(t, l.Remove(1))
+
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		l.Add(v)
+	}
+	assert.Equal(t, 5, l.Len())
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, intsOf(l.Slice(0, 5)))
+	assert.Equal(t, []int{2, 3}, intsOf(l.Slice(1, 3)))
+	assert.Equal(t, []int{4, 5}, intsOf(l.Slice(3, 10)))
+	assert.Nil(t, l.Slice(5, 10))
+
+	assert.True(t, l.Remove(3))
+	assert.False(t, l.Remove(3))
+	assert.Equal(t, 4, l.Len())
+	assert.Equal(t, []int{1, 2, 4, 5}, intsOf(l.Slice(0, 4)))
+}
+
+func TestOrderedList_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	l := newOrderedList(lessInt)
+	values := make(map[int]bool)
+	for i := 0; i < 5000; i++ {
+		v := r.Intn(2000)
+		if values[v] {
+			assert.True(t, l.Remove(v))
+			delete(values, v)
+		} else {
+			l.Add(v)
+			values[v] = true
+		}
+	}
+
+	expected := make([]int, 0, len(values))
+	for v := range values {
+		expected = append(expected, v)
+	}
+	sort.Ints(expected)
+	assert.Equal(t, len(expected), l.Len())
+	for start := 0; start < len(expected); start += 97 {
+		end := start + 50
+		if end > len(expected) {
+			end = len(expected)
+		}
+		assert.Equal(t, expected[start:end], intsOf(l.Slice(start, end)))
+	}
+}
@@ -28,6 +28,10 @@ const (
 	Revision7
 	Revision8
 	Revision9
+	Revision10
+	Revision11
+	Revision12
+	Revision13
 	RevisionReserved
 )
 
@@ -58,6 +62,14 @@ var revisionFlags = []module.Revision{
 	module.UseCompactAPIInfo,
 	// Revision 9
 	module.MultipleFeePayers,
+	// Revision 10
+	module.MultiCallTransaction,
+	// Revision 11
+	module.SponsoredTransaction,
+	// Revision 12
+	module.GoExecutionEngine,
+	// Revision 13
+	module.WasmExecutionEngine,
 }
 
 func init() {
@@ -90,6 +90,17 @@ func (info *Info) CheckEventData(indexed [][]byte, data [][]byte) error {
 	return m.CheckEventData(indexed, data)
 }
 
+func (info *Info) DecodeEvent(indexed [][]byte, data [][]byte) (*DecodedEvent, error) {
+	if len(indexed) < 1 {
+		return nil, ErrNoSignature
+	}
+	m := info.GetMethod(string(indexed[0]))
+	if m == nil {
+		return nil, errors.ErrNotFound
+	}
+	return m.DecodeEvent(indexed, data)
+}
+
 func (info *Info) ToJSON(v module.JSONVersion) (interface{}, error) {
 	jso := make([]interface{}, 0, len(info.methods))
 	for _, method := range info.methods {
@@ -1,6 +1,7 @@
 package scoreapi
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -174,3 +175,41 @@ func TestInfo_Codec(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestInfo_DecodeEvent(t *testing.T) {
+	info := NewInfo(testMethods)
+
+	from := []byte("\x01\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11")
+	to := []byte("\x00\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x11\x12")
+	ev, err := info.DecodeEvent(
+		[][]byte{[]byte("Transfer(Address,Address,int)"), from, to},
+		[][]byte{{0x12, 0x34}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "Transfer", ev.Name)
+	assert.Equal(t, "Transfer(Address,Address,int)", ev.Signature)
+	assert.Len(t, ev.Params, 3)
+
+	bs, err := json.Marshal(ev.Params)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name":"from","type":"Address","indexed":true,"value":"cx1111111111111111111111111111111111111111"},
+		{"name":"to","type":"Address","indexed":true,"value":"hx1111111111111111111111111111111111111112"},
+		{"name":"amount","type":"int","indexed":false,"value":"0x1234"}
+	]`, string(bs))
+
+	_, err = info.DecodeEvent(
+		[][]byte{[]byte("Transfer(Address,Address,int)"), from},
+		[][]byte{to, {0x12, 0x34}},
+	)
+	assert.Error(t, err)
+
+	_, err = info.DecodeEvent(
+		[][]byte{[]byte("TransferEx(Address,Address,int)"), from, to},
+		[][]byte{{0x12, 0x34}},
+	)
+	assert.Error(t, err)
+
+	_, err = info.DecodeEvent([][]byte{}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package scoreapi
+
+import (
+	"encoding/json"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+type apiField struct {
+	Name   string     `json:"name"`
+	Type   string     `json:"type"`
+	Fields []apiField `json:"fields,omitempty"`
+}
+
+type apiParam struct {
+	apiField
+	Indexed string          `json:"indexed,omitempty"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type apiOutput struct {
+	Type string `json:"type"`
+}
+
+type apiMethod struct {
+	Type     string      `json:"type"`
+	Name     string      `json:"name"`
+	Inputs   []apiParam  `json:"inputs"`
+	Outputs  []apiOutput `json:"outputs,omitempty"`
+	ReadOnly string      `json:"readonly,omitempty"`
+	Payable  string      `json:"payable,omitempty"`
+	Isolated string      `json:"isolated,omitempty"`
+}
+
+func dataTypeOf(s string) (DataType, error) {
+	dt := DataTypeOf(s)
+	if dt == Unknown {
+		return dt, errors.IllegalArgumentError.Errorf("UnknownType(type=%s)", s)
+	}
+	return dt, nil
+}
+
+func fieldsOf(fields []apiField) ([]Field, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	res := make([]Field, len(fields))
+	for i, f := range fields {
+		dt, err := dataTypeOf(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := fieldsOf(f.Fields)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = Field{Name: f.Name, Type: dt, Fields: sub}
+	}
+	return res, nil
+}
+
+// MethodsFromJSON parses methods in the format of icx_getScoreApi. All
+// functions in the format are external.
+func MethodsFromJSON(bs []byte) ([]*Method, error) {
+	var apis []apiMethod
+	if err := json.Unmarshal(bs, &apis); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidAPIJSON")
+	}
+	methods := make([]*Method, 0, len(apis))
+	for _, api := range apis {
+		m := &Method{Name: api.Name}
+		switch api.Type {
+		case "function":
+			m.Type = Function
+			m.Flags |= FlagExternal
+		case "fallback":
+			m.Type = Fallback
+		case "eventlog":
+			m.Type = Event
+		default:
+			return nil, errors.IllegalArgumentError.Errorf(
+				"UnknownMethodType(name=%s,type=%s)", api.Name, api.Type)
+		}
+		if api.ReadOnly == "0x1" {
+			m.Flags |= FlagReadOnly
+		}
+		if api.Payable == "0x1" {
+			m.Flags |= FlagPayable
+		}
+		if api.Isolated == "0x1" {
+			m.Flags |= FlagIsolated
+		}
+		for _, input := range api.Inputs {
+			dt, err := dataTypeOf(input.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "InvalidInput(method=%s)", api.Name)
+			}
+			fields, err := fieldsOf(input.Fields)
+			if err != nil {
+				return nil, errors.Wrapf(err, "InvalidInput(method=%s)", api.Name)
+			}
+			if m.Type == Event && input.Indexed == "0x1" {
+				m.Indexed++
+			} else if m.Type != Event && input.Default == nil {
+				m.Indexed++
+			}
+			m.Inputs = append(m.Inputs, Parameter{
+				Name:   input.Name,
+				Type:   dt,
+				Fields: fields,
+			})
+		}
+		for _, output := range api.Outputs {
+			dt, err := dataTypeOf(output.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "InvalidOutput(method=%s)", api.Name)
+			}
+			m.Outputs = append(m.Outputs, dt)
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
@@ -851,6 +851,59 @@ func (a *Method) CheckEventData(indexed [][]byte, data [][]byte) error {
 	return nil
 }
 
+// EventParam is a parameter of a decoded event log.
+type EventParam struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed"`
+	Value   interface{} `json:"value"`
+}
+
+// DecodedEvent is an event log decoded with the event definition.
+type DecodedEvent struct {
+	Name      string       `json:"name"`
+	Signature string       `json:"signature"`
+	Params    []EventParam `json:"params"`
+}
+
+// DecodeEvent decodes the event log with names and types of the inputs.
+func (a *Method) DecodeEvent(indexed [][]byte, data [][]byte) (*DecodedEvent, error) {
+	if !a.IsEvent() {
+		return nil, IllegalEventError.Errorf("NotEvent(name=%s)", a.Name)
+	}
+	if len(indexed)+len(data) != len(a.Inputs)+1 || len(indexed) != a.Indexed+1 {
+		return nil, IllegalEventError.Errorf(
+			"InvalidEventData(exp=%d/%d,given=%d/%d)",
+			a.Indexed, len(a.Inputs), len(indexed)-1, len(indexed)+len(data)-1)
+	}
+	params := make([]EventParam, len(a.Inputs))
+	for i, p := range a.Inputs {
+		var input []byte
+		isIndexed := i < len(indexed)-1
+		if isIndexed {
+			input = indexed[i+1]
+		} else {
+			input = data[i+1-len(indexed)]
+		}
+		value, err := p.Type.ConvertBytesToJSO(input)
+		if err != nil {
+			return nil, IllegalEventError.Wrapf(err,
+				"IllegalEvent(sig=%s,idx=%d)", a.Signature(), i)
+		}
+		params[i] = EventParam{
+			Name:    p.Name,
+			Type:    p.Type.String(),
+			Indexed: isIndexed,
+			Value:   value,
+		}
+	}
+	return &DecodedEvent{
+		Name:      a.Name,
+		Signature: a.Signature(),
+		Params:    params,
+	}, nil
+}
+
 type inputParameters interface {
 	Get(i int, n string) (json.RawMessage, bool)
 	Size() int
@@ -61,6 +61,8 @@ type AccountSnapshot interface {
 	AccountData
 	trie.Object
 	StorageChangedAfter(snapshot AccountSnapshot) bool
+	StorageHash() []byte
+	GetProofOfValue(k []byte) [][]byte
 	Contract() ContractSnapshot
 	ActiveContract() ContractSnapshot
 	NextContract() ContractSnapshot
@@ -466,6 +468,24 @@ func (s *accountSnapshotImpl) StorageChangedAfter(ass AccountSnapshot) bool {
 	return true
 }
 
+// StorageHash returns root hash of the storage. It returns nil if the
+// account has no storage.
+func (s *accountSnapshotImpl) StorageHash() []byte {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.(trie.Immutable).Hash()
+}
+
+// GetProofOfValue returns proof of the value in the storage. It returns nil
+// if there is no value for the key.
+func (s *accountSnapshotImpl) GetProofOfValue(k []byte) [][]byte {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.(trie.Immutable).GetProof(k)
+}
+
 func (s *accountSnapshotImpl) Contract() ContractSnapshot {
 	if s.curContract == nil {
 		return nil
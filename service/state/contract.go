@@ -43,6 +43,8 @@ const (
 	CTAppZip    = "application/zip"
 	CTAppJava   = "application/java"
 	CTAppSystem = "application/x.score.system"
+	CTAppGo     = "application/x.score.go"
+	CTAppWasm   = "application/wasm"
 )
 
 type ContractSnapshot interface {
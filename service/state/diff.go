@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package state
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
+)
+
+// AccountDiffHandler is called for each different account of two world
+// snapshots. key is the key of the account in the world state, and ass1 or
+// ass2 is nil if the account doesn't exist. op follows the convention of
+// ompt.DiffHandler.
+type AccountDiffHandler func(op int, key []byte, ass1, ass2 AccountSnapshot) error
+
+// StorageDiffHandler is called for each different storage value of two
+// accounts. v1 or v2 is nil if the value doesn't exist.
+type StorageDiffHandler func(op int, key, v1, v2 []byte) error
+
+// AccountKeyOf returns the key of the account in the world state.
+func AccountKeyOf(id []byte) []byte {
+	return addressIDToKey(id)
+}
+
+// DiffAccounts calls handler for each different account of two world
+// snapshots in the order of account keys. Sub-tries with the same hash are
+// skipped, so the cost is proportional to the changes.
+func DiffAccounts(wss1, wss2 WorldSnapshot, handler AccountDiffHandler) error {
+	s1, ok1 := wss1.(*worldSnapshotImpl)
+	s2, ok2 := wss2.(*worldSnapshotImpl)
+	if !ok1 || !ok2 {
+		return errors.UnsupportedError.Errorf(
+			"UnsupportedWorldSnapshot(wss1=%T,wss2=%T)", wss1, wss2)
+	}
+	return trie_manager.DiffImmutableForObject(s1.accounts, s2.accounts,
+		func(op int, key []byte, o1, o2 trie.Object) error {
+			ass1, _ := o1.(AccountSnapshot)
+			ass2, _ := o2.(AccountSnapshot)
+			return handler(op, key, ass1, ass2)
+		})
+}
+
+func storeOf(ass AccountSnapshot) trie.Immutable {
+	if s, ok := ass.(*accountSnapshotImpl); ok && s != nil {
+		return s.Store()
+	}
+	return nil
+}
+
+// DiffStorage calls handler for each different storage value of two
+// account snapshots. nil is regarded as an account without storage.
+func DiffStorage(ass1, ass2 AccountSnapshot, handler StorageDiffHandler) error {
+	return trie_manager.DiffImmutable(storeOf(ass1), storeOf(ass2), handler)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+)
+
+func TestDiffAccounts(t *testing.T) {
+	database := db.NewMapDB()
+	ws := NewWorldState(database, nil, nil, nil, nil)
+
+	id1, id2, id3 := []byte("account1"), []byte("account2"), []byte("account3")
+	ws.GetAccountState(id1).SetBalance(big.NewInt(100))
+	as2 := ws.GetAccountState(id2)
+	as2.SetBalance(big.NewInt(200))
+	_, err := as2.SetValue([]byte("key1"), []byte("value1"))
+	assert.NoError(t, err)
+	_, err = as2.SetValue([]byte("key2"), []byte("value2"))
+	assert.NoError(t, err)
+	wss1 := ws.GetSnapshot()
+	assert.NoError(t, wss1.Flush())
+
+	ws.GetAccountState(id1).SetBalance(big.NewInt(50))
+	as2 = ws.GetAccountState(id2)
+	_, err = as2.SetValue([]byte("key1"), []byte("changed"))
+	assert.NoError(t, err)
+	_, err = as2.DeleteValue([]byte("key2"))
+	assert.NoError(t, err)
+	ws.GetAccountState(id3).SetBalance(big.NewInt(300))
+	wss2 := ws.GetSnapshot()
+	assert.NoError(t, wss2.Flush())
+
+	type storageResult struct {
+		op     int
+		key    string
+		v1, v2 string
+	}
+	accounts := make(map[string]int)
+	var storage []storageResult
+	err = DiffAccounts(wss1, wss2, func(op int, key []byte, ass1, ass2 AccountSnapshot) error {
+		accounts[string(key)] = op
+		return DiffStorage(ass1, ass2, func(op int, key, v1, v2 []byte) error {
+			storage = append(storage, storageResult{op, string(key), string(v1), string(v2)})
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		string(AccountKeyOf(id1)): 0,
+		string(AccountKeyOf(id2)): 0,
+		string(AccountKeyOf(id3)): 1,
+	}, accounts)
+	assert.Len(t, storage, 2)
+	for _, s := range storage {
+		switch s.key {
+		case "key1":
+			assert.Equal(t, storageResult{0, "key1", "value1", "changed"}, s)
+		default:
+			assert.Equal(t, -1, s.op)
+			assert.Equal(t, "value2", s.v1)
+		}
+	}
+
+	err = DiffAccounts(wss2, wss2, func(op int, key []byte, ass1, ass2 AccountSnapshot) error {
+		t.Errorf("unexpected difference key=%x", key)
+		return nil
+	})
+	assert.NoError(t, err)
+}
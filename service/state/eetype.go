@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
 )
 
 type EEType string
@@ -15,6 +16,8 @@ const (
 	PythonEE EEType = "python"
 	JavaEE   EEType = "java"
 	SystemEE EEType = "system"
+	GoEE     EEType = "go"
+	WasmEE   EEType = "wasm"
 )
 
 const (
@@ -26,11 +29,15 @@ var (
 		PythonEE: "on_install",
 		JavaEE:   "<init>",
 		SystemEE: "<Install>",
+		GoEE:     "<init>",
+		WasmEE:   "on_install",
 	}
 	updateMethods = map[EEType]string{
 		PythonEE: "on_update",
 		JavaEE:   "<init>",
 		SystemEE: "<Update>",
+		GoEE:     "<init>",
+		WasmEE:   "on_update",
 	}
 	allowUpdateFromTo = map[EEType]map[EEType]bool{
 		PythonEE: {
@@ -40,10 +47,22 @@ var (
 		JavaEE: {
 			JavaEE: true,
 		},
+		GoEE: {
+			GoEE: true,
+		},
+		WasmEE: {
+			WasmEE: true,
+		},
 	}
 	needAudit = map[EEType]bool{
 		PythonEE: true,
 	}
+	// revisionFor has revisions enabling optional types. Nodes may not have
+	// engines for them.
+	revisionFor = map[EEType]module.Revision{
+		GoEE:   module.GoExecutionEngine,
+		WasmEE: module.WasmExecutionEngine,
+	}
 )
 
 func (e EEType) InstallMethod() (string, bool) {
@@ -98,6 +117,21 @@ func (e EEType) NeedAudit() bool {
 	}
 }
 
+// IsOptional returns whether the type is optional. Nodes may not have the
+// engine for the type.
+func (e EEType) IsOptional() bool {
+	_, ok := revisionFor[e]
+	return ok
+}
+
+// IsEnabledBy returns whether the type is enabled by the revision.
+func (e EEType) IsEnabledBy(rev module.Revision) bool {
+	if flag, ok := revisionFor[e]; ok {
+		return rev.Has(flag)
+	}
+	return true
+}
+
 func EETypeFromContentType(ct string) (EEType, bool) {
 	switch ct {
 	case CTAppZip:
@@ -106,6 +140,10 @@ func EETypeFromContentType(ct string) (EEType, bool) {
 		return JavaEE, true
 	case CTAppSystem:
 		return SystemEE, true
+	case CTAppGo:
+		return GoEE, true
+	case CTAppWasm:
+		return WasmEE, true
 	default:
 		return NullEE, false
 	}
@@ -121,7 +159,7 @@ func MustEETypeFromContentType(ct string) EEType {
 
 func ValidateEEType(et EEType) bool {
 	switch et {
-	case PythonEE, JavaEE, SystemEE:
+	case PythonEE, JavaEE, SystemEE, GoEE, WasmEE:
 		return true
 	default:
 		return false
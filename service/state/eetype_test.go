@@ -0,0 +1,40 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+)
+
+func TestEEType_IsEnabledBy(t *testing.T) {
+	for _, et := range []EEType{PythonEE, JavaEE, SystemEE} {
+		assert.False(t, et.IsOptional())
+		assert.True(t, et.IsEnabledBy(0))
+	}
+
+	assert.True(t, GoEE.IsOptional())
+	assert.False(t, GoEE.IsEnabledBy(module.LatestRevision&^module.GoExecutionEngine))
+	assert.True(t, GoEE.IsEnabledBy(module.GoExecutionEngine))
+
+	assert.True(t, WasmEE.IsOptional())
+	assert.False(t, WasmEE.IsEnabledBy(module.GoExecutionEngine))
+	assert.True(t, WasmEE.IsEnabledBy(module.WasmExecutionEngine))
+}
@@ -17,6 +17,7 @@ import (
 // It can be use to WorldState recover state of WorldState to at some point.
 type WorldSnapshot interface {
 	GetAccountSnapshot(id []byte) AccountSnapshot
+	GetProofOfAccount(id []byte) [][]byte
 	GetValidatorSnapshot() ValidatorSnapshot
 	GetExtensionSnapshot() ExtensionSnapshot
 	GetBTPSnapshot() BTPSnapshot
@@ -123,6 +124,12 @@ func (ws *worldSnapshotImpl) GetAccountSnapshot(id []byte) AccountSnapshot {
 	}
 }
 
+// GetProofOfAccount returns proof of the account in the world state.
+// It returns nil if there is no account.
+func (ws *worldSnapshotImpl) GetProofOfAccount(id []byte) [][]byte {
+	return ws.accounts.GetProof(addressIDToKey(id))
+}
+
 type worldStateImpl struct {
 	mutex sync.Mutex
 
@@ -569,6 +569,13 @@ func (wvss *worldVirtualSnapshot) StateHash() []byte {
 	return wvss.base.StateHash()
 }
 
+func (wvss *worldVirtualSnapshot) GetProofOfAccount(id []byte) [][]byte {
+	if err := wvss.realize(); err != nil {
+		return nil
+	}
+	return wvss.base.GetProofOfAccount(id)
+}
+
 func (wvss *worldVirtualSnapshot) Database() db.Database {
 	return wvss.base.Database()
 }
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	// maxStateDiffEntries is the maximum number of accounts and storage
+	// values in a state diff. Remaining differences are truncated.
+	maxStateDiffEntries = 10000
+)
+
+var errStateDiffTruncated = errors.New("StateDiffTruncated")
+
+type storageDiff struct {
+	op     int
+	key    []byte
+	v1, v2 []byte
+}
+
+type accountDiff struct {
+	op       int
+	key      []byte
+	addr     module.Address
+	balance1 *big.Int
+	balance2 *big.Int
+	storage  []storageDiff
+}
+
+type stateDiff struct {
+	stateHash1 []byte
+	stateHash2 []byte
+	accounts   []*accountDiff
+	entries    int
+	truncated  bool
+}
+
+func diffStatusOf(op int) string {
+	switch op {
+	case -1:
+		return "removed"
+	case 1:
+		return "added"
+	default:
+		return "changed"
+	}
+}
+
+func (d *stateDiff) ToJSON(version module.JSONVersion) (interface{}, error) {
+	accounts := make([]interface{}, len(d.accounts))
+	for i, ad := range d.accounts {
+		jso := make(map[string]interface{})
+		jso["key"] = common.HexBytes(ad.key)
+		if ad.addr != nil {
+			jso["address"] = ad.addr
+		}
+		jso["status"] = diffStatusOf(ad.op)
+		if ad.balance1 != nil || ad.balance2 != nil {
+			balance := make(map[string]interface{})
+			if ad.balance1 != nil {
+				balance["from"] = intconv.FormatBigInt(ad.balance1)
+			}
+			if ad.balance2 != nil {
+				balance["to"] = intconv.FormatBigInt(ad.balance2)
+			}
+			jso["balance"] = balance
+		}
+		if len(ad.storage) > 0 {
+			storage := make([]interface{}, len(ad.storage))
+			for j, sd := range ad.storage {
+				sjso := map[string]interface{}{
+					"key":    common.HexBytes(sd.key),
+					"status": diffStatusOf(sd.op),
+				}
+				if sd.v1 != nil {
+					sjso["from"] = common.HexBytes(sd.v1)
+				}
+				if sd.v2 != nil {
+					sjso["to"] = common.HexBytes(sd.v2)
+				}
+				storage[j] = sjso
+			}
+			jso["storage"] = storage
+		}
+		accounts[i] = jso
+	}
+	res := map[string]interface{}{
+		"stateHash1": common.HexBytes(d.stateHash1),
+		"stateHash2": common.HexBytes(d.stateHash2),
+		"accounts":   accounts,
+	}
+	if d.truncated {
+		res["truncated"] = true
+	}
+	return res, nil
+}
+
+func (d *stateDiff) addEntry() error {
+	if d.entries >= maxStateDiffEntries {
+		d.truncated = true
+		return errStateDiffTruncated
+	}
+	d.entries += 1
+	return nil
+}
+
+func balanceOf(ass state.AccountSnapshot) *big.Int {
+	if ass == nil {
+		return nil
+	}
+	return ass.GetBalance()
+}
+
+func (d *stateDiff) addAccount(op int, key []byte, addr module.Address, ass1, ass2 state.AccountSnapshot) error {
+	if err := d.addEntry(); err != nil {
+		return err
+	}
+	ad := &accountDiff{
+		op:   op,
+		key:  key,
+		addr: addr,
+	}
+	d.accounts = append(d.accounts, ad)
+	b1, b2 := balanceOf(ass1), balanceOf(ass2)
+	if b1 == nil || b2 == nil || b1.Cmp(b2) != 0 {
+		ad.balance1, ad.balance2 = b1, b2
+	}
+	return state.DiffStorage(ass1, ass2, func(op int, key, v1, v2 []byte) error {
+		if err := d.addEntry(); err != nil {
+			return err
+		}
+		ad.storage = append(ad.storage, storageDiff{op, key, v1, v2})
+		return nil
+	})
+}
+
+func (d *stateDiff) diffAddresses(wss1, wss2 state.WorldSnapshot, addrs []module.Address) error {
+	for _, addr := range addrs {
+		ass1 := wss1.GetAccountSnapshot(addr.ID())
+		ass2 := wss2.GetAccountSnapshot(addr.ID())
+		var op int
+		switch {
+		case ass1 == nil && ass2 == nil:
+			continue
+		case ass1 == nil:
+			op = 1
+		case ass2 == nil:
+			op = -1
+		default:
+			if bytes.Equal(ass1.Bytes(), ass2.Bytes()) {
+				continue
+			}
+		}
+		key := state.AccountKeyOf(addr.ID())
+		if err := d.addAccount(op, key, addr, ass1, ass2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newStateDiff(wss1, wss2 state.WorldSnapshot, addrs []module.Address) (*stateDiff, error) {
+	d := &stateDiff{
+		stateHash1: wss1.StateHash(),
+		stateHash2: wss2.StateHash(),
+	}
+	var err error
+	if len(addrs) > 0 {
+		err = d.diffAddresses(wss1, wss2, addrs)
+	} else {
+		err = state.DiffAccounts(wss1, wss2,
+			func(op int, key []byte, ass1, ass2 state.AccountSnapshot) error {
+				return d.addAccount(op, key, nil, ass1, ass2)
+			})
+	}
+	if err != nil && err != errStateDiffTruncated {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (m *manager) GetStateDiff(result1, result2 []byte, addrs []module.Address) (module.StateDiff, error) {
+	wss1, err := m.trc.GetWorldSnapshot(result1, nil)
+	if err != nil {
+		return nil, err
+	}
+	wss2, err := m.trc.GetWorldSnapshot(result2, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newStateDiff(wss1, wss2, addrs)
+}
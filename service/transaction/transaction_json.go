@@ -38,8 +38,9 @@ var (
 		},
 		Version3: {
 			exclusion: map[string]bool{
-				"signature": true,
-				"txHash":    true,
+				"signature":         true,
+				"feePayerSignature": true,
+				"txHash":            true,
 			},
 		},
 	}
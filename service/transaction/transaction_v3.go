@@ -3,6 +3,7 @@ package transaction
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"math/big"
 
 	"github.com/icon-project/goloop/common"
@@ -32,6 +33,56 @@ type transactionV3Data struct {
 	Signature common.Signature `json:"signature"`
 	DataType  *string          `json:"dataType,omitempty"`
 	Data      json.RawMessage  `json:"data,omitempty"`
+
+	FeePayer          *common.Address   `json:"feePayer,omitempty"`
+	FeePayerSignature *common.Signature `json:"feePayerSignature,omitempty"`
+}
+
+func (tx *transactionV3Data) RLPEncodeSelf(e codec.Encoder) error {
+	fields := []interface{}{
+		&tx.Version,
+		&tx.From,
+		&tx.To,
+		tx.Value,
+		&tx.StepLimit,
+		&tx.TimeStamp,
+		tx.NID,
+		tx.Nonce,
+		&tx.Signature,
+		tx.DataType,
+		tx.Data,
+	}
+	// fields for sponsored transaction are appended only if they are used
+	// for keeping the bytes of other transactions.
+	if tx.FeePayer != nil || tx.FeePayerSignature != nil {
+		fields = append(fields, tx.FeePayer, tx.FeePayerSignature)
+	}
+	return e.EncodeListOf(fields...)
+}
+
+func (tx *transactionV3Data) RLPDecodeSelf(d codec.Decoder) error {
+	d2, err := d.DecodeList()
+	if err != nil {
+		return err
+	}
+	if _, err := d2.DecodeMulti(
+		&tx.Version,
+		&tx.From,
+		&tx.To,
+		&tx.Value,
+		&tx.StepLimit,
+		&tx.TimeStamp,
+		&tx.NID,
+		&tx.Nonce,
+		&tx.Signature,
+		&tx.DataType,
+		&tx.Data,
+		&tx.FeePayer,
+		&tx.FeePayerSignature,
+	); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
 }
 
 func (tx *transactionV3Data) calcHash() ([]byte, error) {
@@ -61,6 +112,12 @@ func (tx *transactionV3Data) calcHash() ([]byte, error) {
 		sha.Write([]byte(*tx.DataType))
 	}
 
+	// feePayer
+	if tx.FeePayer != nil {
+		sha.Write([]byte(".feePayer."))
+		sha.Write([]byte(tx.FeePayer.String()))
+	}
+
 	// from
 	sha.Write([]byte(".from."))
 	sha.Write([]byte(tx.From.String()))
@@ -125,6 +182,30 @@ func (tx *transactionV3) verifySignature() error {
 	return InvalidSignatureError.New("fail to verify signature")
 }
 
+func (tx *transactionV3) verifyFeePayer() error {
+	if tx.transactionV3Data.FeePayer == nil && tx.FeePayerSignature == nil {
+		return nil
+	}
+	if tx.transactionV3Data.FeePayer == nil || tx.FeePayerSignature == nil {
+		return InvalidTxValue.New("FeePayerWithoutSignature")
+	}
+	payer := tx.FeePayer()
+	if payer.IsContract() || payer.Equal(tx.From()) {
+		return InvalidTxValue.Errorf("InvalidFeePayer(%s)", payer)
+	}
+	if tx.Group() != module.TransactionGroupNormal {
+		return InvalidTxValue.New("FeePayerForPatch")
+	}
+	pk, err := tx.FeePayerSignature.RecoverPublicKey(tx.TxHash())
+	if err != nil {
+		return InvalidSignatureError.Wrap(err, "fail to recover public key of fee payer")
+	}
+	if !common.NewAccountAddressFromPublicKey(pk).Equal(payer) {
+		return InvalidSignatureError.New("fail to verify signature of fee payer")
+	}
+	return nil
+}
+
 func (tx *transactionV3) calcHash() ([]byte, error) {
 	if tx.raw {
 		return calcHashOfTransactionJSON(tx.bytes, Version3)
@@ -148,6 +229,15 @@ func (tx *transactionV3) From() module.Address {
 	return &tx.transactionV3Data.From
 }
 
+// FeePayer returns the address of the account paying the fee of the
+// sponsored transaction. It returns nil if it's not sponsored.
+func (tx *transactionV3) FeePayer() module.Address {
+	if tx.transactionV3Data.FeePayer == nil {
+		return nil
+	}
+	return tx.transactionV3Data.FeePayer
+}
+
 func (tx *transactionV3) ID() []byte {
 	return tx.TxHash()
 }
@@ -210,6 +300,19 @@ func (tx *transactionV3) Verify() error {
 			// if _, err := contract.ParseDepositData(tx.Data); err != nil {
 			// 	return InvalidTxValue.Wrap(err, "TxData is invalid")
 			// }
+		case contract.DataTypeMultiCall:
+			if tx.Data == nil {
+				return InvalidTxValue.New("TxData for multicall is NIL")
+			}
+			if _, err := contract.ParseMultiCallData(tx.Data); err != nil {
+				return InvalidTxValue.Wrap(err, "TxData is invalid")
+			}
+			if tx.Value != nil && tx.Value.Sign() != 0 {
+				return InvalidTxValue.Errorf("InvalidTxValue(%s)", tx.Value.String())
+			}
+			if !tx.To().Equal(tx.From()) {
+				return InvalidTxValue.Errorf("InvalidTxTo(%s)", tx.To())
+			}
 		}
 	}
 
@@ -217,6 +320,9 @@ func (tx *transactionV3) Verify() error {
 	if err := tx.verifySignature(); err != nil {
 		return err
 	}
+	if err := tx.verifyFeePayer(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -229,6 +335,14 @@ func (tx *transactionV3) ValidateNetwork(nid int) bool {
 }
 
 func (tx *transactionV3) PreValidate(wc state.WorldContext, update bool) error {
+	if tx.DataType != nil && *tx.DataType == contract.DataTypeMultiCall &&
+		!wc.Revision().Has(module.MultiCallTransaction) {
+		return InvalidTxValue.New("MultiCallNotEnabled")
+	}
+	payer := tx.FeePayer()
+	if payer != nil && !wc.Revision().Has(module.SponsoredTransaction) {
+		return InvalidTxValue.New("SponsoredTxNotEnabled")
+	}
 	if tx.DataType == nil || *tx.DataType != contract.DataTypePatch {
 		// stepLimit >= default step + input steps
 		cnt, err := MeasureBytesOfData(wc.Revision(), tx.Data)
@@ -242,9 +356,14 @@ func (tx *transactionV3) PreValidate(wc state.WorldContext, update bool) error {
 	}
 
 	// balance >= (fee + value)
+	// For sponsored transaction, fee payer pays the fee.
 	stepPrice := wc.StepPrice()
 
-	trans := new(big.Int).Mul(&tx.StepLimit.Int, stepPrice)
+	fee := new(big.Int).Mul(&tx.StepLimit.Int, stepPrice)
+	trans := new(big.Int)
+	if payer == nil {
+		trans.Set(fee)
+	}
 	if tx.Value != nil {
 		trans.Add(trans, &tx.Value.Int)
 	}
@@ -259,6 +378,19 @@ func (tx *transactionV3) PreValidate(wc state.WorldContext, update bool) error {
 		return AccessDeniedError.New("BlockedAccount")
 	}
 
+	var as3 state.AccountState
+	var balance3 *big.Int
+	if payer != nil {
+		as3 = wc.GetAccountState(payer.ID())
+		balance3 = as3.GetBalance()
+		if balance3.Cmp(fee) < 0 {
+			return NotEnoughBalanceError.Errorf("FeePayerOutOfBalance(balance:%s, fee:%s)", balance3, fee)
+		}
+		if as3.IsBlocked() {
+			return AccessDeniedError.New("BlockedFeePayer")
+		}
+	}
+
 	as2 := wc.GetAccountState(tx.To().ID())
 	if contract.IsCallableDataType(tx.DataType) {
 		if !as2.CanAcceptTx(wc) {
@@ -269,6 +401,9 @@ func (tx *transactionV3) PreValidate(wc state.WorldContext, update bool) error {
 	// for cumulative balance check
 	if update {
 		as1.SetBalance(new(big.Int).Sub(balance1, trans))
+		if as3 != nil {
+			as3.SetBalance(new(big.Int).Sub(balance3, fee))
+		}
 		if tx.Value != nil {
 			balance2 := as2.GetBalance()
 			as2.SetBalance(new(big.Int).Add(balance2, &tx.Value.Int))
@@ -284,10 +419,11 @@ func (tx *transactionV3) GetHandler(cm contract.ContractManager) (Handler, error
 	} else {
 		value = big.NewInt(0)
 	}
-	return NewHandler(cm,
+	return NewSponsoredHandler(cm,
 		tx.Group(),
 		tx.From(),
 		tx.To(),
+		tx.FeePayer(),
 		value,
 		&tx.StepLimit.Int,
 		tx.DataType,
@@ -374,6 +510,12 @@ func (tx *transactionV3) ToJSON(version module.JSONVersion) (interface{}, error)
 	if tx.transactionV3Data.Data != nil {
 		jso["data"] = json.RawMessage(tx.transactionV3Data.Data)
 	}
+	if tx.transactionV3Data.FeePayer != nil {
+		jso["feePayer"] = tx.transactionV3Data.FeePayer
+	}
+	if tx.transactionV3Data.FeePayerSignature != nil {
+		jso["feePayerSignature"] = tx.transactionV3Data.FeePayerSignature
+	}
 	jso["txHash"] = common.HexBytes(tx.ID())
 
 	return jso, nil
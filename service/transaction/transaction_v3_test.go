@@ -0,0 +1,138 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+func signTxMap(t *testing.T, w module.Wallet, tx map[string]interface{}) string {
+	bs, err := SerializeMap(tx, nil, transactionFields[Version3].exclusion)
+	assert.NoError(t, err)
+	sig, err := w.Sign(crypto.SHA3Sum256(append(transactionSaltBytes, bs...)))
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func newTxMap(from, to module.Address) map[string]interface{} {
+	return map[string]interface{}{
+		"version":   "0x3",
+		"from":      from.String(),
+		"to":        to.String(),
+		"value":     "0x10",
+		"stepLimit": "0x186a0",
+		"timestamp": "0x5c9a4ef04c3b8",
+		"nid":       "0x1",
+	}
+}
+
+func parseTxMap(t *testing.T, tx map[string]interface{}) Transaction {
+	js, err := json.Marshal(tx)
+	assert.NoError(t, err)
+	ntx, err := newTransactionFromJSON(js, false)
+	assert.NoError(t, err)
+	return ntx
+}
+
+func TestTransactionV3_FeePayer(t *testing.T) {
+	sender := wallet.New()
+	payer := wallet.New()
+	to := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+
+	txm := newTxMap(sender.Address(), to)
+	txm["feePayer"] = payer.Address().String()
+	txm["feePayerSignature"] = signTxMap(t, payer, txm)
+	txm["signature"] = signTxMap(t, sender, txm)
+
+	tx := parseTxMap(t, txm)
+	assert.NoError(t, tx.Verify())
+	tx3 := tx.(*transactionV3)
+	assert.True(t, payer.Address().Equal(tx3.FeePayer()))
+	assert.False(t, tx3.raw)
+
+	// binary form keeps the fee payer and its signature
+	tx2, err := newTransaction(tx.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID(), tx2.ID())
+	assert.NoError(t, tx2.Verify())
+	assert.True(t, payer.Address().Equal(tx2.(*transactionV3).FeePayer()))
+
+	// signature of other account
+	txm["feePayerSignature"] = signTxMap(t, wallet.New(), txm)
+	tx = parseTxMap(t, txm)
+	assert.True(t, InvalidSignatureError.Equals(tx.Verify()))
+
+	// fee payer without signature
+	delete(txm, "feePayerSignature")
+	tx = parseTxMap(t, txm)
+	assert.True(t, InvalidTxValue.Equals(tx.Verify()))
+
+	// sender can't be the fee payer
+	txm["feePayer"] = sender.Address().String()
+	txm["feePayerSignature"] = signTxMap(t, sender, txm)
+	txm["signature"] = signTxMap(t, sender, txm)
+	tx = parseTxMap(t, txm)
+	assert.True(t, InvalidTxValue.Equals(tx.Verify()))
+}
+
+func TestTransactionV3_BytesWithoutFeePayer(t *testing.T) {
+	sender := wallet.New()
+	to := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+
+	txm := newTxMap(sender.Address(), to)
+	txm["dataType"] = "call"
+	txm["data"] = map[string]interface{}{"method": "transfer"}
+	txm["signature"] = signTxMap(t, sender, txm)
+	tx := parseTxMap(t, txm)
+	assert.NoError(t, tx.Verify())
+
+	// it should be same as the encoding of the fields before sponsored
+	// transaction is introduced.
+	d := &tx.(*transactionV3).transactionV3Data
+	bs := codec.MustMarshalToBytes(&struct {
+		Version   common.HexUint16
+		From      common.Address
+		To        common.Address
+		Value     *common.HexInt
+		StepLimit common.HexInt
+		TimeStamp common.HexInt64
+		NID       *common.HexInt64
+		Nonce     *common.HexInt
+		Signature common.Signature
+		DataType  *string
+		Data      json.RawMessage
+	}{
+		d.Version, d.From, d.To, d.Value, d.StepLimit, d.TimeStamp,
+		d.NID, d.Nonce, d.Signature, d.DataType, d.Data,
+	})
+	assert.Equal(t, bs, tx.Bytes())
+
+	tx2, err := newTransaction(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID(), tx2.ID())
+	assert.Nil(t, tx2.(*transactionV3).FeePayer())
+}
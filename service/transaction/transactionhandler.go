@@ -27,6 +27,7 @@ type transactionHandler struct {
 	group     module.TransactionGroup
 	from      module.Address
 	to        module.Address
+	feePayer  module.Address
 	value     *big.Int
 	stepLimit *big.Int
 	dataType  *string
@@ -39,10 +40,17 @@ type transactionHandler struct {
 }
 
 func NewHandler(cm contract.ContractManager, group module.TransactionGroup, from, to module.Address, value, stepLimit *big.Int, dataType *string, data []byte) (Handler, error) {
+	return NewSponsoredHandler(cm, group, from, to, nil, value, stepLimit, dataType, data)
+}
+
+// NewSponsoredHandler returns a handler for the transaction whose fee is
+// paid by feePayer. If feePayer is nil, then the sender pays the fee.
+func NewSponsoredHandler(cm contract.ContractManager, group module.TransactionGroup, from, to, feePayer module.Address, value, stepLimit *big.Int, dataType *string, data []byte) (Handler, error) {
 	th := &transactionHandler{
 		group:     group,
 		from:      from,
 		to:        to,
+		feePayer:  feePayer,
 		value:     value,
 		stepLimit: stepLimit,
 		dataType:  dataType,
@@ -63,6 +71,8 @@ func NewHandler(cm contract.ContractManager, group module.TransactionGroup, from
 			ctype = contract.CTypePatch
 		case contract.DataTypeDeposit:
 			ctype = contract.CTypeDeposit
+		case contract.DataTypeMultiCall:
+			ctype = contract.CTypeMultiCall
 		default:
 			return nil, InvalidFormat.Errorf("IllegalDataType(type=%s)", *dataType)
 		}
@@ -80,24 +90,34 @@ func (th *transactionHandler) Prepare(ctx contract.Context) (state.WorldContext,
 	return th.chandler.Prepare(ctx)
 }
 
-func (th *transactionHandler) checkBalance(cc contract.CallContext) error {
-	value := new(big.Int).Mul(cc.StepPrice(), th.stepLimit)
-	if th.value != nil {
-		value.Add(value, th.value)
-	}
-	var bal *big.Int
+func (th *transactionHandler) balanceOf(cc contract.CallContext, addr module.Address) *big.Int {
 	if cc.Revision().LegacyBalanceCheck() {
 		wcs := cc.GetProperty(contract.PropInitialSnapshot).(state.WorldSnapshot)
-		if as := wcs.GetAccountSnapshot(th.from.ID()); as != nil {
-			bal = as.GetBalance()
+		if as := wcs.GetAccountSnapshot(addr.ID()); as != nil {
+			return as.GetBalance()
 		} else {
-			bal = new(big.Int)
+			return new(big.Int)
+		}
+	} else {
+		as := cc.GetAccountState(addr.ID())
+		return as.GetBalance()
+	}
+}
+
+func (th *transactionHandler) checkBalance(cc contract.CallContext) error {
+	fee := new(big.Int).Mul(cc.StepPrice(), th.stepLimit)
+	value := new(big.Int)
+	if th.value != nil {
+		value.Set(th.value)
+	}
+	if th.feePayer != nil {
+		if th.balanceOf(cc, th.feePayer).Cmp(fee) < 0 {
+			return scoreresult.ErrOutOfBalance
 		}
 	} else {
-		as := cc.GetAccountState(th.from.ID())
-		bal = as.GetBalance()
+		value.Add(value, fee)
 	}
-	if bal.Cmp(value) < 0 {
+	if th.balanceOf(cc, th.from).Cmp(value) < 0 {
 		return scoreresult.ErrOutOfBalance
 	}
 	if th.to.IsContract() && contract.IsCallableDataType(th.dataType) {
@@ -213,6 +233,21 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 				stepToPay, redeemed, old)
 		}
 	}
+	var sponsor *contract.FeePayer
+	if th.feePayer != nil && stepPrice.Sign() > 0 && stepToPay.Sign() > 0 {
+		sponsor = contract.NewSponsor(th.feePayer)
+		paid, err := sponsor.PaySteps(cc, stepToPay)
+		if err != nil {
+			logger.TSystem("TRANSACTION failed on PaySteps of fee payer")
+			return nil, err
+		} else if paid.Sign() > 0 {
+			old := stepToPay
+			stepToPay = new(big.Int).Sub(stepToPay, paid)
+			redeemed = addBigInt(redeemed, paid)
+			logger.TSystemf("STEP sponsored value=%d payer=%s paid=%d old=%d",
+				stepToPay, th.feePayer, paid, old)
+		}
+	}
 	if stepPrice == nil {
 		logger.Debugf("MKSONG StepPrice is NIL")
 	}
@@ -227,7 +262,7 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 		if cc.Revision().LegacyFeeCharge() {
 			logger.TSystemf("STEP reset value=0 reason=OutOfBalance balance=%d fee=%d", bal, fee)
 			if redeemed != nil {
-				cc.ClearRedeemLogs()
+				th.clearRedeemLogs(cc, sponsor)
 			}
 			stepToPay = new(big.Int)
 			stepUsed = new(big.Int)
@@ -239,19 +274,21 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 			logger.TSystemf("TRANSACTION rollback reason=OutOfBalance balance=%d fee=%d",
 				bal, fee)
 			status = scoreresult.ErrOutOfBalance
+			if redeemed != nil {
+				th.clearRedeemLogs(cc, sponsor)
+			}
 			ctx.Reset(wcs)
 			bal = as.GetBalance()
 			if redeemed != nil {
-				cc.ClearRedeemLogs()
 				logger.TSystemf("STEP rollback value=%d", stepUsed)
 				stepToPay = stepUsed
 			}
 			fee.Mul(stepToPay, stepPrice)
 		} else {
 			if redeemed != nil {
+				th.clearRedeemLogs(cc, sponsor)
 				ctx.Reset(wcs)
 				bal = as.GetBalance()
-				cc.ClearRedeemLogs()
 				logger.TSystemf("STEP rollback value=%d", stepUsed)
 				stepToPay = stepUsed
 			}
@@ -271,9 +308,19 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 		cc.GetEventLogs(receipt)
 		cc.GetBTPMessages(receipt)
 	}
-	if redeemed := cc.GetRedeemLogs(receipt); redeemed && stepToPay.Sign() != 0 {
+	redeemedLogs := cc.GetRedeemLogs(receipt)
+	if sponsor != nil {
+		redeemedLogs = sponsor.GetLogs(receipt) || redeemedLogs
+	}
+	if redeemedLogs && stepToPay.Sign() != 0 {
 		receipt.AddPayment(th.from, stepToPay, stepToPay)
 	}
+	if mh, ok := th.chandler.(*contract.MultiCallHandler); ok {
+		for _, r := range mh.Results() {
+			cs, _ := scoreresult.StatusOf(r.Status)
+			receipt.AddCallResult(cs, r.StepUsed)
+		}
+	}
 	receipt.SetResult(s, stepUsed, stepPrice, addr)
 	receipt.SetReason(status)
 
@@ -281,6 +328,26 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 	return receipt, nil
 }
 
+// clearRedeemLogs clears logs of redeemed steps. The fee paid by the sponsor
+// is refunded here, so it's rolled back even if the state is not reset as
+// with LegacyFeeCharge.
+func (th *transactionHandler) clearRedeemLogs(cc contract.CallContext, sponsor *contract.FeePayer) {
+	cc.ClearRedeemLogs()
+	if sponsor != nil {
+		sponsor.Refund(cc)
+	}
+}
+
+func addBigInt(v1, v2 *big.Int) *big.Int {
+	if v1 == nil {
+		return v2
+	} else if v2 == nil {
+		return v1
+	} else {
+		return new(big.Int).Add(v1, v2)
+	}
+}
+
 func (th *transactionHandler) Dispose() {
 	// Actually it is called after calling Execute(), so cc can't be nil.
 	if th.cc != nil {
@@ -33,6 +33,7 @@ type eventLogJSON struct {
 	Addr    common.Address `json:"scoreAddress"`
 	Indexed []interface{}  `json:"indexed"`
 	Data    []interface{}  `json:"data"`
+	Decoded interface{}    `json:"decoded,omitempty"`
 }
 
 type eventLogData struct {
@@ -172,8 +173,46 @@ const (
 const (
 	ExtensionFeeDetail = 1 << iota
 	ExtensionDisableLogsBloom
+	ExtensionCallResults
 )
 
+// callResult is the result of a call in multicall transaction.
+type callResult struct {
+	Status   module.Status
+	StepUsed common.HexInt
+}
+
+type callResultJSON struct {
+	Status   common.HexUint16 `json:"status"`
+	StepUsed common.HexInt    `json:"stepUsed"`
+	Failure  *failureReason   `json:"failure,omitempty"`
+}
+
+func (r *callResult) ToJSON() *callResultJSON {
+	jso := &callResultJSON{
+		StepUsed: r.StepUsed,
+	}
+	if r.Status == module.StatusSuccess {
+		jso.Status.Value = 1
+	} else {
+		jso.Failure = failureReasonByCode(r.Status)
+	}
+	return jso
+}
+
+func callResultFromJSON(jso *callResultJSON) callResult {
+	var r callResult
+	if jso.Status.Value == 1 {
+		r.Status = module.StatusSuccess
+	} else if jso.Failure != nil {
+		r.Status = module.Status(jso.Failure.CodeValue.Value)
+	} else {
+		r.Status = module.StatusUnknownFailure
+	}
+	r.StepUsed.Set(&jso.StepUsed.Int)
+	return r
+}
+
 type receiptData struct {
 	Status             module.Status
 	To                 common.Address
@@ -185,6 +224,7 @@ type receiptData struct {
 	SCOREAddress       *common.Address
 	FeeDetail          feeDetail
 	DisableLogsBloom   bool
+	CallResults        []callResult
 }
 
 func (r *receiptData) Equal(r2 *receiptData) bool {
@@ -196,7 +236,8 @@ func (r *receiptData) Equal(r2 *receiptData) bool {
 		r.LogsBloom.Equal(&r2.LogsBloom) &&
 		r.SCOREAddress.Equal(r2.SCOREAddress) &&
 		r.DisableLogsBloom == r2.DisableLogsBloom &&
-		reflect.DeepEqual(r.FeeDetail, r2.FeeDetail)
+		reflect.DeepEqual(r.FeeDetail, r2.FeeDetail) &&
+		reflect.DeepEqual(r.CallResults, r2.CallResults)
 }
 
 func (r *receiptData) Extension() int {
@@ -207,6 +248,9 @@ func (r *receiptData) Extension() int {
 	if r.DisableLogsBloom {
 		extension |= ExtensionDisableLogsBloom
 	}
+	if len(r.CallResults) > 0 {
+		extension |= ExtensionCallResults
+	}
 	return extension
 }
 
@@ -318,6 +362,11 @@ func (r *receipt) RLPEncodeSelf(e codec.Encoder) error {
 				return err
 			}
 		}
+		if (extension & ExtensionCallResults) != 0 {
+			if err = e2.Encode(r.data.CallResults); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
 }
@@ -353,6 +402,11 @@ func (r *receipt) RLPDecodeSelf(d codec.Decoder) error {
 					return err
 				}
 			}
+			if (extension & ExtensionCallResults) != 0 {
+				if err := d2.Decode(&r.data.CallResults); err != nil {
+					return err
+				}
+			}
 		} else {
 			return codec.ErrInvalidFormat
 		}
@@ -521,20 +575,23 @@ type Receipt interface {
 	SetResult(status module.Status, used, price *big.Int, addr module.Address)
 	SetReason(e error)
 	Reason() error
+	// AddCallResult adds the result of a call in multicall transaction.
+	AddCallResult(status module.Status, used *big.Int)
 	Flush() error
 }
 
 type receiptJSON struct {
-	To                 common.Address   `json:"to"`
-	CumulativeStepUsed common.HexInt    `json:"cumulativeStepUsed"`
-	StepUsed           common.HexInt    `json:"stepUsed"`
-	StepPrice          common.HexInt    `json:"stepPrice"`
-	SCOREAddress       *common.Address  `json:"scoreAddress,omitempty"`
-	Failure            *failureReason   `json:"failure,omitempty"`
-	EventLogs          []*eventLogJSON  `json:"eventLogs"`
-	LogsBloom          *LogsBloom       `json:"logsBloom"`
-	Status             common.HexUint16 `json:"status"`
-	FeeDetail          feeDetail        `json:"stepUsedDetails,omitempty"`
+	To                 common.Address    `json:"to"`
+	CumulativeStepUsed common.HexInt     `json:"cumulativeStepUsed"`
+	StepUsed           common.HexInt     `json:"stepUsed"`
+	StepPrice          common.HexInt     `json:"stepPrice"`
+	SCOREAddress       *common.Address   `json:"scoreAddress,omitempty"`
+	Failure            *failureReason    `json:"failure,omitempty"`
+	EventLogs          []*eventLogJSON   `json:"eventLogs"`
+	LogsBloom          *LogsBloom        `json:"logsBloom"`
+	Status             common.HexUint16  `json:"status"`
+	FeeDetail          feeDetail         `json:"stepUsedDetails,omitempty"`
+	CallResults        []*callResultJSON `json:"callResults,omitempty"`
 }
 
 func (r *receipt) ToJSON(version module.JSONVersion) (interface{}, error) {
@@ -567,6 +624,14 @@ func (r *receipt) ToJSON(version module.JSONVersion) (interface{}, error) {
 		jso["stepUsedDetails"] = details
 	}
 
+	if len(r.data.CallResults) > 0 {
+		results := make([]*callResultJSON, len(r.data.CallResults))
+		for i := range r.data.CallResults {
+			results[i] = r.data.CallResults[i].ToJSON()
+		}
+		jso["callResults"] = results
+	}
+
 	if r.data.Status == module.StatusSuccess {
 		jso["status"] = "0x1"
 		if r.data.SCOREAddress != nil {
@@ -579,6 +644,50 @@ func (r *receipt) ToJSON(version module.JSONVersion) (interface{}, error) {
 	return jso, nil
 }
 
+// EventLogDecoder returns the decoded form of the event log. It returns nil
+// if it can't decode the event log.
+type EventLogDecoder func(el module.EventLog) interface{}
+
+// EventLogToJSON returns the JSON object of the event log. It includes
+// the decoded form of the event log if dec is not nil.
+func EventLogToJSON(el module.EventLog, version module.JSONVersion, dec EventLogDecoder) interface{} {
+	log, ok := el.(*eventLog)
+	if !ok {
+		log = new(eventLog)
+		log.eventLogData.Addr.Set(el.Address())
+		log.eventLogData.Indexed = el.Indexed()
+		log.eventLogData.Data = el.Data()
+	}
+	jso := log.ToJSON(version)
+	if dec != nil {
+		jso.Decoded = dec(el)
+	}
+	return jso
+}
+
+// ReceiptToJSON returns the JSON object of the receipt. Event logs in it
+// include their decoded forms if dec is not nil.
+func ReceiptToJSON(r module.Receipt, version module.JSONVersion, dec EventLogDecoder) (interface{}, error) {
+	jso, err := r.ToJSON(version)
+	if err != nil || dec == nil {
+		return jso, err
+	}
+	m, ok := jso.(map[string]interface{})
+	if !ok {
+		return jso, nil
+	}
+	logs := make([]interface{}, 0)
+	for itr := r.EventLogIterator(); itr.Has(); itr.Next() {
+		el, err := itr.Get()
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, EventLogToJSON(el, version, dec))
+	}
+	m["eventLogs"] = logs
+	return m, nil
+}
+
 func (r *receipt) MarshalJSON() ([]byte, error) {
 	obj, err := r.ToJSON(module.JSONVersionLast)
 	if err != nil {
@@ -619,6 +728,9 @@ func (r *receipt) UnmarshalJSON(bs []byte) error {
 		data.DisableLogsBloom = true
 	}
 	data.FeeDetail = rjson.FeeDetail
+	for _, cr := range rjson.CallResults {
+		data.CallResults = append(data.CallResults, callResultFromJSON(cr))
+	}
 	if r.data.Extension() != 0 && r.version < Version3 {
 		r.version = Version3
 	}
@@ -641,6 +753,16 @@ func (r *receipt) AddLog(addr module.Address, indexed, data [][]byte) {
 	r.data.LogsBloom.AddLog(&log.eventLogData.Addr, log.eventLogData.Indexed)
 }
 
+func (r *receipt) AddCallResult(status module.Status, used *big.Int) {
+	var cr callResult
+	cr.Status = status
+	cr.StepUsed.Set(used)
+	r.data.CallResults = append(r.data.CallResults, cr)
+	if r.version < Version3 {
+		r.version = Version3
+	}
+}
+
 func (r *receipt) AddBTPMessages(messages list.List) {
 	if r.btpMsgs == nil {
 		r.btpMsgs = list.New()
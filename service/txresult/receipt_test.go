@@ -254,3 +254,81 @@ func TestDecomposeEventSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestReceiptToJSON(t *testing.T) {
+	database := db.NewMapDB()
+	addr := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	r := NewReceipt(database, 0, addr)
+	r.AddLog(addr, [][]byte{[]byte("Event(int)"), {0x01}}, nil)
+	r.SetResult(module.StatusSuccess, big.NewInt(100), big.NewInt(1000), nil)
+
+	jso, err := ReceiptToJSON(r, module.JSONVersion3, nil)
+	assert.NoError(t, err)
+	jb, err := json.Marshal(jso)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(jb), "decoded")
+
+	jso, err = ReceiptToJSON(r, module.JSONVersion3, func(el module.EventLog) interface{} {
+		return map[string]interface{}{"name": "Event"}
+	})
+	assert.NoError(t, err)
+	jb, err = json.Marshal(jso)
+	assert.NoError(t, err)
+
+	var res struct {
+		EventLogs []struct {
+			Indexed []string               `json:"indexed"`
+			Decoded map[string]interface{} `json:"decoded"`
+		} `json:"eventLogs"`
+	}
+	assert.NoError(t, json.Unmarshal(jb, &res))
+	assert.Len(t, res.EventLogs, 1)
+	assert.Equal(t, []string{"Event(int)", "0x1"}, res.EventLogs[0].Indexed)
+	assert.Equal(t, "Event", res.EventLogs[0].Decoded["name"])
+}
+
+func TestReceipt_CallResults(t *testing.T) {
+	database := db.NewMapDB()
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	r := NewReceipt(database, module.LatestRevision, addr)
+	r.AddCallResult(module.StatusSuccess, big.NewInt(100))
+	r.AddCallResult(module.StatusReverted+1, big.NewInt(200))
+	r.SetResult(module.StatusReverted+1, big.NewInt(400), big.NewInt(10), nil)
+
+	r2 := new(receipt)
+	assert.NoError(t, r2.Reset(database, r.Bytes()))
+	assert.Equal(t, r.Bytes(), r2.Bytes())
+	assert.Equal(t, r.(*receipt).data.CallResults, r2.data.CallResults)
+
+	jso, err := r.ToJSON(module.JSONVersion3)
+	assert.NoError(t, err)
+	jb, err := json.Marshal(jso)
+	assert.NoError(t, err)
+
+	var res struct {
+		CallResults []struct {
+			Status   string `json:"status"`
+			StepUsed string `json:"stepUsed"`
+			Failure  *struct {
+				Code string `json:"code"`
+			} `json:"failure"`
+		} `json:"callResults"`
+	}
+	assert.NoError(t, json.Unmarshal(jb, &res))
+	assert.Len(t, res.CallResults, 2)
+	assert.Equal(t, "0x1", res.CallResults[0].Status)
+	assert.Equal(t, "0x64", res.CallResults[0].StepUsed)
+	assert.Nil(t, res.CallResults[0].Failure)
+	assert.Equal(t, "0x0", res.CallResults[1].Status)
+	assert.Equal(t, "0x21", res.CallResults[1].Failure.Code)
+
+	r3, err := NewReceiptFromJSON(database, module.LatestRevision, jb)
+	assert.NoError(t, err)
+	assert.Equal(t, r.Bytes(), r3.Bytes())
+
+	r4 := NewReceipt(database, module.LatestRevision, addr)
+	r4.SetResult(module.StatusSuccess, big.NewInt(100), big.NewInt(10), nil)
+	jso, err = r4.ToJSON(module.JSONVersion3)
+	assert.NoError(t, err)
+	assert.NotContains(t, jso, "callResults")
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+)
+
+// NodePeerIDs returns peer IDs of the nodes.
+func NodePeerIDs(nodes ...*Node) []module.PeerID {
+	ids := make([]module.PeerID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.NM.ID()
+	}
+	return ids
+}
+
+func peerIn(id module.PeerID, ids []module.PeerID) bool {
+	for _, id2 := range ids {
+		if id.Equal(id2) {
+			return true
+		}
+	}
+	return false
+}
+
+func conflictingVote(w module.Wallet, bs []byte) ([]byte, error) {
+	msg, err := consensus.UnmarshalMessage(uint16(consensus.ProtoVote), bs)
+	if err != nil {
+		return nil, err
+	}
+	vote := msg.(*consensus.VoteMessage)
+	var id []byte
+	var psID *consensus.PartSetID
+	if len(vote.BlockID) == 0 {
+		// vote for a block which doesn't exist instead of nil
+		id = crypto.SHA3Sum256(codec.MustMarshalToBytes(vote.Height))
+		psID = &consensus.PartSetID{Count: 1, Hash: id}
+	}
+	cv := consensus.NewVoteMessage(
+		w, vote.Type, vote.Height, vote.Round, id, psID,
+		vote.Timestamp, nil, nil, 0,
+	)
+	return codec.BC.MarshalToBytes(cv)
+}
+
+func conflictingProposal(w module.Wallet, bs []byte) ([]byte, error) {
+	msg, err := consensus.UnmarshalMessage(uint16(consensus.ProtoProposal), bs)
+	if err != nil {
+		return nil, err
+	}
+	pm := msg.(*consensus.ProposalMessage)
+	cpm := consensus.NewProposalMessage()
+	cpm.Height = pm.Height
+	cpm.Round = pm.Round
+	cpm.POLRound = pm.POLRound
+	cpm.BlockPartSetID = &consensus.PartSetID{
+		Count: pm.BlockPartSetID.Count,
+		Hash:  crypto.SHA3Sum256(pm.BlockPartSetID.Hash),
+	}
+	if err := cpm.Sign(w); err != nil {
+		return nil, err
+	}
+	return codec.BC.MarshalToBytes(cpm)
+}
+
+// NewEquivocator returns a tamperer making the node of the wallet send
+// conflicting votes and proposals to the targets. A vote for a block is
+// changed to a nil vote, and a nil vote is changed to a vote for a block
+// which doesn't exist. A proposal is changed to a proposal for a block
+// which doesn't exist. Other peers receive original messages.
+func NewEquivocator(w module.Wallet, targets ...module.PeerID) PacketTamperer {
+	return func(pk *Packet, dst module.PeerID) []*Packet {
+		if pk.MPI != module.ProtoConsensus || !peerIn(dst, targets) {
+			return []*Packet{pk}
+		}
+		var bs []byte
+		var err error
+		switch pk.PI {
+		case consensus.ProtoVote:
+			bs, err = conflictingVote(w, pk.Data)
+		case consensus.ProtoProposal:
+			bs, err = conflictingProposal(w, pk.Data)
+		default:
+			return []*Packet{pk}
+		}
+		if err != nil {
+			log.Warnf("fail to make conflicting message err=%+v", err)
+			return []*Packet{pk}
+		}
+		cpk := *pk
+		cpk.Data = bs
+		return []*Packet{&cpk}
+	}
+}
@@ -115,6 +115,14 @@ func (c *Chain) ValidateTxOnSend() bool {
 	panic("implement me")
 }
 
+func (c *Chain) AccountIndex() bool {
+	return false
+}
+
+func (c *Chain) StateRetention() int64 {
+	return 0
+}
+
 var defaultGenesis = "{\n  \"accounts\": [\n    {\n      \"name\": \"god\",\n      \"address\": \"hx54f7853dc6481b670caf69c5a27c7c8fe5be8269\",\n      \"balance\": \"0x2961fff8ca4a62327800000\"\n    },\n    {\n      \"name\": \"treasury\",\n      \"address\": \"hx1000000000000000000000000000000000000000\",\n      \"balance\": \"0x0\"\n    }\n  ],\n  \"message\": \"A rhizome has no beginning or end; it is always in the middle, between things, interbeing, intermezzo. The tree is filiation, but the rhizome is alliance, uniquely alliance. The tree imposes the verb \\\"to be\\\" but the fabric of the rhizome is the conjunction, \\\"and ... and ...and...\\\"This conjunction carries enough force to shake and uproot the verb \\\"to be.\\\" Where are you going? Where are you coming from? What are you heading for? These are totally useless questions.\\n\\n - Mille Plateaux, Gilles Deleuze & Felix Guattari\\n\\n\\\"Hyperconnect the world\\\"\"\n}\n"
 
 func (c *Chain) Genesis() []byte {
@@ -177,6 +185,14 @@ func (c *Chain) Backup(file string, extra []string) error {
 	panic("implement me")
 }
 
+func (c *Chain) OnlineBackup(file string, extra []string) error {
+	panic("implement me")
+}
+
+func (c *Chain) IncrementalBackup(file string, base int64) error {
+	panic("implement me")
+}
+
 func (c *Chain) RunTask(task string, params json.RawMessage) error {
 	panic("implement me")
 }
@@ -228,6 +244,9 @@ func (c *Chain) WalletFor(dsa string) module.BaseWallet {
 	if bw, ok := c.bwMap[dsa]; ok {
 		return bw
 	}
+	if wp, ok := c.wallet.(module.WalletProvider); ok {
+		return wp.WalletFor(dsa)
+	}
 	switch dsa {
 	case "ecdsa/secp256k1":
 		return c.wallet
@@ -252,6 +271,9 @@ type walletProvider struct {
 }
 
 func (wp *walletProvider) WalletFor(dsa string) module.BaseWallet {
+	if p, ok := wp.wallet.(module.WalletProvider); ok {
+		return p.WalletFor(dsa)
+	}
 	switch dsa {
 	case "ecdsa/secp256k1":
 		return wp.wallet
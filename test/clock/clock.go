@@ -17,6 +17,7 @@
 package clock
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -41,9 +42,10 @@ func (timer *afterFuncTimer) Stop() bool {
 	for i, tm := range timer.cl.afterFuncTimers {
 		if timer == tm {
 			last := len(timer.cl.afterFuncTimers) - 1
-			timer.cl.afterFuncTimers[i] = timer.cl.afterFuncTimers[last]
+			copy(timer.cl.afterFuncTimers[i:], timer.cl.afterFuncTimers[i+1:])
 			timer.cl.afterFuncTimers[last] = nil
 			timer.cl.afterFuncTimers = timer.cl.afterFuncTimers[:last]
+			break
 		}
 	}
 	return true
@@ -90,8 +92,23 @@ func (cl *Clock) Sleep(d time.Duration) {
 	<-timer.C
 }
 
+// NextTime returns the earliest expiration time of the timers. It returns
+// false if there is no timer.
+func (cl *Clock) NextTime() (time.Time, bool) {
+	cl.Lock()
+	defer cl.Unlock()
+
+	var next time.Time
+	for i, tm := range cl.afterFuncTimers {
+		if i == 0 || tm.t.Before(next) {
+			next = tm.t
+		}
+	}
+	return next, len(cl.afterFuncTimers) > 0
+}
+
 func (cl *Clock) PassTime(d time.Duration) {
-	cl.SetTime(cl.now.Add(d))
+	cl.SetTime(cl.Now().Add(d))
 }
 
 func (cl *Clock) SetTime(t time.Time) {
@@ -100,7 +117,14 @@ func (cl *Clock) SetTime(t time.Time) {
 	cl.Lock()
 	defer func() {
 		for _, tm := range timers {
-			tm.f()
+			// the timer may be stopped by previous one.
+			cl.Lock()
+			f := tm.f
+			tm.f = nil
+			cl.Unlock()
+			if f != nil {
+				f()
+			}
 		}
 	}()
 	defer cl.Unlock()
@@ -109,16 +133,22 @@ func (cl *Clock) SetTime(t time.Time) {
 		return
 	}
 	cl.now = t
-	for i := 0; i < len(cl.afterFuncTimers); {
-		tm := cl.afterFuncTimers[i]
+	remains := cl.afterFuncTimers[:0]
+	for _, tm := range cl.afterFuncTimers {
 		if cl.now.Equal(tm.t) || cl.now.After(tm.t) {
-			last := len(cl.afterFuncTimers) - 1
-			cl.afterFuncTimers[i] = cl.afterFuncTimers[last]
-			cl.afterFuncTimers[last] = nil
-			cl.afterFuncTimers = cl.afterFuncTimers[:last]
 			timers = append(timers, tm)
-			continue
+		} else {
+			remains = append(remains, tm)
 		}
-		i++
 	}
+	for i := len(remains); i < len(cl.afterFuncTimers); i++ {
+		cl.afterFuncTimers[i] = nil
+	}
+	cl.afterFuncTimers = remains
+
+	// fire timers in order of expiration so that the result doesn't depend
+	// on the order of registration.
+	sort.SliceStable(timers, func(i, j int) bool {
+		return timers[i].t.Before(timers[j].t)
+	})
 }
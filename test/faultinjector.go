@@ -0,0 +1,281 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+)
+
+// LinkFault specifies faults injected to packets sent over a link.
+type LinkFault struct {
+	// Latency is added to delivery of every packet.
+	Latency time.Duration
+
+	// Jitter is maximum random delay added to Latency. Packets may be
+	// reordered if it's not zero.
+	Jitter time.Duration
+
+	// DropRate is probability of dropping a packet.
+	DropRate float64
+
+	// DuplicateRate is probability of delivering a packet twice.
+	DuplicateRate float64
+}
+
+// PacketTamperer returns packets to be delivered to dst instead of pk.
+// It's used to simulate byzantine behavior of a node.
+type PacketTamperer func(pk *Packet, dst module.PeerID) []*Packet
+
+type FaultStats struct {
+	Delivered  int
+	Dropped    int
+	Duplicated int
+	Blocked    int
+}
+
+type linkKey struct {
+	src string
+	dst string
+}
+
+// FaultInjector injects faults to packets between NetworkManagers sharing
+// it. Without any configuration, it delivers packets without faults.
+//
+// Random decisions for a packet depend only on the seed, the link and the
+// packet itself, so they don't depend on the order of sending goroutines.
+type FaultInjector struct {
+	mu         sync.Mutex
+	seed       int64
+	clock      common.Clock
+	sent       map[uint64]int
+	fault      LinkFault
+	links      map[linkKey]LinkFault
+	partitions map[string]int
+	tamperers  map[string]PacketTamperer
+	stats      FaultStats
+	closed     bool
+}
+
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{
+		seed:      seed,
+		clock:     &common.GoTimeClock{},
+		sent:      make(map[uint64]int),
+		links:     make(map[linkKey]LinkFault),
+		tamperers: make(map[string]PacketTamperer),
+	}
+}
+
+func peerKey(id module.PeerID) string {
+	return string(id.Bytes())
+}
+
+// SetClock sets the clock used for delayed delivery of packets.
+func (fi *FaultInjector) SetClock(cl common.Clock) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.clock = cl
+}
+
+// SetDefaultFault sets faults for links without specific configuration.
+func (fi *FaultInjector) SetDefaultFault(f LinkFault) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.fault = f
+}
+
+// SetLinkFault sets faults for packets from src to dst.
+func (fi *FaultInjector) SetLinkFault(src, dst module.PeerID, f LinkFault) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.links[linkKey{peerKey(src), peerKey(dst)}] = f
+}
+
+// ClearLinkFaults removes all faults including default one.
+func (fi *FaultInjector) ClearLinkFaults() {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.fault = LinkFault{}
+	fi.links = make(map[linkKey]LinkFault)
+}
+
+// Partition splits peers into groups. Packets between peers in different
+// groups are blocked. Peers not in any group can communicate with all
+// peers.
+func (fi *FaultInjector) Partition(groups ...[]module.PeerID) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, id := range group {
+			fi.partitions[peerKey(id)] = i
+		}
+	}
+}
+
+// Heal removes partitions.
+func (fi *FaultInjector) Heal() {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.partitions = nil
+}
+
+// SetTamperer sets tamperer for packets sent by src. Nil tamperer removes
+// it.
+func (fi *FaultInjector) SetTamperer(src module.PeerID, t PacketTamperer) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	if t == nil {
+		delete(fi.tamperers, peerKey(src))
+	} else {
+		fi.tamperers[peerKey(src)] = t
+	}
+}
+
+func (fi *FaultInjector) Stats() FaultStats {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	return fi.stats
+}
+
+// Close stops delivery of packets including delayed ones.
+func (fi *FaultInjector) Close() {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.closed = true
+}
+
+func (fi *FaultInjector) isBlockedInLock(src, dst string) bool {
+	if fi.partitions == nil {
+		return false
+	}
+	g1, ok1 := fi.partitions[src]
+	g2, ok2 := fi.partitions[dst]
+	return ok1 && ok2 && g1 != g2
+}
+
+// randInLock returns random source for the packet. Same packet sent over
+// the link again gets different source.
+func (fi *FaultInjector) randInLock(src, dst string, pk *Packet) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(src))
+	_, _ = h.Write([]byte(dst))
+	_ = binary.Write(h, binary.BigEndian, pk.MPI.Uint16())
+	_ = binary.Write(h, binary.BigEndian, pk.PI.Uint16())
+	_, _ = h.Write(pk.Data)
+	key := h.Sum64()
+	n := fi.sent[key]
+	fi.sent[key] = n + 1
+	return rand.New(rand.NewSource(fi.seed ^ int64(key) + int64(n)))
+}
+
+func delayFor(rnd *rand.Rand, f *LinkFault) time.Duration {
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += time.Duration(rnd.Int63n(int64(f.Jitter)))
+	}
+	return delay
+}
+
+func (fi *FaultInjector) deliver(src module.PeerID, dst Peer, pk *Packet, cb func(bool, error)) {
+	sk, dk := peerKey(src), peerKey(dst.ID())
+
+	fi.mu.Lock()
+	if fi.closed {
+		fi.mu.Unlock()
+		return
+	}
+	if fi.isBlockedInLock(sk, dk) {
+		fi.stats.Blocked++
+		fi.mu.Unlock()
+		return
+	}
+	tamperer := fi.tamperers[sk]
+	fi.mu.Unlock()
+
+	pks := []*Packet{pk}
+	if tamperer != nil {
+		pks = tamperer(pk, dst.ID())
+	}
+
+	type delivery struct {
+		pk    *Packet
+		cb    func(bool, error)
+		delay time.Duration
+	}
+	var deliveries []delivery
+
+	fi.mu.Lock()
+	f, ok := fi.links[linkKey{sk, dk}]
+	if !ok {
+		f = fi.fault
+	}
+	cl := fi.clock
+	for _, p := range pks {
+		rnd := fi.randInLock(sk, dk, p)
+		if f.DropRate > 0 && rnd.Float64() < f.DropRate {
+			fi.stats.Dropped++
+			continue
+		}
+		copies := 1
+		if f.DuplicateRate > 0 && rnd.Float64() < f.DuplicateRate {
+			fi.stats.Duplicated++
+			copies++
+		}
+		for i := 0; i < copies; i++ {
+			fi.stats.Delivered++
+			deliveries = append(deliveries, delivery{p, cb, delayFor(rnd, &f)})
+			cb = nil
+		}
+	}
+	fi.mu.Unlock()
+
+	for _, d := range deliveries {
+		if d.delay <= 0 {
+			dst.notifyPacket(d.pk, d.cb)
+		} else {
+			fi.notifyAfter(cl, dst, d.pk, d.cb, d.delay)
+		}
+	}
+}
+
+func (fi *FaultInjector) notifyAfter(cl common.Clock, dst Peer, pk *Packet, cb func(bool, error), delay time.Duration) {
+	cl.AfterFunc(delay, func() {
+		fi.mu.Lock()
+		closed := fi.closed
+		fi.mu.Unlock()
+		if !closed {
+			dst.notifyPacket(pk, cb)
+		}
+	})
+}
@@ -50,36 +50,7 @@ func NewFixture(t T, o ...FixtureOption) *Fixture {
 		for i := range wallets {
 			wallets[i] = wallet.New()
 		}
-		var validators string
-		for i, w := range wallets {
-			if i > 0 {
-				validators += ", "
-			}
-			validators += fmt.Sprintf(`"%s"`, w.Address())
-		}
-		gs = fmt.Sprintf(`{
-			"accounts": [
-				{
-					"name" : "treasury",
-					"address" : "hx1000000000000000000000000000000000000000",
-					"balance" : "0x0"
-				},
-				{
-					"name" : "god",
-					"address" : "hx0000000000000000000000000000000000000000",
-					"balance" : "0x0"
-				}
-			],
-			"message": "",
-			"nid" : "0x1",
-			"chain" : {
-				"validatorList" : [ %s ]
-			}
-		}`, validators)
-		for i := range wallets {
-			node := f.AddNode(UseGenesis(gs), UseWallet(wallets[i]))
-			f.Validators = append(f.Validators, node)
-		}
+		gs = f.addValidatorNodes(wallets)
 	}
 	if *cf.AddDefaultNode {
 		node := f.AddNode(UseGenesis(gs))
@@ -88,6 +59,46 @@ func NewFixture(t T, o ...FixtureOption) *Fixture {
 	return f
 }
 
+func validatorGenesis(wallets []module.Wallet) string {
+	var validators string
+	for i, w := range wallets {
+		if i > 0 {
+			validators += ", "
+		}
+		validators += fmt.Sprintf(`"%s"`, w.Address())
+	}
+	return fmt.Sprintf(`{
+		"accounts": [
+			{
+				"name" : "treasury",
+				"address" : "hx1000000000000000000000000000000000000000",
+				"balance" : "0x0"
+			},
+			{
+				"name" : "god",
+				"address" : "hx0000000000000000000000000000000000000000",
+				"balance" : "0x0"
+			}
+		],
+		"message": "",
+		"nid" : "0x1",
+		"chain" : {
+			"validatorList" : [ %s ]
+		}
+	}`, validators)
+}
+
+// addValidatorNodes adds validator nodes for the wallets and returns genesis
+// for the nodes.
+func (f *Fixture) addValidatorNodes(wallets []module.Wallet) string {
+	gs := validatorGenesis(wallets)
+	for _, w := range wallets {
+		node := f.AddNode(UseGenesis(gs), UseWallet(w))
+		f.Validators = append(f.Validators, node)
+	}
+	return gs
+}
+
 func (f *Fixture) AddNode(o ...FixtureOption) *Node {
 	eo := make([]FixtureOption, 0, len(o)+1)
 	eo = append(eo, UseConfig(f.BaseConfig))
@@ -109,6 +120,9 @@ func (f *Fixture) AddNodes(n int, o ...FixtureOption) []*Node {
 }
 
 func (f *Fixture) Close() {
+	if f.BaseConfig.Faults != nil {
+		f.BaseConfig.Faults.Close()
+	}
 	for _, n := range f.Nodes {
 		n.Close()
 	}
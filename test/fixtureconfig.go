@@ -23,6 +23,7 @@ import (
 
 	"github.com/icon-project/goloop/block"
 	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/consensus"
 	"github.com/icon-project/goloop/module"
@@ -47,6 +48,8 @@ type FixtureConfig struct {
 	Wallet            module.Wallet
 	AddDefaultNode    *bool
 	WAL               func() consensus.WALManager
+	Faults            *FaultInjector
+	Clock             common.Clock
 }
 
 func NewFixtureConfig(t T, o ...FixtureOption) *FixtureConfig {
@@ -73,7 +76,7 @@ func NewFixtureConfig(t T, o ...FixtureOption) *FixtureConfig {
 			wm := ctx.Config.WAL()
 			wal := path.Join(ctx.Base, "wal")
 			cs := consensus.New(
-				ctx.C, wal, wm, nil, nil, nil,
+				ctx.C, wal, wm, nil, nil, nil, ctx.Config.Clock,
 			)
 			assert.NotNil(ctx.Config.T, cs)
 			return cs
@@ -146,5 +149,11 @@ func (cf *FixtureConfig) Override(cf2 *FixtureConfig) *FixtureConfig {
 	if cf2.WAL != nil {
 		res.WAL = cf2.WAL
 	}
+	if cf2.Faults != nil {
+		res.Faults = cf2.Faults
+	}
+	if cf2.Clock != nil {
+		res.Clock = cf2.Clock
+	}
 	return &res
 }
@@ -17,6 +17,7 @@
 package test
 
 import (
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/consensus"
 	"github.com/icon-project/goloop/module"
@@ -74,3 +75,15 @@ func AddDefaultNode(v bool) FixtureOption {
 func UseBMFactory(f func(ctx *NodeContext) module.BlockManager) FixtureOption {
 	return UseConfig(&FixtureConfig{NewBM: f})
 }
+
+// UseFaultInjector option makes packets between nodes go through the fault
+// injector.
+func UseFaultInjector(fi *FaultInjector) FixtureOption {
+	return UseConfig(&FixtureConfig{Faults: fi})
+}
+
+// UseClock option makes consensus of nodes and the fault injector use the
+// clock instead of the system clock.
+func UseClock(cl common.Clock) FixtureOption {
+	return UseConfig(&FixtureConfig{Clock: cl})
+}
@@ -18,6 +18,7 @@ package test
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
@@ -34,10 +35,14 @@ type NetworkManager struct {
 	rCh    chan packetEntry
 	stopCh chan struct{}
 
+	// number of packets received but not handled yet
+	pending int32
+
 	// mutable data
 	peers    []Peer
 	handlers []*nmHandler
 	roles    map[string]module.Role
+	faults   *FaultInjector
 }
 
 func indexOf(pl []Peer, id module.PeerID) int {
@@ -70,6 +75,7 @@ forLoop:
 			break forLoop
 		case p := <-n.rCh:
 			n.handlePacket(p.pk, p.cb)
+			atomic.AddInt32(&n.pending, -1)
 		}
 	}
 }
@@ -119,9 +125,14 @@ func (n *NetworkManager) detach(p Peer) {
 }
 
 func (n *NetworkManager) notifyPacket(pk *Packet, cb func(rebroadcast bool, err error)) {
+	atomic.AddInt32(&n.pending, 1)
 	n.rCh <- packetEntry{pk, cb}
 }
 
+func (n *NetworkManager) pendingPackets() int {
+	return int(atomic.LoadInt32(&n.pending))
+}
+
 func (n *NetworkManager) handlePacket(pk *Packet, cb func(rebroadcast bool, err error)) {
 	al := common.Lock(&nmMu)
 	defer al.Unlock()
@@ -213,6 +224,23 @@ func (n *NetworkManager) NewPeerForWithAddress(mpi module.ProtocolInfo, w module
 	return p, h
 }
 
+// SetFaultInjector makes packets sent by the network manager go through
+// the fault injector.
+func (n *NetworkManager) SetFaultInjector(fi *FaultInjector) {
+	al := common.Lock(&nmMu)
+	defer al.Unlock()
+
+	n.faults = fi
+}
+
+func (n *NetworkManager) sendPacket(fi *FaultInjector, p Peer, pk *Packet) {
+	if fi != nil {
+		fi.deliver(n.id, p, pk, nil)
+	} else {
+		p.notifyPacket(pk, nil)
+	}
+}
+
 func (n *NetworkManager) Connect(n2 *NetworkManager) {
 	PeerConnect(n, n2)
 }
@@ -241,10 +269,11 @@ func (h *nmHandler) Broadcast(pi module.ProtocolInfo, b []byte, bt module.Broadc
 		b,
 	}
 	peers := append([]Peer(nil), h.n.peers...)
+	fi := h.n.faults
 	al.Unlock()
 
 	for _, p := range peers {
-		p.notifyPacket(pk, nil)
+		h.n.sendPacket(fi, p, pk)
 	}
 	return nil
 }
@@ -267,9 +296,10 @@ func (h *nmHandler) Multicast(pi module.ProtocolInfo, b []byte, role module.Role
 			peers = append(peers, p)
 		}
 	}
+	fi := h.n.faults
 	al.Unlock()
 	for _, p := range peers {
-		p.notifyPacket(pk, nil)
+		h.n.sendPacket(fi, p, pk)
 	}
 	return nil
 }
@@ -288,9 +318,10 @@ func (h *nmHandler) Unicast(pi module.ProtocolInfo, b []byte, id module.PeerID)
 			b,
 		}
 		p := h.n.peers[idx]
+		fi := h.n.faults
 		al.Unlock()
 
-		p.notifyPacket(pk, nil)
+		h.n.sendPacket(fi, p, pk)
 		return nil
 	}
 	return errors.New("no peer")
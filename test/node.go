@@ -74,6 +74,9 @@ func NewNode(t T, o ...FixtureOption) *Node {
 	}
 	c, err := NewChain(t, w, dbase, logger, cf.CVSD, cf.Genesis)
 	assert.NoError(t, err)
+	if cf.Faults != nil {
+		c.nm.SetFaultInjector(cf.Faults)
+	}
 	c.Logger().SetLevel(log.TraceLevel)
 
 	// set up sm
@@ -222,6 +222,19 @@ func (sm *ServiceManager) GetMinimizeBlockGen(result []byte) bool {
 	return scoredb.NewVarDB(as, state.VarMinimizeBlockGen).Bool()
 }
 
+func (sm *ServiceManager) GetRevision(result []byte) int {
+	ws, err := service.NewWorldSnapshot(sm.dbase, sm.plt, result, nil)
+	if err != nil {
+		return 0
+	}
+	ass := ws.GetAccountSnapshot(state.SystemID)
+	as := scoredb.NewStateStoreWith(ass)
+	if as == nil {
+		return 0
+	}
+	return int(scoredb.NewVarDB(as, state.VarRevision).Int64())
+}
+
 func (sm *ServiceManager) GetNextBlockVersion(result []byte) int {
 	if result == nil {
 		return sm.plt.DefaultBlockVersionFor(sm.chain.CID())
@@ -0,0 +1,368 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/test/clock"
+)
+
+// SimulationEpoch is the initial time of the clock of a simulation.
+var SimulationEpoch = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	DefaultSimulationTimeout    = 10 * time.Minute
+	DefaultSimulationSettleTime = 2 * time.Millisecond
+	simulationPollInterval      = 100 * time.Microsecond
+)
+
+// Simulation runs nodes having real chain, block, consensus and service
+// stacks in a process. Consensus of the nodes runs on a virtual clock and
+// nodes are connected through a FaultInjector using the clock.
+//
+// Time passes only in Step. Step waits for the nodes to be idle, then moves
+// the clock to the earliest timer, so scenarios taking long time in real
+// world (timeouts, rotations, catch-ups) run as fast as the nodes can
+// process them. Wallets of the nodes and faults of the network are derived
+// from the seed, so a scenario with the same seed makes the same blocks.
+//
+// Use WaitForHeight and SendTXAndWaitForBlock instead of waiting methods of
+// Fixture, which block without passing time.
+type Simulation struct {
+	*Fixture
+	Clock  *clock.Clock
+	Faults *FaultInjector
+	Seed   int64
+
+	// Timeout is the virtual time to wait for a condition.
+	Timeout time.Duration
+
+	// SettleTime is the real time for which the nodes shall stay idle
+	// before the clock moves.
+	SettleTime time.Duration
+
+	busy    int32
+	genesis string
+	wallets int
+	running []*Node
+}
+
+// NewSimulation returns a simulation having n validator nodes. The nodes
+// are connected to each other, but they're not started.
+func NewSimulation(t T, seed int64, n int, o ...FixtureOption) *Simulation {
+	cl := &clock.Clock{}
+	cl.SetTime(SimulationEpoch)
+
+	eo := make([]FixtureOption, 0, len(o)+3)
+	eo = append(eo, UseFaultInjector(NewFaultInjector(seed)), AddDefaultNode(false))
+	eo = append(eo, o...)
+	eo = append(eo, UseClock(cl))
+	cf := NewFixtureConfig(t, eo...)
+
+	s := &Simulation{
+		Fixture:    &Fixture{BaseConfig: cf},
+		Clock:      cl,
+		Faults:     cf.Faults,
+		Seed:       seed,
+		Timeout:    DefaultSimulationTimeout,
+		SettleTime: DefaultSimulationSettleTime,
+	}
+	s.Faults.SetClock(cl)
+	newBM := cf.NewBM
+	cf.NewBM = func(ctx *NodeContext) module.BlockManager {
+		return &simBlockManager{
+			BlockManager: newBM(ctx),
+			busy:         &s.busy,
+		}
+	}
+
+	wallets := make([]module.Wallet, n)
+	for i := range wallets {
+		wallets[i] = s.newWallet()
+	}
+	s.genesis = s.addValidatorNodes(wallets)
+	NodeInterconnect(s.Nodes)
+	return s
+}
+
+func (s *Simulation) newWallet() module.Wallet {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(s.Seed))
+	binary.BigEndian.PutUint64(b[8:], uint64(s.wallets))
+	s.wallets++
+	sk, err := crypto.ParsePrivateKey(crypto.SHA3Sum256(b[:]))
+	assert.NoError(s.BaseConfig.T, err)
+	w, err := wallet.NewFromPrivateKey(sk)
+	assert.NoError(s.BaseConfig.T, err)
+	return w
+}
+
+// AddNode adds a non-validator node with the genesis of the simulation and
+// connects it to the other nodes. The node is not started.
+func (s *Simulation) AddNode(o ...FixtureOption) *Node {
+	eo := make([]FixtureOption, 0, len(o)+2)
+	eo = append(eo, UseGenesis(s.genesis), UseWallet(s.newWallet()))
+	eo = append(eo, o...)
+	node := s.Fixture.AddNode(eo...)
+	for _, n := range s.Nodes {
+		if n != node {
+			n.NM.Connect(node.NM)
+		}
+	}
+	return node
+}
+
+// Start starts consensus of the nodes. It starts all nodes if no node is
+// given.
+func (s *Simulation) Start(nodes ...*Node) {
+	if len(nodes) == 0 {
+		nodes = s.Nodes
+	}
+	for _, n := range nodes {
+		assert.NoError(s.BaseConfig.T, n.CS.Start())
+		s.running = append(s.running, n)
+	}
+}
+
+// Running returns nodes started by Start.
+func (s *Simulation) Running() []*Node {
+	return s.running
+}
+
+func (s *Simulation) isIdle() bool {
+	if atomic.LoadInt32(&s.busy) > 0 {
+		return false
+	}
+	for _, n := range s.Nodes {
+		if n.NM.pendingPackets() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Settle waits until the nodes don't have any packet or block to process
+// for SettleTime.
+func (s *Simulation) Settle() {
+	idleSince := time.Now()
+	for time.Since(idleSince) < s.SettleTime {
+		time.Sleep(simulationPollInterval)
+		if !s.isIdle() {
+			idleSince = time.Now()
+		}
+	}
+}
+
+func (s *Simulation) advance(limit time.Time) bool {
+	s.Settle()
+	next, ok := s.Clock.NextTime()
+	if !ok || next.After(limit) {
+		next = limit
+	}
+	if !next.After(s.Clock.Now()) {
+		return false
+	}
+	s.Clock.SetTime(next)
+	return true
+}
+
+// Step waits for the nodes to be idle and fires the earliest timers. It
+// returns false if there is no timer.
+func (s *Simulation) Step() bool {
+	s.Settle()
+	next, ok := s.Clock.NextTime()
+	if !ok {
+		return false
+	}
+	s.Clock.SetTime(next)
+	return true
+}
+
+// RunFor runs the simulation for d.
+func (s *Simulation) RunFor(d time.Duration) {
+	limit := s.Clock.Now().Add(d)
+	for s.advance(limit) {
+	}
+}
+
+// RunUntil runs the simulation until cond returns true or d passes. It
+// returns the last result of cond.
+func (s *Simulation) RunUntil(d time.Duration, cond func() bool) bool {
+	limit := s.Clock.Now().Add(d)
+	for {
+		if cond() {
+			return true
+		}
+		if !s.advance(limit) {
+			return cond()
+		}
+	}
+}
+
+// WaitForHeight runs the simulation until all nodes finalize the block of
+// height h. It waits for running nodes if no node is given.
+func (s *Simulation) WaitForHeight(h int64, nodes ...*Node) module.Block {
+	if len(nodes) == 0 {
+		nodes = s.running
+	}
+	ok := s.RunUntil(s.Timeout, func() bool {
+		for _, n := range nodes {
+			if n.GetLastBlock().Height() < h {
+				return false
+			}
+		}
+		return true
+	})
+	if !assert.True(s.BaseConfig.T, ok, "timeout waiting for height=%d", h) {
+		return nil
+	}
+	var blk module.Block
+	for _, n := range nodes {
+		b, err := n.BM.GetBlockByHeight(h)
+		assert.NoError(s.BaseConfig.T, err)
+		if blk == nil {
+			blk = b
+		} else {
+			assert.Equal(s.BaseConfig.T, blk.ID(), b.ID(), "height=%d", h)
+		}
+	}
+	if s.Height < h {
+		s.Height = h
+	}
+	return blk
+}
+
+// NewTx returns a transaction with the current time of the clock as its
+// timestamp.
+func (s *Simulation) NewTx() *Transaction {
+	return NewTx().SetTimestamp(common.UnixMicroFromTime(s.Clock.Now()))
+}
+
+// SendTXAndWaitForBlock sends the transaction to all nodes and runs the
+// simulation until a block including the transaction is finalized.
+func (s *Simulation) SendTXAndWaitForBlock(tx StringerTransaction) module.Block {
+	h := s.GetLastBlock().Height()
+	s.SendTransactionToAll(tx)
+	var blk module.Block
+	ok := s.RunUntil(s.Timeout, func() bool {
+		for ; ; h++ {
+			b, err := s.BM.GetBlockByHeight(h + 1)
+			if err != nil {
+				return false
+			}
+			if s.TXInBlock(tx, b) {
+				blk = b
+				return true
+			}
+		}
+	})
+	assert.True(s.BaseConfig.T, ok, "timeout waiting for tx=%s", tx.String())
+	return blk
+}
+
+// Revision returns revision in the state of the last block of the node.
+func (s *Simulation) Revision(n *Node) int {
+	sm, ok := n.SM.(*ServiceManager)
+	if !assert.True(s.BaseConfig.T, ok) {
+		return 0
+	}
+	return sm.GetRevision(n.GetLastBlock().Result())
+}
+
+// simBlockManager counts asynchronous operations of block manager in
+// progress, so the simulation doesn't pass time while a block is being
+// proposed or imported.
+type simBlockManager struct {
+	module.BlockManager
+	busy *int32
+}
+
+type simCanceler struct {
+	module.Canceler
+	done func()
+}
+
+func (c *simCanceler) Cancel() bool {
+	if c.Canceler.Cancel() {
+		c.done()
+		return true
+	}
+	return false
+}
+
+func (bm *simBlockManager) track(
+	cb func(module.BlockCandidate, error),
+	f func(cb func(module.BlockCandidate, error)) (module.Canceler, error),
+) (module.Canceler, error) {
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			atomic.AddInt32(bm.busy, -1)
+		})
+	}
+	atomic.AddInt32(bm.busy, 1)
+	canceler, err := f(func(bc module.BlockCandidate, err error) {
+		defer done()
+		cb(bc, err)
+	})
+	if err != nil {
+		done()
+		return canceler, err
+	}
+	return &simCanceler{canceler, done}, nil
+}
+
+func (bm *simBlockManager) Propose(
+	parentID []byte,
+	votes module.CommitVoteSet,
+	cb func(module.BlockCandidate, error),
+) (module.Canceler, error) {
+	return bm.track(cb, func(cb func(module.BlockCandidate, error)) (module.Canceler, error) {
+		return bm.BlockManager.Propose(parentID, votes, cb)
+	})
+}
+
+func (bm *simBlockManager) Import(
+	r io.Reader,
+	flags int,
+	cb func(module.BlockCandidate, error),
+) (module.Canceler, error) {
+	return bm.track(cb, func(cb func(module.BlockCandidate, error)) (module.Canceler, error) {
+		return bm.BlockManager.Import(r, flags, cb)
+	})
+}
+
+func (bm *simBlockManager) ImportBlock(
+	blk module.BlockData,
+	flags int,
+	cb func(module.BlockCandidate, error),
+) (module.Canceler, error) {
+	return bm.track(cb, func(cb func(module.BlockCandidate, error)) (module.Canceler, error) {
+		return bm.BlockManager.ImportBlock(blk, flags, cb)
+	})
+}